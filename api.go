@@ -5,6 +5,8 @@ import (
 	"encoding/pem"
 	"math/rand"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"crypto/x509"
@@ -12,6 +14,7 @@ import (
 
 	"crypto/ecdsa"
 
+	"github.com/underlx/disturbancesmlx/discordbot"
 	"github.com/underlx/disturbancesmlx/posplay"
 	"github.com/underlx/disturbancesmlx/resource"
 	"github.com/yarf-framework/yarf"
@@ -41,10 +44,12 @@ func (r *Static) Get(c *yarf.Context) error {
 // APIserver sets up and starts the API server
 func APIserver(trustedClientCertPath string) {
 	resource.RegisterPairConnectionHandler(posplay.TheConnectionHandler)
+	resource.RegisterPairUsageTracker(pairUsageTracker)
+	resource.RegisterFeedbackListener(discordbot.TheFeedbackTriage)
 
 	y := yarf.New()
 
-	v1 := yarf.RouteGroup("/v1")
+	v1 := newDocumentedRouteGroup("/v1")
 
 	v1.Add("/meta", new(resource.Meta).WithNode(rootSqalxNode))
 
@@ -60,6 +65,8 @@ func APIserver(trustedClientCertPath string) {
 
 	v1.Add("/networks", new(resource.Network).WithNode(rootSqalxNode))
 	v1.Add("/networks/:id", new(resource.Network).WithNode(rootSqalxNode))
+	v1.Add("/networks/:id/geojson", new(resource.NetworkGeoJSON).WithNode(rootSqalxNode))
+	v1.Add("/networks/:id/schematic", new(resource.SchematicMap).WithNode(rootSqalxNode).WithTrainPositionProvider(vehicleETAHandler))
 
 	v1.Add("/lines", new(resource.Line).WithNode(rootSqalxNode))
 	v1.Add("/lines/:id", new(resource.Line).WithNode(rootSqalxNode)) // contains logic for when :id is "conditions" to handle /lines/conditions
@@ -73,6 +80,9 @@ func APIserver(trustedClientCertPath string) {
 	v1.Add("/lobbies", new(resource.Lobby).WithNode(rootSqalxNode))
 	v1.Add("/lobbies/:id", new(resource.Lobby).WithNode(rootSqalxNode))
 
+	v1.Add("/fares", new(resource.Fare).WithNode(rootSqalxNode))
+	v1.Add("/fares/:id", new(resource.Fare).WithNode(rootSqalxNode))
+
 	v1.Add("/pois", new(resource.POI).WithNode(rootSqalxNode))
 	v1.Add("/pois/:id", new(resource.POI).WithNode(rootSqalxNode))
 
@@ -94,8 +104,8 @@ func APIserver(trustedClientCertPath string) {
 	v1.Add("/datasets", new(resource.Dataset).WithNode(rootSqalxNode).WithSquirrel(&sdb))
 	v1.Add("/datasets/:id", new(resource.Dataset).WithNode(rootSqalxNode).WithSquirrel(&sdb))
 
-	v1.Add("/stats", new(resource.Stats).WithNode(rootSqalxNode).WithStats(statsHandler))
-	v1.Add("/stats/:id", new(resource.Stats).WithNode(rootSqalxNode).WithStats(statsHandler))
+	v1.Add("/stats", new(resource.Stats).WithNode(rootSqalxNode).WithStats(statsHandler).WithCrowding(crowdingHandler))
+	v1.Add("/stats/:id", new(resource.Stats).WithNode(rootSqalxNode).WithStats(statsHandler).WithCrowding(crowdingHandler))
 
 	v1.Add("/announcements", new(resource.Announcement).WithAnnouncementStore(&annStore))
 	v1.Add("/announcements/:source", new(resource.Announcement).WithAnnouncementStore(&annStore))
@@ -107,6 +117,8 @@ func APIserver(trustedClientCertPath string) {
 	v1.Add("/clientconfigs/android/overlayfs/*", new(Static).WithPath("stationkb/overlayfs/", "/v1/clientconfigs/android/overlayfs/"))
 
 	v1.Add("/trips", new(resource.Trip).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
+	v1.Add("/trips/provisional", new(resource.ProvisionalTrip).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
+	v1.Add("/trips/provisional/:id", new(resource.ProvisionalTrip).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
 	v1.Add("/trips/:id", new(resource.Trip).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
 
 	v1.Add("/rt", new(resource.Realtime).
@@ -116,28 +128,85 @@ func APIserver(trustedClientCertPath string) {
 		WithVehicleHandler(vehicleHandler))
 
 	v1.Add("/feedback", new(resource.Feedback).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
+	v1.Add("/wifiaps/observations", new(resource.WiFiAPObservation).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
 
-	pubkey := getTrustedClientPublicKey(trustedClientCertPath)
+	androidVerifier := getAndroidPairRequestVerifier(trustedClientCertPath)
+	webVerifier := resource.NewChallengePairRequestVerifier("web")
+	iosVerifier := resource.NewChallengePairRequestVerifier("ios")
 
 	v1.Add("/pair", new(resource.Pair).
 		WithNode(rootSqalxNode).
-		WithPublicKey(pubkey).
+		WithVerifier(androidVerifier).
+		WithVerifier(webVerifier).
+		WithVerifier(iosVerifier).
 		WithHashKey(getHashKey()).
 		WithTelemetryChannel(PairRequestTelemetry))
 
+	v1.Add("/pair/challenge", new(resource.PairChallenge).
+		WithNode(rootSqalxNode).
+		WithVerifier(webVerifier).
+		WithVerifier(iosVerifier).
+		WithApprovalURL(posplay.PairChallengeApprovalURL))
+	v1.Add("/pair/challenge/:id", new(resource.PairChallenge).
+		WithNode(rootSqalxNode).
+		WithVerifier(webVerifier).
+		WithVerifier(iosVerifier).
+		WithApprovalURL(posplay.PairChallengeApprovalURL))
+
 	v1.Add("/pair/connections", new(resource.PairConnection).
 		WithNode(rootSqalxNode).
 		WithHashKey(getHashKey()))
 
+	v1.Add("/pair/rotate", new(resource.PairRotation).
+		WithNode(rootSqalxNode).
+		WithHashKey(getHashKey()))
+
+	v1.Add("/pair/data", new(resource.PersonalData).
+		WithNode(rootSqalxNode).
+		WithHashKey(getHashKey()))
+
 	v1.Add("/authtest", new(resource.AuthTest).
 		WithNode(rootSqalxNode).
 		WithHashKey(getHashKey()))
 
-	y.AddGroup(v1)
+	v1.Add("/routes", new(resource.Route).WithNode(rootSqalxNode))
+	v1.Add("/nearby", new(resource.Nearby).WithNode(rootSqalxNode))
+	v1.Add("/isochrones/:station", new(resource.Isochrone).WithNode(rootSqalxNode))
+
+	v1.Add("/events", new(resource.StatusEvents).WithNode(rootSqalxNode).WithEventSource(statusEventBroker))
+
+	openAPI := new(resource.OpenAPI)
+	v1.Add("/openapi.json", openAPI)
+
+	y.AddGroup(v1.GroupRoute)
+
+	// v2 shares the data layer with v1, but its collections are paginated and support filtering and field selection
+	// v1 must be kept unchanged for as long as old clients (see MinAndroidClient in resource.Meta) are supported
+	v2 := newDocumentedRouteGroup("/v2")
+
+	v2.Add("/disturbances", new(resource.DisturbanceV2).WithNode(rootSqalxNode))
+	v2.Add("/disturbances/:id", new(resource.DisturbanceV2).WithNode(rootSqalxNode))
+
+	v2.Add("/stations", new(resource.StationV2).WithNode(rootSqalxNode))
+	v2.Add("/stations/:id", new(resource.StationV2).WithNode(rootSqalxNode))
+
+	v2.Add("/trips", new(resource.TripV2).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
+	v2.Add("/trips/:id", new(resource.TripV2).WithNode(rootSqalxNode).WithHashKey(getHashKey()))
+
+	y.AddGroup(v2.GroupRoute)
+
+	routes := append(v1.paths, v2.paths...)
+	openAPI.WithRoutes(routes)
+	for _, route := range resource.UndocumentedRoutes(routes) {
+		webLog.Println("API route missing from the OpenAPI document:", route)
+	}
+
 	if DEBUG {
 		y.Insert(new(DelayMiddleware))
+		y.Insert(&ContractValidationMiddleware{routes: routes})
 	}
 
+	y.Insert(NewRateLimitMiddleware(routes, getRateLimits(routes), getHashKey()))
 	y.Insert(new(TelemetryMiddleware))
 
 	y.Logger = webLog
@@ -172,6 +241,27 @@ func (m *TelemetryMiddleware) PostDispatch(c *yarf.Context) error {
 	return nil
 }
 
+// getAndroidPairRequestVerifier returns the verifier for pair requests of the Android client.
+// The key in the certificate at trustedClientCertPath is used for requests that don't specify a key ID.
+// Certificates in the same directory named like trusted_client_cert.<key ID>.pem are trusted too, under their key ID
+func getAndroidPairRequestVerifier(trustedClientCertPath string) *resource.ECDSAPairRequestVerifier {
+	verifier := resource.NewECDSAPairRequestVerifier("android").
+		WithKey("default", getTrustedClientPublicKey(trustedClientCertPath))
+
+	ext := filepath.Ext(trustedClientCertPath)
+	prefix := strings.TrimSuffix(trustedClientCertPath, ext) + "."
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		panic("Error looking for additional trusted client certificates: " + err.Error())
+	}
+	for _, match := range matches {
+		keyID := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		verifier.WithKey(keyID, getTrustedClientPublicKey(match))
+	}
+	webLog.Println("Trusted Android client key IDs:", verifier.KeyIDs())
+	return verifier
+}
+
 func getTrustedClientPublicKey(trustedClientCertPath string) *ecdsa.PublicKey {
 	certBytes, err := ioutil.ReadFile(trustedClientCertPath)
 	if err != nil {
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/underlx/disturbancesmlx/resource"
+	"github.com/yarf-framework/yarf"
+)
+
+// documentedRouteGroup is a yarf.GroupRoute that keeps track of the paths of the routes added to it,
+// so they can be described in the OpenAPI document
+type documentedRouteGroup struct {
+	*yarf.GroupRoute
+	prefix string
+	paths  []string
+}
+
+func newDocumentedRouteGroup(prefix string) *documentedRouteGroup {
+	return &documentedRouteGroup{
+		GroupRoute: yarf.RouteGroup(prefix),
+		prefix:     prefix,
+	}
+}
+
+// Add inserts a new resource with its associated route into the group
+func (g *documentedRouteGroup) Add(url string, h yarf.ResourceHandler) {
+	g.paths = append(g.paths, g.prefix+url)
+	g.GroupRoute.Add(url, h)
+}
+
+// matchRoute returns the first of the routes (in yarf path format) that matches the URL path
+func matchRoute(routes []string, path string) string {
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for _, route := range routes {
+		routeParts := strings.Split(strings.Trim(route, "/"), "/")
+		wildcard := routeParts[len(routeParts)-1] == "*"
+		if wildcard {
+			routeParts = routeParts[:len(routeParts)-1]
+			if len(pathParts) < len(routeParts) {
+				continue
+			}
+		} else if len(pathParts) != len(routeParts) {
+			continue
+		}
+		matches := true
+		for i, part := range routeParts {
+			if part != pathParts[i] && !strings.HasPrefix(part, ":") {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return route
+		}
+	}
+	return ""
+}
+
+// recordingResponseWriter is a http.ResponseWriter that keeps a copy of what is written to it
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ContractValidationMiddleware checks API responses against the OpenAPI document, for debugging
+type ContractValidationMiddleware struct {
+	yarf.Middleware
+	routes []string
+}
+
+// PreDispatch runs before the request is dispatched
+func (m *ContractValidationMiddleware) PreDispatch(c *yarf.Context) error {
+	if strings.Contains(c.Request.Header.Get("Accept"), "text/event-stream") {
+		// streams are not described in the OpenAPI document
+		return nil
+	}
+	c.Response = &recordingResponseWriter{
+		ResponseWriter: c.Response,
+		statusCode:     http.StatusOK,
+	}
+	return nil
+}
+
+// PostDispatch runs after the request is dispatched
+func (m *ContractValidationMiddleware) PostDispatch(c *yarf.Context) error {
+	w, ok := c.Response.(*recordingResponseWriter)
+	if !ok {
+		return nil
+	}
+	c.Response = w.ResponseWriter
+	if c.Request.URL.Query().Get("fields") != "" || w.statusCode == http.StatusNotModified {
+		// responses with field selection can't be validated against the full schema
+		return nil
+	}
+	route := matchRoute(m.routes, c.Request.URL.Path)
+	if route == "" {
+		return nil
+	}
+	err := resource.ValidateAgainstOpenAPIDocument(route, c.Request.Method, w.statusCode,
+		w.Header().Get("Content-Type"), w.body.Bytes())
+	if err != nil {
+		webLog.Println("API contract violation:", err)
+	}
+	return nil
+}
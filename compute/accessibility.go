@@ -0,0 +1,114 @@
+package compute
+
+import (
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// StationAccessibility describes which parts of a station can be used without steps at some point in time.
+// Paths with outages, such as broken lifts, are considered unusable. Parts of a station without path information
+// are considered inaccessible, while platforms without gap information are assumed to allow boarding without assistance
+type StationAccessibility struct {
+	Station *types.Station
+	Time    time.Time
+	// StepFreeExits contains the IDs of the exits with a step-free path to their lobby
+	StepFreeExits map[int]bool
+	// StepFreeLobbies contains the IDs of the lobbies that can be reached from the street without steps
+	StepFreeLobbies map[string]bool
+	// StepFreeLines contains the IDs of the lines whose trains can be boarded and left without steps, from and to the street
+	StepFreeLines map[string]bool
+	// StepFreeInterchanges contains "from|to" pairs of IDs of lines between which it is possible to change without steps
+	StepFreeInterchanges map[string]bool
+	// Outages contains the outages of paths of the station at Time
+	Outages []*types.StationPathOutage
+}
+
+// ComputeStationAccessibility returns which parts of a station can be used without steps at the specified time
+func ComputeStationAccessibility(node sqalx.Node, station *types.Station, at time.Time) (*StationAccessibility, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	a := &StationAccessibility{
+		Station:              station,
+		Time:                 at,
+		StepFreeExits:        make(map[int]bool),
+		StepFreeLobbies:      make(map[string]bool),
+		StepFreeLines:        make(map[string]bool),
+		StepFreeInterchanges: make(map[string]bool),
+		Outages:              []*types.StationPathOutage{},
+	}
+
+	platforms, err := station.Platforms(tx)
+	if err != nil {
+		return nil, err
+	}
+	boardable := func(line *types.Line) bool {
+		for _, platform := range platforms {
+			if platform.Line.ID == line.ID {
+				return platform.StepFree()
+			}
+		}
+		return true
+	}
+
+	paths, err := station.Paths(tx)
+	if err != nil {
+		return nil, err
+	}
+	// maps lobby IDs to the lines whose platforms can be reached from them without steps
+	lobbyLines := make(map[string][]*types.Line)
+	for _, path := range paths {
+		outages, err := path.Outages(tx)
+		if err != nil {
+			return nil, err
+		}
+		outOfService := false
+		for _, outage := range outages {
+			if !outage.StartTime.After(at) && (!outage.Ended || outage.EndTime.After(at)) {
+				a.Outages = append(a.Outages, outage)
+				outOfService = true
+			}
+		}
+		if outOfService || !path.Means.StepFree() {
+			continue
+		}
+		switch {
+		case path.Exit != nil:
+			a.StepFreeExits[path.Exit.ID] = true
+			a.StepFreeLobbies[path.Lobby.ID] = true
+		case path.Lobby != nil:
+			lobbyLines[path.Lobby.ID] = append(lobbyLines[path.Lobby.ID], path.Line)
+		case boardable(path.Line) && boardable(path.ToLine):
+			a.StepFreeInterchanges[path.Line.ID+"|"+path.ToLine.ID] = true
+			a.StepFreeInterchanges[path.ToLine.ID+"|"+path.Line.ID] = true
+		}
+	}
+
+	for lobbyID, lines := range lobbyLines {
+		for _, from := range lines {
+			if !boardable(from) {
+				continue
+			}
+			if a.StepFreeLobbies[lobbyID] {
+				a.StepFreeLines[from.ID] = true
+			}
+			// lines whose platforms are reachable from the same lobby can be changed between through it
+			for _, to := range lines {
+				if boardable(to) {
+					a.StepFreeInterchanges[from.ID+"|"+to.ID] = true
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// CanInterchange returns whether it is possible to change between two lines without steps
+func (a *StationAccessibility) CanInterchange(from, to *types.Line) bool {
+	return a.StepFreeInterchanges[from.ID+"|"+to.ID]
+}
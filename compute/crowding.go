@@ -0,0 +1,328 @@
+package compute
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// CrowdingLevel indicates how crowded a line or station is, relative to its historical peak
+type CrowdingLevel string
+
+const (
+	// CrowdingUnknown is used when there is not enough historical data
+	CrowdingUnknown CrowdingLevel = "UNKNOWN"
+	// CrowdingLow is used when occupancy is below a quarter of the historical peak
+	CrowdingLow CrowdingLevel = "LOW"
+	// CrowdingMedium is used when occupancy is below half of the historical peak
+	CrowdingMedium CrowdingLevel = "MEDIUM"
+	// CrowdingHigh is used when occupancy is below 80% of the historical peak
+	CrowdingHigh CrowdingLevel = "HIGH"
+	// CrowdingVeryHigh is used when occupancy is close to or above the historical peak
+	CrowdingVeryHigh CrowdingLevel = "VERY_HIGH"
+)
+
+// crowdingHistoryWeeks is the number of weeks of trips considered when building the occupancy model
+const crowdingHistoryWeeks = 4
+
+// crowdingMinStationSeconds is the minimum time a user is considered to be present at a station he passes through
+const crowdingMinStationSeconds = 30
+
+// occupancyHistogram holds the average number of concurrent users per weekday and hour of the day
+type occupancyHistogram [7][24]float64
+
+func (h *occupancyHistogram) peak() float64 {
+	peak := 0.0
+	for _, day := range h {
+		for _, v := range day {
+			if v > peak {
+				peak = v
+			}
+		}
+	}
+	return peak
+}
+
+// add accounts for the presence of one user between start and end, splitting the time across the hour buckets
+func (h *occupancyHistogram) add(start, end time.Time, loc *time.Location) {
+	start = start.In(loc)
+	end = end.In(loc)
+	for start.Before(end) {
+		bucketEnd := start.Truncate(time.Hour).Add(time.Hour)
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+		h[start.Weekday()][start.Hour()] += bucketEnd.Sub(start).Seconds()
+		start = bucketEnd
+	}
+}
+
+// Crowding contains the expected and current occupancy of a line or station
+type Crowding struct {
+	// Expected is the historical average number of concurrent users for the current weekday and hour
+	Expected float64
+	// Current is the estimated number of concurrent users, based on the historical model and live OIT counts
+	Current       float64
+	ExpectedLevel CrowdingLevel
+	CurrentLevel  CrowdingLevel
+}
+
+// CrowdingHandler estimates line and station crowding from historical trips and real-time activity.
+// It implements resource.CrowdingEstimator
+type CrowdingHandler struct {
+	node         sqalx.Node
+	statsHandler *StatsHandler
+
+	mu                sync.RWMutex
+	lineHistograms    map[string]*occupancyHistogram
+	stationHistograms map[string]*occupancyHistogram
+	locations         map[string]*time.Location
+}
+
+// NewCrowdingHandler returns a new, initialized CrowdingHandler.
+// Update must be called before it can produce meaningful estimates
+func NewCrowdingHandler(node sqalx.Node, statsHandler *StatsHandler) *CrowdingHandler {
+	return &CrowdingHandler{
+		node:              node,
+		statsHandler:      statsHandler,
+		lineHistograms:    make(map[string]*occupancyHistogram),
+		stationHistograms: make(map[string]*occupancyHistogram),
+		locations:         make(map[string]*time.Location),
+	}
+}
+
+// Update rebuilds the historical occupancy model using the trips from the past weeks
+func (h *CrowdingHandler) Update(yieldFor time.Duration) error {
+	tx, err := h.node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -7*crowdingHistoryWeeks)
+	tripIDs, err := types.GetTripIDsBetween(tx, startTime, endTime)
+	if err != nil {
+		return err
+	}
+
+	mainLog.Printf("CrowdingHandler: %d trip IDs\n", len(tripIDs))
+
+	locations := make(map[string]*time.Location)
+	networks, err := types.GetNetworks(tx)
+	if err != nil {
+		return err
+	}
+	for _, network := range networks {
+		loc, err := time.LoadLocation(network.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		locations[network.ID] = loc
+	}
+
+	stationLines := make(map[string][]*types.Line)
+	getStationLines := func(station *types.Station) ([]*types.Line, error) {
+		if lines, ok := stationLines[station.ID]; ok {
+			return lines, nil
+		}
+		lines, err := station.Lines(tx)
+		if err != nil {
+			return nil, err
+		}
+		stationLines[station.ID] = lines
+		return lines, nil
+	}
+
+	lineHistograms := make(map[string]*occupancyHistogram)
+	stationHistograms := make(map[string]*occupancyHistogram)
+	histogramFor := func(m map[string]*occupancyHistogram, id string) *occupancyHistogram {
+		hist, ok := m[id]
+		if !ok {
+			hist = new(occupancyHistogram)
+			m[id] = hist
+		}
+		return hist
+	}
+
+	processTrip := func(trip *types.Trip) error {
+		for useIdx, use := range trip.StationUses {
+			if use.Manual || use.Inferred {
+				// manual path extensions don't contain valid time data
+				continue
+			}
+			loc := locations[use.Station.Network.ID]
+			if loc == nil {
+				loc = time.UTC
+			}
+
+			leaveTime := use.LeaveTime
+			if leaveTime.Sub(use.EntryTime) < crowdingMinStationSeconds*time.Second {
+				leaveTime = use.EntryTime.Add(crowdingMinStationSeconds * time.Second)
+			}
+			// users who stay for too long probably left the station without the client noticing
+			if leaveTime.Sub(use.EntryTime) < 15*time.Minute {
+				histogramFor(stationHistograms, use.Station.ID).add(use.EntryTime, leaveTime, loc)
+			}
+
+			if useIdx+1 >= len(trip.StationUses) {
+				continue
+			}
+			nextUse := trip.StationUses[useIdx+1]
+			if nextUse.Manual || nextUse.Inferred || use.Type == types.Visit || nextUse.EntryTime.Sub(use.LeaveTime) > 10*time.Minute {
+				continue
+			}
+
+			var line *types.Line
+			if use.Type == types.Interchange && use.TargetLine != nil {
+				line = use.TargetLine
+			} else {
+				sourceLines, err := getStationLines(use.Station)
+				if err != nil {
+					return err
+				}
+				targetLines, err := getStationLines(nextUse.Station)
+				if err != nil {
+					return err
+				}
+			findLine:
+				for _, sl := range sourceLines {
+					for _, tl := range targetLines {
+						if sl.ID == tl.ID {
+							line = sl
+							break findLine
+						}
+					}
+				}
+			}
+			if line != nil {
+				histogramFor(lineHistograms, line.ID).add(use.LeaveTime, nextUse.EntryTime, loc)
+			}
+		}
+		return nil
+	}
+
+	// instantiate each trip from DB individually
+	// (instead of using types.GetTrips)
+	// to reduce memory usage
+	for _, tripID := range tripIDs {
+		trip, err := types.GetTrip(tx, tripID)
+		if err != nil {
+			return err
+		}
+
+		if err = processTrip(trip); err != nil {
+			return err
+		}
+
+		if yieldFor > 0 {
+			time.Sleep(yieldFor)
+		}
+	}
+
+	// convert accumulated seconds into the average number of concurrent users
+	for _, m := range []map[string]*occupancyHistogram{lineHistograms, stationHistograms} {
+		for _, hist := range m {
+			for day := range hist {
+				for hour := range hist[day] {
+					hist[day][hour] /= 3600 * crowdingHistoryWeeks
+				}
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lineHistograms = lineHistograms
+	h.stationHistograms = stationHistograms
+	h.locations = locations
+	return nil
+}
+
+func (h *CrowdingHandler) location(network *types.Network) *time.Location {
+	if loc, ok := h.locations[network.ID]; ok {
+		return loc
+	}
+	return time.UTC
+}
+
+func (h *CrowdingHandler) expected(hist *occupancyHistogram, loc *time.Location) float64 {
+	if hist == nil {
+		return 0
+	}
+	now := time.Now().In(loc)
+	return hist[now.Weekday()][now.Hour()]
+}
+
+// lineScale returns the factor by which the historical expectation for a line should be multiplied
+// to match the number of users currently online in transit on it
+func (h *CrowdingHandler) lineScale(line *types.Line) float64 {
+	expected := h.expected(h.lineHistograms[line.ID], h.location(line.Network))
+	oit := float64(h.statsHandler.OITInLine(line, 0))
+	// add-one smoothing keeps the scale sane when there is little data
+	return (oit + 1) / (expected + 1)
+}
+
+func crowdingLevel(value, peak float64) CrowdingLevel {
+	switch {
+	case peak <= 0:
+		return CrowdingUnknown
+	case value < peak*0.25:
+		return CrowdingLow
+	case value < peak*0.5:
+		return CrowdingMedium
+	case value < peak*0.8:
+		return CrowdingHigh
+	default:
+		return CrowdingVeryHigh
+	}
+}
+
+func newCrowding(expected, current, peak float64) *Crowding {
+	return &Crowding{
+		Expected:      expected,
+		Current:       current,
+		ExpectedLevel: crowdingLevel(expected, peak),
+		CurrentLevel:  crowdingLevel(current, peak),
+	}
+}
+
+// LineCrowding returns the expected and current crowding of the specified line
+func (h *CrowdingHandler) LineCrowding(line *types.Line) *Crowding {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hist := h.lineHistograms[line.ID]
+	if hist == nil {
+		return newCrowding(0, 0, 0)
+	}
+	expected := h.expected(hist, h.location(line.Network))
+	return newCrowding(expected, expected*h.lineScale(line), hist.peak())
+}
+
+// StationCrowding returns the expected and current crowding of the specified station.
+// The live estimate is scaled by the average live factor of the lines serving the station
+func (h *CrowdingHandler) StationCrowding(node sqalx.Node, station *types.Station) (*Crowding, error) {
+	lines, err := station.Lines(node)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hist := h.stationHistograms[station.ID]
+	if hist == nil {
+		return newCrowding(0, 0, 0), nil
+	}
+	expected := h.expected(hist, h.location(station.Network))
+	scale := 1.0
+	if len(lines) > 0 {
+		scale = 0
+		for _, line := range lines {
+			scale += h.lineScale(line)
+		}
+		scale /= float64(len(lines))
+	}
+	return newCrowding(expected, expected*scale, hist.peak()), nil
+}
@@ -0,0 +1,156 @@
+package compute
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// ErrNoFare is returned when there are no fare products that can be used for a journey
+var ErrNoFare = errors.New("no fare products available for the journey")
+
+// FareJourney is a continuous use of a network, from entering it to leaving it
+type FareJourney struct {
+	Network     *types.Network
+	Start       time.Time
+	StationUses []*types.StationUse
+}
+
+// FareTicket is a fare product used to pay for one or more consecutive journeys
+type FareTicket struct {
+	Product  *types.FareProduct
+	Journeys []*FareJourney
+}
+
+// Fare is the cheapest combination of fare products that pays for a sequence of journeys
+type Fare struct {
+	Tickets []*FareTicket
+	// Total is expressed in the smallest unit of Currency (e.g. cents)
+	Total    int
+	Currency string
+}
+
+// String returns the total price of the fare in a human-friendly format
+func (fare *Fare) String() string {
+	return FormatPrice(fare.Total, fare.Currency)
+}
+
+// FormatPrice returns a price, expressed in the smallest unit of the currency, in a human-friendly format
+func FormatPrice(price int, currency string) string {
+	symbol := currency
+	switch currency {
+	case "EUR", "":
+		symbol = "€"
+	}
+	return fmt.Sprintf("%d,%02d %s", price/100, price%100, symbol)
+}
+
+// SplitJourneys splits a sequence of station uses into the journeys they correspond to.
+// A new journey begins whenever the network is entered
+func SplitJourneys(uses []*types.StationUse) []*FareJourney {
+	journeys := []*FareJourney{}
+	var current *FareJourney
+	for _, use := range uses {
+		if current == nil || use.Type == types.NetworkEntry || use.Type == types.Visit ||
+			use.Station.Network.ID != current.Network.ID {
+			current = &FareJourney{
+				Network: use.Station.Network,
+				Start:   use.EntryTime,
+			}
+			journeys = append(journeys, current)
+		}
+		current.StationUses = append(current.StationUses, use)
+		if use.Type == types.NetworkExit || use.Type == types.Visit {
+			current = nil
+		}
+	}
+	return journeys
+}
+
+// ComputeFare returns the cheapest way to pay for the journeys made through a sequence of station uses, such as
+// the ones in one or more Trips, taking into account the zones each product is valid in, the validity of the
+// products and how many network entries they allow
+func ComputeFare(node sqalx.Node, uses []*types.StationUse) (*Fare, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	journeys := SplitJourneys(uses)
+
+	products := make(map[string][]*types.FareProduct)
+	stationZones := make(map[string][]*types.FareZone)
+	for _, journey := range journeys {
+		if _, present := products[journey.Network.ID]; !present {
+			products[journey.Network.ID], err = journey.Network.FareProducts(tx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, use := range journey.StationUses {
+			if _, present := stationZones[use.Station.ID]; !present {
+				stationZones[use.Station.ID], err = use.Station.FareZones(tx)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	covers := func(product *types.FareProduct, journey *FareJourney) bool {
+		for _, use := range journey.StationUses {
+			if !product.ValidInZones(stationZones[use.Station.ID]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// cost[i] is the cheapest way to pay for journeys[i:]
+	// choice[i] is the product bought at journey i, and next[i] is the first journey it does not pay for
+	n := len(journeys)
+	cost := make([]int, n+1)
+	choice := make([]*types.FareProduct, n)
+	next := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		cost[i] = math.MaxInt32
+		for _, product := range products[journeys[i].Network.ID] {
+			for j := i; j < n; j++ {
+				if journeys[j].Network.ID != journeys[i].Network.ID || !covers(product, journeys[j]) {
+					break
+				}
+				if j > i && (product.Validity == 0 ||
+					journeys[j].Start.Sub(journeys[i].Start) > time.Duration(product.Validity) ||
+					(product.MaxEntries > 0 && j-i+1 > product.MaxEntries)) {
+					break
+				}
+				if cost[j+1] != math.MaxInt32 && product.Price+cost[j+1] < cost[i] {
+					cost[i] = product.Price + cost[j+1]
+					choice[i] = product
+					next[i] = j + 1
+				}
+			}
+		}
+		if choice[i] == nil {
+			return nil, ErrNoFare
+		}
+	}
+
+	fare := &Fare{
+		Tickets: []*FareTicket{},
+	}
+	for i := 0; i < n; i = next[i] {
+		fare.Tickets = append(fare.Tickets, &FareTicket{
+			Product:  choice[i],
+			Journeys: journeys[i:next[i]],
+		})
+		fare.Total += choice[i].Price
+		fare.Currency = choice[i].Currency
+	}
+	return fare, nil
+}
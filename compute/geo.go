@@ -0,0 +1,239 @@
+package compute
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// StationDistance is a station and its distance, in meters, to a point
+type StationDistance struct {
+	Station *types.Station
+	// WorldCoord is the location of the station closest to the point (usually an exit)
+	WorldCoord [2]float64
+	Distance   float64
+}
+
+// ExitDistance is an exit and its distance, in meters, to a point
+type ExitDistance struct {
+	Exit     *types.Exit
+	Distance float64
+}
+
+// POIDistance is a POI and its distance, in meters, to a point
+type POIDistance struct {
+	POI      *types.POI
+	Distance float64
+}
+
+// IsochroneStation is a station that can be reached within the duration of an Isochrone
+type IsochroneStation struct {
+	Station *types.Station
+	// TravelTime is the time it takes to reach the station, without leaving it
+	TravelTime time.Duration
+	// WorldCoords are the locations of the exits of the station (or of its POIs, if it has no exits)
+	WorldCoords [][2]float64
+	// WalkingRadius is how far, in meters, it is possible to walk from each location in the remaining time
+	WalkingRadius float64
+}
+
+// Isochrone contains everything that can be reached within some time from a station
+type Isochrone struct {
+	Origin    *types.Station
+	Departure time.Time
+	Duration  time.Duration
+	Stations  []*IsochroneStation
+}
+
+// StationWorldCoords returns the locations of the exits of a station.
+// The locations of the POIs of the station are returned when it has no exits
+func StationWorldCoords(node sqalx.Node, station *types.Station) ([][2]float64, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	coords := [][2]float64{}
+	lobbies, err := station.Lobbies(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, lobby := range lobbies {
+		exits, err := lobby.Exits(tx)
+		if err != nil {
+			return nil, err
+		}
+		for _, exit := range exits {
+			coords = append(coords, exit.WorldCoord)
+		}
+	}
+	if len(coords) == 0 {
+		pois, err := station.POIs(tx)
+		if err != nil {
+			return nil, err
+		}
+		for _, poi := range pois {
+			coords = append(coords, poi.WorldCoord)
+		}
+	}
+	return coords, nil
+}
+
+// NearestStations returns up to limit stations, sorted by increasing distance to a [latitude, longitude] coordinate
+func NearestStations(node sqalx.Node, coord [2]float64, limit int) ([]StationDistance, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	stations, err := types.GetStations(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []StationDistance{}
+	for _, station := range stations {
+		coords, err := StationWorldCoords(tx, station)
+		if err != nil {
+			return nil, err
+		}
+		if len(coords) == 0 {
+			continue
+		}
+		sd := StationDistance{
+			Station:  station,
+			Distance: -1,
+		}
+		for _, c := range coords {
+			distance := utils.WorldDistance(coord, c)
+			if sd.Distance < 0 || distance < sd.Distance {
+				sd.Distance = distance
+				sd.WorldCoord = c
+			}
+		}
+		result = append(result, sd)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Distance < result[j].Distance
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// NearestExits returns up to limit exits, sorted by increasing distance to a [latitude, longitude] coordinate
+func NearestExits(node sqalx.Node, coord [2]float64, limit int) ([]ExitDistance, error) {
+	exits, err := types.GetExits(node)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ExitDistance, len(exits))
+	for i, exit := range exits {
+		result[i] = ExitDistance{
+			Exit:     exit,
+			Distance: utils.WorldDistance(coord, exit.WorldCoord),
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Distance < result[j].Distance
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// POIsWithinRadius returns the POIs within radius meters of a [latitude, longitude] coordinate, sorted by increasing distance
+func POIsWithinRadius(node sqalx.Node, coord [2]float64, radius float64) ([]POIDistance, error) {
+	pois, err := types.GetPOIs(node)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []POIDistance{}
+	for _, poi := range pois {
+		distance := utils.WorldDistance(coord, poi.WorldCoord)
+		if distance <= radius {
+			result = append(result, POIDistance{
+				POI:      poi,
+				Distance: distance,
+			})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Distance < result[j].Distance
+	})
+	return result, nil
+}
+
+// ComputeIsochrone returns the stations that can be reached within the specified duration when departing from
+// a station at the specified time, and how far it is possible to walk from their exits in the remaining time.
+// If accessibleOnly is true, only stations that can be reached and left without steps are included
+func ComputeIsochrone(node sqalx.Node, origin *types.Station, departAt time.Time, duration time.Duration, accessibleOnly bool) (*Isochrone, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	graph, err := NewRoutingGraph(tx)
+	if err != nil {
+		return nil, err
+	}
+	if accessibleOnly {
+		graph.AccessibleOnly()
+	}
+	err = graph.AddWalkingConnections()
+	if err != nil {
+		return nil, err
+	}
+
+	times, err := graph.TravelTimes(origin, departAt, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	isochrone := &Isochrone{
+		Origin:    origin,
+		Departure: departAt,
+		Duration:  duration,
+		Stations:  []*IsochroneStation{},
+	}
+	for id, travelTime := range times {
+		station := graph.stations[id]
+		is := &IsochroneStation{
+			Station:    station,
+			TravelTime: travelTime,
+		}
+		remaining := duration - travelTime
+		if id != origin.ID {
+			// leaving the origin station is not needed, as one starts outside of it
+			remaining -= routingStationExitSeconds * time.Second
+		}
+		if remaining > 0 {
+			open, err := graph.stationOpenAt(station, departAt.Add(travelTime))
+			if err != nil {
+				return nil, err
+			}
+			if open {
+				is.WalkingRadius = remaining.Seconds() * routingWalkingSpeed
+			}
+		}
+		is.WorldCoords, err = StationWorldCoords(tx, station)
+		if err != nil {
+			return nil, err
+		}
+		isochrone.Stations = append(isochrone.Stations, is)
+	}
+	sort.Slice(isochrone.Stations, func(i, j int) bool {
+		return isochrone.Stations[i].TravelTime < isochrone.Stations[j].TravelTime
+	})
+	return isochrone, nil
+}
@@ -46,7 +46,7 @@ func SimulateRealtime(node sqalx.Node, fromTime time.Time, toTime time.Time, wri
 		for useIdx := 0; useIdx < len(trip.StationUses); useIdx++ {
 			curUse := trip.StationUses[useIdx]
 
-			if curUse.Manual {
+			if curUse.Manual || curUse.Inferred {
 				// manual path extensions don't contain valid time data
 				// skip
 				continue
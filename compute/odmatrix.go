@@ -0,0 +1,301 @@
+package compute
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// ODDayType classifies days for the purposes of origin-destination analysis
+type ODDayType string
+
+const (
+	// ODWeekday is used for Monday to Friday, except holidays
+	ODWeekday ODDayType = "WEEKDAY"
+	// ODSaturday is used for Saturdays, except holidays
+	ODSaturday ODDayType = "SATURDAY"
+	// ODSundayHoliday is used for Sundays and holidays
+	ODSundayHoliday ODDayType = "SUNDAY_HOLIDAY"
+)
+
+// ODDayTypes contains all the day types, in display order
+var ODDayTypes = []ODDayType{ODWeekday, ODSaturday, ODSundayHoliday}
+
+// ODHourBand is a range of hours of the day, used to group trips in origin-destination analysis
+type ODHourBand struct {
+	ID string
+	// Start is the first hour of the day included in the band
+	Start int
+	// End is the first hour of the day after the band
+	End int
+}
+
+// ODHourBands contains all the hour bands, in display order. Together they cover the whole day
+var ODHourBands = []ODHourBand{
+	{ID: "EARLY", Start: 0, End: 7},
+	{ID: "AM_PEAK", Start: 7, End: 10},
+	{ID: "MIDDAY", Start: 10, End: 16},
+	{ID: "PM_PEAK", Start: 16, End: 20},
+	{ID: "EVENING", Start: 20, End: 24},
+}
+
+// ODMatrixMinimumSubmitters is the k in the k-anonymity guarantee of the published matrices:
+// flows with trips from fewer distinct submitters than this are suppressed
+const ODMatrixMinimumSubmitters = 5
+
+// odMatrixWindowDays is the number of days of trips considered when building the matrices
+const odMatrixWindowDays = 90
+
+// ODFlow is the number of trips between an origin and a destination station
+type ODFlow struct {
+	Origin      *types.Station
+	Destination *types.Station
+	Trips       int
+}
+
+// ODMatrix is an anonymised origin-destination matrix for a network, day type and hour band
+type ODMatrix struct {
+	Network  *types.Network
+	DayType  ODDayType
+	HourBand ODHourBand
+	// Flows contains the flows that satisfy the k-anonymity threshold, sorted by decreasing number of trips
+	Flows []*ODFlow
+	// TotalTrips is the number of trips considered, including those in suppressed flows
+	TotalTrips int
+	// SuppressedTrips is the number of trips in flows that did not satisfy the k-anonymity threshold
+	SuppressedTrips int
+}
+
+// Stations returns the stations that appear in the flows of this matrix, sorted by ID
+func (m *ODMatrix) Stations() []*types.Station {
+	seen := make(map[string]*types.Station)
+	for _, flow := range m.Flows {
+		seen[flow.Origin.ID] = flow.Origin
+		seen[flow.Destination.ID] = flow.Destination
+	}
+	stations := []*types.Station{}
+	for _, station := range seen {
+		stations = append(stations, station)
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].ID < stations[j].ID
+	})
+	return stations
+}
+
+// ODMatrixHandler periodically builds anonymised origin-destination matrices from submitted trips
+type ODMatrixHandler struct {
+	node sqalx.Node
+
+	mu          sync.RWMutex
+	matrices    map[string][]*ODMatrix
+	windowStart time.Time
+	windowEnd   time.Time
+}
+
+// NewODMatrixHandler returns a new, initialized ODMatrixHandler.
+// Update must be called before any matrices are available
+func NewODMatrixHandler(node sqalx.Node) *ODMatrixHandler {
+	return &ODMatrixHandler{
+		node:     node,
+		matrices: make(map[string][]*ODMatrix),
+	}
+}
+
+type odCell struct {
+	trips      int
+	submitters map[string]bool
+}
+
+type odKey struct {
+	network, dayType, band, origin, destination string
+}
+
+// ODDayTypeFor returns the day type of the given day in the given network
+func ODDayTypeFor(network *types.Network, day time.Time) ODDayType {
+	for _, holiday := range network.Holidays {
+		if int(holiday) == day.YearDay() {
+			return ODSundayHoliday
+		}
+	}
+	switch day.Weekday() {
+	case time.Saturday:
+		return ODSaturday
+	case time.Sunday:
+		return ODSundayHoliday
+	default:
+		return ODWeekday
+	}
+}
+
+// ODHourBandFor returns the hour band containing the given time of day
+func ODHourBandFor(t time.Time) ODHourBand {
+	for _, band := range ODHourBands {
+		if t.Hour() >= band.Start && t.Hour() < band.End {
+			return band
+		}
+	}
+	return ODHourBands[len(ODHourBands)-1]
+}
+
+// Update rebuilds the matrices using the trips from the past months
+func (h *ODMatrixHandler) Update(yieldFor time.Duration) error {
+	tx, err := h.node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	endTime := time.Now()
+	startTime := endTime.AddDate(0, 0, -odMatrixWindowDays)
+	tripIDs, err := types.GetTripIDsBetween(tx, startTime, endTime)
+	if err != nil {
+		return err
+	}
+
+	mainLog.Printf("ODMatrixHandler: %d trip IDs\n", len(tripIDs))
+
+	networks, err := types.GetNetworks(tx)
+	if err != nil {
+		return err
+	}
+	locations := make(map[string]*time.Location)
+	for _, network := range networks {
+		loc, err := time.LoadLocation(network.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		locations[network.ID] = loc
+	}
+
+	cells := make(map[odKey]*odCell)
+	stations := make(map[string]*types.Station)
+	totals := make(map[[3]string]int)
+
+	// instantiate each trip from DB individually
+	// (instead of using types.GetTrips)
+	// to reduce memory usage
+	for _, tripID := range tripIDs {
+		trip, err := types.GetTrip(tx, tripID)
+		if err != nil {
+			return err
+		}
+
+		if yieldFor > 0 {
+			time.Sleep(yieldFor)
+		}
+
+		if len(trip.StationUses) <= 1 {
+			// station visit or invalid trip
+			continue
+		}
+		origin := trip.StationUses[0].Station
+		destination := trip.StationUses[len(trip.StationUses)-1].Station
+		if origin.ID == destination.ID || origin.Network.ID != destination.Network.ID {
+			continue
+		}
+
+		loc := locations[origin.Network.ID]
+		if loc == nil {
+			loc = time.UTC
+		}
+		start := trip.StartTime.In(loc)
+		dayType := ODDayTypeFor(origin.Network, start)
+		band := ODHourBandFor(start)
+
+		key := odKey{origin.Network.ID, string(dayType), band.ID, origin.ID, destination.ID}
+		cell, ok := cells[key]
+		if !ok {
+			cell = &odCell{submitters: make(map[string]bool)}
+			cells[key] = cell
+		}
+		cell.trips++
+		cell.submitters[trip.Submitter.Key] = true
+		stations[origin.ID] = origin
+		stations[destination.ID] = destination
+		totals[[3]string{key.network, key.dayType, key.band}]++
+	}
+
+	matricesByKey := make(map[[3]string]*ODMatrix)
+	matrices := make(map[string][]*ODMatrix)
+	for _, network := range networks {
+		for _, dayType := range ODDayTypes {
+			for _, band := range ODHourBands {
+				matrixKey := [3]string{network.ID, string(dayType), band.ID}
+				matrix := &ODMatrix{
+					Network:    network,
+					DayType:    dayType,
+					HourBand:   band,
+					Flows:      []*ODFlow{},
+					TotalTrips: totals[matrixKey],
+				}
+				matricesByKey[matrixKey] = matrix
+				matrices[network.ID] = append(matrices[network.ID], matrix)
+			}
+		}
+	}
+
+	for key, cell := range cells {
+		matrix := matricesByKey[[3]string{key.network, key.dayType, key.band}]
+		if matrix == nil {
+			continue
+		}
+		if len(cell.submitters) < ODMatrixMinimumSubmitters {
+			matrix.SuppressedTrips += cell.trips
+			continue
+		}
+		matrix.Flows = append(matrix.Flows, &ODFlow{
+			Origin:      stations[key.origin],
+			Destination: stations[key.destination],
+			Trips:       cell.trips,
+		})
+	}
+
+	for _, matrix := range matricesByKey {
+		sort.Slice(matrix.Flows, func(i, j int) bool {
+			if matrix.Flows[i].Trips == matrix.Flows[j].Trips {
+				if matrix.Flows[i].Origin.ID == matrix.Flows[j].Origin.ID {
+					return matrix.Flows[i].Destination.ID < matrix.Flows[j].Destination.ID
+				}
+				return matrix.Flows[i].Origin.ID < matrix.Flows[j].Origin.ID
+			}
+			return matrix.Flows[i].Trips > matrix.Flows[j].Trips
+		})
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.matrices = matrices
+	h.windowStart = startTime
+	h.windowEnd = endTime
+	return nil
+}
+
+// Window returns the time range covered by the current matrices
+func (h *ODMatrixHandler) Window() (time.Time, time.Time) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.windowStart, h.windowEnd
+}
+
+// Matrices returns all the matrices for the specified network
+func (h *ODMatrixHandler) Matrices(network *types.Network) []*ODMatrix {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.matrices[network.ID]
+}
+
+// Matrix returns the matrix for the specified network, day type and hour band, or nil if it is not available
+func (h *ODMatrixHandler) Matrix(network *types.Network, dayType ODDayType, bandID string) *ODMatrix {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, matrix := range h.matrices[network.ID] {
+		if matrix.DayType == dayType && matrix.HourBand.ID == bandID {
+			return matrix
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package compute
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+type pairUsage struct {
+	lastSeen time.Time
+	requests int64
+}
+
+// PairUsageTracker accumulates the usage of APIPairs in memory and periodically persists it,
+// so that authenticating a request doesn't require a database write
+type PairUsageTracker struct {
+	node sqalx.Node
+
+	mu      sync.Mutex
+	pending map[string]*pairUsage
+}
+
+// NewPairUsageTracker returns a new, initialized PairUsageTracker
+func NewPairUsageTracker(node sqalx.Node) *PairUsageTracker {
+	return &PairUsageTracker{
+		node:    node,
+		pending: make(map[string]*pairUsage),
+	}
+}
+
+// Record registers a request made by the specified pair
+func (t *PairUsageTracker) Record(pair *types.APIPair) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage, ok := t.pending[pair.Key]
+	if !ok {
+		usage = &pairUsage{}
+		t.pending[pair.Key] = usage
+	}
+	usage.lastSeen = time.Now()
+	usage.requests++
+}
+
+// Flush persists the usage accumulated since the last flush
+func (t *PairUsageTracker) Flush() error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[string]*pairUsage)
+	t.mu.Unlock()
+
+	// keep going on errors, so one bad pair doesn't prevent the usage of the others from being recorded
+	var err error
+	for key, usage := range pending {
+		if e := types.RecordPairUsage(t.node, key, usage.lastSeen, usage.requests); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Run flushes the accumulated usage at the specified interval, until the process exits
+func (t *PairUsageTracker) Run(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		err := t.Flush()
+		if err != nil {
+			mainLog.Println("PairUsageTracker: " + err.Error())
+		}
+	}
+}
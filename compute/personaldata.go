@@ -0,0 +1,134 @@
+package compute
+
+import (
+	"fmt"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// resolvePersonalDataSubjects returns the APIPair and the PosPlay player linked to the specified ones.
+// Either argument may be nil, and either result may be nil if there is no such link
+func resolvePersonalDataSubjects(node sqalx.Node, pair *types.APIPair, player *types.PPPlayer) (*types.APIPair, *types.PPPlayer, error) {
+	if pair != nil && player == nil {
+		ppPair, err := types.GetPPPairForKey(node, pair.Key)
+		if err == nil {
+			player, err = types.GetPPPlayer(node, ppPair.DiscordID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	} else if player != nil && pair == nil {
+		ppPair, err := types.GetPPPair(node, player.DiscordID)
+		if err == nil {
+			pair = ppPair.Pair
+		}
+	}
+	return pair, player, nil
+}
+
+func personalDataSubjectIDs(pair *types.APIPair, player *types.PPPlayer) (string, uint64) {
+	pairKey := ""
+	var discordID uint64
+	if pair != nil {
+		pairKey = pair.Key
+	}
+	if player != nil {
+		discordID = player.DiscordID
+	}
+	return pairKey, discordID
+}
+
+// ExportPersonalData returns an archive with all the personal data linked to the specified APIPair or PosPlay player
+// (one of them may be nil), including the data of the PosPlay player the pair is connected to, and vice-versa.
+// The operation is recorded in the personal data audit log, with the specified origin
+func ExportPersonalData(node sqalx.Node, pair *types.APIPair, player *types.PPPlayer, origin string) (*types.PersonalDataArchive, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pair, player, err = resolvePersonalDataSubjects(tx, pair, player)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := types.ExportPersonalData(tx, pair, player)
+	if err != nil {
+		return nil, err
+	}
+
+	pairKey, discordID := personalDataSubjectIDs(pair, player)
+	details := fmt.Sprintf("%d trips, %d feedback", len(archive.Trips), len(archive.Feedback))
+	err = types.LogPersonalDataOperation(tx, types.PersonalDataExport, pairKey, discordID, origin, details)
+	if err != nil {
+		return nil, err
+	}
+	mainLog.Printf("Personal data export: pair %s, player %d, origin %s: %s\n", pairKey, discordID, origin, details)
+	return archive, tx.Commit()
+}
+
+// ErasePersonalData deletes all the personal data linked to the specified APIPair or PosPlay player
+// (one of them may be nil), including the data of the PosPlay player the pair is connected to, and vice-versa.
+// The pair itself is deleted, so the client must pair again to keep using authenticated features.
+// If keepTrips is true, trips are anonymised instead of deleted, leaving aggregate statistics unchanged.
+// Otherwise, their contributions to typical seconds are retracted and the connection times are refreshed.
+// The operation is recorded in the personal data audit log, with the specified origin
+func ErasePersonalData(node sqalx.Node, pair *types.APIPair, player *types.PPPlayer, keepTrips bool, origin string) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	pair, player, err = resolvePersonalDataSubjects(tx, pair, player)
+	if err != nil {
+		return err
+	}
+
+	tripCount := 0
+	if pair != nil {
+		tripCount, err = types.ErasePairPersonalData(tx, pair, keepTrips)
+		if err != nil {
+			return err
+		}
+	}
+
+	if player != nil {
+		err = types.ErasePPPlayerPersonalData(tx, player)
+		if err != nil {
+			return err
+		}
+	}
+
+	operation := types.PersonalDataErasure
+	details := fmt.Sprintf("%d trips deleted", tripCount)
+	if keepTrips {
+		operation = types.PersonalDataAnonymisation
+		details = fmt.Sprintf("%d trips anonymised", tripCount)
+	}
+	pairKey, discordID := personalDataSubjectIDs(pair, player)
+	err = types.LogPersonalDataOperation(tx, operation, pairKey, discordID, origin, details)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	mainLog.Printf("Personal data %s: pair %s, player %d, origin %s: %s\n", operation, pairKey, discordID, origin, details)
+
+	if !keepTrips && tripCount > 0 {
+		// the buckets were already updated when the samples were retracted,
+		// but the connection times are only recomputed from them here
+		go func() {
+			err := UpdateTypicalSeconds(node, TypicalSecondsWindow, 0)
+			if err != nil {
+				mainLog.Println("ErasePersonalData: " + err.Error())
+			}
+		}()
+	}
+	return nil
+}
@@ -5,10 +5,42 @@ package compute
 import "reflect"
 
 var Types = map[string]reflect.Type{
-	"PassengerReading": reflect.TypeOf((*PassengerReading)(nil)).Elem(),
-	"ReportHandler":    reflect.TypeOf((*ReportHandler)(nil)).Elem(),
-	"StatsHandler":     reflect.TypeOf((*StatsHandler)(nil)).Elem(),
-	"TrainETA":         reflect.TypeOf((*TrainETA)(nil)).Elem(),
+	"Crowding":             reflect.TypeOf((*Crowding)(nil)).Elem(),
+	"CrowdingHandler":      reflect.TypeOf((*CrowdingHandler)(nil)).Elem(),
+	"CrowdingLevel":        reflect.TypeOf((*CrowdingLevel)(nil)).Elem(),
+	"ExitDistance":         reflect.TypeOf((*ExitDistance)(nil)).Elem(),
+	"Fare":                 reflect.TypeOf((*Fare)(nil)).Elem(),
+	"FareJourney":          reflect.TypeOf((*FareJourney)(nil)).Elem(),
+	"FareTicket":           reflect.TypeOf((*FareTicket)(nil)).Elem(),
+	"Isochrone":            reflect.TypeOf((*Isochrone)(nil)).Elem(),
+	"IsochroneStation":     reflect.TypeOf((*IsochroneStation)(nil)).Elem(),
+	"ODDayType":            reflect.TypeOf((*ODDayType)(nil)).Elem(),
+	"ODFlow":               reflect.TypeOf((*ODFlow)(nil)).Elem(),
+	"ODHourBand":           reflect.TypeOf((*ODHourBand)(nil)).Elem(),
+	"ODMatrix":             reflect.TypeOf((*ODMatrix)(nil)).Elem(),
+	"ODMatrixHandler":      reflect.TypeOf((*ODMatrixHandler)(nil)).Elem(),
+	"POIDistance":          reflect.TypeOf((*POIDistance)(nil)).Elem(),
+	"PairUsageTracker":     reflect.TypeOf((*PairUsageTracker)(nil)).Elem(),
+	"PassengerReading":     reflect.TypeOf((*PassengerReading)(nil)).Elem(),
+	"ReportHandler":        reflect.TypeOf((*ReportHandler)(nil)).Elem(),
+	"RetentionHandler":     reflect.TypeOf((*RetentionHandler)(nil)).Elem(),
+	"RetentionPolicy":      reflect.TypeOf((*RetentionPolicy)(nil)).Elem(),
+	"RetentionReport":      reflect.TypeOf((*RetentionReport)(nil)).Elem(),
+	"Route":                reflect.TypeOf((*Route)(nil)).Elem(),
+	"RouteLeg":             reflect.TypeOf((*RouteLeg)(nil)).Elem(),
+	"RouteTransfer":        reflect.TypeOf((*RouteTransfer)(nil)).Elem(),
+	"RoutingGraph":         reflect.TypeOf((*RoutingGraph)(nil)).Elem(),
+	"S2LSFeedbackContents": reflect.TypeOf((*S2LSFeedbackContents)(nil)).Elem(),
+	"S2LSScannedNetwork":   reflect.TypeOf((*S2LSScannedNetwork)(nil)).Elem(),
+	"SchematicMap":         reflect.TypeOf((*SchematicMap)(nil)).Elem(),
+	"StationAccessibility": reflect.TypeOf((*StationAccessibility)(nil)).Elem(),
+	"StationDistance":      reflect.TypeOf((*StationDistance)(nil)).Elem(),
+	"StatsHandler":         reflect.TypeOf((*StatsHandler)(nil)).Elem(),
+	"StatusEvent":          reflect.TypeOf((*StatusEvent)(nil)).Elem(),
+	"StatusEventBroker":    reflect.TypeOf((*StatusEventBroker)(nil)).Elem(),
+	"StatusEventType":      reflect.TypeOf((*StatusEventType)(nil)).Elem(),
+	"TrainETA":             reflect.TypeOf((*TrainETA)(nil)).Elem(),
+	"TripValidation":       reflect.TypeOf((*TripValidation)(nil)).Elem(),
 	"TripsScatterplotNumTripsVsAvgSpeedPoint": reflect.TypeOf((*TripsScatterplotNumTripsVsAvgSpeedPoint)(nil)).Elem(),
 	"TypicalSecondsEntry":                     reflect.TypeOf((*TypicalSecondsEntry)(nil)).Elem(),
 	"TypicalSecondsMinMax":                    reflect.TypeOf((*TypicalSecondsMinMax)(nil)).Elem(),
@@ -17,23 +49,83 @@ var Types = map[string]reflect.Type{
 }
 
 var Functions = map[string]reflect.Value{
-	"AverageSpeed":                       reflect.ValueOf(AverageSpeed),
-	"AverageSpeedCached":                 reflect.ValueOf(AverageSpeedCached),
-	"AverageSpeedFilter":                 reflect.ValueOf(AverageSpeedFilter),
-	"Initialize":                         reflect.ValueOf(Initialize),
-	"NewReportHandler":                   reflect.ValueOf(NewReportHandler),
-	"NewStatsHandler":                    reflect.ValueOf(NewStatsHandler),
-	"NewVehicleETAHandler":               reflect.ValueOf(NewVehicleETAHandler),
-	"NewVehicleHandler":                  reflect.ValueOf(NewVehicleHandler),
-	"SimulateRealtime":                   reflect.ValueOf(SimulateRealtime),
-	"TripsScatterplotNumTripsVsAvgSpeed": reflect.ValueOf(TripsScatterplotNumTripsVsAvgSpeed),
-	"TypicalSecondsByDowAndHour":         reflect.ValueOf(TypicalSecondsByDowAndHour),
-	"UpdateStatusMsgTypes":               reflect.ValueOf(UpdateStatusMsgTypes),
-	"UpdateTypicalSeconds":               reflect.ValueOf(UpdateTypicalSeconds),
+	"AlternativeRoutes":                       reflect.ValueOf(AlternativeRoutes),
+	"AlternativeRoutesCached":                 reflect.ValueOf(AlternativeRoutesCached),
+	"AverageSpeed":                            reflect.ValueOf(AverageSpeed),
+	"AverageSpeedCached":                      reflect.ValueOf(AverageSpeedCached),
+	"AverageSpeedFilter":                      reflect.ValueOf(AverageSpeedFilter),
+	"ClearTripNormalizerCache":                reflect.ValueOf(ClearTripNormalizerCache),
+	"ComputeFare":                             reflect.ValueOf(ComputeFare),
+	"ComputeIsochrone":                        reflect.ValueOf(ComputeIsochrone),
+	"ComputeRoute":                            reflect.ValueOf(ComputeRoute),
+	"ComputeStationAccessibility":             reflect.ValueOf(ComputeStationAccessibility),
+	"ErasePersonalData":                       reflect.ValueOf(ErasePersonalData),
+	"ExportPersonalData":                      reflect.ValueOf(ExportPersonalData),
+	"FormatPrice":                             reflect.ValueOf(FormatPrice),
+	"IngestTripTypicalSeconds":                reflect.ValueOf(IngestTripTypicalSeconds),
+	"Initialize":                              reflect.ValueOf(Initialize),
+	"NearestExits":                            reflect.ValueOf(NearestExits),
+	"NearestStations":                         reflect.ValueOf(NearestStations),
+	"NewAlternativeRoutingGraph":              reflect.ValueOf(NewAlternativeRoutingGraph),
+	"NewCrowdingHandler":                      reflect.ValueOf(NewCrowdingHandler),
+	"NewODMatrixHandler":                      reflect.ValueOf(NewODMatrixHandler),
+	"NewPairUsageTracker":                     reflect.ValueOf(NewPairUsageTracker),
+	"NewReportHandler":                        reflect.ValueOf(NewReportHandler),
+	"NewRetentionHandler":                     reflect.ValueOf(NewRetentionHandler),
+	"NewRoutingGraph":                         reflect.ValueOf(NewRoutingGraph),
+	"NewSchematicMap":                         reflect.ValueOf(NewSchematicMap),
+	"NewStatsHandler":                         reflect.ValueOf(NewStatsHandler),
+	"NewStatusEventBroker":                    reflect.ValueOf(NewStatusEventBroker),
+	"NewVehicleETAHandler":                    reflect.ValueOf(NewVehicleETAHandler),
+	"NewVehicleHandler":                       reflect.ValueOf(NewVehicleHandler),
+	"NormalizeAllTrips":                       reflect.ValueOf(NormalizeAllTrips),
+	"NormalizeTrip":                           reflect.ValueOf(NormalizeTrip),
+	"ODDayTypeFor":                            reflect.ValueOf(ODDayTypeFor),
+	"ODHourBandFor":                           reflect.ValueOf(ODHourBandFor),
+	"POIsWithinRadius":                        reflect.ValueOf(POIsWithinRadius),
+	"ParseS2LSFeedback":                       reflect.ValueOf(ParseS2LSFeedback),
+	"PublishWiFiAPSuggestions":                reflect.ValueOf(PublishWiFiAPSuggestions),
+	"RenderSchematicMapPNG":                   reflect.ValueOf(RenderSchematicMapPNG),
+	"RenderSchematicMapSVG":                   reflect.ValueOf(RenderSchematicMapSVG),
+	"SimulateRealtime":                        reflect.ValueOf(SimulateRealtime),
+	"SplitJourneys":                           reflect.ValueOf(SplitJourneys),
+	"StationWorldCoords":                      reflect.ValueOf(StationWorldCoords),
+	"TripsScatterplotNumTripsVsAvgSpeed":      reflect.ValueOf(TripsScatterplotNumTripsVsAvgSpeed),
+	"TypicalSecondsByDowAndHour":              reflect.ValueOf(TypicalSecondsByDowAndHour),
+	"UpdateStatusMsgTypes":                    reflect.ValueOf(UpdateStatusMsgTypes),
+	"UpdateTypicalSeconds":                    reflect.ValueOf(UpdateTypicalSeconds),
+	"UpdateWiFiAPSuggestions":                 reflect.ValueOf(UpdateWiFiAPSuggestions),
+	"UpdateWiFiAPSuggestionsFromObservations": reflect.ValueOf(UpdateWiFiAPSuggestionsFromObservations),
+	"ValidateTrip":                            reflect.ValueOf(ValidateTrip),
 }
 
 var Variables = map[string]reflect.Value{
-	"ErrInfoNotReady": reflect.ValueOf(&ErrInfoNotReady),
+	"DefaultRetentionPolicy":  reflect.ValueOf(&DefaultRetentionPolicy),
+	"ErrInfoNotReady":         reflect.ValueOf(&ErrInfoNotReady),
+	"ErrNoFare":               reflect.ValueOf(&ErrNoFare),
+	"ErrNoRoute":              reflect.ValueOf(&ErrNoRoute),
+	"ErrSameStation":          reflect.ValueOf(&ErrSameStation),
+	"ErrStreamingUnsupported": reflect.ValueOf(&ErrStreamingUnsupported),
+	"ODDayTypes":              reflect.ValueOf(&ODDayTypes),
+	"ODHourBands":             reflect.ValueOf(&ODHourBands),
 }
 
-var Consts = map[string]reflect.Value{}
+var Consts = map[string]reflect.Value{
+	"CrowdingHigh":                         reflect.ValueOf(CrowdingHigh),
+	"CrowdingLow":                          reflect.ValueOf(CrowdingLow),
+	"CrowdingMedium":                       reflect.ValueOf(CrowdingMedium),
+	"CrowdingUnknown":                      reflect.ValueOf(CrowdingUnknown),
+	"CrowdingVeryHigh":                     reflect.ValueOf(CrowdingVeryHigh),
+	"DefaultWiFiAPSuggestionMinSubmitters": reflect.ValueOf(DefaultWiFiAPSuggestionMinSubmitters),
+	"MinWiFiAPObservationStationShare":     reflect.ValueOf(MinWiFiAPObservationStationShare),
+	"ODMatrixMinimumSubmitters":            reflect.ValueOf(ODMatrixMinimumSubmitters),
+	"ODSaturday":                           reflect.ValueOf(ODSaturday),
+	"ODSundayHoliday":                      reflect.ValueOf(ODSundayHoliday),
+	"ODWeekday":                            reflect.ValueOf(ODWeekday),
+	"StatusEventDisturbanceClose":          reflect.ValueOf(StatusEventDisturbanceClose),
+	"StatusEventDisturbanceOpen":           reflect.ValueOf(StatusEventDisturbanceOpen),
+	"StatusEventNewCondition":              reflect.ValueOf(StatusEventNewCondition),
+	"StatusEventNewStatus":                 reflect.ValueOf(StatusEventNewStatus),
+	"TypicalSecondsWindow":                 reflect.ValueOf(TypicalSecondsWindow),
+	"WiFiAPObservationWindow":              reflect.ValueOf(WiFiAPObservationWindow),
+}
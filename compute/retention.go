@@ -0,0 +1,146 @@
+package compute
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// RetentionPolicy defines for how long personal data is kept. Zero values disable the respective rule
+type RetentionPolicy struct {
+	// TripSubmitterMonths is the number of months after which trips are unlinked from their submitters.
+	// The station uses are kept, so the trips keep contributing to statistics
+	TripSubmitterMonths int
+	// FeedbackSubmitterMonths is the number of months after which feedback is unlinked from its submitters
+	FeedbackSubmitterMonths int
+	// PairRequestDays is the number of days after which Android pair requests, which contain IP addresses and Android IDs, are deleted
+	PairRequestDays int
+	// DryRun makes the scheduled runs only report what would be done, without changing anything
+	DryRun bool
+}
+
+// DefaultRetentionPolicy is the retention policy used when none is configured.
+// It is a dry run, so that operators can check what the suggested periods would affect before enabling them
+var DefaultRetentionPolicy = RetentionPolicy{
+	TripSubmitterMonths:     24,
+	FeedbackSubmitterMonths: 12,
+	PairRequestDays:         90,
+	DryRun:                  true,
+}
+
+// RetentionReport describes the outcome of applying a RetentionPolicy
+type RetentionReport struct {
+	Time   time.Time
+	DryRun bool
+	// the cutoffs are zero for disabled rules
+	TripsCutoff        time.Time
+	FeedbackCutoff     time.Time
+	PairRequestsCutoff time.Time
+	// when DryRun is true, these are the numbers of items that would be affected
+	TripsAnonymised     int
+	FeedbackAnonymised  int
+	PairRequestsDeleted int
+}
+
+func (r *RetentionReport) String() string {
+	verb := "applied"
+	if r.DryRun {
+		verb = "dry run"
+	}
+	return fmt.Sprintf("Retention policy %s: %d trips anonymised, %d feedback anonymised, %d pair requests deleted",
+		verb, r.TripsAnonymised, r.FeedbackAnonymised, r.PairRequestsDeleted)
+}
+
+// RetentionHandler periodically applies a data retention policy
+type RetentionHandler struct {
+	node   sqalx.Node
+	policy RetentionPolicy
+
+	mu         sync.Mutex
+	lastReport *RetentionReport
+}
+
+// NewRetentionHandler returns a new, initialized RetentionHandler
+func NewRetentionHandler(node sqalx.Node, policy RetentionPolicy) *RetentionHandler {
+	return &RetentionHandler{
+		node:   node,
+		policy: policy,
+	}
+}
+
+// Policy returns the retention policy of this handler
+func (h *RetentionHandler) Policy() RetentionPolicy {
+	return h.policy
+}
+
+// LastReport returns the report of the last run, or nil if the policy was never applied
+func (h *RetentionHandler) LastReport() *RetentionReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastReport
+}
+
+// Run applies the retention policy, or only reports what would be done if the policy or the dryRun argument say so
+func (h *RetentionHandler) Run(dryRun bool) (*RetentionReport, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	report := &RetentionReport{
+		Time:   time.Now(),
+		DryRun: dryRun || h.policy.DryRun,
+	}
+
+	tx, err := h.node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if h.policy.TripSubmitterMonths > 0 {
+		report.TripsCutoff = report.Time.AddDate(0, -h.policy.TripSubmitterMonths, 0)
+		if report.DryRun {
+			report.TripsAnonymised, err = types.CountTripsWithSubmitterOlderThan(tx, report.TripsCutoff)
+		} else {
+			report.TripsAnonymised, err = types.AnonymiseTripsOlderThan(tx, report.TripsCutoff)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if h.policy.FeedbackSubmitterMonths > 0 {
+		report.FeedbackCutoff = report.Time.AddDate(0, -h.policy.FeedbackSubmitterMonths, 0)
+		if report.DryRun {
+			report.FeedbackAnonymised, err = types.CountFeedbackWithSubmitterOlderThan(tx, report.FeedbackCutoff)
+		} else {
+			report.FeedbackAnonymised, err = types.AnonymiseFeedbackOlderThan(tx, report.FeedbackCutoff)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if h.policy.PairRequestDays > 0 {
+		report.PairRequestsCutoff = report.Time.AddDate(0, 0, -h.policy.PairRequestDays)
+		if report.DryRun {
+			report.PairRequestsDeleted, err = types.CountAndroidPairRequestsOlderThan(tx, report.PairRequestsCutoff)
+		} else {
+			report.PairRequestsDeleted, err = types.DeleteAndroidPairRequestsOlderThan(tx, report.PairRequestsCutoff)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	mainLog.Println(report.String())
+	h.lastReport = report
+	return report, nil
+}
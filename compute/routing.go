@@ -0,0 +1,766 @@
+package compute
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+const (
+	// routingDisturbanceHorizon is how far from the current time a departure must be for ongoing disturbances to be considered
+	routingDisturbanceHorizon = 2 * time.Hour
+	// routingDisturbancePenalty is added to the waiting time when boarding a line with an ongoing disturbance
+	routingDisturbancePenalty = 15 * time.Minute
+	// routingMaxWalkingDistance is the maximum distance, in meters, between the exits of two stations for walking between them
+	routingMaxWalkingDistance = 1200
+	// routingWalkingSpeed is the walking speed in meters per second, already accounting for detours through the streets
+	routingWalkingSpeed = 1.0
+	// routingStationExitSeconds is the time it takes to go from the platforms to the street, or the opposite
+	routingStationExitSeconds = 120
+	// alternativeRoutesCacheTTL is for how long the alternative routes to a line are reused
+	alternativeRoutesCacheTTL = 2 * time.Minute
+)
+
+var alternativeRoutesCache = cache.New(alternativeRoutesCacheTTL, 10*time.Minute)
+
+// ErrNoRoute is returned when there is no way to travel between two stations at the requested time
+var ErrNoRoute = errors.New("no route found")
+
+// ErrSameStation is returned when a route is requested between a station and itself
+var ErrSameStation = errors.New("origin and destination stations are the same")
+
+// Route is a way to travel between two stations
+type Route struct {
+	From      *types.Station
+	To        *types.Station
+	Departure time.Time
+	Arrival   time.Time
+	Legs      []*RouteLeg
+	Transfers []*RouteTransfer
+}
+
+// RouteLeg is a part of a Route where a single line is ridden in a single direction, or where the user walks.
+// In walking legs, Line and Direction are nil and Walk is true
+type RouteLeg struct {
+	Walk      bool
+	Line      *types.Line
+	Direction *types.Station
+	// Stations contains the stations the train goes through, including the first and last ones
+	Stations  []*types.Station
+	Departure time.Time
+	Arrival   time.Time
+	// Disturbed is true when the line had an ongoing disturbance at the time the route was computed
+	Disturbed bool
+}
+
+// RouteTransfer is a change of line in a Route
+type RouteTransfer struct {
+	Station *types.Station
+	From    *types.Line
+	To      *types.Line
+	Start   time.Time
+	End     time.Time
+}
+
+// Duration returns the time it takes to travel through the route
+func (route *Route) Duration() time.Duration {
+	return route.Arrival.Sub(route.Departure)
+}
+
+// StationUses returns the station uses a trip through the route would have.
+// Walking between stations means leaving the network and entering it again
+func (route *Route) StationUses() []*types.StationUse {
+	uses := []*types.StationUse{}
+	var previous *RouteLeg
+	for _, leg := range route.Legs {
+		if leg.Walk {
+			if len(uses) > 0 {
+				uses[len(uses)-1].Type = types.NetworkExit
+			}
+			previous = leg
+			continue
+		}
+		for i, station := range leg.Stations {
+			if i == 0 && previous != nil && !previous.Walk {
+				// the interchange station was already added by the previous leg
+				last := uses[len(uses)-1]
+				last.Type = types.Interchange
+				last.SourceLine = previous.Line
+				last.TargetLine = leg.Line
+				last.LeaveTime = leg.Departure
+				continue
+			}
+			use := &types.StationUse{
+				Station:   station,
+				Type:      types.GoneThrough,
+				EntryTime: leg.Departure,
+				LeaveTime: leg.Departure,
+			}
+			switch {
+			case i == 0:
+				use.Type = types.NetworkEntry
+			case i == len(leg.Stations)-1:
+				use.EntryTime = leg.Arrival
+				use.LeaveTime = leg.Arrival
+			}
+			uses = append(uses, use)
+		}
+		previous = leg
+	}
+	if len(uses) > 0 {
+		uses[len(uses)-1].Type = types.NetworkExit
+	}
+	return uses
+}
+
+// RoutingGraph is a time-dependent graph of the stations, built from the Connections and Transfers
+type RoutingGraph struct {
+	node      sqalx.Node
+	edges     map[string][]*routingEdge
+	transfers map[string]*types.Transfer
+	stations  map[string]*types.Station
+	disturbed map[string]bool
+	avoided   map[string]bool
+	// accessibility caches the accessibility of the stations when only step-free routes are allowed
+	accessibility map[string]*StationAccessibility
+	// lineClosed caches the result of Line.ClosedAt, keyed by line ID and minute
+	lineClosed map[string]bool
+}
+
+// routingEdge is a Connection ridden in a Line towards Direction.
+// Walking edges have no line nor direction, and a connection that doesn't exist in the database
+type routingEdge struct {
+	connection *types.Connection
+	line       *types.Line
+	direction  *types.Station
+}
+
+// NewRoutingGraph builds a RoutingGraph with the current network topology and ongoing disturbances.
+// The returned graph is only valid while the node is
+func NewRoutingGraph(node sqalx.Node) (*RoutingGraph, error) {
+	g := &RoutingGraph{
+		node:       node,
+		edges:      make(map[string][]*routingEdge),
+		transfers:  make(map[string]*types.Transfer),
+		stations:   make(map[string]*types.Station),
+		disturbed:  make(map[string]bool),
+		avoided:    make(map[string]bool),
+		lineClosed: make(map[string]bool),
+	}
+
+	lines, err := types.GetLines(node)
+	if err != nil {
+		return nil, err
+	}
+	// maps a pair of station IDs to the lines (and their direction) that connect them
+	lineEdges := make(map[string][]*routingEdge)
+	for _, line := range lines {
+		stations, err := line.Stations(node)
+		if err != nil {
+			return nil, err
+		}
+		open := []*types.Station{}
+		for _, station := range stations {
+			closed, err := station.Closed(node)
+			if err != nil {
+				return nil, err
+			}
+			if !closed {
+				open = append(open, station)
+			}
+		}
+		for i := 0; i+1 < len(open); i++ {
+			lineEdges[open[i].ID+"|"+open[i+1].ID] = append(lineEdges[open[i].ID+"|"+open[i+1].ID],
+				&routingEdge{line: line, direction: open[len(open)-1]})
+			lineEdges[open[i+1].ID+"|"+open[i].ID] = append(lineEdges[open[i+1].ID+"|"+open[i].ID],
+				&routingEdge{line: line, direction: open[0]})
+		}
+	}
+
+	// closed compat connections skip over closed stations, just like the lineEdges above
+	connections, err := types.GetConnections(node, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, connection := range connections {
+		g.stations[connection.From.ID] = connection.From
+		g.stations[connection.To.ID] = connection.To
+		for _, edge := range lineEdges[connection.From.ID+"|"+connection.To.ID] {
+			g.edges[connection.From.ID] = append(g.edges[connection.From.ID], &routingEdge{
+				connection: connection,
+				line:       edge.line,
+				direction:  edge.direction,
+			})
+		}
+	}
+
+	transfers, err := types.GetTransfers(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		g.transfers[transfer.Station.ID+"|"+transfer.From.ID+"|"+transfer.To.ID] = transfer
+	}
+
+	disturbances, err := types.GetOngoingDisturbances(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, disturbance := range disturbances {
+		g.disturbed[disturbance.Line.ID] = true
+	}
+	return g, nil
+}
+
+// AvoidLine excludes a line from the routes computed by this graph
+func (g *RoutingGraph) AvoidLine(line *types.Line) {
+	g.avoided[line.ID] = true
+}
+
+// AccessibleOnly restricts the routes computed by this graph to those that can be travelled without steps.
+// Paths inside stations that are out of service, such as broken lifts, block the routes that would use them
+func (g *RoutingGraph) AccessibleOnly() {
+	g.accessibility = make(map[string]*StationAccessibility)
+}
+
+// stationAccessibility returns the accessibility of a station, or nil if the graph is not restricted to accessible routes.
+// The accessibility is computed for the time of the first request for each station
+func (g *RoutingGraph) stationAccessibility(station *types.Station, at time.Time) (*StationAccessibility, error) {
+	if g.accessibility == nil {
+		return nil, nil
+	}
+	if a, present := g.accessibility[station.ID]; present {
+		return a, nil
+	}
+	a, err := ComputeStationAccessibility(g.node, station, at)
+	if err != nil {
+		return nil, err
+	}
+	g.accessibility[station.ID] = a
+	return a, nil
+}
+
+// canLeave returns whether it is possible to leave the station of a label towards the street
+func (g *RoutingGraph) canLeave(label *routingLabel) (bool, error) {
+	if label.edge == nil || label.edge.line == nil {
+		// already outside
+		return true, nil
+	}
+	a, err := g.stationAccessibility(label.station, label.arrival)
+	if err != nil || a == nil {
+		return a == nil, err
+	}
+	return a.StepFreeLines[label.edge.line.ID], nil
+}
+
+// AddWalkingConnections allows for walking between stations whose exits are close to each other.
+// The location of the points of interest of a station is used when it has no exits
+func (g *RoutingGraph) AddWalkingConnections() error {
+	coords := make(map[string][][2]float64)
+	for id, station := range g.stations {
+		var err error
+		coords[id], err = StationWorldCoords(g.node, station)
+		if err != nil {
+			return err
+		}
+	}
+
+	for fromID, fromCoords := range coords {
+		for toID, toCoords := range coords {
+			if fromID == toID {
+				continue
+			}
+			distance := math.Inf(1)
+			for _, a := range fromCoords {
+				for _, b := range toCoords {
+					distance = math.Min(distance, utils.WorldDistance(a, b))
+				}
+			}
+			if distance > routingMaxWalkingDistance {
+				continue
+			}
+			g.edges[fromID] = append(g.edges[fromID], &routingEdge{
+				connection: &types.Connection{
+					From:           g.stations[fromID],
+					To:             g.stations[toID],
+					TypicalSeconds: 2*routingStationExitSeconds + int(distance/routingWalkingSpeed),
+					WorldLength:    int(distance),
+				},
+			})
+		}
+	}
+	return nil
+}
+
+// routingLabel is a state of the route search: having arrived at a station, riding a line in a direction
+type routingLabel struct {
+	station  *types.Station
+	edge     *routingEdge
+	arrival  time.Time
+	previous *routingLabel
+	// the following are only set when the line was boarded at the previous station
+	boarded       bool
+	transfer      *types.Transfer
+	transferStart time.Time
+	transferEnd   time.Time
+	departure     time.Time
+	index         int
+}
+
+func (l *routingLabel) key() string {
+	if l.edge == nil {
+		return l.station.ID
+	}
+	if l.edge.line == nil {
+		return l.station.ID + "|walk"
+	}
+	return l.station.ID + "|" + l.edge.line.ID + "|" + l.edge.direction.ID
+}
+
+type routingQueue []*routingLabel
+
+func (q routingQueue) Len() int { return len(q) }
+
+func (q routingQueue) Less(i, j int) bool { return q[i].arrival.Before(q[j].arrival) }
+
+func (q routingQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *routingQueue) Push(x interface{}) {
+	label := x.(*routingLabel)
+	label.index = len(*q)
+	*q = append(*q, label)
+}
+
+func (q *routingQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	label := old[n-1]
+	*q = old[0 : n-1]
+	return label
+}
+
+// Route returns the route that arrives the earliest at the destination station when departing at the specified time
+func (g *RoutingGraph) Route(from, to *types.Station, departAt time.Time) (*Route, error) {
+	if from.ID == to.ID {
+		return nil, ErrSameStation
+	}
+	if _, present := g.stations[from.ID]; !present {
+		return nil, ErrNoRoute
+	}
+	if _, present := g.stations[to.ID]; !present {
+		return nil, ErrNoRoute
+	}
+	open, err := g.stationOpenAt(from, departAt)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, ErrNoRoute
+	}
+
+	var route *Route
+	err = g.search(from, departAt, func(label *routingLabel, considerDisturbances bool) (bool, error) {
+		if label.station.ID != to.ID {
+			return true, nil
+		}
+		open, err := g.stationOpenAt(to, label.arrival)
+		if err != nil || !open {
+			return false, err
+		}
+		canLeave, err := g.canLeave(label)
+		if err != nil || !canLeave {
+			// arriving through another line may still allow for leaving
+			return err == nil, err
+		}
+		route = g.buildRoute(label, considerDisturbances)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if route == nil {
+		return nil, ErrNoRoute
+	}
+	return route, nil
+}
+
+// TravelTimes returns the time it takes to reach each station that can be reached within maxDuration,
+// when departing from the specified station at the specified time. The origin station is included
+func (g *RoutingGraph) TravelTimes(from *types.Station, departAt time.Time, maxDuration time.Duration) (map[string]time.Duration, error) {
+	times := make(map[string]time.Duration)
+	if _, present := g.stations[from.ID]; !present {
+		return times, nil
+	}
+	open, err := g.stationOpenAt(from, departAt)
+	if err != nil || !open {
+		return times, err
+	}
+
+	err = g.search(from, departAt, func(label *routingLabel, considerDisturbances bool) (bool, error) {
+		duration := label.arrival.Sub(departAt)
+		if duration > maxDuration {
+			return false, nil
+		}
+		if _, present := times[label.station.ID]; present {
+			return true, nil
+		}
+		canLeave, err := g.canLeave(label)
+		if err != nil || !canLeave {
+			return err == nil, err
+		}
+		times[label.station.ID] = duration
+		return true, nil
+	})
+	return times, err
+}
+
+// search visits the states reachable from a station in order of arrival time, until visit returns false
+func (g *RoutingGraph) search(from *types.Station, departAt time.Time, visit func(label *routingLabel, considerDisturbances bool) (bool, error)) error {
+	considerDisturbances := departAt.Sub(time.Now()) < routingDisturbanceHorizon &&
+		time.Now().Sub(departAt) < routingDisturbanceHorizon
+
+	settled := make(map[string]bool)
+	queue := &routingQueue{}
+	heap.Push(queue, &routingLabel{
+		station: from,
+		arrival: departAt,
+	})
+	for queue.Len() > 0 {
+		label := heap.Pop(queue).(*routingLabel)
+		if settled[label.key()] {
+			continue
+		}
+		settled[label.key()] = true
+
+		cont, err := visit(label, considerDisturbances)
+		if err != nil || !cont {
+			return err
+		}
+
+		for _, edge := range g.edges[label.station.ID] {
+			next, err := g.follow(label, edge, considerDisturbances)
+			if err != nil {
+				return err
+			}
+			if next != nil && !settled[next.key()] {
+				heap.Push(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// follow returns the label for riding the specified edge after reaching the station of the specified label,
+// or nil if that is not possible
+func (g *RoutingGraph) follow(label *routingLabel, edge *routingEdge, considerDisturbances bool) (*routingLabel, error) {
+	next := &routingLabel{
+		station:  edge.connection.To,
+		edge:     edge,
+		previous: label,
+	}
+	if edge.line == nil {
+		return g.followWalk(label, next)
+	}
+	if g.avoided[edge.line.ID] {
+		return nil, nil
+	}
+	switch {
+	case label.edge == nil || label.edge.line == nil:
+		// entering the network
+		next.boarded = true
+		next.departure = label.arrival.Add(time.Duration(edge.connection.TypicalWaitingSeconds) * time.Second)
+	case label.edge.line.ID == edge.line.ID && label.edge.direction.ID == edge.direction.ID:
+		// staying on the train
+		next.departure = label.arrival.Add(time.Duration(edge.connection.TypicalStopSeconds) * time.Second)
+	case label.edge.line.ID == edge.line.ID:
+		// going back on the same line. this is never faster, but it keeps the search complete
+		next.boarded = true
+		next.departure = label.arrival.Add(time.Duration(edge.connection.TypicalWaitingSeconds) * time.Second)
+	default:
+		transfer, present := g.transfers[label.station.ID+"|"+label.edge.line.ID+"|"+edge.line.ID]
+		if !present {
+			return nil, nil
+		}
+		next.boarded = true
+		next.transfer = transfer
+		next.transferStart = label.arrival
+		next.transferEnd = label.arrival.Add(time.Duration(transfer.TypicalSeconds) * time.Second)
+		next.departure = next.transferEnd.Add(time.Duration(edge.connection.TypicalWaitingSeconds) * time.Second)
+	}
+	if next.boarded && considerDisturbances && g.disturbed[edge.line.ID] {
+		next.departure = next.departure.Add(routingDisturbancePenalty)
+	}
+
+	closed, err := g.lineClosedAt(edge.line, next.departure)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		return nil, nil
+	}
+	if next.boarded {
+		accessible, err := g.canBoard(label, edge)
+		if err != nil || !accessible {
+			return nil, err
+		}
+	}
+	next.arrival = next.departure.Add(time.Duration(edge.connection.TypicalSeconds) * time.Second)
+	return next, nil
+}
+
+// canBoard returns whether it is possible to board the line of an edge at the station of a label,
+// coming from the street or from the line of the label
+func (g *RoutingGraph) canBoard(label *routingLabel, edge *routingEdge) (bool, error) {
+	a, err := g.stationAccessibility(label.station, label.arrival)
+	if err != nil || a == nil {
+		return a == nil, err
+	}
+	if label.edge == nil || label.edge.line == nil {
+		return a.StepFreeLines[edge.line.ID], nil
+	}
+	return a.CanInterchange(label.edge.line, edge.line), nil
+}
+
+func (g *RoutingGraph) followWalk(label, next *routingLabel) (*routingLabel, error) {
+	if label.edge != nil && label.edge.line == nil {
+		// walking twice in a row is never useful
+		return nil, nil
+	}
+	canLeave, err := g.canLeave(label)
+	if err != nil || !canLeave {
+		return nil, err
+	}
+	next.boarded = true
+	next.departure = label.arrival
+	next.arrival = next.departure.Add(time.Duration(next.edge.connection.TypicalSeconds) * time.Second)
+
+	// both stations must be open, to leave one and enter the other
+	open, err := g.stationOpenAt(label.station, next.departure)
+	if err != nil || !open {
+		return nil, err
+	}
+	open, err = g.stationOpenAt(next.station, next.arrival)
+	if err != nil || !open {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (g *RoutingGraph) lineClosedAt(line *types.Line, at time.Time) (bool, error) {
+	key := line.ID + "|" + at.Truncate(time.Minute).String()
+	if closed, present := g.lineClosed[key]; present {
+		return closed, nil
+	}
+	closed, err := line.ClosedAt(g.node, at)
+	if err != nil {
+		return false, err
+	}
+	g.lineClosed[key] = closed
+	return closed, nil
+}
+
+// stationOpenAt returns whether at least one lobby of the station is open at the specified time
+func (g *RoutingGraph) stationOpenAt(station *types.Station, at time.Time) (bool, error) {
+	lobbies, err := station.Lobbies(g.node)
+	if err != nil {
+		return false, err
+	}
+	for _, lobby := range lobbies {
+		closed, err := lobby.ClosedAt(g.node, at)
+		if err != nil {
+			return false, err
+		}
+		if !closed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (g *RoutingGraph) buildRoute(last *routingLabel, considerDisturbances bool) *Route {
+	labels := []*routingLabel{}
+	for label := last; label.edge != nil; label = label.previous {
+		labels = append([]*routingLabel{label}, labels...)
+	}
+
+	route := &Route{
+		From:      labels[0].previous.station,
+		To:        last.station,
+		Departure: labels[0].previous.arrival,
+		Arrival:   last.arrival,
+		Legs:      []*RouteLeg{},
+		Transfers: []*RouteTransfer{},
+	}
+	var leg *RouteLeg
+	for _, label := range labels {
+		if label.boarded {
+			if label.transfer != nil {
+				route.Transfers = append(route.Transfers, &RouteTransfer{
+					Station: label.previous.station,
+					From:    label.transfer.From,
+					To:      label.transfer.To,
+					Start:   label.transferStart,
+					End:     label.transferEnd,
+				})
+			}
+			leg = &RouteLeg{
+				Walk:      label.edge.line == nil,
+				Line:      label.edge.line,
+				Direction: label.edge.direction,
+				Stations:  []*types.Station{label.previous.station},
+				Departure: label.departure,
+			}
+			if !leg.Walk {
+				leg.Disturbed = considerDisturbances && g.disturbed[label.edge.line.ID]
+			}
+			route.Legs = append(route.Legs, leg)
+		}
+		leg.Stations = append(leg.Stations, label.station)
+		leg.Arrival = label.arrival
+	}
+	return route
+}
+
+// ComputeRoute returns the route that arrives the earliest at the destination station when departing at the specified time.
+// If accessibleOnly is true, only routes that can be travelled without steps are considered
+func ComputeRoute(node sqalx.Node, from, to *types.Station, departAt time.Time, accessibleOnly bool) (*Route, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	graph, err := NewRoutingGraph(tx)
+	if err != nil {
+		return nil, err
+	}
+	if accessibleOnly {
+		graph.AccessibleOnly()
+	}
+	return graph.Route(from, to, departAt)
+}
+
+// AlternativeRoutes returns suggestions of routes that avoid a line, allowing for walking between nearby stations.
+// The routes are computed between the consecutive important stations of the line (its termini and the stations
+// where it is possible to transfer to other lines), and between its termini.
+// If accessibleOnly is true, only routes that can be travelled without steps are considered.
+// To compute the alternatives to multiple lines, use NewAlternativeRoutingGraph and RoutingGraph.AlternativeRoutes
+func AlternativeRoutes(node sqalx.Node, line *types.Line, departAt time.Time, accessibleOnly bool) ([]*Route, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	graph, err := NewAlternativeRoutingGraph(tx, accessibleOnly)
+	if err != nil {
+		return nil, err
+	}
+	return graph.AlternativeRoutes(line, departAt)
+}
+
+// AlternativeRoutesCached is like AlternativeRoutes, with a departure time of now, but reuses the routes computed
+// for the same line in the last few minutes, as computing them is expensive and they seldom change within that time
+func AlternativeRoutesCached(node sqalx.Node, line *types.Line, accessibleOnly bool) ([]*Route, error) {
+	key := line.ID + "|" + strconv.FormatBool(accessibleOnly)
+	if routes, ok := alternativeRoutesCache.Get(key); ok {
+		return routes.([]*Route), nil
+	}
+	routes, err := AlternativeRoutes(node, line, time.Now(), accessibleOnly)
+	if err != nil {
+		return nil, err
+	}
+	alternativeRoutesCache.SetDefault(key, routes)
+	return routes, nil
+}
+
+// NewAlternativeRoutingGraph builds a RoutingGraph that allows for walking between nearby stations,
+// suitable for computing the alternative routes to any line.
+// If accessibleOnly is true, only routes that can be travelled without steps are considered.
+// The returned graph is only valid while the node is
+func NewAlternativeRoutingGraph(node sqalx.Node, accessibleOnly bool) (*RoutingGraph, error) {
+	graph, err := NewRoutingGraph(node)
+	if err != nil {
+		return nil, err
+	}
+	if accessibleOnly {
+		graph.AccessibleOnly()
+	}
+	err = graph.AddWalkingConnections()
+	if err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// AlternativeRoutes returns suggestions of routes that avoid a line, in addition to the lines already avoided by this graph.
+// See the AlternativeRoutes function for the stations between which the routes are computed.
+// The graph is left as it was, so it can be reused for other lines
+func (g *RoutingGraph) AlternativeRoutes(line *types.Line, departAt time.Time) ([]*Route, error) {
+	avoided := g.avoided
+	g.avoided = map[string]bool{line.ID: true}
+	for id := range avoided {
+		g.avoided[id] = true
+	}
+	defer func() { g.avoided = avoided }()
+
+	tx, err := g.node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	stations, err := line.Stations(tx)
+	if err != nil {
+		return nil, err
+	}
+	important := []*types.Station{}
+	for i, station := range stations {
+		if closed, err := station.Closed(tx); err != nil {
+			return nil, err
+		} else if closed {
+			continue
+		}
+		lines, err := station.Lines(tx)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 || i == len(stations)-1 || len(lines) > 1 {
+			important = append(important, station)
+		}
+	}
+	if len(important) < 2 {
+		return []*Route{}, nil
+	}
+
+	pairs := [][2]*types.Station{}
+	for i := 0; i+1 < len(important); i++ {
+		pairs = append(pairs, [2]*types.Station{important[i], important[i+1]})
+	}
+	if len(important) > 2 {
+		pairs = append(pairs, [2]*types.Station{important[0], important[len(important)-1]})
+	}
+
+	routes := []*Route{}
+	for _, pair := range pairs {
+		route, err := g.Route(pair[0], pair[1], departAt)
+		if err == ErrNoRoute || err == ErrSameStation {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
@@ -0,0 +1,131 @@
+package compute
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// S2LSFeedbackContents is the structured contents of a types.S2LSincorrectDetection feedback
+type S2LSFeedbackContents struct {
+	// ExpectedStation is the ID of the station the user was in, empty if the user was not in a station
+	ExpectedStation string `json:"expectedStation"`
+	// DetectedStation is the ID of the station detected by the client, empty if no station was detected
+	DetectedStation string `json:"detectedStation"`
+	// WiFiNetworks contains the networks in range, as scanned by the client when the feedback was submitted
+	WiFiNetworks []S2LSScannedNetwork `json:"wiFiNetworks"`
+}
+
+// S2LSScannedNetwork is a WiFi network scanned by the client
+type S2LSScannedNetwork struct {
+	BSSID string `json:"bssid"`
+	SSID  string `json:"ssid"`
+	Level int    `json:"level"`
+}
+
+// ParseS2LSFeedback parses the contents of a types.S2LSincorrectDetection feedback
+func ParseS2LSFeedback(feedback *types.Feedback) (*S2LSFeedbackContents, error) {
+	if feedback.Type != types.S2LSincorrectDetection {
+		return nil, errors.New("ParseS2LSFeedback: feedback is of type " + string(feedback.Type))
+	}
+	var contents S2LSFeedbackContents
+	err := json.Unmarshal([]byte(feedback.Contents), &contents)
+	if err != nil {
+		return nil, errors.New("ParseS2LSFeedback: " + err.Error())
+	}
+	if contents.ExpectedStation == contents.DetectedStation {
+		return nil, errors.New("ParseS2LSFeedback: expected and detected stations are the same")
+	}
+	if len(contents.WiFiNetworks) == 0 {
+		return nil, errors.New("ParseS2LSFeedback: no scanned networks")
+	}
+	for i := range contents.WiFiNetworks {
+		contents.WiFiNetworks[i].BSSID = strings.ToLower(strings.TrimSpace(contents.WiFiNetworks[i].BSSID))
+	}
+	return &contents, nil
+}
+
+type wiFiAPChangeEvidence struct {
+	ssid        string
+	submitters  map[string]bool
+	feedbackIDs []string
+}
+
+// UpdateWiFiAPSuggestions aggregates the unresolved S2LS feedback into suggested changes to the WiFi APs of the stations.
+// APs seen where the client failed to detect a station are suggested for addition to the station the user was in,
+// as long as their SSID is one already present in the dataset (so that hotspots and the like aren't suggested).
+// APs that led the client to detect the wrong station are suggested for removal from that station.
+// Only changes supported by the feedback of at least minSubmitters API pairs are suggested.
+// Pending suggestions no longer supported by enough feedback are deleted; reviewed suggestions are kept
+func UpdateWiFiAPSuggestions(node sqalx.Node, minSubmitters int) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dataset, err := loadWiFiAPDataset(tx)
+	if err != nil {
+		return err
+	}
+
+	feedbacks, err := types.GetUnresolvedFeedbacksWithType(tx, types.S2LSincorrectDetection)
+	if err != nil {
+		return err
+	}
+
+	evidence := make(map[wiFiAPChange]*wiFiAPChangeEvidence)
+	addEvidence := func(change wiFiAPChange, ssid string, feedback *types.Feedback) {
+		e, present := evidence[change]
+		if !present {
+			e = &wiFiAPChangeEvidence{
+				submitters: make(map[string]bool),
+			}
+			evidence[change] = e
+		}
+		e.ssid = ssid
+		e.submitters[feedback.Submitter.Key] = true
+		if len(e.feedbackIDs) == 0 || e.feedbackIDs[len(e.feedbackIDs)-1] != feedback.ID {
+			e.feedbackIDs = append(e.feedbackIDs, feedback.ID)
+		}
+	}
+
+	for _, feedback := range feedbacks {
+		contents, err := ParseS2LSFeedback(feedback)
+		if err != nil {
+			// feedback submitted by old clients, or otherwise malformed. Leave it for the humans
+			continue
+		}
+		_, expectedExists := dataset.stations[contents.ExpectedStation]
+		_, detectedExists := dataset.stations[contents.DetectedStation]
+		for _, network := range contents.WiFiNetworks {
+			if expectedExists && !dataset.links[network.BSSID][contents.ExpectedStation] && dataset.ssids[network.SSID] {
+				addEvidence(wiFiAPChange{network.BSSID, contents.ExpectedStation, types.WiFiAPSuggestionAdd}, network.SSID, feedback)
+			}
+			if detectedExists && dataset.links[network.BSSID][contents.DetectedStation] {
+				addEvidence(wiFiAPChange{network.BSSID, contents.DetectedStation, types.WiFiAPSuggestionRemove}, network.SSID, feedback)
+			}
+		}
+	}
+
+	supported := make(map[wiFiAPChange]*wiFiAPChangeSupport)
+	for change, e := range evidence {
+		if len(e.submitters) < minSubmitters {
+			continue
+		}
+		supported[change] = &wiFiAPChangeSupport{
+			ssid:        e.ssid,
+			submitters:  len(e.submitters),
+			feedbackIDs: e.feedbackIDs,
+		}
+	}
+
+	err = storeWiFiAPSuggestions(tx, dataset, types.WiFiAPSuggestionFromFeedback, supported)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,629 @@
+package compute
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	// schematicMapGridSize is the distance in pixels between consecutive stations
+	schematicMapGridSize = 40
+	// schematicMapMargin is the space in pixels around the map, which leaves room for the labels
+	schematicMapMargin = 120
+)
+
+// schematicMapDirections are the directions a line can take, in order of preference
+var schematicMapDirections = [][2]float64{
+	{0, 1}, {1, 0}, {1, 1}, {1, -1}, {0, -1}, {-1, 0}, {-1, -1}, {-1, 1},
+}
+
+// SchematicMap is a schematic layout of a network, where stations are placed in a grid
+// and lines only go horizontally, vertically or diagonally between interchanges
+type SchematicMap struct {
+	Network   *types.Network
+	Lines     []*types.Line
+	Stations  map[string]*types.Station
+	Positions map[string][2]float64
+	// LineStations contains the stations of each line, in order, indexed by line ID
+	LineStations  map[string][]*types.Station
+	Interchanges  map[string]bool
+	ClosedStation map[string]bool
+	DisturbedLine map[string]bool
+}
+
+// NewSchematicMap lays out a schematic map of the specified network, from the order of the stations
+// of each line and the interchanges between them
+func NewSchematicMap(node sqalx.Node, network *types.Network) (*SchematicMap, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	m := &SchematicMap{
+		Network:       network,
+		Stations:      make(map[string]*types.Station),
+		Positions:     make(map[string][2]float64),
+		LineStations:  make(map[string][]*types.Station),
+		Interchanges:  make(map[string]bool),
+		ClosedStation: make(map[string]bool),
+		DisturbedLine: make(map[string]bool),
+	}
+
+	m.Lines, err = network.Lines(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range m.Lines {
+		stations, err := line.Stations(tx)
+		if err != nil {
+			return nil, err
+		}
+		m.LineStations[line.ID] = stations
+		for _, station := range stations {
+			m.Stations[station.ID] = station
+			closed, err := station.Closed(tx)
+			if err != nil {
+				return nil, err
+			}
+			m.ClosedStation[station.ID] = closed
+		}
+		disturbances, err := line.OngoingDisturbances(tx, false)
+		if err != nil {
+			return nil, err
+		}
+		m.DisturbedLine[line.ID] = len(disturbances) > 0
+	}
+
+	transfers, err := types.GetTransfers(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		m.Interchanges[transfer.Station.ID] = true
+	}
+
+	m.layout()
+	return m, nil
+}
+
+// layout places the longest line first, and then, repeatedly, the line with the most stations already placed
+func (m *SchematicMap) layout() {
+	placed := make(map[string]bool)
+	for len(placed) < len(m.Lines) {
+		var best *types.Line
+		bestAnchors := -1
+		for _, line := range m.Lines {
+			if placed[line.ID] {
+				continue
+			}
+			anchors := 0
+			for _, station := range m.LineStations[line.ID] {
+				if _, present := m.Positions[station.ID]; present {
+					anchors++
+				}
+			}
+			if anchors > bestAnchors || (anchors == bestAnchors && len(m.LineStations[line.ID]) > len(m.LineStations[best.ID])) {
+				best = line
+				bestAnchors = anchors
+			}
+		}
+		m.placeLine(m.LineStations[best.ID])
+		placed[best.ID] = true
+	}
+}
+
+func (m *SchematicMap) placeLine(stations []*types.Station) {
+	if len(stations) == 0 {
+		return
+	}
+	anchors := []int{}
+	for i, station := range stations {
+		if _, present := m.Positions[station.ID]; present {
+			anchors = append(anchors, i)
+		}
+	}
+	if len(anchors) == 0 {
+		// not connected to what was placed so far, start below it
+		maxY := -2.0
+		for _, pos := range m.Positions {
+			maxY = math.Max(maxY, pos[1])
+		}
+		m.Positions[stations[0].ID] = [2]float64{0, maxY + 2}
+		anchors = []int{0}
+	}
+
+	var bestPositions map[string][2]float64
+	bestCollisions := -1
+	for _, direction := range schematicMapDirections {
+		positions := m.extendLine(stations, anchors, direction)
+		collisions := 0
+		for id, pos := range positions {
+			for otherID, other := range m.Positions {
+				if otherID != id && math.Abs(pos[0]-other[0]) < 0.5 && math.Abs(pos[1]-other[1]) < 0.5 {
+					collisions++
+				}
+			}
+		}
+		if bestCollisions < 0 || collisions < bestCollisions {
+			bestPositions = positions
+			bestCollisions = collisions
+		}
+	}
+	for id, pos := range bestPositions {
+		m.Positions[id] = pos
+	}
+}
+
+// extendLine returns the positions of the stations of a line that were not placed yet, when the line
+// goes through the already placed anchors and extends beyond them in the specified direction
+func (m *SchematicMap) extendLine(stations []*types.Station, anchors []int, direction [2]float64) map[string][2]float64 {
+	positions := make(map[string][2]float64)
+	first := m.Positions[stations[anchors[0]].ID]
+	for i := 0; i < anchors[0]; i++ {
+		steps := float64(anchors[0] - i)
+		positions[stations[i].ID] = [2]float64{first[0] - direction[0]*steps, first[1] - direction[1]*steps}
+	}
+	for a := 0; a+1 < len(anchors); a++ {
+		path := schematicMapPath(m.Positions[stations[anchors[a]].ID], m.Positions[stations[anchors[a+1]].ID])
+		span := float64(anchors[a+1] - anchors[a])
+		for i := anchors[a] + 1; i < anchors[a+1]; i++ {
+			positions[stations[i].ID] = schematicMapPointAlong(path, float64(i-anchors[a])/span)
+		}
+	}
+	last := m.Positions[stations[anchors[len(anchors)-1]].ID]
+	for i := anchors[len(anchors)-1] + 1; i < len(stations); i++ {
+		steps := float64(i - anchors[len(anchors)-1])
+		positions[stations[i].ID] = [2]float64{last[0] + direction[0]*steps, last[1] + direction[1]*steps}
+	}
+	return positions
+}
+
+// schematicMapPath returns the points of an octilinear path between two points: a diagonal segment followed by
+// a horizontal or vertical one, like in most schematic maps
+func schematicMapPath(from, to [2]float64) [][2]float64 {
+	dx, dy := to[0]-from[0], to[1]-from[1]
+	diagonal := math.Min(math.Abs(dx), math.Abs(dy))
+	if diagonal < 1e-9 || math.Abs(math.Abs(dx)-math.Abs(dy)) < 1e-9 {
+		return [][2]float64{from, to}
+	}
+	bend := [2]float64{from[0] + math.Copysign(diagonal, dx), from[1] + math.Copysign(diagonal, dy)}
+	return [][2]float64{from, bend, to}
+}
+
+// schematicMapPointAlong returns the point at fraction f of the length of a path
+func schematicMapPointAlong(path [][2]float64, f float64) [2]float64 {
+	length := 0.0
+	for i := 0; i+1 < len(path); i++ {
+		length += math.Hypot(path[i+1][0]-path[i][0], path[i+1][1]-path[i][1])
+	}
+	remaining := f * length
+	for i := 0; i+1 < len(path); i++ {
+		segment := math.Hypot(path[i+1][0]-path[i][0], path[i+1][1]-path[i][1])
+		if remaining <= segment && segment > 0 {
+			g := remaining / segment
+			return [2]float64{path[i][0] + (path[i+1][0]-path[i][0])*g, path[i][1] + (path[i+1][1]-path[i][1])*g}
+		}
+		remaining -= segment
+	}
+	return path[len(path)-1]
+}
+
+// trainPosition returns the position of a train in the map, or false if it can't be placed
+func (m *SchematicMap) trainPosition(node sqalx.Node, eta *types.VehicleETA) (*types.Line, [2]float64, bool) {
+	line, err := eta.VehicleIDgetLine(node)
+	if err != nil {
+		return nil, [2]float64{}, false
+	}
+	stations := m.LineStations[line.ID]
+	index := -1
+	for i, station := range stations {
+		if station.ID == eta.Station.ID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, [2]float64{}, false
+	}
+	pos := m.Positions[eta.Station.ID]
+	if eta.LiveETA() <= 0 {
+		return line, pos, true
+	}
+
+	// the train is coming from the previous station in its direction
+	previous := index + 1
+	if eta.Direction.ID == stations[len(stations)-1].ID {
+		previous = index - 1
+	}
+	if previous < 0 || previous >= len(stations) {
+		return line, pos, true
+	}
+	typicalSeconds := 120.0
+	if connection, err := types.GetConnection(node, stations[previous].ID, eta.Station.ID, true); err == nil && connection.TypicalSeconds > 0 {
+		typicalSeconds = float64(connection.TypicalSeconds)
+	}
+	f := math.Min(1, eta.LiveETA().Seconds()/typicalSeconds)
+	from := m.Positions[stations[previous].ID]
+	return line, [2]float64{pos[0] + (from[0]-pos[0])*f, pos[1] + (from[1]-pos[1])*f}, true
+}
+
+// schematicMapShapes are the shapes of a schematic map, in pixels, which are drawn by both the SVG and PNG renderers
+type schematicMapShapes struct {
+	width    float64
+	height   float64
+	lines    []schematicMapLineShape
+	stations []schematicMapStationShape
+	trains   []schematicMapTrainShape
+}
+
+type schematicMapLineShape struct {
+	line      *types.Line
+	points    [][2]float64
+	disturbed bool
+}
+
+type schematicMapStationShape struct {
+	station *types.Station
+	center  [2]float64
+	radius  float64
+	closed  bool
+}
+
+type schematicMapTrainShape struct {
+	id     string
+	line   *types.Line
+	center [2]float64
+}
+
+// shapes projects the map to pixels, with the current state of the lines and stations
+// and the positions of the trains, which may be nil
+func (m *SchematicMap) shapes(node sqalx.Node, trains map[string]*types.VehicleETA) (*schematicMapShapes, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, pos := range m.Positions {
+		minX, minY = math.Min(minX, pos[0]), math.Min(minY, pos[1])
+		maxX, maxY = math.Max(maxX, pos[0]), math.Max(maxY, pos[1])
+	}
+	if len(m.Positions) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 0, 0
+	}
+	project := func(pos [2]float64) [2]float64 {
+		return [2]float64{schematicMapMargin + (pos[0]-minX)*schematicMapGridSize, schematicMapMargin + (pos[1]-minY)*schematicMapGridSize}
+	}
+	s := &schematicMapShapes{
+		width:  2*schematicMapMargin + (maxX-minX)*schematicMapGridSize,
+		height: 2*schematicMapMargin + (maxY-minY)*schematicMapGridSize,
+	}
+
+	for _, line := range m.Lines {
+		shape := schematicMapLineShape{
+			line:      line,
+			disturbed: m.DisturbedLine[line.ID],
+		}
+		stations := m.LineStations[line.ID]
+		for i, station := range stations {
+			path := [][2]float64{m.Positions[station.ID]}
+			if i+1 < len(stations) {
+				// the last point is the next station, which is added in the next iteration
+				path = schematicMapPath(m.Positions[station.ID], m.Positions[stations[i+1].ID])
+				path = path[:len(path)-1]
+			}
+			for _, point := range path {
+				shape.points = append(shape.points, project(point))
+			}
+		}
+		s.lines = append(s.lines, shape)
+	}
+
+	ids := make([]string, 0, len(m.Stations))
+	for id := range m.Stations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		shape := schematicMapStationShape{
+			station: m.Stations[id],
+			center:  project(m.Positions[id]),
+			radius:  6,
+			closed:  m.ClosedStation[id],
+		}
+		if m.Interchanges[id] {
+			shape.radius = 9
+		}
+		s.stations = append(s.stations, shape)
+	}
+
+	trainIDs := make([]string, 0, len(trains))
+	for id := range trains {
+		trainIDs = append(trainIDs, id)
+	}
+	sort.Strings(trainIDs)
+	for _, id := range trainIDs {
+		line, pos, ok := m.trainPosition(tx, trains[id])
+		if !ok {
+			continue
+		}
+		s.trains = append(s.trains, schematicMapTrainShape{
+			id:     id,
+			line:   line,
+			center: project(pos),
+		})
+	}
+	return s, nil
+}
+
+// RenderSVG draws the map in the SVG format, with the current state of the lines and stations
+// and the positions of the trains, which may be nil
+func (m *SchematicMap) RenderSVG(node sqalx.Node, trains map[string]*types.VehicleETA) ([]byte, error) {
+	s, err := m.shapes(node, trains)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif">`,
+		s.width, s.height, s.width, s.height)
+	fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(m.Network.Name))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="#fff"/>`)
+
+	for _, shape := range s.lines {
+		points := ""
+		for _, point := range shape.points {
+			points += fmt.Sprintf("%.1f,%.1f ", point[0], point[1])
+		}
+		style := ""
+		if shape.disturbed {
+			style = ` stroke-dasharray="10 6" stroke-opacity="0.6"`
+		}
+		fmt.Fprintf(&b, `<polyline id="line-%s" points="%s" fill="none" stroke="#%s" stroke-width="8" stroke-linejoin="round" stroke-linecap="round"%s><title>%s</title></polyline>`,
+			html.EscapeString(shape.line.ID), points, html.EscapeString(shape.line.Color), style, html.EscapeString(shape.line.Name))
+	}
+
+	for _, shape := range s.stations {
+		x, y := shape.center[0], shape.center[1]
+		fill, textStyle := "#fff", ""
+		if shape.closed {
+			fill = "#bbb"
+			textStyle = ` fill="#888" text-decoration="line-through"`
+		}
+		fmt.Fprintf(&b, `<g id="station-%s"><circle cx="%.1f" cy="%.1f" r="%.0f" fill="%s" stroke="#000" stroke-width="2"/>`,
+			html.EscapeString(shape.station.ID), x, y, shape.radius, fill)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="11"%s>%s</text></g>`,
+			x+shape.radius+4, y-shape.radius-2, textStyle, html.EscapeString(shape.station.Name))
+	}
+
+	for _, shape := range s.trains {
+		fmt.Fprintf(&b, `<circle class="train" cx="%.1f" cy="%.1f" r="5" fill="#%s" stroke="#000" stroke-width="1.5"><title>%s</title></circle>`,
+			shape.center[0], shape.center[1], html.EscapeString(shape.line.Color), html.EscapeString(shape.id))
+	}
+
+	b.WriteString("</svg>")
+	return b.Bytes(), nil
+}
+
+// RenderPNG draws the map in the PNG format, for clients that can't display SVG images, like Discord.
+// The result looks like the one of RenderSVG
+func (m *SchematicMap) RenderPNG(node sqalx.Node, trains map[string]*types.VehicleETA) ([]byte, error) {
+	s, err := m.shapes(node, trains)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    11,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	c := newSchematicMapCanvas(int(math.Ceil(s.width)), int(math.Ceil(s.height)))
+
+	for _, shape := range s.lines {
+		lineColor := parseSchematicMapColor(shape.line.Color)
+		dashes := []float64{}
+		if shape.disturbed {
+			// a 60% opaque line over the white background
+			lineColor = schematicMapBlend(lineColor, color.White, 0.6)
+			dashes = []float64{10, 6}
+		}
+		for _, segment := range schematicMapDashes(shape.points, dashes) {
+			c.segment(segment[0], segment[1], 8, lineColor)
+		}
+	}
+
+	for _, shape := range s.stations {
+		fill, textColor := color.Color(color.White), color.Color(color.Black)
+		if shape.closed {
+			fill = color.RGBA{0xbb, 0xbb, 0xbb, 0xff}
+			textColor = color.RGBA{0x88, 0x88, 0x88, 0xff}
+		}
+		c.circle(shape.center, shape.radius+1, color.Black)
+		c.circle(shape.center, shape.radius-1, fill)
+
+		d := &font.Drawer{
+			Dst:  c.img,
+			Src:  image.NewUniform(textColor),
+			Face: face,
+			Dot:  fixed.P(int(math.Round(shape.center[0]+shape.radius+4)), int(math.Round(shape.center[1]-shape.radius-2))),
+		}
+		start := d.Dot
+		d.DrawString(shape.station.Name)
+		if shape.closed {
+			y := float64(start.Y.Round()) - 3.5
+			c.segment([2]float64{float64(start.X.Round()), y}, [2]float64{float64(d.Dot.X.Round()), y}, 1, textColor)
+		}
+	}
+
+	for _, shape := range s.trains {
+		c.circle(shape.center, 5.75, color.Black)
+		c.circle(shape.center, 4.25, parseSchematicMapColor(shape.line.Color))
+	}
+
+	var b bytes.Buffer
+	err = png.Encode(&b, c.img)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// schematicMapCanvas rasterises the shapes of a schematic map with anti-aliasing
+type schematicMapCanvas struct {
+	img *image.RGBA
+	r   *vector.Rasterizer
+}
+
+func newSchematicMapCanvas(width, height int) *schematicMapCanvas {
+	c := &schematicMapCanvas{
+		img: image.NewRGBA(image.Rect(0, 0, width, height)),
+		r:   vector.NewRasterizer(width, height),
+	}
+	draw.Draw(c.img, c.img.Bounds(), image.White, image.Point{}, draw.Src)
+	return c
+}
+
+// fill draws the path added to the rasterizer by the specified function.
+// Each shape is drawn on its own because the rasterizer cancels out overlapping paths with opposite orientations
+func (c *schematicMapCanvas) fill(fillColor color.Color, path func(r *vector.Rasterizer)) {
+	c.r.Reset(c.img.Bounds().Dx(), c.img.Bounds().Dy())
+	path(c.r)
+	c.r.Draw(c.img, c.img.Bounds(), image.NewUniform(fillColor), image.Point{})
+}
+
+func (c *schematicMapCanvas) circle(center [2]float64, radius float64, fillColor color.Color) {
+	// control point distance for approximating a quarter of a circle with a cubic Bézier curve
+	k := radius * 0.5523
+	x, y, r := float32(center[0]), float32(center[1]), float32(radius)
+	kk := float32(k)
+	c.fill(fillColor, func(rz *vector.Rasterizer) {
+		rz.MoveTo(x+r, y)
+		rz.CubeTo(x+r, y+kk, x+kk, y+r, x, y+r)
+		rz.CubeTo(x-kk, y+r, x-r, y+kk, x-r, y)
+		rz.CubeTo(x-r, y-kk, x-kk, y-r, x, y-r)
+		rz.CubeTo(x+kk, y-r, x+r, y-kk, x+r, y)
+		rz.ClosePath()
+	})
+}
+
+// segment draws a line segment with round caps, so that consecutive segments have round joins
+func (c *schematicMapCanvas) segment(from, to [2]float64, width float64, strokeColor color.Color) {
+	length := math.Hypot(to[0]-from[0], to[1]-from[1])
+	if length > 0 {
+		nx, ny := -(to[1]-from[1])/length*width/2, (to[0]-from[0])/length*width/2
+		c.fill(strokeColor, func(rz *vector.Rasterizer) {
+			rz.MoveTo(float32(from[0]+nx), float32(from[1]+ny))
+			rz.LineTo(float32(to[0]+nx), float32(to[1]+ny))
+			rz.LineTo(float32(to[0]-nx), float32(to[1]-ny))
+			rz.LineTo(float32(from[0]-nx), float32(from[1]-ny))
+			rz.ClosePath()
+		})
+	}
+	c.circle(from, width/2, strokeColor)
+	c.circle(to, width/2, strokeColor)
+}
+
+// schematicMapDashes splits a polyline into the segments that are drawn with the specified dash pattern,
+// which alternates between the lengths of the dashes and of the gaps. An empty pattern draws the whole polyline
+func schematicMapDashes(points [][2]float64, pattern []float64) [][2][2]float64 {
+	segments := [][2][2]float64{}
+	if len(pattern) == 0 {
+		for i := 0; i+1 < len(points); i++ {
+			segments = append(segments, [2][2]float64{points[i], points[i+1]})
+		}
+		if len(points) == 1 {
+			segments = append(segments, [2][2]float64{points[0], points[0]})
+		}
+		return segments
+	}
+
+	index, remaining := 0, pattern[0]
+	for i := 0; i+1 < len(points); i++ {
+		from, to := points[i], points[i+1]
+		length := math.Hypot(to[0]-from[0], to[1]-from[1])
+		done := 0.0
+		for done < length {
+			step := math.Min(remaining, length-done)
+			if index%2 == 0 {
+				a := schematicMapPointAlong([][2]float64{from, to}, done/length)
+				b := schematicMapPointAlong([][2]float64{from, to}, (done+step)/length)
+				segments = append(segments, [2][2]float64{a, b})
+			}
+			done += step
+			remaining -= step
+			if remaining <= 0 {
+				index = (index + 1) % len(pattern)
+				remaining = pattern[index]
+			}
+		}
+	}
+	return segments
+}
+
+// parseSchematicMapColor parses a color in the RRGGBB format used by Line.Color, returning black if it is invalid
+func parseSchematicMapColor(s string) color.RGBA {
+	c, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || len(c) != 3 {
+		return color.RGBA{0, 0, 0, 0xff}
+	}
+	return color.RGBA{c[0], c[1], c[2], 0xff}
+}
+
+// schematicMapBlend returns the color obtained by drawing c with the specified opacity over the background
+func schematicMapBlend(c color.RGBA, background color.Color, opacity float64) color.RGBA {
+	br, bg, bb, _ := background.RGBA()
+	blend := func(v uint8, b uint32) uint8 {
+		return uint8(math.Round(float64(v)*opacity + float64(b>>8)*(1-opacity)))
+	}
+	return color.RGBA{blend(c.R, br), blend(c.G, bg), blend(c.B, bb), 0xff}
+}
+
+// RenderSchematicMapSVG lays out and draws the schematic map of a network in the SVG format,
+// including the positions of the trains, which may be nil
+func RenderSchematicMapSVG(node sqalx.Node, network *types.Network, trains map[string]*types.VehicleETA) ([]byte, error) {
+	m, err := NewSchematicMap(node, network)
+	if err != nil {
+		return nil, err
+	}
+	return m.RenderSVG(node, trains)
+}
+
+// RenderSchematicMapPNG lays out and draws the schematic map of a network in the PNG format,
+// including the positions of the trains, which may be nil
+func RenderSchematicMapPNG(node sqalx.Node, network *types.Network, trains map[string]*types.VehicleETA) ([]byte, error) {
+	m, err := NewSchematicMap(node, network)
+	if err != nil {
+		return nil, err
+	}
+	return m.RenderPNG(node, trains)
+}
@@ -0,0 +1,301 @@
+package compute
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// StatusEventType is the type of a StatusEvent
+type StatusEventType string
+
+const (
+	// StatusEventNewStatus is the type of the events sent when a line has a new status
+	StatusEventNewStatus StatusEventType = "status"
+	// StatusEventDisturbanceOpen is the type of the events sent when a disturbance begins
+	StatusEventDisturbanceOpen StatusEventType = "disturbance-open"
+	// StatusEventDisturbanceClose is the type of the events sent when a disturbance ends
+	StatusEventDisturbanceClose StatusEventType = "disturbance-close"
+	// StatusEventNewCondition is the type of the events sent when a line has a new condition
+	StatusEventNewCondition StatusEventType = "condition"
+
+	// statusEventReplayLimit is the maximum number of statuses and conditions replayed when resuming
+	statusEventReplayLimit = 200
+	// statusEventSubscriberBuffer is the number of events that can be queued for a subscriber before it is dropped
+	statusEventSubscriberBuffer = 50
+	// statusEventKeepAliveInterval is the interval between comments sent to keep idle streams open
+	statusEventKeepAliveInterval = 30 * time.Second
+)
+
+// ErrStreamingUnsupported is returned when a ResponseWriter can't be used to stream events
+var ErrStreamingUnsupported = errors.New("Streaming unsupported")
+
+// StatusEvent is a change in the status of a line.
+// Events derived from a status share the ID of the status; condition events have the ID of the condition
+type StatusEvent struct {
+	ID          string
+	Type        StatusEventType
+	Time        time.Time
+	Line        *types.Line
+	Status      *types.Status
+	Disturbance *types.Disturbance
+	Condition   *types.LineCondition
+}
+
+// StatusEventBroker distributes StatusEvents to subscribers, such as Server-Sent Events streams
+type StatusEventBroker struct {
+	node        sqalx.Node
+	mutex       sync.Mutex
+	subscribers map[chan StatusEvent]struct{}
+}
+
+// NewStatusEventBroker returns a new, initialized StatusEventBroker
+func NewStatusEventBroker(node sqalx.Node) *StatusEventBroker {
+	return &StatusEventBroker{
+		node:        node,
+		subscribers: make(map[chan StatusEvent]struct{}),
+	}
+}
+
+// Subscribe returns a channel where new events will be sent.
+// The channel is closed if the subscriber doesn't keep up with the events, or when Unsubscribe is called
+func (b *StatusEventBroker) Subscribe() chan StatusEvent {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	ch := make(chan StatusEvent, statusEventSubscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops sending events to a channel returned by Subscribe
+func (b *StatusEventBroker) Unsubscribe(ch chan StatusEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if _, present := b.subscribers[ch]; present {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+func (b *StatusEventBroker) publish(events []StatusEvent) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for ch := range b.subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+				// slow subscriber. it can resume using the ID of the last event it received
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+			if _, present := b.subscribers[ch]; !present {
+				break
+			}
+		}
+	}
+}
+
+// PublishStatus sends the events for a status that was just added to a line
+func (b *StatusEventBroker) PublishStatus(status *types.Status) error {
+	events, err := statusEvents(b.node, status)
+	if err != nil {
+		return err
+	}
+	b.publish(events)
+	return nil
+}
+
+// PublishCondition sends the event for a condition that was just added to a line
+func (b *StatusEventBroker) PublishCondition(condition *types.LineCondition) {
+	b.publish([]StatusEvent{conditionEvent(condition)})
+}
+
+// EventsSince returns the events that happened after the event with the specified ID, in chronological order
+func (b *StatusEventBroker) EventsSince(lastEventID string) ([]StatusEvent, error) {
+	tx, err := b.node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	var since time.Time
+	if status, err := types.GetStatus(tx, lastEventID); err == nil {
+		since = status.Time
+	} else if condition, err := types.GetLineCondition(tx, lastEventID); err == nil {
+		since = condition.Time
+	} else {
+		// unknown event, nothing to replay
+		return []StatusEvent{}, nil
+	}
+
+	// fetch one more of each than the limit, so that we know where the first omitted item is
+	statuses, err := types.GetStatusesAfter(tx, since, statusEventReplayLimit+1)
+	if err != nil {
+		return nil, err
+	}
+	conditions, err := types.GetLineConditionsAfter(tx, since, statusEventReplayLimit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	type replayItem struct {
+		time      time.Time
+		status    *types.Status
+		condition *types.LineCondition
+	}
+	items := []replayItem{}
+	for _, status := range statuses {
+		items = append(items, replayItem{time: status.Time, status: status})
+	}
+	for _, condition := range conditions {
+		items = append(items, replayItem{time: condition.Time, condition: condition})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].time.Before(items[j].time)
+	})
+	if len(items) > statusEventReplayLimit {
+		// clients resume from the time of the last event they received, so we can't stop in the middle of
+		// items sharing the same time, or those left out would never be replayed
+		cut := statusEventReplayLimit
+		for cut > 0 && !items[cut-1].time.Before(items[statusEventReplayLimit].time) {
+			cut--
+		}
+		if cut == 0 {
+			// all items share the same time, there's no better place to stop
+			cut = statusEventReplayLimit
+		}
+		items = items[:cut]
+	}
+
+	events := []StatusEvent{}
+	for _, item := range items {
+		if item.condition != nil {
+			events = append(events, conditionEvent(item.condition))
+			continue
+		}
+		statusEvents, err := statusEvents(tx, item.status)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, statusEvents...)
+	}
+	return events, nil
+}
+
+// Stream sends events to a client using Server-Sent Events, until the client disconnects or falls behind.
+// If the client is resuming, the events it missed are sent first. The write function must write a single event.
+// An error is only returned if the stream could not be started, in which case nothing was written
+func (b *StatusEventBroker) Stream(w http.ResponseWriter, r *http.Request, write func(StatusEvent) error) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrStreamingUnsupported
+	}
+
+	// subscribe before replaying so no events are missed in between
+	ch := b.Subscribe()
+	defer b.Unsubscribe(ch)
+
+	replay := []StatusEvent{}
+	if lastEventID := utils.LastServerSentEventID(r); lastEventID != "" {
+		var err error
+		replay, err = b.EventsSince(lastEventID)
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// disable buffering in nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// retry is in milliseconds
+	w.Write([]byte("retry: 5000\n\n"))
+	flusher.Flush()
+
+	// events derived from the same status share its ID, so they are told apart by their type
+	replayed := make(map[string]bool)
+	for _, event := range replay {
+		if err := write(event); err != nil {
+			return nil
+		}
+		replayed[event.ID+"/"+string(event.Type)] = true
+	}
+
+	keepAlive := time.NewTicker(statusEventKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				// we fell behind. the client will reconnect and resume
+				return nil
+			}
+			if replayed[event.ID+"/"+string(event.Type)] {
+				continue
+			}
+			if err := write(event); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			// comments are ignored by clients
+			if _, err := w.Write([]byte(": keepalive\n\n")); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return nil
+		}
+	}
+}
+
+// statusEvents returns the events caused by a status: the status itself and, if applicable,
+// the beginning or end of a disturbance
+func statusEvents(node sqalx.Node, status *types.Status) ([]StatusEvent, error) {
+	events := []StatusEvent{{
+		ID:     status.ID,
+		Type:   StatusEventNewStatus,
+		Time:   status.Time,
+		Line:   status.Line,
+		Status: status,
+	}}
+
+	disturbance, err := types.GetDisturbanceWithStatus(node, status.ID)
+	if err != nil {
+		// statuses that don't belong to a disturbance don't open or close any
+		return events, nil
+	}
+	event := StatusEvent{
+		ID:          status.ID,
+		Time:        status.Time,
+		Line:        status.Line,
+		Status:      status,
+		Disturbance: disturbance,
+	}
+	switch {
+	case disturbance.UStartTime.Equal(status.Time):
+		event.Type = StatusEventDisturbanceOpen
+	case disturbance.UEnded && disturbance.UEndTime.Equal(status.Time):
+		event.Type = StatusEventDisturbanceClose
+	default:
+		return events, nil
+	}
+	return append(events, event), nil
+}
+
+func conditionEvent(condition *types.LineCondition) StatusEvent {
+	return StatusEvent{
+		ID:        condition.ID,
+		Type:      StatusEventNewCondition,
+		Time:      condition.Time,
+		Line:      condition.Line,
+		Condition: condition,
+	}
+}
@@ -0,0 +1,355 @@
+package compute
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// tripNormalizeMaxInferredUses is the maximum number of stations that can be inferred between two consecutive station uses.
+// Longer gaps are left untouched, as they most likely correspond to bogus data rather than missed stations
+const tripNormalizeMaxInferredUses = 10
+
+// tripNormalizer fills gaps in trips using the network graph
+type tripNormalizer struct {
+	node         sqalx.Node
+	outgoing     map[string][]*types.Connection
+	stationLines map[string][]*types.Line
+}
+
+// tripNormalizerGraph caches the outgoing connections of each station, so that the network graph is not rebuilt
+// for every trip that is normalized
+var tripNormalizerGraph map[string][]*types.Connection
+var tripNormalizerGraphLock sync.Mutex
+
+// ClearTripNormalizerCache clears the network graph cached for trip normalization.
+// It must be called when connections change
+func ClearTripNormalizerCache() {
+	tripNormalizerGraphLock.Lock()
+	defer tripNormalizerGraphLock.Unlock()
+	tripNormalizerGraph = nil
+}
+
+func newTripNormalizer(node sqalx.Node) (*tripNormalizer, error) {
+	tripNormalizerGraphLock.Lock()
+	defer tripNormalizerGraphLock.Unlock()
+	if tripNormalizerGraph == nil {
+		connections, err := types.GetConnections(node, true)
+		if err != nil {
+			return nil, err
+		}
+		graph := make(map[string][]*types.Connection)
+		for _, connection := range connections {
+			graph[connection.From.ID] = append(graph[connection.From.ID], connection)
+		}
+		tripNormalizerGraph = graph
+	}
+	return &tripNormalizer{
+		node:         node,
+		outgoing:     tripNormalizerGraph,
+		stationLines: make(map[string][]*types.Line),
+	}, nil
+}
+
+func (n *tripNormalizer) lines(station *types.Station) ([]*types.Line, error) {
+	if lines, ok := n.stationLines[station.ID]; ok {
+		return lines, nil
+	}
+	lines, err := station.Lines(n.node)
+	if err != nil {
+		return nil, err
+	}
+	n.stationLines[station.ID] = lines
+	return lines, nil
+}
+
+// commonLine returns the line serving both stations, giving priority to the preferred line if it is one of them
+func (n *tripNormalizer) commonLine(a, b *types.Station, preferred *types.Line) (*types.Line, error) {
+	aLines, err := n.lines(a)
+	if err != nil {
+		return nil, err
+	}
+	bLines, err := n.lines(b)
+	if err != nil {
+		return nil, err
+	}
+	var found *types.Line
+	for _, aLine := range aLines {
+		for _, bLine := range bLines {
+			if aLine.ID != bLine.ID {
+				continue
+			}
+			if preferred != nil && aLine.ID == preferred.ID {
+				return aLine, nil
+			}
+			if found == nil {
+				found = aLine
+			}
+		}
+	}
+	return found, nil
+}
+
+type normalizeQueueItem struct {
+	station string
+	seconds int
+	index   int
+}
+
+type normalizeQueue []*normalizeQueueItem
+
+func (q normalizeQueue) Len() int           { return len(q) }
+func (q normalizeQueue) Less(i, j int) bool { return q[i].seconds < q[j].seconds }
+func (q normalizeQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *normalizeQueue) Push(x interface{}) {
+	item := x.(*normalizeQueueItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *normalizeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// shortestPath returns the connections in the fastest path between two stations, according to their typical times,
+// or nil if there is no path
+func (n *tripNormalizer) shortestPath(from, to string) []*types.Connection {
+	best := map[string]int{from: 0}
+	via := make(map[string]*types.Connection)
+	q := &normalizeQueue{{station: from}}
+	for q.Len() > 0 {
+		item := heap.Pop(q).(*normalizeQueueItem)
+		if item.station == to {
+			break
+		}
+		if item.seconds > best[item.station] {
+			continue
+		}
+		for _, connection := range n.outgoing[item.station] {
+			seconds := item.seconds + connection.TypicalSeconds + connection.TypicalStopSeconds + 1
+			if s, ok := best[connection.To.ID]; !ok || seconds < s {
+				best[connection.To.ID] = seconds
+				via[connection.To.ID] = connection
+				heap.Push(q, &normalizeQueueItem{station: connection.To.ID, seconds: seconds})
+			}
+		}
+	}
+	if _, ok := via[to]; !ok {
+		return nil
+	}
+	path := []*types.Connection{}
+	for cur := to; cur != from; cur = via[cur].From.ID {
+		path = append([]*types.Connection{via[cur]}, path...)
+	}
+	return path
+}
+
+// fillGap returns the inferred station uses between two consecutive uses that are not directly connected
+func (n *tripNormalizer) fillGap(sourceUse, targetUse *types.StationUse) ([]*types.StationUse, error) {
+	path := n.shortestPath(sourceUse.Station.ID, targetUse.Station.ID)
+	if len(path) < 2 || len(path)-1 > tripNormalizeMaxInferredUses {
+		return nil, nil
+	}
+
+	// interpolate times proportionally to the typical time of each connection
+	totalTypical := 0
+	for _, connection := range path {
+		totalTypical += connection.TypicalSeconds + connection.TypicalStopSeconds + 1
+	}
+	gap := targetUse.EntryTime.Sub(sourceUse.LeaveTime)
+	if gap < 0 {
+		gap = 0
+	}
+
+	var prevLine *types.Line
+	if sourceUse.Type == types.Interchange {
+		prevLine = sourceUse.TargetLine
+	}
+	uses := []*types.StationUse{}
+	elapsed := 0
+	for i, connection := range path[:len(path)-1] {
+		elapsed += connection.TypicalSeconds + connection.TypicalStopSeconds + 1
+		at := sourceUse.LeaveTime.Add(time.Duration(float64(gap) * float64(elapsed) / float64(totalTypical)))
+		use := &types.StationUse{
+			Station:   connection.To,
+			EntryTime: at,
+			LeaveTime: at,
+			Type:      types.GoneThrough,
+			Inferred:  true,
+		}
+
+		inLine, err := n.commonLine(connection.From, connection.To, prevLine)
+		if err != nil {
+			return nil, err
+		}
+		outLine, err := n.commonLine(path[i+1].From, path[i+1].To, inLine)
+		if err != nil {
+			return nil, err
+		}
+		if inLine != nil && outLine != nil && inLine.ID != outLine.ID {
+			use.Type = types.Interchange
+			use.SourceLine = inLine
+			use.TargetLine = outLine
+		}
+		prevLine = outLine
+		uses = append(uses, use)
+	}
+	return uses, nil
+}
+
+// normalize fills in missing stations and interchange lines in a trip. It returns whether the trip was changed
+func (n *tripNormalizer) normalize(trip *types.Trip) (bool, error) {
+	if len(trip.StationUses) <= 1 {
+		return false, nil
+	}
+
+	changed := false
+	uses := []*types.StationUse{trip.StationUses[0]}
+	for useIdx := 1; useIdx < len(trip.StationUses); useIdx++ {
+		sourceUse := trip.StationUses[useIdx-1]
+		targetUse := trip.StationUses[useIdx]
+		if sourceUse.Station.ID != targetUse.Station.ID {
+			if _, err := types.GetConnection(n.node, sourceUse.Station.ID, targetUse.Station.ID, true); err != nil {
+				inferred, err := n.fillGap(sourceUse, targetUse)
+				if err != nil {
+					return false, err
+				}
+				if len(inferred) > 0 {
+					uses = append(uses, inferred...)
+					changed = true
+				}
+			}
+		}
+		uses = append(uses, targetUse)
+	}
+
+	// infer the lines of interchanges where the client left them empty
+	for useIdx, use := range uses {
+		if use.Type != types.Interchange || useIdx == 0 || useIdx == len(uses)-1 {
+			continue
+		}
+		if use.SourceLine == nil {
+			line, err := n.commonLine(uses[useIdx-1].Station, use.Station, nil)
+			if err != nil {
+				return false, err
+			}
+			if line != nil {
+				use.SourceLine = line
+				changed = true
+			}
+		}
+		if use.TargetLine == nil {
+			line, err := n.commonLine(use.Station, uses[useIdx+1].Station, nil)
+			if err != nil {
+				return false, err
+			}
+			if line != nil && (use.SourceLine == nil || line.ID != use.SourceLine.ID) {
+				use.TargetLine = line
+				changed = true
+			}
+		}
+	}
+
+	trip.StationUses = uses
+	return changed, nil
+}
+
+// NormalizeTrip fills in the stations a client missed between two consecutive station uses, along the fastest path,
+// interpolating their times from the typical times of the connections, and infers the lines of interchanges
+// where the client left them empty. Inferred station uses are marked as such.
+// The trip is modified in place but not persisted. It returns whether the trip was changed
+func NormalizeTrip(node sqalx.Node, trip *types.Trip) (bool, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Commit() // read-only tx
+
+	normalizer, err := newTripNormalizer(tx)
+	if err != nil {
+		return false, err
+	}
+	return normalizer.normalize(trip)
+}
+
+// NormalizeAllTrips runs NormalizeTrip over all the trips in the database, persisting the changed ones.
+// Each trip is processed in its own transaction, so the process can be interrupted and restarted at any time
+func NormalizeAllTrips(node sqalx.Node, yieldFor time.Duration) error {
+	tripIDs, err := types.GetTripIDs(node)
+	if err != nil {
+		return err
+	}
+
+	mainLog.Printf("NormalizeAllTrips: %d trip IDs\n", len(tripIDs))
+
+	normalizer, err := newTripNormalizer(node)
+	if err != nil {
+		return err
+	}
+
+	normalizedCount := 0
+	for i, tripID := range tripIDs {
+		err := func() error {
+			tx, err := node.Beginx()
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback()
+
+			trip, err := types.GetTrip(tx, tripID)
+			if err != nil {
+				return err
+			}
+
+			normalizer.node = tx
+			changed, err := normalizer.normalize(trip)
+			if err != nil || !changed {
+				return err
+			}
+
+			validation, err := ValidateTrip(tx, trip)
+			if err != nil {
+				return err
+			}
+			trip.Quality = validation.Quality
+			trip.QualityIssues = validation.Issues
+
+			err = trip.Update(tx)
+			if err != nil {
+				return err
+			}
+
+			err = IngestTripTypicalSeconds(tx, trip)
+			if err != nil {
+				return err
+			}
+			normalizedCount++
+			return tx.Commit()
+		}()
+		if err != nil {
+			// a single broken trip shouldn't stop the backfill
+			mainLog.Printf("NormalizeAllTrips: trip %s: %s\n", tripID, err)
+		}
+
+		if i%5000 == 0 {
+			mainLog.Printf("NormalizeAllTrips: processed %d of %d, %d normalized\n", i, len(tripIDs), normalizedCount)
+		}
+
+		if yieldFor > 0 {
+			time.Sleep(yieldFor)
+		}
+	}
+	mainLog.Printf("NormalizeAllTrips: done, %d normalized\n", normalizedCount)
+	return nil
+}
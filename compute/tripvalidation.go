@@ -0,0 +1,113 @@
+package compute
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// tripMaxPlausibleSpeed is the maximum average speed, in km/h, at which a train can travel between two stations
+const tripMaxPlausibleSpeed = 100
+
+// tripMinTypicalSecondsRatio is the minimum fraction of the typical travel time of a connection
+// that a trip can take to travel it without being considered suspicious
+const tripMinTypicalSecondsRatio = 0.5
+
+// tripMaxPlausibleDuration is the maximum duration of a plausible trip
+const tripMaxPlausibleDuration = 6 * time.Hour
+
+// TripValidation is the result of validating a Trip with ValidateTrip
+type TripValidation struct {
+	// Score is between 0 (certainly bogus) and 1 (no anomalies found)
+	Score   float64
+	Quality types.TripQuality
+	Issues  []string
+}
+
+func (v *TripValidation) addIssue(penalty float64, format string, a ...interface{}) {
+	v.Score *= penalty
+	v.Issues = append(v.Issues, fmt.Sprintf(format, a...))
+}
+
+// ValidateTrip scores the plausibility of a trip against the network graph and the typical travel times.
+// It checks that times are monotonic, that consecutive stations are connected, that interchanges
+// correspond to existing transfers and that travel speeds are physically possible
+func ValidateTrip(node sqalx.Node, trip *types.Trip) (*TripValidation, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	v := &TripValidation{
+		Score:  1,
+		Issues: []string{},
+	}
+
+	if trip.EndTime.Sub(trip.StartTime) > tripMaxPlausibleDuration {
+		v.addIssue(0.5, "trip took %s", trip.EndTime.Sub(trip.StartTime).String())
+	}
+
+	for useIdx, use := range trip.StationUses {
+		if !use.Manual && !use.Inferred && use.LeaveTime.Before(use.EntryTime) {
+			v.addIssue(0.7, "left %s before entering it", use.Station.ID)
+		}
+
+		if use.Type == types.Interchange && use.SourceLine != nil && use.TargetLine != nil {
+			if _, err := types.GetTransfer(tx, use.Station.ID, use.SourceLine.ID, use.TargetLine.ID); err != nil {
+				v.addIssue(0.5, "no transfer at %s from %s to %s", use.Station.ID, use.SourceLine.ID, use.TargetLine.ID)
+			}
+		}
+
+		if useIdx == len(trip.StationUses)-1 {
+			break
+		}
+		nextUse := trip.StationUses[useIdx+1]
+
+		if nextUse.Station.ID == use.Station.ID {
+			v.addIssue(0.8, "%s used twice in a row", use.Station.ID)
+			continue
+		}
+
+		connection, err := types.GetConnection(tx, use.Station.ID, nextUse.Station.ID, true)
+		if err != nil {
+			v.addIssue(0.3, "no connection from %s to %s", use.Station.ID, nextUse.Station.ID)
+			continue
+		}
+
+		if use.Manual || use.Inferred || nextUse.Manual || nextUse.Inferred {
+			// manual path extensions don't contain valid time data
+			continue
+		}
+
+		if nextUse.EntryTime.Before(use.LeaveTime) {
+			v.addIssue(0.6, "entered %s before leaving %s", nextUse.Station.ID, use.Station.ID)
+			continue
+		}
+
+		seconds := nextUse.EntryTime.Sub(use.LeaveTime).Seconds()
+		// stations may be entered and left within the same second when the client did not capture their times correctly
+		if seconds < 1 {
+			continue
+		}
+		if connection.WorldLength > 0 && float64(connection.WorldLength)/1000/(seconds/3600) > tripMaxPlausibleSpeed {
+			v.addIssue(0.1, "travelled from %s to %s at %.0f km/h", use.Station.ID, nextUse.Station.ID,
+				float64(connection.WorldLength)/1000/(seconds/3600))
+		} else if connection.TypicalSeconds > 0 && seconds < float64(connection.TypicalSeconds)*tripMinTypicalSecondsRatio {
+			v.addIssue(0.7, "travelled from %s to %s in %.0f seconds (typically %d)", use.Station.ID, nextUse.Station.ID,
+				seconds, connection.TypicalSeconds)
+		}
+	}
+
+	switch {
+	case v.Score >= 0.65:
+		v.Quality = types.TripQualityGood
+	case v.Score >= 0.25:
+		v.Quality = types.TripQualitySuspicious
+	default:
+		v.Quality = types.TripQualityImplausible
+	}
+	return v, nil
+}
@@ -9,143 +9,174 @@ import (
 	"github.com/underlx/disturbancesmlx/types"
 )
 
-// UpdateTypicalSeconds calculates and updates the TypicalSeconds
-// for all the Connections and Transfers where that can be done using the registered
-// Trips from the past month.
-// Current TypicalSeconds are ignored and discarded.
-func UpdateTypicalSeconds(node sqalx.Node, yieldFor time.Duration) error {
+// TypicalSecondsWindow is the default sliding window of trips considered by UpdateTypicalSeconds
+const TypicalSecondsWindow = 30 * 24 * time.Hour
+
+// typicalSecondsMinHourlySamples is the minimum number of samples for a connection in a weekday and hour
+// for hour-specific typical times to be stored
+const typicalSecondsMinHourlySamples = 3
+
+// typicalSecondsSamples extracts the duration samples for Connections and Transfers contained in a Trip
+func typicalSecondsSamples(node sqalx.Node, trip *types.Trip) ([]*types.TypicalSecondsSample, error) {
+	samples := []*types.TypicalSecondsSample{}
+	if len(trip.StationUses) <= 1 {
+		// station visit or invalid trip
+		// can't extract any data about connections
+		return samples, nil
+	}
+
 	tx, err := node.Beginx()
 	if err != nil {
-		return err
+		return samples, err
 	}
-	defer tx.Rollback()
+	defer tx.Commit() // read-only tx
 
-	startTime := time.Now().AddDate(0, -1, 0)
-	tripIDs, err := types.GetTripIDsBetween(tx, startTime, time.Now())
-	if err != nil {
-		return err
+	newSample := func(kind types.TypicalSecondsKind, station, from, to string, at time.Time, seconds float64) {
+		if loc, err := time.LoadLocation(trip.StationUses[0].Station.Network.Timezone); err == nil {
+			at = at.In(loc)
+		}
+		samples = append(samples, &types.TypicalSecondsSample{
+			Kind:    kind,
+			Station: station,
+			From:    from,
+			To:      to,
+			Day:     time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC),
+			Hour:    at.Hour(),
+			Seconds: seconds,
+		})
 	}
 
-	mainLog.Printf("UpdateTypicalSeconds: %d trip IDs\n", len(tripIDs))
+	for useIdx := 0; useIdx < len(trip.StationUses)-1; useIdx++ {
+		sourceUse := trip.StationUses[useIdx]
 
-	// we can use pointers as keys in the following maps because types implements an internal cache
-	// that ensures the pointers to the transfers stay the same throughout this transaction
-	// (i.e. only one instance of each transfer is brought into memory)
-	transferAvgNumerator := make(map[*types.Transfer]float64)
-	transferAvgDenominator := make(map[*types.Transfer]float64)
+		if sourceUse.Manual || sourceUse.Inferred {
+			// manual path extensions don't contain valid time data
+			// skip
+			continue
+		}
 
-	// we can use pointers as keys in the following maps because types implements an internal cache
-	// that ensures the pointers to the transfers stay the same throughout this transaction
-	// (i.e. only one instance of each connection is brought into memory)
-	connectionAvgNumerator := make(map[*types.Connection]float64)
-	connectionAvgDenominator := make(map[*types.Connection]float64)
-	connectionStopAvgNumerator := make(map[*types.Connection]float64)
-	connectionStopAvgDenominator := make(map[*types.Connection]float64)
-	connectionWaitAvgNumerator := make(map[*types.Connection]float64)
-	connectionWaitAvgDenominator := make(map[*types.Connection]float64)
+		// if this is a transfer, process it
+		if sourceUse.Type == types.Interchange {
+			transfer, err := types.GetTransfer(tx, sourceUse.Station.ID, sourceUse.SourceLine.ID, sourceUse.TargetLine.ID)
+			if err != nil {
+				// transfer might no longer exist (closed stations, etc.)
+				// move on
+				mainLog.Printf("%s: Transfer on %s from %s to %s skipped\n", trip.ID, sourceUse.Station.ID, sourceUse.SourceLine.ID, sourceUse.TargetLine.ID)
+				return samples, nil
+			}
 
-	processTransfer := func(transfer *types.Transfer, use *types.StationUse) error {
-		seconds := use.LeaveTime.Sub(use.EntryTime).Seconds()
-		// if going from one line to another took more than 15 minutes,
-		// probably what really happened was that the client's clock was adjusted
-		// in the meantime, OR the user decided to go shop or something at the station
-		if seconds < 15*60 {
-			transferAvgNumerator[transfer] += seconds - 20
-			transferAvgDenominator[transfer]++
+			seconds := sourceUse.LeaveTime.Sub(sourceUse.EntryTime).Seconds()
+			// if going from one line to another took more than 15 minutes,
+			// probably what really happened was that the client's clock was adjusted
+			// in the meantime, OR the user decided to go shop or something at the station
+			if seconds < 15*60 {
+				newSample(types.TypicalSecondsTransfer, transfer.Station.ID, transfer.From.ID, transfer.To.ID, sourceUse.EntryTime, seconds-20)
+			}
+		}
+
+		targetUse := trip.StationUses[useIdx+1]
+
+		if targetUse.Manual || targetUse.Inferred {
+			// manual path extensions don't contain valid time data
+			// skip
+			continue
+		}
+
+		connection, err := types.GetConnection(tx, sourceUse.Station.ID, targetUse.Station.ID, true)
+		if err != nil {
+			// connection might no longer exist (closed stations, etc.)
+			// move on
+			mainLog.Printf("%s: Connection from %s to %s skipped\n", trip.ID, sourceUse.Station.ID, targetUse.Station.ID)
+			continue
+		}
+		if useIdx+2 < len(trip.StationUses) && trip.StationUses[useIdx+2].EntryTime.Sub(targetUse.EntryTime) < 1*time.Second {
+			// this station use is certainly a forced extension to make up for a station the client did not capture correct times for
+			// skip
+			continue
 		}
-		return nil
-	}
 
-	processConnection := func(connection *types.Connection, sourceUse *types.StationUse, targetUse *types.StationUse) error {
 		seconds := targetUse.EntryTime.Sub(sourceUse.LeaveTime).Seconds()
 		// if going from one station to another took more than 10 minutes,
 		// probably what really happened was that the client's clock was adjusted
 		// in the meantime
 		if seconds < 10*60 {
-			connectionAvgNumerator[connection] += seconds + 20
-			connectionAvgDenominator[connection]++
+			newSample(types.TypicalSecondsConnection, "", connection.From.ID, connection.To.ID, sourceUse.EntryTime, seconds+20)
 		}
 
 		waitSeconds := sourceUse.LeaveTime.Sub(sourceUse.EntryTime).Seconds()
 		if sourceUse.Type == types.NetworkEntry && waitSeconds < 60*3 {
-			connectionWaitAvgNumerator[connection] += waitSeconds - 20
-			connectionWaitAvgDenominator[connection]++
+			newSample(types.TypicalSecondsConnectionWait, "", connection.From.ID, connection.To.ID, sourceUse.EntryTime, waitSeconds-20)
 		} else if sourceUse.Type == types.GoneThrough && waitSeconds < 60*3 {
-			connectionStopAvgNumerator[connection] += waitSeconds - 20
-			connectionStopAvgDenominator[connection]++
+			newSample(types.TypicalSecondsConnectionStop, "", connection.From.ID, connection.To.ID, sourceUse.EntryTime, waitSeconds-20)
 		}
-		return nil
 	}
+	return samples, nil
+}
 
-	processTrip := func(trip *types.Trip) error {
-		if len(trip.StationUses) <= 1 {
-			// station visit or invalid trip
-			// can't extract any data about connections
-			return nil
-		}
-
-		for useIdx := 0; useIdx < len(trip.StationUses)-1; useIdx++ {
-			sourceUse := trip.StationUses[useIdx]
-
-			if sourceUse.Manual {
-				// manual path extensions don't contain valid time data
-				// skip
-				continue
-			}
+// IngestTripTypicalSeconds adds the duration samples of a newly submitted or edited Trip to the
+// running sums used by UpdateTypicalSeconds, replacing the samples previously extracted from the same trip
+func IngestTripTypicalSeconds(node sqalx.Node, trip *types.Trip) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-			// if this is a transfer, process it
-			if sourceUse.Type == types.Interchange {
-				transfer, err := types.GetTransfer(tx, sourceUse.Station.ID, sourceUse.SourceLine.ID, sourceUse.TargetLine.ID)
-				if err != nil {
-					// transfer might no longer exist (closed stations, etc.)
-					// move on
-					mainLog.Printf("%s: Transfer on %s from %s to %s skipped\n", trip.ID, sourceUse.Station.ID, sourceUse.SourceLine.ID, sourceUse.TargetLine.ID)
-					return nil
-				}
+	samples := []*types.TypicalSecondsSample{}
+	// low-quality trips are still marked as ingested, so they are not considered again unless edited
+	if trip.Quality != types.TripQualitySuspicious && trip.Quality != types.TripQualityImplausible {
+		samples, err = typicalSecondsSamples(tx, trip)
+		if err != nil {
+			return err
+		}
+	}
 
-				if err = processTransfer(transfer, sourceUse); err != nil {
-					return err
-				}
-			}
+	err = types.StoreTypicalSecondsSamples(tx, trip.ID, samples)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
-			targetUse := trip.StationUses[useIdx+1]
+type typicalSecondsKey struct {
+	kind     types.TypicalSecondsKind
+	station  string
+	from, to string
+}
 
-			if targetUse.Manual {
-				// manual path extensions don't contain valid time data
-				// skip
-				continue
-			}
+type typicalSecondsAverage struct {
+	numerator   float64
+	denominator int64
+}
 
-			connection, err := types.GetConnection(tx, sourceUse.Station.ID, targetUse.Station.ID, true)
-			if err != nil {
-				// connection might no longer exist (closed stations, etc.)
-				// move on
-				mainLog.Printf("%s: Connection from %s to %s skipped\n", trip.ID, sourceUse.Station.ID, targetUse.Station.ID)
-				continue
-			}
-			if useIdx+2 < len(trip.StationUses) && trip.StationUses[useIdx+2].EntryTime.Sub(targetUse.EntryTime) < 1*time.Second {
-				// this station use is certainly a forced extension to make up for a station the client did not capture correct times for
-				// skip
-				continue
-			}
+func (a typicalSecondsAverage) value() int {
+	return int(math.Round(a.numerator / float64(a.denominator)))
+}
 
-			if err = processConnection(connection, sourceUse, targetUse); err != nil {
-				return err
-			}
-		}
-		return nil
+// UpdateTypicalSeconds calculates and updates the TypicalSeconds
+// for all the Connections and Transfers where that can be done using the registered
+// Trips within the specified sliding window, as well as hour-specific typical times for Connections.
+// Trips are ingested incrementally: only those that were submitted or edited since the last run
+// (and that were not ingested when they were submitted) are processed, each in its own transaction,
+// so an interrupted update resumes where it stopped
+func UpdateTypicalSeconds(node sqalx.Node, window time.Duration, yieldFor time.Duration) error {
+	endTime := time.Now()
+	startTime := endTime.Add(-window)
+
+	tripIDs, err := types.GetTripIDsPendingTypicalSeconds(node, startTime)
+	if err != nil {
+		return err
 	}
 
-	// instantiate each trip from DB individually
-	// (instead of using types.GetTrips)
-	// to reduce memory usage
+	mainLog.Printf("UpdateTypicalSeconds: %d pending trip IDs\n", len(tripIDs))
+
 	for _, tripID := range tripIDs {
-		trip, err := types.GetTrip(tx, tripID)
+		trip, err := types.GetTrip(node, tripID)
 		if err != nil {
 			return err
 		}
 
-		if err = processTrip(trip); err != nil {
+		if err = IngestTripTypicalSeconds(node, trip); err != nil {
 			return err
 		}
 
@@ -154,97 +185,202 @@ func UpdateTypicalSeconds(node sqalx.Node, yieldFor time.Duration) error {
 		}
 	}
 
-	for connection, denominator := range connectionAvgDenominator {
-		if denominator < 2 {
-			// data is not significant enough
-			continue
-		}
-		average := connectionAvgNumerator[connection] / denominator
-		connection.TypicalSeconds = int(math.Round(average))
-		mainLog.Printf("Updating connection from %s to %s with %d (%f)\n", connection.From.ID, connection.To.ID, connection.TypicalSeconds, denominator)
-		err := connection.Update(tx)
-		if err != nil {
-			return err
-		}
+	windowStartDay := time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, time.UTC)
+	err = types.PruneTypicalSeconds(node, windowStartDay)
+	if err != nil {
+		return err
 	}
 
-	for connection, denominator := range connectionStopAvgDenominator {
-		if denominator < 2 {
-			// data is not significant enough
-			continue
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sums, err := types.SumTypicalSecondsBuckets(tx, windowStartDay, endTime.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	averages := make(map[typicalSecondsKey]typicalSecondsAverage)
+	hourlyAverages := make(map[typicalSecondsKey]map[time.Weekday]map[int]typicalSecondsAverage)
+	for _, sum := range sums {
+		key := typicalSecondsKey{sum.Kind, sum.Station, sum.From, sum.To}
+		average := averages[key]
+		average.numerator += sum.Numerator
+		average.denominator += sum.Denominator
+		averages[key] = average
+
+		if hourlyAverages[key] == nil {
+			hourlyAverages[key] = make(map[time.Weekday]map[int]typicalSecondsAverage)
 		}
-		average := connectionStopAvgNumerator[connection] / denominator
-		connection.TypicalStopSeconds = int(math.Round(average))
-		mainLog.Printf("Updating connection from %s to %s with stop %d (%f)\n", connection.From.ID, connection.To.ID, connection.TypicalStopSeconds, denominator)
-		err := connection.Update(tx)
-		if err != nil {
-			return err
+		if hourlyAverages[key][sum.Weekday] == nil {
+			hourlyAverages[key][sum.Weekday] = make(map[int]typicalSecondsAverage)
 		}
+		hourlyAverages[key][sum.Weekday][sum.Hour] = typicalSecondsAverage{sum.Numerator, sum.Denominator}
 	}
 
-	for connection, denominator := range connectionWaitAvgDenominator {
-		if denominator < 2 {
-			// data is not significant enough
-			continue
+	// process keys in a stable order, with connection travel times before stop and waiting times,
+	// and those before transfers, which depend on the stop times
+	keys := []typicalSecondsKey{}
+	for key := range averages {
+		keys = append(keys, key)
+	}
+	kindOrder := map[types.TypicalSecondsKind]int{
+		types.TypicalSecondsConnection:     0,
+		types.TypicalSecondsConnectionStop: 1,
+		types.TypicalSecondsConnectionWait: 2,
+		types.TypicalSecondsTransfer:       3,
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return kindOrder[keys[i].kind] < kindOrder[keys[j].kind]
 		}
-		average := connectionWaitAvgNumerator[connection] / denominator
-		connection.TypicalWaitingSeconds = int(math.Round(average))
-		mainLog.Printf("Updating connection from %s to %s with wait %d (%f)\n", connection.From.ID, connection.To.ID, connection.TypicalWaitingSeconds, denominator)
-		err := connection.Update(tx)
-		if err != nil {
-			return err
+		if keys[i].station != keys[j].station {
+			return keys[i].station < keys[j].station
 		}
-	}
+		if keys[i].from != keys[j].from {
+			return keys[i].from < keys[j].from
+		}
+		return keys[i].to < keys[j].to
+	})
 
-	for transfer, denominator := range transferAvgDenominator {
-		if denominator < 2 {
+	// we can use pointers as keys in the following maps because types implements an internal cache
+	// that ensures the pointers to the connections stay the same throughout this transaction
+	// (i.e. only one instance of each connection is brought into memory)
+	updatedConnections := make(map[*types.Connection]bool)
+	connectionsWithStopData := []*types.Connection{}
+
+	for _, key := range keys {
+		average := averages[key]
+		if average.denominator < 2 {
 			// data is not significant enough
 			continue
 		}
-		average := transferAvgNumerator[transfer] / denominator
 
-		// subtract average of stop times, because the pathfinding algos can't
-		// deal with edges that have different weights depending on where one
-		// "comes from"
+		switch key.kind {
+		case types.TypicalSecondsConnection, types.TypicalSecondsConnectionStop, types.TypicalSecondsConnectionWait:
+			connection, err := types.GetConnection(tx, key.from, key.to, true)
+			if err != nil {
+				// connection might no longer exist (closed stations, etc.)
+				// move on
+				continue
+			}
+			switch key.kind {
+			case types.TypicalSecondsConnection:
+				connection.TypicalSeconds = average.value()
+				mainLog.Printf("Updating connection from %s to %s with %d (%d)\n", connection.From.ID, connection.To.ID, connection.TypicalSeconds, average.denominator)
+			case types.TypicalSecondsConnectionStop:
+				connection.TypicalStopSeconds = average.value()
+				connectionsWithStopData = append(connectionsWithStopData, connection)
+				mainLog.Printf("Updating connection from %s to %s with stop %d (%d)\n", connection.From.ID, connection.To.ID, connection.TypicalStopSeconds, average.denominator)
+			case types.TypicalSecondsConnectionWait:
+				connection.TypicalWaitingSeconds = average.value()
+				mainLog.Printf("Updating connection from %s to %s with wait %d (%d)\n", connection.From.ID, connection.To.ID, connection.TypicalWaitingSeconds, average.denominator)
+			}
+			updatedConnections[connection] = true
+		case types.TypicalSecondsTransfer:
+			transfer, err := types.GetTransfer(tx, key.station, key.from, key.to)
+			if err != nil {
+				// transfer might no longer exist (closed stations, etc.)
+				// move on
+				continue
+			}
+
+			// subtract average of stop times, because the pathfinding algos can't
+			// deal with edges that have different weights depending on where one
+			// "comes from"
+			outgoingDestConnections := []*types.Connection{}
+			for _, connection := range connectionsWithStopData {
+				if connection.From.ID != transfer.Station.ID {
+					continue
+				}
+				lines, err := connection.To.Lines(tx)
+				if err != nil {
+					return err
+				}
+				for _, line := range lines {
+					if line.ID == transfer.To.ID {
+						outgoingDestConnections = append(outgoingDestConnections, connection)
+						break
+					}
+				}
+			}
 
-		outgoingConnections := []*types.Connection{}
-		for connection := range connectionStopAvgDenominator {
-			if connection.From.ID == transfer.Station.ID {
-				outgoingConnections = append(outgoingConnections, connection)
+			avgStopTime := 0
+			for _, connection := range outgoingDestConnections {
+				avgStopTime += connection.TypicalStopSeconds
+			}
+			seconds := average.numerator / float64(average.denominator)
+			if len(outgoingDestConnections) > 0 {
+				seconds -= float64(avgStopTime) / float64(len(outgoingDestConnections))
 			}
-		}
 
-		outgoingDestConnections := []*types.Connection{}
-		for _, connection := range outgoingConnections {
-			lines, err := connection.To.Lines(tx)
+			transfer.TypicalSeconds = int(math.Round(seconds))
+			mainLog.Printf("Updating transfer from %s to %s with %d (%d)\n", transfer.From.ID, transfer.To.ID, transfer.TypicalSeconds, average.denominator)
+			err = transfer.Update(tx)
 			if err != nil {
 				return err
 			}
-			for _, line := range lines {
-				if line.ID == transfer.To.ID {
-					outgoingDestConnections = append(outgoingDestConnections, connection)
-					break
-				}
-			}
 		}
+	}
 
-		avgStopTime := 0
-		for _, connection := range outgoingDestConnections {
-			avgStopTime += connection.TypicalStopSeconds
-		}
-		if len(outgoingDestConnections) > 0 {
-			average -= float64(avgStopTime) / float64(len(outgoingDestConnections))
+	for connection := range updatedConnections {
+		err := connection.Update(tx)
+		if err != nil {
+			return err
 		}
+	}
 
-		transfer.TypicalSeconds = int(math.Round(average))
-		mainLog.Printf("Updating transfer from %s to %s with %d (%f)\n", transfer.From.ID, transfer.To.ID, transfer.TypicalSeconds, denominator)
-		err := transfer.Update(tx)
+	err = types.DeleteAllConnectionHourlyTimes(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key.kind != types.TypicalSecondsConnection {
+			continue
+		}
+		connection, err := types.GetConnection(tx, key.from, key.to, true)
 		if err != nil {
-			return err
+			continue
+		}
+		for weekday, hours := range hourlyAverages[key] {
+			for hour, average := range hours {
+				if average.denominator < typicalSecondsMinHourlySamples {
+					// data is not significant enough
+					continue
+				}
+				times := &types.ConnectionHourlyTimes{
+					Connection:            connection,
+					Weekday:               weekday,
+					Hour:                  hour,
+					TypicalWaitingSeconds: connection.TypicalWaitingSeconds,
+					TypicalStopSeconds:    connection.TypicalStopSeconds,
+					TypicalSeconds:        average.value(),
+				}
+				stopKey := typicalSecondsKey{types.TypicalSecondsConnectionStop, key.station, key.from, key.to}
+				if stop := hourlyAverages[stopKey][weekday][hour]; stop.denominator >= typicalSecondsMinHourlySamples {
+					times.TypicalStopSeconds = stop.value()
+				}
+				waitKey := typicalSecondsKey{types.TypicalSecondsConnectionWait, key.station, key.from, key.to}
+				if wait := hourlyAverages[waitKey][weekday][hour]; wait.denominator >= typicalSecondsMinHourlySamples {
+					times.TypicalWaitingSeconds = wait.value()
+				}
+				err = times.Update(tx)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
-	return tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	ClearTripNormalizerCache()
+	return nil
 }
 
 // TypicalSecondsEntry makes the items of the result of ComputeTypicalSeconds
@@ -367,7 +503,7 @@ func TypicalSecondsByDowAndHour(node sqalx.Node, startTime, endTime time.Time) (
 		for useIdx := 0; useIdx < len(trip.StationUses)-1; useIdx++ {
 			sourceUse := trip.StationUses[useIdx]
 
-			if sourceUse.Manual {
+			if sourceUse.Manual || sourceUse.Inferred {
 				// manual path extensions don't contain valid time data
 				// skip
 				continue
@@ -390,7 +526,7 @@ func TypicalSecondsByDowAndHour(node sqalx.Node, startTime, endTime time.Time) (
 
 			targetUse := trip.StationUses[useIdx+1]
 
-			if targetUse.Manual {
+			if targetUse.Manual || targetUse.Inferred {
 				// manual path extensions don't contain valid time data
 				// skip
 				continue
@@ -104,18 +104,22 @@ func (h *VehicleHandler) NextTrainETA(node sqalx.Node, station *types.Station, d
 	}
 	userAtIdx := cursor
 
+	now := time.Now()
 	getConnectionDuration := func(from, to string) int {
 		h.connectionDurationCacheMutex.Lock()
 		defer h.connectionDurationCacheMutex.Unlock()
-		if s, present := h.connectionDurationCache[from+"#"+to]; present {
+		// typical times are hour-specific, so the hour is part of the cache key
+		cacheKey := fmt.Sprintf("%s#%s#%d#%d", from, to, now.Weekday(), now.Hour())
+		if s, present := h.connectionDurationCache[cacheKey]; present {
 			return s
 		}
 		connection, err := types.GetConnection(tx, from, to, true)
 		if err != nil {
 			return 0
 		}
-		s := connection.TypicalSeconds + connection.TypicalStopSeconds
-		h.connectionDurationCache[from+"#"+to] = s
+		travel, stop := connection.TypicalSecondsAt(tx, now)
+		s := travel + stop
+		h.connectionDurationCache[cacheKey] = s
 		return s
 	}
 
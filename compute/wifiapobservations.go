@@ -0,0 +1,72 @@
+package compute
+
+import (
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// WiFiAPObservationWindow is how long crowd-sourced WiFi AP observations are kept and considered for suggestions
+const WiFiAPObservationWindow = 60 * 24 * time.Hour
+
+// MinWiFiAPObservationStationShare is the minimum fraction of the sightings of an AP that must have happened
+// in the same station, for the AP to be considered as belonging to that station
+const MinWiFiAPObservationStationShare = 0.8
+
+// UpdateWiFiAPSuggestionsFromObservations aggregates the crowd-sourced WiFi AP observations made within the window
+// into suggested additions of APs to stations, and deletes the observations older than the window.
+// An AP is suggested for addition to a station when it was observed there by at least minSubmitters API pairs,
+// when most of its sightings (see MinWiFiAPObservationStationShare) happened in that station, and when its SSID
+// is one already present in the dataset (so that hotspots and the like aren't suggested)
+func UpdateWiFiAPSuggestionsFromObservations(node sqalx.Node, window time.Duration, minSubmitters int) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	since := time.Now().Add(-window)
+	_, err = types.DeleteWiFiAPObservationsOlderThan(tx, since)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := loadWiFiAPDataset(tx)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := types.GetWiFiAPObservationSummariesSince(tx, since)
+	if err != nil {
+		return err
+	}
+
+	// a pair that saw the AP in multiple stations counts once for each,
+	// which errs on the side of not suggesting APs that are seen in many places
+	sightings := make(map[string]int)
+	for _, summary := range summaries {
+		sightings[summary.BSSID] += summary.Submitters
+	}
+
+	supported := make(map[wiFiAPChange]*wiFiAPChangeSupport)
+	for _, summary := range summaries {
+		if _, present := dataset.stations[summary.StationID]; !present ||
+			dataset.links[summary.BSSID][summary.StationID] ||
+			!dataset.ssids[summary.SSID] ||
+			summary.Submitters < minSubmitters ||
+			float64(summary.Submitters)/float64(sightings[summary.BSSID]) < MinWiFiAPObservationStationShare {
+			continue
+		}
+		supported[wiFiAPChange{summary.BSSID, summary.StationID, types.WiFiAPSuggestionAdd}] = &wiFiAPChangeSupport{
+			ssid:       summary.SSID,
+			submitters: summary.Submitters,
+		}
+	}
+
+	err = storeWiFiAPSuggestions(tx, dataset, types.WiFiAPSuggestionFromObservations, supported)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,228 @@
+package compute
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	uuid "github.com/satori/go.uuid"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// DefaultWiFiAPSuggestionMinSubmitters is the default number of distinct API pairs that must have submitted
+// feedback or observations supporting a WiFi AP change, before that change is suggested for review
+const DefaultWiFiAPSuggestionMinSubmitters = 3
+
+type wiFiAPChange struct {
+	bssid     string
+	stationID string
+	action    types.WiFiAPSuggestionAction
+}
+
+type wiFiAPChangeSupport struct {
+	ssid        string
+	submitters  int
+	feedbackIDs []string
+}
+
+// wiFiAPDataset is the WiFi AP data of all stations, indexed for the aggregation of suggestions
+type wiFiAPDataset struct {
+	stations map[string]*types.Station
+	// links[bssid][stationID] is true if the AP is associated with the station
+	links map[string]map[string]bool
+	// ssids contains the SSIDs of all the APs in the dataset
+	ssids map[string]bool
+}
+
+func loadWiFiAPDataset(node sqalx.Node) (*wiFiAPDataset, error) {
+	stations, err := types.GetStations(node)
+	if err != nil {
+		return nil, err
+	}
+	dataset := &wiFiAPDataset{
+		stations: make(map[string]*types.Station),
+		links:    make(map[string]map[string]bool),
+		ssids:    make(map[string]bool),
+	}
+	for _, station := range stations {
+		dataset.stations[station.ID] = station
+		aps, err := station.WiFiAPs(node)
+		if err != nil {
+			return nil, err
+		}
+		for _, ap := range aps {
+			if dataset.links[ap.BSSID] == nil {
+				dataset.links[ap.BSSID] = make(map[string]bool)
+			}
+			dataset.links[ap.BSSID][station.ID] = true
+			dataset.ssids[ap.SSID] = true
+		}
+	}
+	return dataset, nil
+}
+
+// storeWiFiAPSuggestions creates or updates the suggestions from the specified source for the supported changes.
+// Pending suggestions from the same source that are no longer supported are deleted; reviewed suggestions are kept
+func storeWiFiAPSuggestions(node sqalx.Node, dataset *wiFiAPDataset, source types.WiFiAPSuggestionSource, supported map[wiFiAPChange]*wiFiAPChangeSupport) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	existing, err := types.GetUnpublishedWiFiAPSuggestions(tx, source)
+	if err != nil {
+		return err
+	}
+	existingByChange := make(map[wiFiAPChange]*types.WiFiAPSuggestion)
+	for _, suggestion := range existing {
+		existingByChange[wiFiAPChange{suggestion.BSSID, suggestion.Station.ID, suggestion.Action}] = suggestion
+	}
+
+	now := time.Now()
+	for change, support := range supported {
+		suggestion, present := existingByChange[change]
+		if present {
+			delete(existingByChange, change)
+		} else {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return err
+			}
+			suggestion = &types.WiFiAPSuggestion{
+				ID:      id.String(),
+				BSSID:   change.bssid,
+				Station: dataset.stations[change.stationID],
+				Action:  change.action,
+				Status:  types.WiFiAPSuggestionPending,
+				Source:  source,
+				Created: now,
+			}
+		}
+		suggestion.SSID = support.ssid
+		suggestion.Submitters = support.submitters
+		suggestion.FeedbackIDs = support.feedbackIDs
+		if suggestion.FeedbackIDs == nil {
+			suggestion.FeedbackIDs = []string{}
+		}
+		suggestion.Updated = now
+		err = suggestion.Update(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, suggestion := range existingByChange {
+		if suggestion.Status != types.WiFiAPSuggestionPending {
+			continue
+		}
+		err = suggestion.Delete(tx)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// PublishWiFiAPSuggestions applies the approved WiFiAPSuggestions for stations of the specified network
+// and publishes a new version of the network's dataset, crediting author.
+// The feedback supporting the applied suggestions is marked as resolved and returned
+func PublishWiFiAPSuggestions(node sqalx.Node, network *types.Network, author string) ([]*types.Feedback, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	suggestions, err := types.GetWiFiAPSuggestionsWithStatus(tx, types.WiFiAPSuggestionApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	feedbackIDs := make(map[string]bool)
+	applied := 0
+	for _, suggestion := range suggestions {
+		if suggestion.Station.Network.ID != network.ID {
+			continue
+		}
+		ap := &types.WiFiAP{
+			BSSID: suggestion.BSSID,
+			SSID:  suggestion.SSID,
+		}
+		switch suggestion.Action {
+		case types.WiFiAPSuggestionAdd:
+			lines, err := suggestion.Station.Lines(tx)
+			if err != nil {
+				return nil, err
+			}
+			var line *types.Line
+			if len(lines) == 1 {
+				line = lines[0]
+			}
+			err = ap.AddToStation(tx, suggestion.Station, line)
+			if err != nil {
+				return nil, err
+			}
+		case types.WiFiAPSuggestionRemove:
+			err = ap.RemoveFromStation(tx, suggestion.Station)
+			if err != nil {
+				return nil, err
+			}
+		}
+		suggestion.Status = types.WiFiAPSuggestionPublished
+		suggestion.Updated = now
+		err = suggestion.Update(tx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range suggestion.FeedbackIDs {
+			feedbackIDs[id] = true
+		}
+		applied++
+	}
+	if applied == 0 {
+		return nil, errors.New("PublishWiFiAPSuggestions: no approved suggestions for network " + network.ID)
+	}
+
+	dataset, err := types.GetDataset(tx, network.ID)
+	if err != nil {
+		dataset = &types.Dataset{
+			Network: network,
+		}
+	}
+	dataset.Version = now.Format(time.RFC3339)
+	hasAuthor := false
+	for _, a := range dataset.Authors {
+		if a == author {
+			hasAuthor = true
+			break
+		}
+	}
+	if !hasAuthor {
+		dataset.Authors = append(dataset.Authors, author)
+	}
+	err = dataset.Update(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := []*types.Feedback{}
+	for id := range feedbackIDs {
+		feedback, err := types.GetFeedback(tx, id)
+		if err != nil {
+			// the feedback may have been deleted meanwhile
+			continue
+		}
+		if feedback.Status == types.FeedbackResolved {
+			continue
+		}
+		feedback.Status = types.FeedbackResolved
+		err = feedback.Update(tx)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, feedback)
+	}
+	return resolved, tx.Commit()
+}
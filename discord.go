@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/gbl08ma/ankiddie"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/resource"
 
 	uuid "github.com/satori/go.uuid"
@@ -169,6 +170,11 @@ func (r *BotCommandReceiver) GetAnkiddie() *ankiddie.Ankiddie {
 	return kiddie
 }
 
+// GetVehicleETAHandler returns a reference to the global vehicle ETA handler
+func (r *BotCommandReceiver) GetVehicleETAHandler() *compute.VehicleETAHandler {
+	return vehicleETAHandler
+}
+
 // SetMQTTGatewayEnabled enables or disables the MQTT gateway
 func (r *BotCommandReceiver) SetMQTTGatewayEnabled(enabled bool) string {
 	if resource.EnableMQTTGateway == enabled {
@@ -1,6 +1,7 @@
 package discordbot
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,6 +24,7 @@ import (
 	"github.com/gbl08ma/sqalx"
 	cache "github.com/patrickmn/go-cache"
 	uuid "github.com/satori/go.uuid"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/utils"
 )
@@ -143,6 +145,8 @@ func Start(snode sqalx.Node, swebsiteURL string, keybox *keybox.Keybox,
 		adminChannelID = ""
 	}
 
+	TheFeedbackTriage.channelID, _ = keybox.Get("feedbackChannel")
+
 	dg, err := discordgo.New("Bot " + discordToken)
 	if err != nil {
 		return err
@@ -248,8 +252,11 @@ func Start(snode sqalx.Node, swebsiteURL string, keybox *keybox.Keybox,
 		muteManager.PermaUnmuteChannel(m.ChannelID)
 		s.ChannelMessageSend(m.ChannelID, "🤗🙌")
 	}).WithRequirePrivilege(PrivilegeAdmin))
+	commandLib.Register(NewCommand("route", handleRoute))
+	commandLib.Register(NewCommand("map", handleMap))
 	commandLib.Register(NewCommand("setstatus", handleStatus).WithRequirePrivilege(PrivilegeAdmin))
 	commandLib.Register(NewCommand("addlinestatus", handleLineStatus).WithRequirePrivilege(PrivilegeAdmin))
+	commandLib.Register(NewCommand("pathoutage", handlePathOutage).WithRequirePrivilege(PrivilegeAdmin))
 	commandLib.Register(NewCommand("scraper", handleControlScraper).WithRequirePrivilege(PrivilegeAdmin))
 	commandLib.Register(NewCommand("notifs", handleControlNotifs).WithRequirePrivilege(PrivilegeAdmin))
 	commandLib.Register(NewCommand("russia", handleRUSSIA).WithRequirePrivilege(PrivilegeAdmin))
@@ -281,6 +288,7 @@ func Start(snode sqalx.Node, swebsiteURL string, keybox *keybox.Keybox,
 	new(SQLSystem).Setup(node, commandLib, PrivilegeAdmin)
 
 	reactionHandlers = append(reactionHandlers, ThePosPlayBridge)
+	reactionHandlers = append(reactionHandlers, TheFeedbackTriage)
 	messageHandlers = append(messageHandlers, ThePosPlayBridge)
 
 	infoHandler, err := NewInfoHandler(node)
@@ -448,6 +456,71 @@ func messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 }
 
+func handleRoute(s *discordgo.Session, m *discordgo.MessageCreate, words []string) {
+	if len(words) < 2 {
+		s.ChannelMessageSend(m.ChannelID, "🆖 utilização: `"+commandLib.prefix+"route \"origem\" \"destino\" [HH:MM] [acessível]`")
+		return
+	}
+
+	departAt := time.Time{}
+	accessibleOnly := false
+	for _, word := range words[2:] {
+		if word == "acessível" || word == "acessivel" {
+			accessibleOnly = true
+			continue
+		}
+		t, err := time.Parse("15:04", word)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "🆖 hora inválida, use o formato HH:MM")
+			return
+		}
+		departAt = t
+	}
+
+	embed, err := buildRouteMessage(words[0], words[1], departAt, accessibleOnly)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+		return
+	}
+	s.ChannelMessageSendEmbed(m.ChannelID, embed.MessageEmbed)
+}
+
+func handleMap(s *discordgo.Session, m *discordgo.MessageCreate, words []string) {
+	networkID := "pt-ml"
+	if len(words) > 0 {
+		networkID = words[0]
+	}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+		return
+	}
+	defer tx.Commit() // read-only tx
+
+	network, err := types.GetNetwork(tx, networkID)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "🆖 rede desconhecida")
+		return
+	}
+
+	var trains map[string]*types.VehicleETA
+	if handler := cmdReceiver.GetVehicleETAHandler(); handler != nil {
+		trains = handler.TrainPositions()
+	}
+	// Discord doesn't preview SVG images
+	image, err := compute.RenderSchematicMapPNG(tx, network, trains)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+		return
+	}
+	_, err = s.ChannelFileSend(m.ChannelID, "mapa-"+network.ID+".png", bytes.NewReader(image))
+	if err != nil {
+		botLog.Println(err)
+		s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+	}
+}
+
 func handleLineStatus(s *discordgo.Session, m *discordgo.MessageCreate, words []string) {
 	if len(words) < 3 {
 		s.ChannelMessageSend(m.ChannelID, "🆖 missing arguments")
@@ -509,6 +582,134 @@ func handleLineStatus(s *discordgo.Session, m *discordgo.MessageCreate, words []
 	s.ChannelMessageSend(m.ChannelID, "✅")
 }
 
+func handlePathOutage(s *discordgo.Session, m *discordgo.MessageCreate, words []string) {
+	if len(words) < 1 {
+		s.ChannelMessageSend(m.ChannelID, "🆖 missing arguments")
+		return
+	}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+		return
+	}
+	defer tx.Rollback()
+
+	switch words[0] {
+	case "paths":
+		if len(words) < 2 {
+			s.ChannelMessageSend(m.ChannelID, "🆖 missing station ID argument")
+			return
+		}
+		station, err := types.GetStation(tx, words[1])
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "🆖 unknown station")
+			return
+		}
+		paths, err := station.Paths(tx)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		msg := "**Paths of " + station.Name + "**\n"
+		for _, path := range paths {
+			msg += "`" + path.ID + "` " + describeStationPath(path) + "\n"
+		}
+		s.ChannelMessageSend(m.ChannelID, msg)
+		return
+	case "list":
+		outages, err := types.GetOngoingStationPathOutages(tx)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		msg := "**Ongoing path outages**\n"
+		for _, outage := range outages {
+			msg += fmt.Sprintf("`%s` %s, %s, since %s: %s\n", outage.ID, outage.Path.Station.Name,
+				describeStationPath(outage.Path), outage.StartTime.Format(time.RFC3339), outage.Description)
+		}
+		s.ChannelMessageSend(m.ChannelID, msg)
+		return
+	case "start":
+		if len(words) < 3 {
+			s.ChannelMessageSend(m.ChannelID, "🆖 missing path ID or description arguments")
+			return
+		}
+		path, err := types.GetStationPath(tx, words[1])
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "🆖 unknown path")
+			return
+		}
+		id, err := uuid.NewV4()
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		outage := &types.StationPathOutage{
+			ID:          id.String(),
+			Path:        path,
+			StartTime:   time.Now().UTC(),
+			Description: strings.Join(words[2:], " "),
+		}
+		err = outage.Update(tx)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		s.ChannelMessageSend(m.ChannelID, "✅ `"+outage.ID+"`")
+		return
+	case "end":
+		if len(words) < 2 {
+			s.ChannelMessageSend(m.ChannelID, "🆖 missing outage ID argument")
+			return
+		}
+		outage, err := types.GetStationPathOutage(tx, words[1])
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "🆖 unknown outage")
+			return
+		}
+		if outage.Ended {
+			s.ChannelMessageSend(m.ChannelID, "❌ already ended")
+			return
+		}
+		outage.Ended = true
+		outage.EndTime = time.Now().UTC()
+		err = outage.Update(tx)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+		err = tx.Commit()
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, "❌ "+err.Error())
+			return
+		}
+	default:
+		s.ChannelMessageSend(m.ChannelID, "🆖 first argument must be `paths`, `list`, `start` or `end`")
+		return
+	}
+	s.ChannelMessageSend(m.ChannelID, "✅")
+}
+
+// describeStationPath returns a short description of the places connected by a path and of its means
+func describeStationPath(path *types.StationPath) string {
+	var from, to string
+	switch {
+	case path.Lobby == nil:
+		from, to = "line "+path.Line.Name, "line "+path.ToLine.Name
+	case path.Exit != nil:
+		from, to = "lobby "+path.Lobby.Name, "exit "+strings.Join(path.Exit.Streets, ", ")
+	default:
+		from, to = "lobby "+path.Lobby.Name, "line "+path.Line.Name
+	}
+	return fmt.Sprintf("%s ↔ %s (%s)", from, to, path.Means)
+}
+
 func handleControlScraper(s *discordgo.Session, m *discordgo.MessageCreate, words []string) {
 	if len(words) < 2 {
 		s.ChannelMessageSend(m.ChannelID, "🆖 missing arguments")
@@ -186,3 +186,26 @@ func (l *CommandLibrary) Name() string {
 func (l *CommandLibrary) isAdminChannel(channelID string) bool {
 	return l.adminChannelID != "" && channelID == l.adminChannelID
 }
+
+// userPrivilege returns the privilege of a user acting outside of the command channels,
+// e.g. through reactions: the bot owner has root privilege and those who can see the
+// special admin channel have admin privilege
+func (l *CommandLibrary) userPrivilege(s *discordgo.Session, userID string) Privilege {
+	if userID == l.botOwnerUserID {
+		return PrivilegeRoot
+	}
+	if l.adminChannelID == "" {
+		return PrivilegeEveryone
+	}
+	permissions, err := s.State.UserChannelPermissions(userID, l.adminChannelID)
+	if err != nil {
+		permissions, err = s.UserChannelPermissions(userID, l.adminChannelID)
+		if err != nil {
+			return PrivilegeEveryone
+		}
+	}
+	if permissions&discordgo.PermissionViewChannel != 0 {
+		return PrivilegeAdmin
+	}
+	return PrivilegeEveryone
+}
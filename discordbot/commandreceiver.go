@@ -2,6 +2,7 @@ package discordbot
 
 import (
 	"github.com/gbl08ma/ankiddie"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 )
 
@@ -52,6 +53,9 @@ type CommandReceiver interface {
 	// GetAnkiddie returns a reference to the global Ankiddie system
 	GetAnkiddie() *ankiddie.Ankiddie
 
+	// GetVehicleETAHandler returns a reference to the global vehicle ETA handler
+	GetVehicleETAHandler() *compute.VehicleETAHandler
+
 	// SetMQTTGatewayEnabled enables or disables the MQTT gateway
 	SetMQTTGatewayEnabled(enabled bool) string
 
@@ -0,0 +1,158 @@
+package discordbot
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// this file contains functions to triage user feedback on Discord
+
+const (
+	feedbackTriagedEmoji  = "👀"
+	feedbackResolvedEmoji = "✅"
+	feedbackAssignEmoji   = "🙋"
+	feedbackReopenEmoji   = "🔁"
+)
+
+var feedbackTriageEmojis = []string{feedbackTriagedEmoji, feedbackResolvedEmoji, feedbackAssignEmoji, feedbackReopenEmoji}
+
+// FeedbackTriage posts the feedback submitted by users to a Discord channel,
+// where it can be triaged using reactions
+type FeedbackTriage struct {
+	channelID               string
+	reactionsHandledCount   int
+	reactionsActedUponCount int
+}
+
+// TheFeedbackTriage is the FeedbackTriage of the bot
+// (exported so it can be registered as a resource.FeedbackListener)
+var TheFeedbackTriage = new(FeedbackTriage)
+
+// FeedbackSubmitted implements resource.FeedbackListener
+func (t *FeedbackTriage) FeedbackSubmitted(feedback *types.Feedback) {
+	if session == nil || t.channelID == "" {
+		return
+	}
+	// don't hold the API request while talking to Discord
+	go func() {
+		msg, err := session.ChannelMessageSendEmbed(t.channelID, buildFeedbackEmbed(feedback).MessageEmbed)
+		if err != nil {
+			botLog.Println(err)
+			return
+		}
+		for _, emoji := range feedbackTriageEmojis {
+			session.MessageReactionAdd(t.channelID, msg.ID, emoji)
+		}
+
+		feedback.DiscordMessageID = msg.ID
+		err = feedback.Update(node)
+		if err != nil {
+			botLog.Println(err)
+		}
+	}()
+}
+
+// FeedbackUpdated updates the Discord message of a feedback, after it was triaged elsewhere
+func (t *FeedbackTriage) FeedbackUpdated(feedback *types.Feedback) {
+	if session == nil || t.channelID == "" || feedback.DiscordMessageID == "" {
+		return
+	}
+	_, err := session.ChannelMessageEditEmbed(t.channelID, feedback.DiscordMessageID, buildFeedbackEmbed(feedback).MessageEmbed)
+	if err != nil {
+		botLog.Println(err)
+	}
+}
+
+// HandleReaction attempts to handle the provided reaction
+func (t *FeedbackTriage) HandleReaction(s *discordgo.Session, m *discordgo.MessageReactionAdd) bool {
+	t.reactionsHandledCount++
+	if t.channelID == "" || m.ChannelID != t.channelID {
+		return false
+	}
+
+	// the feedback channel may be visible to more people than those who should triage feedback
+	if commandLib == nil || commandLib.userPrivilege(s, m.UserID) < PrivilegeAdmin {
+		return false
+	}
+
+	feedback, err := types.GetFeedbackForDiscordMessage(node, m.MessageID)
+	if err != nil {
+		return false
+	}
+
+	switch m.Emoji.Name {
+	case feedbackTriagedEmoji:
+		feedback.Status = types.FeedbackTriaged
+	case feedbackResolvedEmoji:
+		feedback.Status = types.FeedbackResolved
+	case feedbackReopenEmoji:
+		feedback.Status = types.FeedbackNew
+	case feedbackAssignEmoji:
+		user, err := s.User(m.UserID)
+		if err != nil {
+			botLog.Println(err)
+			return true
+		}
+		if feedback.Assignee == user.Username {
+			feedback.Assignee = ""
+		} else {
+			feedback.Assignee = user.Username
+		}
+	default:
+		return false
+	}
+
+	err = feedback.Update(node)
+	if err != nil {
+		botLog.Println(err)
+		return true
+	}
+	t.FeedbackUpdated(feedback)
+	// remove the reaction so that the same action can be taken again later
+	s.MessageReactionRemove(m.ChannelID, m.MessageID, m.Emoji.Name, m.UserID)
+	t.reactionsActedUponCount++
+	return true
+}
+
+// ReactionsHandled returns the number of reactions handled by this FeedbackTriage
+func (t *FeedbackTriage) ReactionsHandled() int {
+	return t.reactionsHandledCount
+}
+
+// ReactionsActedUpon returns the number of reactions acted upon by this FeedbackTriage
+func (t *FeedbackTriage) ReactionsActedUpon() int {
+	return t.reactionsActedUponCount
+}
+
+// Name returns the name of this reaction handler
+func (t *FeedbackTriage) Name() string {
+	return "FeedbackTriage"
+}
+
+func buildFeedbackEmbed(feedback *types.Feedback) *Embed {
+	status := map[types.FeedbackStatus]string{
+		types.FeedbackNew:      "🆕 Novo",
+		types.FeedbackTriaged:  feedbackTriagedEmoji + " Em análise",
+		types.FeedbackResolved: feedbackResolvedEmoji + " Resolvido",
+	}[feedback.Status]
+	color := map[types.FeedbackStatus]int{
+		types.FeedbackNew:      0xe74c3c,
+		types.FeedbackTriaged:  0xf1c40f,
+		types.FeedbackResolved: 0x2ecc71,
+	}[feedback.Status]
+	assignee := feedback.Assignee
+	if assignee == "" {
+		assignee = "ninguém"
+	}
+
+	return NewEmbed().
+		SetTitle("Feedback: "+string(feedback.Type)).
+		SetDescription(feedback.Contents).
+		SetURL(websiteURL+"/internal/feedback?status="+string(feedback.Status)+"#"+feedback.ID).
+		SetColor(color).
+		AddInlineField("Estado", status).
+		AddInlineField("Atribuído a", assignee).
+		SetFooter(feedback.ID + " | " + feedbackTriagedEmoji + " analisar " + feedbackResolvedEmoji + " resolver " +
+			feedbackAssignEmoji + " atribuir " + feedbackReopenEmoji + " reabrir").
+		Truncate()
+}
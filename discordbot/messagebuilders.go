@@ -1,17 +1,25 @@
 package discordbot
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"net/url"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/gbl08ma/sqalx"
 	"go.tianon.xyz/progress"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/utils"
 )
@@ -208,6 +216,22 @@ func buildLineMessage(id string) (*Embed, error) {
 		}
 	}
 
+	if len(disturbances) > 0 && !disturbances[0].UEnded {
+		routes, err := compute.AlternativeRoutes(tx, line, now, false)
+		if err != nil {
+			// the line status is more important than the alternatives, so send it without them
+			botLog.Println(err)
+		}
+		for _, route := range routes {
+			routeStr := ""
+			for _, leg := range route.Legs {
+				routeStr += buildRouteLegString(leg, loc) + "\n"
+			}
+			embed.AddField(fmt.Sprintf("Alternativa de %s para %s (%d minutos)",
+				route.From.Name, route.To.Name, int(math.Ceil(route.Duration().Minutes()))), routeStr)
+		}
+	}
+
 	embed.AddInlineField("Disponibilidade últimos 7 dias", weekAvString).
 		AddInlineField("Disponibilidade últimos 30 dias", monthAvString)
 
@@ -388,6 +412,127 @@ func buildStationMessage(id string) (*Embed, error) {
 	return embed, nil
 }
 
+// findStation returns the station with the specified ID or name (case and accent insensitive)
+func findStation(tx sqalx.Node, query string) (*types.Station, error) {
+	if station, err := types.GetStation(tx, query); err == nil {
+		return station, nil
+	}
+	stations, err := types.GetStations(tx)
+	if err != nil {
+		return nil, err
+	}
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	normalize := func(str string) string {
+		result, _, _ := transform.String(t, strings.ToLower(str))
+		return result
+	}
+	query = normalize(query)
+	for _, station := range stations {
+		if normalize(station.Name) == query {
+			return station, nil
+		}
+		for _, altName := range station.AltNames {
+			if normalize(altName) == query {
+				return station, nil
+			}
+		}
+	}
+	return nil, errors.New("estação desconhecida: " + query)
+}
+
+func buildRouteMessage(fromQuery, toQuery string, departTime time.Time, accessibleOnly bool) (*Embed, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	from, err := findStation(tx, fromQuery)
+	if err != nil {
+		return nil, err
+	}
+	to, err := findStation(tx, toQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(from.Network.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	departAt := time.Now().In(loc)
+	if !departTime.IsZero() {
+		departAt = time.Date(departAt.Year(), departAt.Month(), departAt.Day(), departTime.Hour(), departTime.Minute(), 0, 0, loc)
+	}
+
+	route, err := compute.ComputeRoute(tx, from, to, departAt, accessibleOnly)
+	if err == compute.ErrSameStation {
+		return nil, errors.New("a origem e o destino são a mesma estação")
+	} else if err == compute.ErrNoRoute && accessibleOnly {
+		return nil, errors.New("não há forma de ir de " + from.Name + " para " + to.Name + " sem degraus a essa hora")
+	} else if err == compute.ErrNoRoute {
+		return nil, errors.New("não há forma de ir de " + from.Name + " para " + to.Name + " a essa hora")
+	} else if err != nil {
+		return nil, err
+	}
+
+	embed := NewEmbed().
+		SetTitle("De __" + from.Name + "__ para __" + to.Name + "__").
+		SetDescription(fmt.Sprintf("Partida às %s, chegada prevista às %s (%d minutos)",
+			route.Departure.In(loc).Format("15:04"),
+			route.Arrival.In(loc).Format("15:04"),
+			int(math.Ceil(route.Duration().Minutes()))))
+
+	if accessibleOnly {
+		embed.SetDescription(embed.Description + "\n♿ Percurso sem degraus")
+	}
+
+	for i, leg := range route.Legs {
+		title := "A pé"
+		if !leg.Walk {
+			title = "Linha " + leg.Line.Name
+			if i > 0 && !route.Legs[i-1].Walk {
+				title = "Trocar para a linha " + leg.Line.Name
+			}
+		}
+		embed.AddField(title, buildRouteLegString(leg, loc))
+	}
+
+	fare, err := compute.ComputeFare(tx, route.StationUses())
+	if err == nil {
+		products := []string{}
+		for _, ticket := range fare.Tickets {
+			products = append(products, ticket.Product.Name)
+		}
+		embed.AddField("Tarifa", fmt.Sprintf("%s (%s)", fare.String(), strings.Join(products, " + ")))
+	} else if err != compute.ErrNoFare {
+		return nil, err
+	}
+	return embed, nil
+}
+
+func buildRouteLegString(leg *compute.RouteLeg, loc *time.Location) string {
+	first := leg.Stations[0]
+	last := leg.Stations[len(leg.Stations)-1]
+	if leg.Walk {
+		return fmt.Sprintf("🚶 [%s](%s/s/%s) → [%s](%s/s/%s), %s–%s",
+			first.Name, websiteURL, first.ID,
+			last.Name, websiteURL, last.ID,
+			leg.Departure.In(loc).Format("15:04"), leg.Arrival.In(loc).Format("15:04"))
+	}
+	legStr := fmt.Sprintf("%s [%s](%s/s/%s) → [%s](%s/s/%s)\n",
+		getEmojiForLine(leg.Line.ID),
+		first.Name, websiteURL, first.ID,
+		last.Name, websiteURL, last.ID)
+	legStr += fmt.Sprintf("Sentido %s, %d paragens, %s–%s",
+		leg.Direction.Name, len(leg.Stations)-1,
+		leg.Departure.In(loc).Format("15:04"), leg.Arrival.In(loc).Format("15:04"))
+	if leg.Disturbed {
+		legStr += "\n⚠️ Esta linha tem uma perturbação em curso"
+	}
+	return legStr
+}
+
 func buildLobbyMesage(id string) (*Embed, error) {
 	tx, err := node.Beginx()
 	if err != nil {
@@ -10,6 +10,7 @@ var Types = map[string]reflect.Type{
 	"CommandLibrary":  reflect.TypeOf((*CommandLibrary)(nil)).Elem(),
 	"CommandReceiver": reflect.TypeOf((*CommandReceiver)(nil)).Elem(),
 	"Embed":           reflect.TypeOf((*Embed)(nil)).Elem(),
+	"FeedbackTriage":  reflect.TypeOf((*FeedbackTriage)(nil)).Elem(),
 	"InfoHandler":     reflect.TypeOf((*InfoHandler)(nil)).Elem(),
 	"MessageHandler":  reflect.TypeOf((*MessageHandler)(nil)).Elem(),
 	"MuteManager":     reflect.TypeOf((*MuteManager)(nil)).Elem(),
@@ -47,7 +48,8 @@ var Functions = map[string]reflect.Value{
 }
 
 var Variables = map[string]reflect.Value{
-	"ThePosPlayBridge": reflect.ValueOf(&ThePosPlayBridge),
+	"TheFeedbackTriage": reflect.ValueOf(&TheFeedbackTriage),
+	"ThePosPlayBridge":  reflect.ValueOf(&ThePosPlayBridge),
 }
 
 var Consts = map[string]reflect.Value{
@@ -41,6 +41,7 @@ require (
 	github.com/vmihailenco/msgpack v4.0.4+incompatible
 	github.com/yarf-framework/yarf v0.8.6
 	go.tianon.xyz/progress v0.0.0-20210607050815-67b5d511c1e4
+	golang.org/x/image v0.5.0
 	golang.org/x/oauth2 v0.5.0
 	golang.org/x/text v0.7.0
 	gopkg.in/alexcesaro/statsd.v2 v2.0.0
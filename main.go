@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/underlx/disturbancesmlx/mqttgateway"
@@ -39,6 +40,11 @@ var (
 	vehicleETAHandler *compute.VehicleETAHandler
 	reportHandler     *compute.ReportHandler
 	statsHandler      *compute.StatsHandler
+	crowdingHandler   *compute.CrowdingHandler
+	odMatrixHandler   *compute.ODMatrixHandler
+	retentionHandler  *compute.RetentionHandler
+	pairUsageTracker  *compute.PairUsageTracker
+	statusEventBroker *compute.StatusEventBroker
 	mqttGateway       *mqttgateway.MQTTGateway
 
 	// GitCommit is provided by govvv at compile-time
@@ -92,6 +98,13 @@ func main() {
 	vehicleETAHandler = compute.NewVehicleETAHandler(rootSqalxNode)
 	// done like this to ensure rootSqalxNode is not nil at this point
 	reportHandler = compute.NewReportHandler(statsHandler, rootSqalxNode, handleNewStatus)
+	statusEventBroker = compute.NewStatusEventBroker(rootSqalxNode)
+	crowdingHandler = compute.NewCrowdingHandler(rootSqalxNode, statsHandler)
+	odMatrixHandler = compute.NewODMatrixHandler(rootSqalxNode)
+	retentionHandler = compute.NewRetentionHandler(rootSqalxNode, getRetentionPolicy())
+	mainLog.Printf("Retention policy: %+v\n", retentionHandler.Policy())
+	pairUsageTracker = compute.NewPairUsageTracker(rootSqalxNode)
+	go pairUsageTracker.Run(1 * time.Minute)
 
 	compute.Initialize(rootSqalxNode, mainLog)
 
@@ -117,6 +130,7 @@ func main() {
 			VehicleHandler:    vehicleHandler,
 			VehicleETAHandler: vehicleETAHandler,
 			StatsHandler:      statsHandler,
+			PairUsageTracker:  pairUsageTracker,
 			AuthHashKey:       getHashKey(),
 		})
 		if err != nil {
@@ -165,7 +179,7 @@ func main() {
 	go func() {
 		time.Sleep(5 * time.Second)
 		for {
-			err := compute.UpdateTypicalSeconds(rootSqalxNode, 10*time.Millisecond)
+			err := compute.UpdateTypicalSeconds(rootSqalxNode, compute.TypicalSecondsWindow, 10*time.Millisecond)
 			if err != nil {
 				mainLog.Println(err)
 			}
@@ -174,6 +188,52 @@ func main() {
 		}
 	}()
 
+	go func() {
+		time.Sleep(10 * time.Second)
+		for {
+			err := crowdingHandler.Update(10 * time.Millisecond)
+			if err != nil {
+				mainLog.Println(err)
+			}
+			time.Sleep(6 * time.Hour)
+		}
+	}()
+
+	go func() {
+		time.Sleep(15 * time.Second)
+		for {
+			err := odMatrixHandler.Update(10 * time.Millisecond)
+			if err != nil {
+				mainLog.Println(err)
+			}
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+
+	go func() {
+		time.Sleep(20 * time.Second)
+		for {
+			report, err := retentionHandler.Run(false)
+			if err != nil {
+				mainLog.Println(err)
+			} else {
+				mainLog.Println(report)
+			}
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+
+	go func() {
+		time.Sleep(25 * time.Second)
+		for {
+			err := compute.UpdateWiFiAPSuggestionsFromObservations(rootSqalxNode, compute.WiFiAPObservationWindow, compute.DefaultWiFiAPSuggestionMinSubmitters)
+			if err != nil {
+				mainLog.Println(err)
+			}
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+
 	if DEBUG {
 		pair, err := types.NewPair(rootSqalxNode, "test", time.Now(), getHashKey())
 		if err != nil {
@@ -306,6 +366,34 @@ func main() {
 	}
 }
 
+// getRetentionPolicy reads the data retention policy from the "retention" keybox.
+// Data is only changed when the keybox sets dryRun to "false", and then only the periods it specifies apply.
+// Otherwise, the policy is a dry run, using the default periods for those that aren't specified
+func getRetentionPolicy() compute.RetentionPolicy {
+	policy := compute.DefaultRetentionPolicy
+	retentionKeybox, present := secrets.GetBox("retention")
+	if !present {
+		return policy
+	}
+	if value, present := retentionKeybox.Get("dryRun"); present && value == "false" {
+		policy = compute.RetentionPolicy{}
+	}
+
+	readInt := func(key string, dest *int) {
+		if value, present := retentionKeybox.Get(key); present {
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				mainLog.Fatalln("Invalid " + key + " in retention keybox")
+			}
+			*dest = i
+		}
+	}
+	readInt("tripSubmitterMonths", &policy.TripSubmitterMonths)
+	readInt("feedbackSubmitterMonths", &policy.FeedbackSubmitterMonths)
+	readInt("pairRequestDays", &policy.PairRequestDays)
+	return policy
+}
+
 func printLatestDisturbance(node sqalx.Node) {
 	tx, err := node.Beginx()
 	if err != nil {
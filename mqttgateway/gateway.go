@@ -28,6 +28,7 @@ type MQTTGateway struct {
 	vehicleHandler    *compute.VehicleHandler
 	vehicleETAhandler *compute.VehicleETAHandler
 	statsHandler      *compute.StatsHandler
+	pairUsageTracker  *compute.PairUsageTracker
 	listenAddr        string
 	wsListenAddr      string
 	publicHost        string
@@ -60,6 +61,7 @@ type Config struct {
 	VehicleHandler    *compute.VehicleHandler
 	VehicleETAHandler *compute.VehicleETAHandler
 	StatsHandler      *compute.StatsHandler
+	PairUsageTracker  *compute.PairUsageTracker
 }
 
 type userInfo struct {
@@ -76,6 +78,7 @@ func New(c Config) (*MQTTGateway, error) {
 		vehicleHandler:    c.VehicleHandler,
 		vehicleETAhandler: c.VehicleETAHandler,
 		statsHandler:      c.StatsHandler,
+		pairUsageTracker:  c.PairUsageTracker,
 		authHashKey:       c.AuthHashKey,
 		stopChan:          make(chan interface{}, 1),
 		etaAvailability:   "all",
@@ -164,8 +167,11 @@ func (g *MQTTGateway) Start() error {
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
 		fastTicker := time.NewTicker(2 * time.Second)
+		cleanupTicker := time.NewTicker(1 * time.Hour)
 		for {
 			select {
+			case <-cleanupTicker.C:
+				g.deleteOldProvisionalTrips()
 			case <-fastTicker.C:
 				err := g.SendVehiclePositions()
 				if err != nil {
@@ -254,6 +260,9 @@ func (g *MQTTGateway) handleOnConnect(client *gmqtt.Client) (code uint8) {
 	if err != nil {
 		return packets.CodeBadUsernameorPsw
 	}
+	if g.pairUsageTracker != nil {
+		g.pairUsageTracker.Record(pair)
+	}
 	g.Log.Println("Pair", pair.Key, "connected to the MQTT gateway")
 	client.SetUserData(userInfo{
 		Pair:        pair,
@@ -295,6 +304,12 @@ func (g *MQTTGateway) handleOnSubscribe(client *gmqtt.Client, topic packets.Topi
 		}
 		g.Log.Println("  " + topic.Name)
 
+		if !info.IsWebSocket &&
+			(topic.Name == realTimeLocationAckTopic("msgpack", info.Pair) ||
+				topic.Name == realTimeLocationAckTopic("dev-msgpack", info.Pair)) {
+			return topic.Qos
+		}
+
 		if topic.Name == "json/vehiclepos" ||
 			topic.Name == "msgpack/vehiclepos" ||
 			topic.Name == "dev-msgpack/vehiclepos" {
@@ -346,6 +361,8 @@ type payloadRealtimeLocation struct {
 	StationID string `msgpack:"s" json:"s"`
 	// DirectionID may be missing/empty if the user just entered the network
 	DirectionID string `msgpack:"d" json:"d"`
+	// RequestID may be missing/empty if the client does not want an acknowledgement
+	RequestID string `msgpack:"r" json:"r"`
 }
 
 func (g *MQTTGateway) handleOnPublish(client *gmqtt.Client, publish *packets.Publish) bool {
@@ -379,45 +396,62 @@ func (g *MQTTGateway) handleRealTimeLocationPublish(info userInfo, client *gmqtt
 		return
 	}
 
-	tx, err := g.Node.Beginx()
+	tripID, err := g.processRealTimeLocation(info, &request)
 	if err != nil {
 		g.Log.Println(err)
-		return
+	}
+
+	if request.RequestID != "" {
+		topicPrefix := strings.SplitN(string(publish.TopicName), "/", 2)[0]
+		g.sendRealTimeLocationAck(client, topicPrefix, info.Pair, &request, tripID, err == nil)
+	}
+}
+
+func (g *MQTTGateway) processRealTimeLocation(info userInfo, request *payloadRealtimeLocation) (string, error) {
+	if !info.Pair.HasScope(types.PairScopeRealtime) {
+		return "", errors.New("pair " + info.Pair.Key + " is not allowed to send real-time location reports")
+	}
+
+	tx, err := g.Node.Beginx()
+	if err != nil {
+		return "", err
 	}
 	defer tx.Commit() // read-only tx
 
 	station, err := types.GetStation(tx, request.StationID)
 	if err != nil {
-		g.Log.Println(err)
-		return
+		return "", err
 	}
 
 	lines, err := station.Lines(tx)
 	if err != nil {
-		g.Log.Println(err)
-		return
+		return "", err
 	}
 
-	if g.statsHandler != nil {
-		if request.DirectionID == "" {
-			g.statsHandler.RegisterActivity(lines, info.Pair, true)
-		} else {
-			g.statsHandler.RegisterActivity(lines, info.Pair, false)
+	var direction *types.Station
+	if request.DirectionID != "" {
+		direction, err = types.GetStation(tx, request.DirectionID)
+		if err != nil {
+			return "", err
 		}
 	}
 
-	if g.vehicleHandler != nil {
-		if request.DirectionID != "" {
-			direction, err := types.GetStation(tx, request.DirectionID)
-			if err != nil {
-				g.Log.Println(err)
-				return
-			}
+	if g.statsHandler != nil {
+		g.statsHandler.RegisterActivity(lines, info.Pair, direction == nil)
+	}
 
+	if g.vehicleHandler != nil {
+		if direction != nil {
 			g.vehicleHandler.RegisterTrainPassenger(station, direction)
 			g.Log.Println("Received real-time location report through MQTT, station", station.ID, "direction", direction.ID)
 		} else {
 			g.Log.Println("Received real-time location report through MQTT, station", station.ID)
 		}
 	}
+
+	return g.stitchRealTimeLocation(info.Pair, &types.RealtimeLocationReport{
+		Station:   station,
+		Direction: direction,
+		Time:      time.Now(),
+	})
 }
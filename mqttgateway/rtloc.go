@@ -0,0 +1,80 @@
+package mqttgateway
+
+import (
+	"time"
+
+	"github.com/gbl08ma/gmqtt"
+	"github.com/gbl08ma/gmqtt/pkg/packets"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/vmihailenco/msgpack"
+)
+
+// ProvisionalTripMaxReportGap is the maximum time between two real-time location reports for them
+// to be considered part of the same provisional trip
+const ProvisionalTripMaxReportGap = 20 * time.Minute
+
+// ProvisionalTripRetention is how long provisional trips are kept after their last report
+const ProvisionalTripRetention = 7 * 24 * time.Hour
+
+type payloadRealtimeLocationAck struct {
+	RequestID string `msgpack:"r" json:"r"`
+	Success   bool   `msgpack:"ok" json:"ok"`
+	// TripID is the ID of the provisional trip the report was added to
+	TripID string `msgpack:"t" json:"t"`
+}
+
+func realTimeLocationAckTopic(topicPrefix string, pair *types.APIPair) string {
+	return topicPrefix + "/rtloc-ack/" + pair.Key
+}
+
+func (g *MQTTGateway) sendRealTimeLocationAck(client *gmqtt.Client, topicPrefix string, pair *types.APIPair, request *payloadRealtimeLocation, tripID string, success bool) {
+	payload, err := msgpack.Marshal(&payloadRealtimeLocationAck{
+		RequestID: request.RequestID,
+		Success:   success,
+		TripID:    tripID,
+	})
+	if err != nil {
+		g.Log.Println(err)
+		return
+	}
+
+	g.server.Publish(&packets.Publish{
+		Qos:       packets.QOS_1,
+		TopicName: []byte(realTimeLocationAckTopic(topicPrefix, pair)),
+		Payload:   payload,
+	}, client.ClientOptions().ClientID)
+}
+
+// stitchRealTimeLocation adds the report to the ongoing provisional trip of the pair, creating a new one if needed,
+// and returns the ID of the provisional trip
+func (g *MQTTGateway) stitchRealTimeLocation(pair *types.APIPair, report *types.RealtimeLocationReport) (string, error) {
+	tx, err := g.Node.Beginx()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	trip, err := types.GetLatestProvisionalTripForSubmitter(tx, pair)
+	if err != nil ||
+		report.Time.Sub(trip.LastReportTime) > ProvisionalTripMaxReportGap ||
+		// the user entered the network again after travelling
+		(report.Direction == nil && trip.HasDirection()) {
+		trip, err = types.NewProvisionalTrip(tx, pair, report.Time)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = trip.AddReport(tx, report)
+	if err != nil {
+		return "", err
+	}
+	return trip.ID, tx.Commit()
+}
+
+func (g *MQTTGateway) deleteOldProvisionalTrips() {
+	err := types.DeleteProvisionalTripsOlderThan(g.Node, time.Now().Add(-ProvisionalTripRetention))
+	if err != nil {
+		g.Log.Println(err)
+	}
+}
@@ -5,16 +5,20 @@ package main
 import "reflect"
 
 var Types = map[string]reflect.Type{
-	"AnnouncementStore":   reflect.TypeOf((*AnnouncementStore)(nil)).Elem(),
-	"BotCommandReceiver":  reflect.TypeOf((*BotCommandReceiver)(nil)).Elem(),
-	"DelayMiddleware":     reflect.TypeOf((*DelayMiddleware)(nil)).Elem(),
-	"Static":              reflect.TypeOf((*Static)(nil)).Elem(),
-	"TelemetryMiddleware": reflect.TypeOf((*TelemetryMiddleware)(nil)).Elem(),
+	"AnnouncementStore":            reflect.TypeOf((*AnnouncementStore)(nil)).Elem(),
+	"BotCommandReceiver":           reflect.TypeOf((*BotCommandReceiver)(nil)).Elem(),
+	"ContractValidationMiddleware": reflect.TypeOf((*ContractValidationMiddleware)(nil)).Elem(),
+	"DelayMiddleware":              reflect.TypeOf((*DelayMiddleware)(nil)).Elem(),
+	"RateLimit":                    reflect.TypeOf((*RateLimit)(nil)).Elem(),
+	"RateLimitMiddleware":          reflect.TypeOf((*RateLimitMiddleware)(nil)).Elem(),
+	"Static":                       reflect.TypeOf((*Static)(nil)).Elem(),
+	"TelemetryMiddleware":          reflect.TypeOf((*TelemetryMiddleware)(nil)).Elem(),
 }
 
 var Functions = map[string]reflect.Value{
 	"APIserver":                            reflect.ValueOf(APIserver),
 	"DiscordBot":                           reflect.ValueOf(DiscordBot),
+	"NewRateLimitMiddleware":               reflect.ValueOf(NewRateLimitMiddleware),
 	"RegisterAndStartNewRSSScraper":        reflect.ValueOf(RegisterAndStartNewRSSScraper),
 	"SendMetaBroadcast":                    reflect.ValueOf(SendMetaBroadcast),
 	"SendNotificationForAnnouncement":      reflect.ValueOf(SendNotificationForAnnouncement),
@@ -35,6 +39,7 @@ var Variables = map[string]reflect.Value{
 	"BuildDate":            reflect.ValueOf(&BuildDate),
 	"GitCommit":            reflect.ValueOf(&GitCommit),
 	"PairRequestTelemetry": reflect.ValueOf(&PairRequestTelemetry),
+	"RateLimitTelemetry":   reflect.ValueOf(&RateLimitTelemetry),
 }
 
 var Consts = map[string]reflect.Value{
@@ -458,7 +458,7 @@ func (s *VisitStationsAchievementStrategy) HandleTrip(context *types.PPAchieveme
 
 	visitedInThisTrip := make(map[string]bool)
 	for _, use := range trip.StationUses {
-		if !use.Manual {
+		if !use.Manual && !use.Inferred {
 			visitedInThisTrip[use.Station.ID] = true
 		}
 	}
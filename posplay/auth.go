@@ -34,6 +34,9 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	returnPath, _ := session.Values["oauthReturnPath"].(string)
+	delete(session.Values, "oauthReturnPath")
+
 	code := r.FormValue("code")
 	token, err := oauthConfig.Exchange(r.Context(), code)
 	if err != nil {
@@ -57,6 +60,8 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 
 	if ppsession.GoToOnboarding {
 		http.Redirect(w, r, BaseURL()+"/welcome", http.StatusTemporaryRedirect)
+	} else if strings.HasPrefix(returnPath, "/") && !strings.HasPrefix(returnPath, "//") && returnPath != "/login" {
+		http.Redirect(w, r, BaseURL()+returnPath, http.StatusTemporaryRedirect)
 	} else {
 		http.Redirect(w, r, BaseURL()+"/", http.StatusTemporaryRedirect)
 	}
@@ -31,6 +31,7 @@ var Functions = map[string]reflect.Value{
 	"NameForNotificationMethod":               reflect.ValueOf(NameForNotificationMethod),
 	"NameForNotificationType":                 reflect.ValueOf(NameForNotificationType),
 	"NewSession":                              reflect.ValueOf(NewSession),
+	"PairChallengeApprovalURL":                reflect.ValueOf(PairChallengeApprovalURL),
 	"RegisterDiscussionParticipationCallback": reflect.ValueOf(RegisterDiscussionParticipationCallback),
 	"RegisterEventWinCallback":                reflect.ValueOf(RegisterEventWinCallback),
 	"RegisterReport":                          reflect.ValueOf(RegisterReport),
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"log"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -181,6 +182,11 @@ func BaseURL() string {
 	return websiteURL
 }
 
+// PairChallengeApprovalURL returns the URL of the page where players can approve a types.PairChallenge
+func PairChallengeApprovalURL(challengeID string) string {
+	return websiteURL + "/pair/approve?challenge=" + url.QueryEscape(challengeID)
+}
+
 // RegisterTripSubmission schedules a trip submission for analysis
 func RegisterTripSubmission(trip *types.Trip) {
 	tripSubmissionsChan <- trip.ID
@@ -56,10 +56,18 @@ func processTripForReward(id string) error {
 }
 
 func computeTripXPReward(trip *types.Trip) (int, int, int, bool) {
+	if trip.Quality == types.TripQualityImplausible {
+		return 0, 0, 0, false
+	}
+
 	visitedStations := make(map[string]bool)
 	interchanges := make(map[string]bool)
 	var network *types.Network
 	for _, use := range trip.StationUses {
+		if use.Inferred {
+			// stations filled in by the server shouldn't make trips worth more than what the client submitted
+			continue
+		}
 		visitedStations[use.Station.ID] = true
 		if use.Type == types.Interchange {
 			interchanges[use.Station.ID] = true
@@ -83,6 +91,11 @@ func computeTripXPReward(trip *types.Trip) (int, int, int, bool) {
 		xp = int(math.Round(float64(xp) * 1.2))
 	}
 
+	if trip.Quality == types.TripQualitySuspicious {
+		// discount trips with anomalies, which may have been farmed
+		xp = int(math.Round(float64(xp) * 0.5))
+	}
+
 	return xp, len(visitedStations), len(interchanges), !onpeak
 }
 
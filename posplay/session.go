@@ -162,6 +162,10 @@ func oauthLogin(r *http.Request, w http.ResponseWriter) error {
 	session, _ := config.Store.Get(r, SessionName)
 
 	session.Values["oauthState"] = uuid.String()
+	if r.Method == http.MethodGet {
+		// so that the user is sent back to the page they were trying to access, once logged in
+		session.Values["oauthReturnPath"] = r.URL.RequestURI()
+	}
 
 	err = session.Save(r, w)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/discordbot"
 )
@@ -199,6 +200,71 @@ func pairStatus(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+func pairChallengePage(w http.ResponseWriter, r *http.Request) {
+	session, redirected, err := GetSession(r, w, true)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if redirected {
+		return
+	}
+
+	tx, err := config.Node.Beginx()
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback()
+
+	discordID := uidConvS(session.DiscordInfo.ID)
+
+	player, err := types.GetPPPlayer(tx, discordID)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p := struct {
+		pageCommons
+		Challenge *types.PairChallenge
+		Approved  bool
+	}{}
+	p.pageCommons, err = initPageCommons(tx, w, r, "Autorizar aplicação", session, player)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	p.SidebarSelected = "pair"
+
+	challenge, err := types.GetPairChallenge(tx, r.FormValue("challenge"))
+	if err == nil && !challenge.Expired() && !challenge.Redeemed() {
+		p.Challenge = challenge
+	}
+
+	if r.Method == http.MethodPost && p.Challenge != nil && !p.Challenge.Approved() {
+		err = p.Challenge.Approve(tx, discordID)
+		if err != nil {
+			config.Log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		p.Approved = true
+	}
+
+	err = webtemplate.ExecuteTemplate(w, "pairchallenge.html", p)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	tx.Commit()
+}
+
 func settingsPage(w http.ResponseWriter, r *http.Request) {
 	settingsLikePage(w, r, false)
 }
@@ -252,6 +318,8 @@ func settingsLikePage(w http.ResponseWriter, r *http.Request, isOnboarding bool)
 		NotifTypes    []string
 		NotifMethods  []string
 		HasPair       bool
+		// ErasureUnconfirmed is true when the user tried to erase their data without typing the confirmation
+		ErasureUnconfirmed bool
 	}{
 		NotifTypes:    []string{NotificationTypeGuildEventWon, NotificationTypeAchievementAchieved},
 		NotifMethods:  []string{NotificationMethodDiscordDM, NotificationMethodAppNotif},
@@ -269,6 +337,7 @@ func settingsLikePage(w http.ResponseWriter, r *http.Request, isOnboarding bool)
 		return
 	}
 	p.SidebarSelected = "settings"
+	p.ErasureUnconfirmed = r.URL.Query().Get("erasure") == "unconfirmed"
 	p.GuildMember, err = discordbot.ProjectGuildMember(session.DiscordInfo.ID)
 	if err != nil {
 		p.GuildMember = nil
@@ -373,6 +442,86 @@ func settingsLikePage(w http.ResponseWriter, r *http.Request, isOnboarding bool)
 	}
 }
 
+func personalDataExportPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	session, redirected, err := GetSession(r, w, true)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if redirected {
+		return
+	}
+
+	player, err := types.GetPPPlayer(config.Node, uidConvS(session.DiscordInfo.ID))
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	archive, err := compute.ExportPersonalData(config.Node, nil, player, "posplay")
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"posplay-"+session.DiscordInfo.ID+".json\"")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(archive)
+	if err != nil {
+		config.Log.Println(err)
+	}
+}
+
+func personalDataErasurePage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	session, redirected, err := GetSession(r, w, true)
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if redirected {
+		return
+	}
+
+	r.ParseForm()
+	if r.Form.Get("confirm") != "ELIMINAR" {
+		http.Redirect(w, r, BaseURL()+"/settings?erasure=unconfirmed", http.StatusSeeOther)
+		return
+	}
+
+	player, err := types.GetPPPlayer(config.Node, uidConvS(session.DiscordInfo.ID))
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	keepTrips := r.Form.Get("trips") != "delete"
+	err = compute.ErasePersonalData(config.Node, nil, player, keepTrips, "posplay")
+	if err != nil {
+		config.Log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	session.Logout(r, w)
+	http.Redirect(w, r, BaseURL(), http.StatusSeeOther)
+}
+
 func xpTransactionHistoryPage(w http.ResponseWriter, r *http.Request) {
 	session, redirected, err := GetSession(r, w, true)
 	if err != nil {
@@ -403,7 +552,11 @@ func xpTransactionHistoryPage(w http.ResponseWriter, r *http.Request) {
 		pageCommons
 
 		XPTransactions []*types.PPXPTransaction
-	}{}
+		// TripFares maps the IDs of trip submission transactions to the fare of the trip
+		TripFares map[string]string
+	}{
+		TripFares: make(map[string]string),
+	}
 	p.pageCommons, err = initPageCommons(tx, w, r, "Histórico de recompensas", session, player)
 	if err != nil {
 		config.Log.Println(err)
@@ -418,6 +571,27 @@ func xpTransactionHistoryPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, xptx := range p.XPTransactions {
+		if xptx.Type != "TRIP_SUBMIT_REWARD" {
+			continue
+		}
+		tripID, ok := xptx.UnmarshalExtra()["trip_id"].(string)
+		if !ok {
+			continue
+		}
+		trip, err := types.GetTrip(tx, tripID)
+		if err != nil {
+			// the trip may have been deleted
+			continue
+		}
+		fare, err := compute.ComputeFare(tx, trip.StationUses)
+		if err == nil {
+			p.TripFares[xptx.ID] = fare.String()
+		} else if err != compute.ErrNoFare {
+			config.Log.Println(err)
+		}
+	}
+
 	err = webtemplate.ExecuteTemplate(w, "xptransactions.html", p)
 	if err != nil {
 		config.Log.Println(err)
@@ -52,7 +52,10 @@ func ConfigureRouter(router *mux.Router) {
 	router.HandleFunc("/", homePage)
 	router.HandleFunc("/pair", pairPage)
 	router.HandleFunc("/pair/status", pairStatus)
+	router.HandleFunc("/pair/approve", pairChallengePage)
 	router.HandleFunc("/settings", settingsPage)
+	router.HandleFunc("/settings/data/export", personalDataExportPage)
+	router.HandleFunc("/settings/data/erase", personalDataErasurePage)
 	router.HandleFunc("/xptx", xpTransactionHistoryPage)
 	router.HandleFunc("/achievements", achievementsPage)
 	router.HandleFunc("/achievements/{id:[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-4[0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}}", achievementPage)
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// RateLimit allows each client to make Requests requests every Period, in bursts of up to Requests requests
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// defaultRateLimits contains the rate limits of the API routes that clients could abuse,
+// either because they write to the database or because they are expensive to serve
+var defaultRateLimits = map[string]RateLimit{
+	"/v1/pair":                  {10, time.Hour},
+	"/v1/pair/challenge":        {20, time.Hour},
+	"/v1/pair/challenge/:id":    {120, time.Minute},
+	"/v1/trips":                 {120, time.Hour},
+	"/v1/trips/:id":             {120, time.Hour},
+	"/v1/trips/provisional":     {120, time.Hour},
+	"/v1/trips/provisional/:id": {120, time.Hour},
+	"/v1/rt":                    {60, time.Minute},
+	"/v1/feedback":              {10, time.Hour},
+	"/v1/wifiaps/observations":  {60, time.Hour},
+	"/v2/trips":                 {300, time.Hour},
+	"/v2/trips/:id":             {300, time.Hour},
+}
+
+// tokenBucket holds the tokens of a client for a route. A token is spent on each request
+// and tokens are replenished continuously, at the rate of the RateLimit
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimitMiddleware limits how often each client can make requests to each API route.
+// Clients are identified by their API pair, if they authenticate successfully, or by their IP address otherwise
+type RateLimitMiddleware struct {
+	yarf.Middleware
+	routes  []string
+	limits  map[string]RateLimit
+	hashKey []byte
+
+	mu      sync.Mutex
+	buckets *cache.Cache
+	// pairVerifications caches whether a pair key and secret are correct, so that rate limiting
+	// doesn't cost a database query on every request. It only affects how clients are identified,
+	// authorization is still checked by the resources
+	pairVerifications *cache.Cache
+}
+
+// NewRateLimitMiddleware returns a new RateLimitMiddleware that enforces the specified limits
+// on the routes (in yarf path format) they are specified for
+func NewRateLimitMiddleware(routes []string, limits map[string]RateLimit, hashKey []byte) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		routes:  routes,
+		limits:  limits,
+		hashKey: hashKey,
+		// once a bucket is left alone for a whole period, it is full again and there is no point in keeping it
+		buckets:           cache.New(1*time.Hour, 10*time.Minute),
+		pairVerifications: cache.New(5*time.Minute, 10*time.Minute),
+	}
+}
+
+// clientKey returns the key identifying the client that made the request
+func (m *RateLimitMiddleware) clientKey(c *yarf.Context) string {
+	if key, secret, ok := c.Request.BasicAuth(); ok {
+		verificationKey := key + ":" + secret
+		correct, present := m.pairVerifications.Get(verificationKey)
+		if !present {
+			_, err := types.GetPairIfCorrect(rootSqalxNode, key, secret, m.hashKey)
+			correct = err == nil
+			m.pairVerifications.SetDefault(verificationKey, correct)
+		}
+		if correct.(bool) {
+			return "pair:" + key
+		}
+	}
+	return "ip:" + c.GetClientIP()
+}
+
+// take spends a token of the bucket with the specified key, returning whether that was possible
+// and if not, how long until a token becomes available
+func (m *RateLimitMiddleware) take(key string, limit RateLimit) (bool, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+
+	bucket := &tokenBucket{
+		tokens:  float64(limit.Requests),
+		updated: now,
+	}
+	if b, present := m.buckets.Get(key); present {
+		bucket = b.(*tokenBucket)
+		bucket.tokens = math.Min(float64(limit.Requests), bucket.tokens+now.Sub(bucket.updated).Seconds()*rate)
+		bucket.updated = now
+	}
+	m.buckets.Set(key, bucket, limit.Period)
+
+	if bucket.tokens < 1 {
+		return false, time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// PreDispatch runs before the request is dispatched
+func (m *RateLimitMiddleware) PreDispatch(c *yarf.Context) error {
+	route := matchRoute(m.routes, c.Request.URL.Path)
+	limit, present := m.limits[route]
+	if !present {
+		return nil
+	}
+
+	allowed, retryAfter := m.take(route+"|"+m.clientKey(c), limit)
+	if allowed {
+		return nil
+	}
+
+	// non-blocking send
+	select {
+	case RateLimitTelemetry <- route:
+	default:
+	}
+
+	c.Response.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	return &yarf.CustomError{
+		HTTPCode:  http.StatusTooManyRequests,
+		ErrorMsg:  "Rate limit exceeded",
+		ErrorBody: "Rate limit exceeded",
+	}
+}
+
+// getRateLimits returns the rate limits for the API routes, overriding the defaults with those
+// in the "rateLimits" keybox. Limits are specified per route, as "requests/period" (e.g. "60/1h").
+// A limit of "none" removes the limit of a route
+func getRateLimits(routes []string) map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	for route, limit := range defaultRateLimits {
+		limits[route] = limit
+	}
+
+	rateLimitsKeybox, present := secrets.GetBox("rateLimits")
+	if !present {
+		return limits
+	}
+
+	for _, route := range routes {
+		value, present := rateLimitsKeybox.Get(route)
+		if !present {
+			continue
+		}
+		if value == "none" {
+			delete(limits, route)
+			continue
+		}
+		limit, err := parseRateLimit(value)
+		if err != nil {
+			mainLog.Fatalln("Invalid rate limit for " + route + " in rateLimits keybox: " + err.Error())
+		}
+		limits[route] = limit
+	}
+	return limits
+}
+
+func parseRateLimit(value string) (RateLimit, error) {
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return RateLimit{}, errors.New("expected requests/period")
+	}
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return RateLimit{}, err
+	}
+	period, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return RateLimit{}, err
+	}
+	if requests <= 0 || period <= 0 {
+		return RateLimit{}, errors.New("requests and period must be positive")
+	}
+	return RateLimit{requests, period}, nil
+}
@@ -0,0 +1,149 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// gaps are in millimeters
+type apiPlatform struct {
+	Line      string `msgpack:"line" json:"line" protobuf:"1"`
+	GapWidth  int    `msgpack:"gapWidth" json:"gapWidth" protobuf:"2"`
+	GapHeight int    `msgpack:"gapHeight" json:"gapHeight" protobuf:"3"`
+	StepFree  bool   `msgpack:"stepFree" json:"stepFree" protobuf:"4"`
+}
+
+// paths connect a lobby to an exit or to the platforms of a line, or the platforms of two lines
+type apiStationPath struct {
+	ID           string `msgpack:"id" json:"id" protobuf:"1"`
+	Lobby        string `msgpack:"lobby,omitempty" json:"lobby,omitempty" protobuf:"2"`
+	Exit         int    `msgpack:"exit,omitempty" json:"exit,omitempty" protobuf:"3"`
+	Line         string `msgpack:"line,omitempty" json:"line,omitempty" protobuf:"4"`
+	ToLine       string `msgpack:"toLine,omitempty" json:"toLine,omitempty" protobuf:"5"`
+	Means        string `msgpack:"means" json:"means" protobuf:"6"`
+	StepFree     bool   `msgpack:"stepFree" json:"stepFree" protobuf:"7"`
+	OutOfService bool   `msgpack:"outOfService" json:"outOfService" protobuf:"8"`
+}
+
+type apiLineInterchange struct {
+	From string `msgpack:"from" json:"from" protobuf:"1"`
+	To   string `msgpack:"to" json:"to" protobuf:"2"`
+}
+
+type apiStationAccessibility struct {
+	StepFreeLines        []string             `msgpack:"stepFreeLines" json:"stepFreeLines" protobuf:"1"`
+	StepFreeInterchanges []apiLineInterchange `msgpack:"stepFreeInterchanges" json:"stepFreeInterchanges" protobuf:"2"`
+	Platforms            []apiPlatform        `msgpack:"platforms" json:"platforms" protobuf:"3"`
+	Paths                []apiStationPath     `msgpack:"paths" json:"paths" protobuf:"4"`
+}
+
+type apiLobbyAccessibility struct {
+	StepFree      bool             `msgpack:"stepFree" json:"stepFree"`
+	StepFreeExits []int            `msgpack:"stepFreeExits" json:"stepFreeExits"`
+	Paths         []apiStationPath `msgpack:"paths" json:"paths"`
+}
+
+func buildAPIStationPaths(paths []*types.StationPath, accessibility *compute.StationAccessibility) []apiStationPath {
+	outOfService := make(map[string]bool)
+	for _, outage := range accessibility.Outages {
+		outOfService[outage.Path.ID] = true
+	}
+	data := make([]apiStationPath, len(paths))
+	for i, path := range paths {
+		data[i] = apiStationPath{
+			ID:           path.ID,
+			Means:        string(path.Means),
+			StepFree:     path.Means.StepFree(),
+			OutOfService: outOfService[path.ID],
+		}
+		if path.Lobby != nil {
+			data[i].Lobby = path.Lobby.ID
+		}
+		if path.Exit != nil {
+			data[i].Exit = path.Exit.ID
+		}
+		if path.Line != nil {
+			data[i].Line = path.Line.ID
+		}
+		if path.ToLine != nil {
+			data[i].ToLine = path.ToLine.ID
+		}
+	}
+	return data
+}
+
+func buildAPIStationAccessibility(tx sqalx.Node, station *types.Station) (apiStationAccessibility, error) {
+	data := apiStationAccessibility{
+		StepFreeLines:        []string{},
+		StepFreeInterchanges: []apiLineInterchange{},
+		Platforms:            []apiPlatform{},
+	}
+	accessibility, err := compute.ComputeStationAccessibility(tx, station, time.Now())
+	if err != nil {
+		return data, err
+	}
+
+	lines, err := station.Lines(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, from := range lines {
+		if accessibility.StepFreeLines[from.ID] {
+			data.StepFreeLines = append(data.StepFreeLines, from.ID)
+		}
+		for _, to := range lines {
+			if from.ID != to.ID && accessibility.CanInterchange(from, to) {
+				data.StepFreeInterchanges = append(data.StepFreeInterchanges, apiLineInterchange{
+					From: from.ID,
+					To:   to.ID,
+				})
+			}
+		}
+	}
+
+	platforms, err := station.Platforms(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, platform := range platforms {
+		data.Platforms = append(data.Platforms, apiPlatform{
+			Line:      platform.Line.ID,
+			GapWidth:  platform.GapWidth,
+			GapHeight: platform.GapHeight,
+			StepFree:  platform.StepFree(),
+		})
+	}
+
+	paths, err := station.Paths(tx)
+	if err != nil {
+		return data, err
+	}
+	data.Paths = buildAPIStationPaths(paths, accessibility)
+	return data, nil
+}
+
+func buildAPILobbyAccessibility(tx sqalx.Node, lobby *types.Lobby, exits []*types.Exit) (apiLobbyAccessibility, error) {
+	data := apiLobbyAccessibility{
+		StepFreeExits: []int{},
+	}
+	accessibility, err := compute.ComputeStationAccessibility(tx, lobby.Station, time.Now())
+	if err != nil {
+		return data, err
+	}
+	data.StepFree = accessibility.StepFreeLobbies[lobby.ID]
+	for _, exit := range exits {
+		if accessibility.StepFreeExits[exit.ID] {
+			data.StepFreeExits = append(data.StepFreeExits, exit.ID)
+		}
+	}
+
+	paths, err := lobby.Paths(tx)
+	if err != nil {
+		return data, err
+	}
+	data.Paths = buildAPIStationPaths(paths, accessibility)
+	return data, nil
+}
@@ -10,6 +10,11 @@ type AuthTest struct {
 	resource
 }
 
+type apiAuthTestResult struct {
+	Result string `msgpack:"result" json:"result"`
+	Key    string `msgpack:"key" json:"key"`
+}
+
 // WithNode associates a sqalx Node with this resource
 func (r *AuthTest) WithNode(node sqalx.Node) *AuthTest {
 	r.node = node
@@ -26,14 +31,11 @@ func (r *AuthTest) WithHashKey(key []byte) *AuthTest {
 func (r *AuthTest) Get(c *yarf.Context) error {
 	pair, err := r.AuthenticateClient(c)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
-	RenderData(c, struct {
-		Result string `msgpack:"result" json:"result"`
-		Key    string `msgpack:"key" json:"key"`
-	}{
+	RenderData(c, apiAuthTestResult{
 		Result: "ok",
 		Key:    pair.Key,
 	}, "no-cache, no-store, must-revalidate")
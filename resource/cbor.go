@@ -0,0 +1,332 @@
+package resource
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// minimal CBOR (RFC 7049) encoder and decoder for the generic values produced and consumed by encoding/json
+
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborBytes    = 2 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborTag      = 6 << 5
+	cborSimple   = 7 << 5
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+)
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+func cborMarshal(v interface{}) ([]byte, error) {
+	return cborAppend(nil, v)
+}
+
+func cborAppendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(b, major|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(b, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	b = append(b, major|27)
+	return append(b, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+func cborAppendInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return cborAppendHead(b, cborNegative, uint64(-(i + 1)))
+	}
+	return cborAppendHead(b, cborUnsigned, uint64(i))
+}
+
+func cborAppendFloat(b []byte, f float64) []byte {
+	b = append(b, cborSimple|27)
+	bits := math.Float64bits(f)
+	return append(b, byte(bits>>56), byte(bits>>48), byte(bits>>40), byte(bits>>32), byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+func cborAppend(b []byte, v interface{}) ([]byte, error) {
+	var err error
+	switch value := v.(type) {
+	case nil:
+		return append(b, cborSimple|22), nil
+	case bool:
+		if value {
+			return append(b, cborSimple|21), nil
+		}
+		return append(b, cborSimple|20), nil
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return cborAppendInt(b, i), nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return cborAppendFloat(b, f), nil
+	case int64:
+		return cborAppendInt(b, value), nil
+	case uint64:
+		return cborAppendHead(b, cborUnsigned, value), nil
+	case float64:
+		return cborAppendFloat(b, value), nil
+	case string:
+		b = cborAppendHead(b, cborText, uint64(len(value)))
+		return append(b, value...), nil
+	case []byte:
+		b = cborAppendHead(b, cborBytes, uint64(len(value)))
+		return append(b, value...), nil
+	case []interface{}:
+		b = cborAppendHead(b, cborArray, uint64(len(value)))
+		for _, item := range value {
+			b, err = cborAppend(b, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]interface{}:
+		// sort keys so the encoding is deterministic (this matters for ETags)
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b = cborAppendHead(b, cborMap, uint64(len(value)))
+		for _, key := range keys {
+			b, _ = cborAppend(b, key)
+			b, err = cborAppend(b, value[key])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported type %T", v)
+}
+
+func cborUnmarshal(b []byte) (interface{}, error) {
+	d := cborDecoder{data: b}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, errors.New("cbor: unexpected data after value")
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) next(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// head returns the major type, additional information and argument of the next data item
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info := b[0]&0xe0, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		b, err = d.next(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(b[0]), nil
+	case info == 25:
+		b, err = d.next(2)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err = d.next(4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err = d.next(8)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, binary.BigEndian.Uint64(b), nil
+	case info == cborIndefinite:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, errors.New("cbor: invalid additional information")
+}
+
+func (d *cborDecoder) atBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) value() (interface{}, error) {
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUnsigned:
+		return arg, nil
+	case cborNegative:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflow")
+		}
+		return -1 - int64(arg), nil
+	case cborBytes, cborText:
+		var s []byte
+		if info == cborIndefinite {
+			for !d.atBreak() {
+				chunk, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				switch c := chunk.(type) {
+				case string:
+					s = append(s, c...)
+				case []byte:
+					s = append(s, c...)
+				}
+			}
+		} else {
+			s, err = d.next(int(arg))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if major == cborText {
+			return string(s), nil
+		}
+		return append([]byte{}, s...), nil
+	case cborArray:
+		a := []interface{}{}
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.atBreak() {
+				break
+			}
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, item)
+		}
+		return a, nil
+	case cborMap:
+		m := make(map[string]interface{})
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.atBreak() {
+				break
+			}
+			key, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			switch k := key.(type) {
+			case string:
+				m[k] = value
+			case uint64:
+				m[strconv.FormatUint(k, 10)] = value
+			case int64:
+				m[strconv.FormatInt(k, 10)] = value
+			default:
+				return nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+		}
+		return m, nil
+	case cborTag:
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		if arg == 1 {
+			// epoch-based date/time
+			switch v := value.(type) {
+			case uint64:
+				return time.Unix(int64(v), 0), nil
+			case int64:
+				return time.Unix(v, 0), nil
+			case float64:
+				sec, frac := math.Modf(v)
+				return time.Unix(int64(sec), int64(frac*1e9)), nil
+			}
+		}
+		return value, nil
+	case cborSimple:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			return cborHalfToFloat(uint16(arg)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case 27:
+			return math.Float64frombits(arg), nil
+		}
+	}
+	return nil, errors.New("cbor: unsupported data item")
+}
+
+func cborHalfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
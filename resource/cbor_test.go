@@ -0,0 +1,158 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// most test vectors are from Appendix A of RFC 7049
+
+func TestCBORGoldenEncoding(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		encoded string
+	}{
+		{int64(0), "00"},
+		{int64(23), "17"},
+		{int64(24), "1818"},
+		{int64(100), "1864"},
+		{int64(1000), "1903e8"},
+		{int64(1000000), "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{int64(-1), "20"},
+		{int64(-1000), "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{json.Number("10"), "0a"},
+		{json.Number("-1.5"), "fbbff8000000000000"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]interface{}{}, "80"},
+		{[]interface{}{int64(1), int64(2), int64(3)}, "83010203"},
+		{map[string]interface{}{}, "a0"},
+		// keys are sorted
+		{map[string]interface{}{"b": []interface{}{int64(2), int64(3)}, "a": int64(1)}, "a26161016162820203"},
+	}
+
+	for _, test := range tests {
+		encoded, err := cborMarshal(test.value)
+		if err != nil {
+			t.Errorf("%#v: %s", test.value, err)
+			continue
+		}
+		if hex.EncodeToString(encoded) != test.encoded {
+			t.Errorf("%#v: got %x, want %s", test.value, encoded, test.encoded)
+		}
+	}
+}
+
+func TestCBORGoldenDecoding(t *testing.T) {
+	tests := []struct {
+		encoded string
+		value   interface{}
+	}{
+		{"00", uint64(0)},
+		{"1bffffffffffffffff", uint64(18446744073709551615)},
+		{"3903e7", int64(-1000)},
+		{"f93c00", 1.0},
+		{"f97bff", 65504.0},
+		{"f9c400", -4.0},
+		{"fa47c35000", 100000.0},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f6", nil},
+		{"6449455446", "IETF"},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"83018202039f0405ff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"9f018202039f0405ffff", []interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"a26161016162820203", map[string]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"bf61610161629f0203ffff", map[string]interface{}{"a": uint64(1), "b": []interface{}{uint64(2), uint64(3)}}},
+		{"a201020304", map[string]interface{}{"1": uint64(2), "3": uint64(4)}},
+		{"c11a514b67b0", time.Unix(1363896240, 0)},
+	}
+
+	for _, test := range tests {
+		b, _ := hex.DecodeString(test.encoded)
+		value, err := cborUnmarshal(b)
+		if err != nil {
+			t.Errorf("%s: %s", test.encoded, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, test.value) {
+			t.Errorf("%s: got %#v, want %#v", test.encoded, value, test.value)
+		}
+	}
+
+	value, err := cborUnmarshal([]byte{0xf9, 0x7c, 0x00})
+	if err != nil || value != math.Inf(1) {
+		t.Errorf("f97c00: got %#v (%v), want +Inf", value, err)
+	}
+}
+
+func TestCBORDecodingErrors(t *testing.T) {
+	for _, encoded := range []string{"", "18", "62c3", "8301", "a161", "0000", "1c", "ff"} {
+		b, _ := hex.DecodeString(encoded)
+		if _, err := cborUnmarshal(b); err == nil {
+			t.Errorf("%s: expected an error", encoded)
+		}
+	}
+}
+
+func TestCBORCodecRoundTrip(t *testing.T) {
+	route := apiRoute{
+		From:      "pt-ml-ap",
+		To:        "pt-ml-ss",
+		Departure: time.Date(2018, 3, 1, 8, 0, 0, 0, time.UTC),
+		Arrival:   time.Date(2018, 3, 1, 8, 25, 0, 0, time.UTC),
+		Duration:  1500,
+		Legs: []apiRouteLeg{{
+			Line:      "pt-ml-vermelha",
+			Direction: "pt-ml-ss",
+			Stations:  []string{"pt-ml-ap", "pt-ml-en", "pt-ml-ss"},
+			Departure: time.Date(2018, 3, 1, 8, 0, 0, 0, time.UTC),
+			Arrival:   time.Date(2018, 3, 1, 8, 25, 0, 0, time.UTC),
+		}},
+		Transfers: []apiRouteTransfer{},
+		Fare: &apiFare{
+			Total:    150,
+			Currency: "EUR",
+			Tickets:  []apiFareTicket{{Product: "single", Journeys: 1}},
+		},
+	}
+
+	codec := new(cborCodec)
+	encoded, err := codec.Encode(route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded apiRoute
+	err = codec.Decode(bytes.NewReader(encoded), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, route) {
+		t.Errorf("got %+v, want %+v", decoded, route)
+	}
+
+	// the encoding must be deterministic, since ETags are computed from it
+	again, err := codec.Encode(route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, again) {
+		t.Error("encoding the same value twice produced different results")
+	}
+}
@@ -0,0 +1,241 @@
+package resource
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// Codec encodes API responses and decodes API requests in a specific representation
+type Codec interface {
+	// ContentType returns the value of the Content-Type header of the encoded representation
+	ContentType() string
+	// Matches returns whether the codec should be used for a request with the specified Accept or Content-Type header
+	Matches(header string) bool
+	// CanEncode returns whether the codec is able to represent v
+	CanEncode(v interface{}) bool
+	// CanDecode returns whether the codec should be used to decode requests
+	CanDecode() bool
+	Encode(v interface{}) ([]byte, error)
+	Decode(r io.Reader, v interface{}) error
+}
+
+var (
+	codecsLock sync.RWMutex
+	// codecs are tried in the order they were registered
+	codecs = []Codec{
+		new(jsonCodec),
+		new(xmlCodec),
+		new(msgpackCodec),
+		new(cborCodec),
+		new(protobufCodec),
+	}
+	// defaultCodec is used when no other codec matches
+	defaultCodec Codec = &jsonCodec{indent: true}
+)
+
+// RegisterCodec adds a codec to the list of codecs used for requests and responses.
+// Codecs registered later have lower priority than the ones registered before
+func RegisterCodec(codec Codec) {
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	codecs = append(codecs, codec)
+}
+
+// codecForResponse returns the first codec that matches the Accept header and can encode v.
+// The default codec is returned when none matches
+func codecForResponse(header string, v interface{}) Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	for _, codec := range codecs {
+		if codec.Matches(header) && codec.CanEncode(v) {
+			return codec
+		}
+	}
+	return defaultCodec
+}
+
+// codecForRequest returns the first codec that matches the Content-Type header and can decode requests.
+// The default codec is returned when none matches
+func codecForRequest(header string) Codec {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	for _, codec := range codecs {
+		if codec.Matches(header) && codec.CanDecode() {
+			return codec
+		}
+	}
+	return defaultCodec
+}
+
+type jsonCodec struct {
+	indent bool
+}
+
+func (c *jsonCodec) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+func (c *jsonCodec) Matches(header string) bool {
+	return strings.Contains(header, "json")
+}
+
+func (c *jsonCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *jsonCodec) CanDecode() bool {
+	return true
+}
+
+func (c *jsonCodec) Encode(v interface{}) ([]byte, error) {
+	if c.indent {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
+
+func (c *jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// xmlCodec is only used for responses: the request types are not designed to be decoded from XML,
+// and no client submits data in this representation
+type xmlCodec struct{}
+
+func (c *xmlCodec) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+func (c *xmlCodec) Matches(header string) bool {
+	return strings.Contains(header, "xml") && !strings.Contains(header, "xhtml")
+}
+
+func (c *xmlCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *xmlCodec) CanDecode() bool {
+	return false
+}
+
+func (c *xmlCodec) Encode(v interface{}) ([]byte, error) {
+	return xml.Marshal(v)
+}
+
+func (c *xmlCodec) Decode(r io.Reader, v interface{}) error {
+	return errors.New("XML requests are not supported")
+}
+
+type msgpackCodec struct{}
+
+func (c *msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (c *msgpackCodec) Matches(header string) bool {
+	return strings.Contains(header, "msgpack")
+}
+
+func (c *msgpackCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *msgpackCodec) CanDecode() bool {
+	return true
+}
+
+func (c *msgpackCodec) Encode(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (c *msgpackCodec) Decode(r io.Reader, v interface{}) error {
+	return msgpack.NewDecoder(r).Decode(v)
+}
+
+// cborCodec uses the same structure as the JSON representation
+type cborCodec struct{}
+
+func (c *cborCodec) ContentType() string {
+	return "application/cbor"
+}
+
+func (c *cborCodec) Matches(header string) bool {
+	return strings.Contains(header, "cbor")
+}
+
+func (c *cborCodec) CanEncode(v interface{}) bool {
+	return true
+}
+
+func (c *cborCodec) CanDecode() bool {
+	return true
+}
+
+func (c *cborCodec) Encode(v interface{}) ([]byte, error) {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(j)))
+	decoder.UseNumber()
+	var generic interface{}
+	err = decoder.Decode(&generic)
+	if err != nil {
+		return nil, err
+	}
+	return cborMarshal(generic)
+}
+
+func (c *cborCodec) Decode(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	generic, err := cborUnmarshal(b)
+	if err != nil {
+		return err
+	}
+	j, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
+
+// protobufCodec encodes the types with protobuf struct tags, see api.proto
+type protobufCodec struct{}
+
+func (c *protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (c *protobufCodec) Matches(header string) bool {
+	return strings.Contains(header, "protobuf")
+}
+
+func (c *protobufCodec) CanEncode(v interface{}) bool {
+	return protobufCanEncode(v)
+}
+
+func (c *protobufCodec) CanDecode() bool {
+	return true
+}
+
+func (c *protobufCodec) Encode(v interface{}) ([]byte, error) {
+	return protobufMarshal(v)
+}
+
+func (c *protobufCodec) Decode(r io.Reader, v interface{}) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return protobufUnmarshal(b, v)
+}
@@ -0,0 +1,416 @@
+package resource
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// the pair used by the authenticated requests is created by testdata/contract_fixtures.sql
+const (
+	contractTestPairKey    = "contract-pair-01"
+	contractTestPairSecret = "contract-test-secret"
+)
+
+var contractTestHashKey = []byte("contract-test-hash-key")
+
+// contractTestRequest is a request made by TestAPIContracts.
+// route is the path the resource is registered with, as used in apiOperations
+type contractTestRequest struct {
+	method string
+	route  string
+	url    string
+	body   string
+}
+
+// contractTestRequests are made in order, so requests that change or delete the fixtures come last
+var contractTestRequests = []contractTestRequest{
+	{"GET", "/v1/meta", "/v1/meta", ""},
+	{"GET", "/v1/gateways", "/v1/gateways", ""},
+	{"GET", "/v1/maps", "/v1/maps", ""},
+	{"GET", "/v1/networks", "/v1/networks", ""},
+	{"GET", "/v1/networks/:id", "/v1/networks/pt-ml", ""},
+	{"GET", "/v1/networks/:id/schematic", "/v1/networks/pt-ml/schematic", ""},
+	{"GET", "/v1/networks/:id/geojson", "/v1/networks/pt-ml/geojson?locale=en", ""},
+	{"GET", "/v1/lines", "/v1/lines", ""},
+	{"GET", "/v1/lines/:id", "/v1/lines/pt-ml-azul", ""},
+	{"GET", "/v1/lines/:id", "/v1/lines/conditions", ""},
+	{"GET", "/v1/lines/conditions/:id", "/v1/lines/conditions/condition-1", ""},
+	{"GET", "/v1/lines/:lineid/conditions", "/v1/lines/pt-ml-azul/conditions", ""},
+	{"GET", "/v1/stations", "/v1/stations", ""},
+	{"GET", "/v1/stations/:id", "/v1/stations/pt-ml-bc", ""},
+	{"GET", "/v1/stations/:sid/lobbies", "/v1/stations/pt-ml-bc/lobbies", ""},
+	{"GET", "/v1/lobbies", "/v1/lobbies", ""},
+	{"GET", "/v1/lobbies/:id", "/v1/lobbies/pt-ml-bc-baixa", ""},
+	{"GET", "/v1/fares", "/v1/fares?network=pt-ml", ""},
+	{"GET", "/v1/fares/:id", "/v1/fares/pt-ml-single", ""},
+	{"GET", "/v1/pois", "/v1/pois", ""},
+	{"GET", "/v1/pois/:id", "/v1/pois/poi-arco", ""},
+	{"GET", "/v1/connections", "/v1/connections", ""},
+	{"GET", "/v1/connections/:from/:to", "/v1/connections/pt-ml-rs/pt-ml-bc", ""},
+	{"GET", "/v1/transfers", "/v1/transfers", ""},
+	{"GET", "/v1/transfers/:station/:from/:to", "/v1/transfers/pt-ml-bc/pt-ml-azul/pt-ml-verde", ""},
+	{"GET", "/v1/disturbances", "/v1/disturbances?start=2018-01-01T00:00:00Z", ""},
+	{"GET", "/v1/disturbances/:id", "/v1/disturbances/disturbance-1?expand=alternatives", ""},
+	{"GET", "/v1/datasets", "/v1/datasets", ""},
+	{"GET", "/v1/datasets/:id", "/v1/datasets/pt-ml", ""},
+	{"GET", "/v1/stats", "/v1/stats", ""},
+	{"GET", "/v1/stats/:id", "/v1/stats/pt-ml?start=2018-01-01T00:00:00Z", ""},
+	{"GET", "/v1/announcements", "/v1/announcements", ""},
+	{"GET", "/v1/announcements/:source", "/v1/announcements/pt-ml-facebook", ""},
+	{"GET", "/v1/trips", "/v1/trips", ""},
+	{"GET", "/v1/trips/provisional", "/v1/trips/provisional", ""},
+	{"GET", "/v1/trips/provisional/:id", "/v1/trips/provisional/provisional-trip-1", ""},
+	{"GET", "/v1/trips/:id", "/v1/trips/trip-1", ""},
+	{"GET", "/v1/pair/challenge/:id", "/v1/pair/challenge/challenge-pending", ""},
+	{"GET", "/v1/pair/connections", "/v1/pair/connections", ""},
+	{"GET", "/v1/pair/data", "/v1/pair/data", ""},
+	{"GET", "/v1/authtest", "/v1/authtest", ""},
+	{"GET", "/v1/routes", "/v1/routes?from=pt-ml-rs&to=pt-ml-ro&departAt=2018-03-01T10:00:00Z", ""},
+	{"GET", "/v1/nearby", "/v1/nearby?lat=38.7106&lon=-9.1405", ""},
+	{"GET", "/v1/isochrones/:station", "/v1/isochrones/pt-ml-bc?departAt=2018-03-01T10:00:00Z", ""},
+	{"GET", "/v1/openapi.json", "/v1/openapi.json", ""},
+	{"GET", "/v2/disturbances", "/v2/disturbances", ""},
+	{"GET", "/v2/disturbances/:id", "/v2/disturbances/disturbance-1", ""},
+	{"GET", "/v2/stations", "/v2/stations", ""},
+	{"GET", "/v2/stations/:id", "/v2/stations/pt-ml-bc", ""},
+	{"GET", "/v2/trips", "/v2/trips", ""},
+	{"GET", "/v2/trips/:id", "/v2/trips/trip-1", ""},
+
+	{"POST", "/v1/rt", "/v1/rt", `{"s": "pt-ml-bc", "d": "pt-ml-ro"}`},
+	{"POST", "/v1/feedback", "/v1/feedback",
+		`{"id": "0f4b8bb4-5c6b-4d43-9f6e-2f4d3c2f1a10", "timestamp": "2018-03-01T10:00:00Z", "type": "bug", "contents": "test"}`},
+	{"POST", "/v1/wifiaps/observations", "/v1/wifiaps/observations",
+		`{"stationId": "pt-ml-bc", "networks": [{"bssid": "00:11:22:33:44:66", "ssid": "metrolisboa", "level": -60}]}`},
+	{"POST", "/v1/pair/challenge", "/v1/pair/challenge", `{"type": "web"}`},
+	{"POST", "/v1/pair", "/v1/pair", `{"type": "web", "challenge": "challenge-approved"}`},
+	{"DELETE", "/v1/trips/provisional/:id", "/v1/trips/provisional/provisional-trip-1", ""},
+	// the secret used by the test remains valid after the rotation, as the previous secret
+	{"POST", "/v1/pair/rotate", "/v1/pair/rotate", ""},
+	{"DELETE", "/v1/pair/data", "/v1/pair/data", ""},
+}
+
+// contractTestSkipped are the documented operations that TestAPIContracts doesn't request, and why
+var contractTestSkipped = map[string]string{
+	"GET /v1/meta/backers":                      "serves files from the stationkb repository, which tests don't have",
+	"GET /v1/clientconfigs/android":             "lists files from the stationkb repository, which tests don't have",
+	"GET /v1/stationkb/*":                       "served by the Static resource of the main package",
+	"GET /v1/mapassets/*":                       "served by the Static resource of the main package",
+	"GET /v1/clientconfigs/android/overlayfs/*": "served by the Static resource of the main package",
+	"GET /v1/events":                            "the event stream never ends",
+	"POST /v1/disturbances/reports":             "accepted reports are sent to PosPlay, which doesn't run in tests",
+	"POST /v1/trips":                            "submitted trips are sent to PosPlay, which doesn't run in tests",
+	"PUT /v1/trips/:id":                         "edited trips are sent to PosPlay, which doesn't run in tests",
+	"POST /v1/pair/connections":                 "connections are handled by PosPlay, which doesn't run in tests",
+}
+
+type contractTestRoute struct {
+	route   string
+	handler yarf.ResourceHandler
+}
+
+// contractTestRoutes returns the resources that serve contractTestRequests, set up like in APIserver and in the same order,
+// as yarf matches routes in the order they were added
+func contractTestRoutes(node sqalx.Node) []contractTestRoute {
+	sdb := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+	stats := contractTestStats{}
+	announcements := contractTestAnnouncements{{
+		Time:    time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC),
+		Network: &types.Network{ID: "pt-ml"},
+		Title:   "Test",
+		Body:    "Test announcement",
+		URL:     "https://example.com/",
+		Source:  "pt-ml-facebook",
+	}}
+	challengeVerifier := NewChallengePairRequestVerifier("web")
+	approvalURL := func(challengeID string) string {
+		return "https://example.com/pair/" + challengeID
+	}
+	openAPI := new(OpenAPI)
+
+	routes := []contractTestRoute{
+		{"/v1/meta", new(Meta).WithNode(node)},
+		{"/v1/gateways", new(Gateway)},
+		{"/v1/maps", new(Map).WithNode(node)},
+		{"/v1/networks", new(Network).WithNode(node)},
+		{"/v1/networks/:id", new(Network).WithNode(node)},
+		{"/v1/networks/:id/geojson", new(NetworkGeoJSON).WithNode(node)},
+		{"/v1/networks/:id/schematic", new(SchematicMap).WithNode(node)},
+		{"/v1/lines", new(Line).WithNode(node)},
+		{"/v1/lines/:id", new(Line).WithNode(node)},
+		{"/v1/lines/conditions/:id", new(LineCondition).WithNode(node)},
+		{"/v1/lines/:lineid/conditions", new(LineCondition).WithNode(node)},
+		{"/v1/stations", new(Station).WithNode(node)},
+		{"/v1/stations/:id", new(Station).WithNode(node)},
+		{"/v1/stations/:sid/lobbies", new(Lobby).WithNode(node)},
+		{"/v1/lobbies", new(Lobby).WithNode(node)},
+		{"/v1/lobbies/:id", new(Lobby).WithNode(node)},
+		{"/v1/fares", new(Fare).WithNode(node)},
+		{"/v1/fares/:id", new(Fare).WithNode(node)},
+		{"/v1/pois", new(POI).WithNode(node)},
+		{"/v1/pois/:id", new(POI).WithNode(node)},
+		{"/v1/connections", new(Connection).WithNode(node)},
+		{"/v1/connections/:from/:to", new(Connection).WithNode(node)},
+		{"/v1/transfers", new(Transfer).WithNode(node)},
+		{"/v1/transfers/:station/:from/:to", new(Transfer).WithNode(node)},
+		{"/v1/disturbances", new(Disturbance).WithNode(node)},
+		{"/v1/disturbances/:id", new(Disturbance).WithNode(node)},
+		{"/v1/datasets", new(Dataset).WithNode(node).WithSquirrel(&sdb)},
+		{"/v1/datasets/:id", new(Dataset).WithNode(node).WithSquirrel(&sdb)},
+		{"/v1/stats", new(Stats).WithNode(node).WithStats(stats).WithCrowding(stats)},
+		{"/v1/stats/:id", new(Stats).WithNode(node).WithStats(stats).WithCrowding(stats)},
+		{"/v1/announcements", new(Announcement).WithAnnouncementStore(announcements)},
+		{"/v1/announcements/:source", new(Announcement).WithAnnouncementStore(announcements)},
+		{"/v1/trips", new(Trip).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/trips/provisional", new(ProvisionalTrip).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/trips/provisional/:id", new(ProvisionalTrip).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/trips/:id", new(Trip).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/rt", new(Realtime).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/feedback", new(Feedback).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/wifiaps/observations", new(WiFiAPObservation).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/pair", new(Pair).WithNode(node).WithVerifier(challengeVerifier).WithHashKey(contractTestHashKey)},
+		{"/v1/pair/challenge", new(PairChallenge).WithNode(node).WithVerifier(challengeVerifier).WithApprovalURL(approvalURL)},
+		{"/v1/pair/challenge/:id", new(PairChallenge).WithNode(node).WithVerifier(challengeVerifier).WithApprovalURL(approvalURL)},
+		{"/v1/pair/connections", new(PairConnection).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/pair/rotate", new(PairRotation).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/pair/data", new(PersonalData).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/authtest", new(AuthTest).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v1/routes", new(Route).WithNode(node)},
+		{"/v1/nearby", new(Nearby).WithNode(node)},
+		{"/v1/isochrones/:station", new(Isochrone).WithNode(node)},
+		{"/v1/openapi.json", openAPI},
+		{"/v2/disturbances", new(DisturbanceV2).WithNode(node)},
+		{"/v2/disturbances/:id", new(DisturbanceV2).WithNode(node)},
+		{"/v2/stations", new(StationV2).WithNode(node)},
+		{"/v2/stations/:id", new(StationV2).WithNode(node)},
+		{"/v2/trips", new(TripV2).WithNode(node).WithHashKey(contractTestHashKey)},
+		{"/v2/trips/:id", new(TripV2).WithNode(node).WithHashKey(contractTestHashKey)},
+	}
+
+	paths := make([]string, len(routes))
+	for i := range routes {
+		paths[i] = routes[i].route
+	}
+	openAPI.WithRoutes(paths)
+	return routes
+}
+
+// contractTestStats is a StatsCalculator and CrowdingEstimator with fixed results
+type contractTestStats struct{}
+
+func (contractTestStats) OITInNetwork(network *types.Network, approximateTo int) int {
+	return 5
+}
+
+func (contractTestStats) OITInLine(line *types.Line, approximateTo int) int {
+	return 5
+}
+
+func (contractTestStats) LineCrowding(line *types.Line) *compute.Crowding {
+	return &compute.Crowding{
+		Expected:      10,
+		Current:       12,
+		ExpectedLevel: compute.CrowdingLow,
+		CurrentLevel:  compute.CrowdingMedium,
+	}
+}
+
+func (s contractTestStats) StationCrowding(node sqalx.Node, station *types.Station) (*compute.Crowding, error) {
+	return s.LineCrowding(nil), nil
+}
+
+// contractTestAnnouncements is a types.AnnouncementStore with fixed announcements
+type contractTestAnnouncements []*types.Announcement
+
+func (a contractTestAnnouncements) AllAnnouncements() []*types.Announcement {
+	return a
+}
+
+func (a contractTestAnnouncements) SourceAnnouncements(source string) []*types.Announcement {
+	anns := []*types.Announcement{}
+	for _, ann := range a {
+		if ann.Source == source {
+			anns = append(anns, ann)
+		}
+	}
+	return anns
+}
+
+func TestAPIContractCoverage(t *testing.T) {
+	routes := make(map[string]bool)
+	for _, route := range contractTestRoutes(nil) {
+		routes[route.route] = true
+	}
+
+	requested := make(map[string]bool)
+	for _, request := range contractTestRequests {
+		if !routes[request.route] {
+			t.Errorf("%s %s: no resource is registered for the route", request.method, request.route)
+		}
+		requested[request.method+" "+request.route] = true
+	}
+
+	documented := make(map[string]bool)
+	for route, operations := range apiOperations {
+		for _, op := range operations {
+			operation := op.Method + " " + route
+			documented[operation] = true
+			_, skipped := contractTestSkipped[operation]
+			if !requested[operation] && !skipped {
+				t.Errorf("%s is not requested by TestAPIContracts", operation)
+			} else if requested[operation] && skipped {
+				t.Errorf("%s is requested by TestAPIContracts, but is also marked as skipped", operation)
+			}
+		}
+	}
+	for operation := range requested {
+		if !documented[operation] {
+			t.Errorf("%s is requested by TestAPIContracts, but is not documented", operation)
+		}
+	}
+	for operation := range contractTestSkipped {
+		if !documented[operation] {
+			t.Errorf("%s is marked as skipped, but is not documented", operation)
+		}
+	}
+}
+
+// TestAPIContracts makes contractTestRequests against a database seeded with testdata/contract_fixtures.sql,
+// and checks the responses against the OpenAPI document, once with JSON and once with msgpack responses.
+// It needs a PostgreSQL database, specified in TEST_DATABASE_URL, where it creates (and then drops) a schema
+func TestAPIContracts(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	for _, accept := range []string{"application/json", "application/msgpack"} {
+		t.Run(accept, func(t *testing.T) {
+			// requests change the fixtures, so each encoding gets its own database
+			node := newContractTestDatabase(t, databaseURL)
+			y := yarf.New()
+			for _, route := range contractTestRoutes(node) {
+				y.Add(route.route, route.handler)
+			}
+
+			for _, request := range contractTestRequests {
+				var body io.Reader
+				if request.body != "" {
+					body = strings.NewReader(request.body)
+				}
+				req := httptest.NewRequest(request.method, request.url, body)
+				req.Header.Set("Accept", accept)
+				// request bodies are always JSON, only the encoding of the responses is being tested
+				req.Header.Set("Content-Type", "application/json")
+				req.SetBasicAuth(contractTestPairKey, contractTestPairSecret)
+				rec := httptest.NewRecorder()
+				y.ServeHTTP(rec, req)
+
+				if rec.Code == http.StatusUnauthorized {
+					// the OpenAPI document allows this, but it means the fixtures are broken
+					t.Errorf("%s %s: authentication failed", request.method, request.url)
+					continue
+				}
+				err := ValidateAgainstOpenAPIDocument(request.route, request.method, rec.Code,
+					rec.Header().Get("Content-Type"), rec.Body.Bytes())
+				if err != nil {
+					t.Errorf("%s %s: %s\n%s", request.method, request.url, err, rec.Body.String())
+				}
+			}
+		})
+	}
+}
+
+// newContractTestDatabase creates a schema with the tables in schema.sql and the fixtures in testdata/contract_fixtures.sql,
+// which is dropped once the test finishes
+func newContractTestDatabase(t *testing.T, databaseURL string) sqalx.Node {
+	schemaName := fmt.Sprintf("contract_test_%d", time.Now().UnixNano())
+
+	adminDB, err := sqlx.Open("postgres", databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = adminDB.Exec("CREATE SCHEMA " + schemaName)
+	if err != nil {
+		adminDB.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, err := adminDB.Exec("DROP SCHEMA " + schemaName + " CASCADE")
+		if err != nil {
+			t.Error(err)
+		}
+		adminDB.Close()
+	})
+
+	db, err := sqlx.Open("postgres", withSearchPath(databaseURL, schemaName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// runs before the schema is dropped
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	schema, err := ioutil.ReadFile("../schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// schema.sql starts by dropping the tables it creates, which don't exist in the new schema
+	statements := []string{}
+	for _, line := range strings.Split(string(schema), "\n") {
+		if !strings.HasPrefix(line, "DROP TABLE") {
+			statements = append(statements, line)
+		}
+	}
+	_, err = db.Exec(strings.Join(statements, "\n"))
+	if err != nil {
+		t.Fatalf("schema.sql: %s", err)
+	}
+
+	fixtures, err := ioutil.ReadFile("testdata/contract_fixtures.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(string(fixtures))
+	if err != nil {
+		t.Fatalf("contract_fixtures.sql: %s", err)
+	}
+	_, err = db.Exec("UPDATE api_pair SET secret = $1 WHERE key = $2",
+		types.ComputeAPISecretHash(contractTestPairSecret, contractTestHashKey), contractTestPairKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := sqalx.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return node
+}
+
+// withSearchPath adds the search_path run-time parameter to a lib/pq connection string, in URL or key=value format
+func withSearchPath(databaseURL, schemaName string) string {
+	if u, err := url.Parse(databaseURL); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		query.Set("search_path", schemaName)
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	return databaseURL + " search_path=" + schemaName
+}
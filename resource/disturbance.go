@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/yarf-framework/yarf"
 )
@@ -14,42 +15,44 @@ type Disturbance struct {
 }
 
 type apiDisturbance struct {
-	ID          string                `msgpack:"id" json:"id"`
-	Official    bool                  `msgpack:"official" json:"official"`
-	OStartTime  time.Time             `msgpack:"oStartTime" json:"oStartTime"`
-	OEndTime    time.Time             `msgpack:"oEndTime" json:"oEndTime"`
-	OEnded      bool                  `msgpack:"oEnded" json:"oEnded"`
-	UStartTime  time.Time             `msgpack:"startTime" json:"startTime"`
-	UEndTime    time.Time             `msgpack:"endTime" json:"endTime"`
-	UEnded      bool                  `msgpack:"ended" json:"ended"`
+	ID          string                `msgpack:"id" json:"id" protobuf:"1"`
+	Official    bool                  `msgpack:"official" json:"official" protobuf:"2"`
+	OStartTime  time.Time             `msgpack:"oStartTime" json:"oStartTime" protobuf:"3"`
+	OEndTime    time.Time             `msgpack:"oEndTime" json:"oEndTime" protobuf:"4"`
+	OEnded      bool                  `msgpack:"oEnded" json:"oEnded" protobuf:"5"`
+	UStartTime  time.Time             `msgpack:"startTime" json:"startTime" protobuf:"6"`
+	UEndTime    time.Time             `msgpack:"endTime" json:"endTime" protobuf:"7"`
+	UEnded      bool                  `msgpack:"ended" json:"ended" protobuf:"8"`
 	Line        *types.Line     `msgpack:"-" json:"-"`
-	Description string                `msgpack:"description" json:"description"`
-	Notes       string                `msgpack:"notes" json:"notes"`
+	Description string                `msgpack:"description" json:"description" protobuf:"9"`
+	Notes       string                `msgpack:"notes" json:"notes" protobuf:"10"`
 	Statuses    []*types.Status `msgpack:"-" json:"-"`
 }
 
 type apiDisturbanceWrapper struct {
 	apiDisturbance `msgpack:",inline"`
-	NetworkID      string                            `msgpack:"network" json:"network"`
-	LineID         string                            `msgpack:"line" json:"line"`
-	Categories     []types.DisturbanceCategory `msgpack:"categories" json:"categories"`
-	APIstatuses    []apiStatusWrapper                `msgpack:"statuses" json:"statuses"`
+	NetworkID      string                            `msgpack:"network" json:"network" protobuf:"11"`
+	LineID         string                            `msgpack:"line" json:"line" protobuf:"12"`
+	Categories     []types.DisturbanceCategory `msgpack:"categories" json:"categories" protobuf:"13"`
+	APIstatuses    []apiStatusWrapper                `msgpack:"statuses" json:"statuses" protobuf:"14"`
+	// Alternatives is only included when requested with expand=alternatives, and only for ongoing disturbances
+	Alternatives []apiRoute `msgpack:"alternatives,omitempty" json:"alternatives,omitempty" protobuf:"15"`
 }
 
 type apiStatus struct {
-	ID         string                        `msgpack:"id" json:"id"`
-	Time       time.Time                     `msgpack:"time" json:"time"`
+	ID         string                        `msgpack:"id" json:"id" protobuf:"1"`
+	Time       time.Time                     `msgpack:"time" json:"time" protobuf:"2"`
 	Line       *types.Line             `msgpack:"-" json:"-"`
-	IsDowntime bool                          `msgpack:"downtime" json:"downtime"`
-	Status     string                        `msgpack:"status" json:"status"`
+	IsDowntime bool                          `msgpack:"downtime" json:"downtime" protobuf:"3"`
+	Status     string                        `msgpack:"status" json:"status" protobuf:"4"`
 	Source     *types.Source           `msgpack:"-" json:"-"`
-	MsgType    types.StatusMessageType `msgpack:"msgType" json:"msgType"`
+	MsgType    types.StatusMessageType `msgpack:"msgType" json:"msgType" protobuf:"5"`
 }
 
 type apiStatusWrapper struct {
 	apiStatus      `msgpack:",inline"`
-	SourceID       string `msgpack:"source" json:"source"`
-	OfficialSource bool   `msgpack:"officialSource" json:"officialSource"`
+	SourceID       string `msgpack:"source" json:"source" protobuf:"6"`
+	OfficialSource bool   `msgpack:"officialSource" json:"officialSource" protobuf:"7"`
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -67,30 +70,23 @@ func (r *Disturbance) Get(c *yarf.Context) error {
 	defer tx.Commit() // read-only tx
 
 	omitDuplicateStatus := c.Request.URL.Query().Get("omitduplicatestatus") == "true"
+	expandAlternatives := c.Request.URL.Query().Get("expand") == "alternatives"
+	// the graph used to compute alternatives is expensive to build, so it is built at most once per request
+	alternatives := &disturbanceAlternatives{
+		node:           tx,
+		accessibleOnly: c.Request.URL.Query().Get("accessible") == "true",
+	}
 
 	if c.Param("id") != "" {
 		disturbance, err := types.GetDisturbance(tx, c.Param("id"))
 		if err != nil {
 			return err
 		}
-		data := apiDisturbanceWrapper{
-			apiDisturbance: apiDisturbance(*disturbance),
-			NetworkID:      disturbance.Line.Network.ID,
-			LineID:         disturbance.Line.ID,
-			Categories:     disturbance.Categories(),
-		}
-
-		data.APIstatuses = []apiStatusWrapper{}
-		prevStatusText := ""
-		for i, status := range disturbance.Statuses {
-			sw := apiStatusWrapper{
-				apiStatus:      apiStatus(*status),
-				SourceID:       status.Source.ID,
-				OfficialSource: status.Source.Official,
-			}
-			if !omitDuplicateStatus || prevStatusText != status.Status || i == 0 {
-				prevStatusText = status.Status
-				data.APIstatuses = append(data.APIstatuses, sw)
+		data := buildAPIDisturbanceWrapper(disturbance, omitDuplicateStatus)
+		if expandAlternatives {
+			err = alternatives.add(&data, disturbance)
+			if err != nil {
+				return err
 			}
 		}
 
@@ -129,24 +125,11 @@ func (r *Disturbance) Get(c *yarf.Context) error {
 		}
 		apidisturbances := make([]apiDisturbanceWrapper, len(disturbances))
 		for i := range disturbances {
-			apidisturbances[i] = apiDisturbanceWrapper{
-				apiDisturbance: apiDisturbance(*disturbances[i]),
-				NetworkID:      disturbances[i].Line.Network.ID,
-				LineID:         disturbances[i].Line.ID,
-				Categories:     disturbances[i].Categories(),
-			}
-
-			apidisturbances[i].APIstatuses = []apiStatusWrapper{}
-			prevStatusText := ""
-			for j, status := range disturbances[i].Statuses {
-				sw := apiStatusWrapper{
-					apiStatus:      apiStatus(*status),
-					SourceID:       status.Source.ID,
-					OfficialSource: status.Source.Official,
-				}
-				if !omitDuplicateStatus || prevStatusText != status.Status || j == 0 {
-					prevStatusText = status.Status
-					apidisturbances[i].APIstatuses = append(apidisturbances[i].APIstatuses, sw)
+			apidisturbances[i] = buildAPIDisturbanceWrapper(disturbances[i], omitDuplicateStatus)
+			if expandAlternatives {
+				err = alternatives.add(&apidisturbances[i], disturbances[i])
+				if err != nil {
+					return err
 				}
 			}
 		}
@@ -154,3 +137,57 @@ func (r *Disturbance) Get(c *yarf.Context) error {
 	}
 	return nil
 }
+
+func buildAPIDisturbanceWrapper(disturbance *types.Disturbance, omitDuplicateStatus bool) apiDisturbanceWrapper {
+	data := apiDisturbanceWrapper{
+		apiDisturbance: apiDisturbance(*disturbance),
+		NetworkID:      disturbance.Line.Network.ID,
+		LineID:         disturbance.Line.ID,
+		Categories:     disturbance.Categories(),
+	}
+
+	data.APIstatuses = []apiStatusWrapper{}
+	prevStatusText := ""
+	for i, status := range disturbance.Statuses {
+		sw := apiStatusWrapper{
+			apiStatus:      apiStatus(*status),
+			SourceID:       status.Source.ID,
+			OfficialSource: status.Source.Official,
+		}
+		if !omitDuplicateStatus || prevStatusText != status.Status || i == 0 {
+			prevStatusText = status.Status
+			data.APIstatuses = append(data.APIstatuses, sw)
+		}
+	}
+	return data
+}
+
+// disturbanceAlternatives computes the alternative routes to the lines of ongoing disturbances,
+// lazily building a routing graph that is reused for all of them
+type disturbanceAlternatives struct {
+	node           sqalx.Node
+	accessibleOnly bool
+	graph          *compute.RoutingGraph
+}
+
+func (a *disturbanceAlternatives) add(data *apiDisturbanceWrapper, disturbance *types.Disturbance) error {
+	if disturbance.UEnded {
+		return nil
+	}
+	if a.graph == nil {
+		var err error
+		a.graph, err = compute.NewAlternativeRoutingGraph(a.node, a.accessibleOnly)
+		if err != nil {
+			return err
+		}
+	}
+	routes, err := a.graph.AlternativeRoutes(disturbance.Line, time.Now())
+	if err != nil {
+		return err
+	}
+	data.Alternatives = make([]apiRoute, len(routes))
+	for i := range routes {
+		data.Alternatives[i] = buildAPIRoute(routes[i])
+	}
+	return nil
+}
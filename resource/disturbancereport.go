@@ -43,9 +43,9 @@ func (r *DisturbanceReport) WithReportHandler(handler ReportHandler) *Disturbanc
 
 // Post serves HTTP POST requests on this resource
 func (r *DisturbanceReport) Post(c *yarf.Context) error {
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeReports)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -0,0 +1,156 @@
+package resource
+
+import (
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// Fare composites resource
+type Fare struct {
+	resource
+}
+
+type apiFareZone struct {
+	ID       string   `msgpack:"id" json:"id" protobuf:"1"`
+	Name     string   `msgpack:"name" json:"name" protobuf:"2"`
+	Network  string   `msgpack:"network" json:"network" protobuf:"3"`
+	Stations []string `msgpack:"stations" json:"stations" protobuf:"4"`
+}
+
+// prices are in the smallest unit of the currency
+type apiFareProduct struct {
+	ID         string         `msgpack:"id" json:"id" protobuf:"1"`
+	Name       string         `msgpack:"name" json:"name" protobuf:"2"`
+	Network    string         `msgpack:"network" json:"network" protobuf:"3"`
+	Price      int            `msgpack:"price" json:"price" protobuf:"4"`
+	Currency   string         `msgpack:"currency" json:"currency" protobuf:"5"`
+	Validity   types.Duration `msgpack:"validity" json:"validity" protobuf:"6"`
+	MaxEntries int            `msgpack:"maxEntries" json:"maxEntries" protobuf:"7"`
+	Zones      []string       `msgpack:"zones" json:"zones" protobuf:"8"`
+}
+
+type apiFares struct {
+	Zones    []apiFareZone    `msgpack:"zones" json:"zones" protobuf:"1"`
+	Products []apiFareProduct `msgpack:"products" json:"products" protobuf:"2"`
+}
+
+type apiFare struct {
+	Total    int             `msgpack:"total" json:"total" protobuf:"1"`
+	Currency string          `msgpack:"currency" json:"currency" protobuf:"2"`
+	Tickets  []apiFareTicket `msgpack:"tickets" json:"tickets" protobuf:"3"`
+}
+
+// Journeys is the number of consecutive journeys paid for with the product
+type apiFareTicket struct {
+	Product  string `msgpack:"product" json:"product" protobuf:"1"`
+	Journeys int    `msgpack:"journeys" json:"journeys" protobuf:"2"`
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *Fare) WithNode(node sqalx.Node) *Fare {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *Fare) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	if c.Param("id") != "" {
+		product, err := types.GetFareProduct(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+		RenderData(c, buildAPIFareProduct(product), "s-maxage=10")
+		return nil
+	}
+
+	var zones []*types.FareZone
+	var products []*types.FareProduct
+	if networkID := c.Request.URL.Query().Get("network"); networkID != "" {
+		network, err := types.GetNetwork(tx, networkID)
+		if err != nil {
+			return err
+		}
+		zones, err = network.FareZones(tx)
+		if err != nil {
+			return err
+		}
+		products, err = network.FareProducts(tx)
+		if err != nil {
+			return err
+		}
+	} else {
+		zones, err = types.GetFareZones(tx)
+		if err != nil {
+			return err
+		}
+		products, err = types.GetFareProducts(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	data := apiFares{
+		Zones:    make([]apiFareZone, len(zones)),
+		Products: make([]apiFareProduct, len(products)),
+	}
+	for i, zone := range zones {
+		stations, err := zone.Stations(tx)
+		if err != nil {
+			return err
+		}
+		data.Zones[i] = apiFareZone{
+			ID:       zone.ID,
+			Name:     zone.Name,
+			Network:  zone.Network.ID,
+			Stations: make([]string, len(stations)),
+		}
+		for j := range stations {
+			data.Zones[i].Stations[j] = stations[j].ID
+		}
+	}
+	for i := range products {
+		data.Products[i] = buildAPIFareProduct(products[i])
+	}
+	RenderData(c, data, "s-maxage=10")
+	return nil
+}
+
+func buildAPIFareProduct(product *types.FareProduct) apiFareProduct {
+	data := apiFareProduct{
+		ID:         product.ID,
+		Name:       product.Name,
+		Network:    product.Network.ID,
+		Price:      product.Price,
+		Currency:   product.Currency,
+		Validity:   product.Validity,
+		MaxEntries: product.MaxEntries,
+		Zones:      make([]string, len(product.Zones)),
+	}
+	for i := range product.Zones {
+		data.Zones[i] = product.Zones[i].ID
+	}
+	return data
+}
+
+func buildAPIFare(fare *compute.Fare) *apiFare {
+	data := &apiFare{
+		Total:    fare.Total,
+		Currency: fare.Currency,
+		Tickets:  make([]apiFareTicket, len(fare.Tickets)),
+	}
+	for i, ticket := range fare.Tickets {
+		data.Tickets[i] = apiFareTicket{
+			Product:  ticket.Product.ID,
+			Journeys: len(ticket.Journeys),
+		}
+	}
+	return data
+}
@@ -9,6 +9,18 @@ import (
 	"github.com/yarf-framework/yarf"
 )
 
+// FeedbackListener is notified of the feedback submitted through the API
+type FeedbackListener interface {
+	FeedbackSubmitted(feedback *types.Feedback)
+}
+
+var feedbackListeners = []FeedbackListener{}
+
+// RegisterFeedbackListener registers a feedback listener
+func RegisterFeedbackListener(listener FeedbackListener) {
+	feedbackListeners = append(feedbackListeners, listener)
+}
+
 // Feedback composites resource
 type Feedback struct {
 	resource
@@ -35,9 +47,9 @@ func (r *Feedback) WithHashKey(key []byte) *Feedback {
 
 // Post serves HTTP POST requests on this resource
 func (r *Feedback) Post(c *yarf.Context) error {
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeFeedback)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -47,6 +59,21 @@ func (r *Feedback) Post(c *yarf.Context) error {
 		return err
 	}
 
+	validType := false
+	for _, feedbackType := range types.FeedbackTypes {
+		if request.Type == feedbackType {
+			validType = true
+			break
+		}
+	}
+	if !validType {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid feedback type",
+			ErrorBody: "Invalid feedback type",
+		}
+	}
+
 	tx, err := r.Beginx()
 	if err != nil {
 		return err
@@ -68,6 +95,7 @@ func (r *Feedback) Post(c *yarf.Context) error {
 		Time:      request.Time,
 		Type:      request.Type,
 		Contents:  request.Contents,
+		Status:    types.FeedbackNew,
 	}
 
 	err = feedback.Update(tx)
@@ -80,6 +108,10 @@ func (r *Feedback) Post(c *yarf.Context) error {
 		return err
 	}
 
+	for _, listener := range feedbackListeners {
+		listener.FeedbackSubmitted(&feedback)
+	}
+
 	c.Response.WriteHeader(http.StatusCreated)
 	r.render(c, &feedback)
 	return nil
@@ -0,0 +1,205 @@
+package resource
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+const (
+	// nearbyDefaultRadius is the search radius, in meters, used when none is specified
+	nearbyDefaultRadius = 500
+	// nearbyMaxRadius is the maximum search radius, in meters
+	nearbyMaxRadius = 5000
+	// nearbyMaxExits is the maximum number of exits included in the results
+	nearbyMaxExits = 20
+	// isochroneDefaultMinutes is the duration of the isochrones when none is specified
+	isochroneDefaultMinutes = 20
+	// isochroneMaxMinutes is the maximum duration of the isochrones
+	isochroneMaxMinutes = 90
+)
+
+// Nearby composites resource
+type Nearby struct {
+	resource
+}
+
+// Isochrone composites resource
+type Isochrone struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *Nearby) WithNode(node sqalx.Node) *Nearby {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *Nearby) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	query := c.Request.URL.Query()
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || math.Abs(lat) > 90 {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid latitude",
+			ErrorBody: "Invalid latitude",
+		}
+	}
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil || math.Abs(lon) > 180 {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid longitude",
+			ErrorBody: "Invalid longitude",
+		}
+	}
+	radius := float64(nearbyDefaultRadius)
+	if query.Get("radius") != "" {
+		radius, err = strconv.ParseFloat(query.Get("radius"), 64)
+		if err != nil || radius <= 0 || radius > nearbyMaxRadius {
+			return &yarf.CustomError{
+				HTTPCode:  http.StatusBadRequest,
+				ErrorMsg:  "Invalid radius",
+				ErrorBody: "Invalid radius, must be between 0 and " + strconv.Itoa(nearbyMaxRadius) + " meters",
+			}
+		}
+	}
+	coord := [2]float64{lat, lon}
+
+	fc := newGeoJSONFeatureCollection()
+
+	stations, err := compute.NearestStations(tx, coord, math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	for i, sd := range stations {
+		// the nearest station is always included, even if it is outside the radius
+		if i > 0 && sd.Distance > radius {
+			break
+		}
+		fc.add(sd.Station.ID, geoJSONPoint(sd.WorldCoord), map[string]interface{}{
+			"kind":     "station",
+			"name":     sd.Station.Name,
+			"network":  sd.Station.Network.ID,
+			"distance": math.Round(sd.Distance),
+		})
+	}
+
+	exits, err := compute.NearestExits(tx, coord, nearbyMaxExits)
+	if err != nil {
+		return err
+	}
+	for _, ed := range exits {
+		if ed.Distance > radius {
+			break
+		}
+		fc.add(strconv.Itoa(ed.Exit.ID), geoJSONPoint(ed.Exit.WorldCoord), map[string]interface{}{
+			"kind":     "exit",
+			"name":     strings.Join(ed.Exit.Streets, ", "),
+			"type":     ed.Exit.Type,
+			"lobby":    ed.Exit.Lobby.ID,
+			"station":  ed.Exit.Lobby.Station.ID,
+			"distance": math.Round(ed.Distance),
+		})
+	}
+
+	pois, err := compute.POIsWithinRadius(tx, coord, radius)
+	if err != nil {
+		return err
+	}
+	for _, pd := range pois {
+		fc.add(pd.POI.ID, geoJSONPoint(pd.POI.WorldCoord), map[string]interface{}{
+			"kind":     "poi",
+			"name":     pd.POI.Names[pd.POI.MainLocale],
+			"names":    pd.POI.Names,
+			"type":     pd.POI.Type,
+			"distance": math.Round(pd.Distance),
+		})
+	}
+
+	RenderData(c, fc, "s-maxage=60")
+	return nil
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *Isochrone) WithNode(node sqalx.Node) *Isochrone {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *Isochrone) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	station, err := types.GetStation(tx, c.Param("station"))
+	if err != nil {
+		return err
+	}
+
+	minutes := isochroneDefaultMinutes
+	if m := c.Request.URL.Query().Get("minutes"); m != "" {
+		minutes, err = strconv.Atoi(m)
+		if err != nil || minutes <= 0 || minutes > isochroneMaxMinutes {
+			return &yarf.CustomError{
+				HTTPCode:  http.StatusBadRequest,
+				ErrorMsg:  "Invalid minutes",
+				ErrorBody: "Invalid minutes, must be between 1 and " + strconv.Itoa(isochroneMaxMinutes),
+			}
+		}
+	}
+	departAt, err := parseTimeParam(c, "departAt")
+	if err != nil {
+		return err
+	}
+	if departAt.IsZero() {
+		departAt = time.Now()
+	}
+
+	accessibleOnly := c.Request.URL.Query().Get("accessible") == "true"
+
+	isochrone, err := compute.ComputeIsochrone(tx, station, departAt, time.Duration(minutes)*time.Minute, accessibleOnly)
+	if err != nil {
+		return err
+	}
+
+	fc := newGeoJSONFeatureCollection()
+	for _, is := range isochrone.Stations {
+		properties := map[string]interface{}{
+			"kind":          "station",
+			"name":          is.Station.Name,
+			"travelTime":    int(is.TravelTime.Seconds()),
+			"walkingRadius": math.Round(is.WalkingRadius),
+		}
+		if len(is.WorldCoords) > 0 {
+			fc.add(is.Station.ID, geoJSONPoint(is.WorldCoords[0]), properties)
+		}
+		if is.WalkingRadius > 0 && len(is.WorldCoords) > 0 {
+			fc.add(is.Station.ID+"-walk", geoJSONCircles(is.WorldCoords, is.WalkingRadius), map[string]interface{}{
+				"kind":          "walkingArea",
+				"station":       is.Station.ID,
+				"walkingRadius": math.Round(is.WalkingRadius),
+			})
+		}
+	}
+
+	RenderData(c, fc, "s-maxage=60")
+	return nil
+}
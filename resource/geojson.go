@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"math"
+)
+
+// apiGeoJSONFeatureCollection is a GeoJSON (RFC 7946) FeatureCollection
+type apiGeoJSONFeatureCollection struct {
+	Type     string              `msgpack:"type" json:"type"`
+	Features []apiGeoJSONFeature `msgpack:"features" json:"features"`
+}
+
+type apiGeoJSONFeature struct {
+	Type       string                 `msgpack:"type" json:"type"`
+	ID         string                 `msgpack:"id,omitempty" json:"id,omitempty"`
+	Geometry   apiGeoJSONGeometry     `msgpack:"geometry" json:"geometry"`
+	Properties map[string]interface{} `msgpack:"properties" json:"properties"`
+}
+
+type apiGeoJSONGeometry struct {
+	Type        string      `msgpack:"type" json:"type"`
+	Coordinates interface{} `msgpack:"coordinates" json:"coordinates"`
+}
+
+// geoJSONCirclePoints is the number of vertices of the polygons used to approximate circles
+const geoJSONCirclePoints = 32
+
+func newGeoJSONFeatureCollection() apiGeoJSONFeatureCollection {
+	return apiGeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []apiGeoJSONFeature{},
+	}
+}
+
+func (fc *apiGeoJSONFeatureCollection) add(id string, geometry apiGeoJSONGeometry, properties map[string]interface{}) {
+	fc.Features = append(fc.Features, apiGeoJSONFeature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   geometry,
+		Properties: properties,
+	})
+}
+
+// geoJSONPosition converts a [latitude, longitude] coordinate to a GeoJSON [longitude, latitude] position
+func geoJSONPosition(coord [2]float64) []float64 {
+	return []float64{coord[1], coord[0]}
+}
+
+func geoJSONPoint(coord [2]float64) apiGeoJSONGeometry {
+	return apiGeoJSONGeometry{
+		Type:        "Point",
+		Coordinates: geoJSONPosition(coord),
+	}
+}
+
+// geoJSONCircleRing returns a closed ring approximating a circle with the specified radius in meters
+func geoJSONCircleRing(center [2]float64, radius float64) [][]float64 {
+	const metersPerDegree = 111320
+	ring := make([][]float64, geoJSONCirclePoints+1)
+	for i := 0; i < geoJSONCirclePoints; i++ {
+		angle := 2 * math.Pi * float64(i) / geoJSONCirclePoints
+		ring[i] = geoJSONPosition([2]float64{
+			center[0] + radius*math.Cos(angle)/metersPerDegree,
+			center[1] + radius*math.Sin(angle)/(metersPerDegree*math.Cos(center[0]*math.Pi/180)),
+		})
+	}
+	ring[geoJSONCirclePoints] = ring[0]
+	return ring
+}
+
+// geoJSONCircles returns a MultiPolygon approximating circles with the same radius around each center
+func geoJSONCircles(centers [][2]float64, radius float64) apiGeoJSONGeometry {
+	polygons := make([][][][]float64, len(centers))
+	for i, center := range centers {
+		polygons[i] = [][][]float64{geoJSONCircleRing(center, radius)}
+	}
+	return apiGeoJSONGeometry{
+		Type:        "MultiPolygon",
+		Coordinates: polygons,
+	}
+}
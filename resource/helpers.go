@@ -1,10 +1,8 @@
 package resource
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	msgpack "gopkg.in/vmihailenco/msgpack.v2"
@@ -12,6 +10,7 @@ import (
 	"log"
 
 	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/yarf-framework/yarf"
 )
@@ -29,17 +28,8 @@ func (r *resource) Beginx() (sqalx.Node, error) {
 
 // DecodeRequest decodes a request according to its headers and places the result in v
 func (r *resource) DecodeRequest(c *yarf.Context, v interface{}) error {
-	contentType := c.Request.Header.Get("Content-Type")
-	var err error
-	switch {
-	case strings.Contains(contentType, "msgpack"):
-		err = msgpack.NewDecoder(c.Request.Body).Decode(v)
-	case strings.Contains(contentType, "json"):
-		fallthrough
-	default:
-		err = json.NewDecoder(c.Request.Body).Decode(v)
-	}
-
+	codec := codecForRequest(c.Request.Header.Get("Content-Type"))
+	err := codec.Decode(c.Request.Body, v)
 	if err != nil {
 		return &yarf.CustomError{
 			HTTPCode:  http.StatusBadRequest,
@@ -50,8 +40,19 @@ func (r *resource) DecodeRequest(c *yarf.Context, v interface{}) error {
 	return nil
 }
 
-// AuthenticateClient authenticates a API client
-func (r *resource) AuthenticateClient(c *yarf.Context) (pair *types.APIPair, err error) {
+var pairUsageTracker *compute.PairUsageTracker
+
+// RegisterPairUsageTracker registers the tracker that records the usage of API pairs authenticated by resources
+func RegisterPairUsageTracker(tracker *compute.PairUsageTracker) {
+	pairUsageTracker = tracker
+}
+
+// errMissingScope is returned by AuthenticateClient when the pair is not allowed to use the requested resource
+var errMissingScope = errors.New("Pair lacks the required scope")
+
+// AuthenticateClient authenticates a API client. If scopes are specified,
+// the client must have all of them in order to be successfully authenticated
+func (r *resource) AuthenticateClient(c *yarf.Context, scopes ...string) (pair *types.APIPair, err error) {
 	tx, err := r.node.Beginx()
 	if err != nil {
 		return nil, err
@@ -68,9 +69,30 @@ func (r *resource) AuthenticateClient(c *yarf.Context) (pair *types.APIPair, err
 		return nil, errors.New("Incorrect authorization")
 	}
 
+	if pairUsageTracker != nil {
+		pairUsageTracker.Record(pair)
+	}
+
+	for _, scope := range scopes {
+		if !pair.HasScope(scope) {
+			return nil, errMissingScope
+		}
+	}
+
 	return pair, nil
 }
 
+// RenderAuthenticationError writes a 403 forbidden to the response if the error was caused by the client
+// lacking the required scopes, and a 401 unauthorized otherwise
+func RenderAuthenticationError(c *yarf.Context, err error) {
+	if err == errMissingScope {
+		c.Response.WriteHeader(http.StatusForbidden)
+		c.Response.Write([]byte("Forbidden.\n"))
+		return
+	}
+	RenderUnauthorized(c)
+}
+
 // RenderUnauthorized writes a 401 unauthorized to the response
 // and requests authentication
 func RenderUnauthorized(c *yarf.Context) {
@@ -80,27 +102,29 @@ func RenderUnauthorized(c *yarf.Context) {
 }
 
 // RenderData takes a interface{} object and writes the encoded representation of it.
-// Encoding used will be idented JSON, non-idented JSON, Msgpack or XML
+// Encoding used will be the one of the registered codec that matches the Accept header, or idented JSON
 func RenderData(c *yarf.Context, data interface{}, cacheControl string) {
 	if cacheControl != "" {
 		c.Response.Header().Set("Cache-Control", cacheControl)
 	}
-	accept := c.Request.Header.Get("Accept")
-	switch {
-	case strings.Contains(accept, "json"):
-		c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
-		c.RenderJSON(data)
-	case strings.Contains(accept, "xml") && !strings.Contains(accept, "xhtml"):
-		c.Response.Header().Set("Content-Type", "application/xml; charset=utf-8")
-		c.RenderXML(data)
-	case strings.Contains(accept, "msgpack"):
-		RenderMsgpack(c, data)
-	default:
-		c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
-		c.RenderJSONIndent(data)
+	contentType, encoded, err := encodeData(c, data)
+	c.Response.Header().Set("Content-Type", contentType)
+	if err != nil {
+		log.Println(err)
+		c.Response.Write([]byte(err.Error()))
+	} else {
+		c.Response.Write(encoded)
 	}
 }
 
+// encodeData encodes data according to the Accept header of the request,
+// returning the content type of the encoded representation
+func encodeData(c *yarf.Context, data interface{}) (string, []byte, error) {
+	codec := codecForResponse(c.Request.Header.Get("Accept"), data)
+	encoded, err := codec.Encode(data)
+	return codec.ContentType(), encoded, err
+}
+
 // RenderMsgpack takes a interface{} object and writes the Msgpack encoded string of it.
 func RenderMsgpack(c *yarf.Context, data interface{}) {
 	c.Response.Header().Set("Content-Type", "application/msgpack")
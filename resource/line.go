@@ -12,37 +12,37 @@ type Line struct {
 }
 
 type apiLine struct {
-	ID          string               `msgpack:"id" json:"id"`
-	Name        string               `msgpack:"name" json:"name"`
-	MainLocale  string               `msgpack:"mainLocale" json:"mainLocale"`
-	Names       map[string]string    `msgpack:"names" json:"names"`
-	Color       string               `msgpack:"color" json:"color"`
-	TypicalCars int                  `msgpack:"typCars" json:"typCars"`
-	Order       int                  `msgpack:"order" json:"order"`
+	ID          string               `msgpack:"id" json:"id" protobuf:"1"`
+	Name        string               `msgpack:"name" json:"name" protobuf:"2"`
+	MainLocale  string               `msgpack:"mainLocale" json:"mainLocale" protobuf:"3"`
+	Names       map[string]string    `msgpack:"names" json:"names" protobuf:"4"`
+	Color       string               `msgpack:"color" json:"color" protobuf:"5"`
+	TypicalCars int                  `msgpack:"typCars" json:"typCars" protobuf:"6"`
+	Order       int                  `msgpack:"order" json:"order" protobuf:"7"`
 	Network     *types.Network `msgpack:"-" json:"-"`
-	ExternalID  string               `msgpack:"externalID" json:"externalID"`
+	ExternalID  string               `msgpack:"externalID" json:"externalID" protobuf:"8"`
 }
 
 type apiLineSchedule struct {
 	Line         *types.Line    `msgpack:"-" json:"-"`
-	Holiday      bool                 `msgpack:"holiday" json:"holiday"`
-	Day          int                  `msgpack:"day" json:"day"`
-	Open         bool                 `msgpack:"open" json:"open"`
-	OpenTime     types.Time     `msgpack:"openTime" json:"openTime"`
-	OpenDuration types.Duration `msgpack:"duration" json:"duration"`
+	Holiday      bool                 `msgpack:"holiday" json:"holiday" protobuf:"1"`
+	Day          int                  `msgpack:"day" json:"day" protobuf:"2"`
+	Open         bool                 `msgpack:"open" json:"open" protobuf:"3"`
+	OpenTime     types.Time     `msgpack:"openTime" json:"openTime" protobuf:"4"`
+	OpenDuration types.Duration `msgpack:"duration" json:"duration" protobuf:"5"`
 }
 
 type apiLinePath struct {
-	ID   string       `msgpack:"id" json:"id"`
-	Path [][2]float64 `msgpack:"path" json:"path"`
+	ID   string       `msgpack:"id" json:"id" protobuf:"1"`
+	Path [][2]float64 `msgpack:"path" json:"path" protobuf:"2"`
 }
 
 type apiLineWrapper struct {
 	apiLine   `msgpack:",inline"`
-	NetworkID string            `msgpack:"network" json:"network"`
-	Stations  []string          `msgpack:"stations" json:"stations"`
-	Schedule  []apiLineSchedule `msgpack:"schedule" json:"schedule"`
-	Paths     []apiLinePath     `msgpack:"worldPaths" json:"worldPaths"`
+	NetworkID string            `msgpack:"network" json:"network" protobuf:"9"`
+	Stations  []string          `msgpack:"stations" json:"stations" protobuf:"10"`
+	Schedule  []apiLineSchedule `msgpack:"schedule" json:"schedule" protobuf:"11"`
+	Paths     []apiLinePath     `msgpack:"worldPaths" json:"worldPaths" protobuf:"12"`
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -35,11 +35,12 @@ type exitWrapper struct {
 }
 
 type apiLobbyWrapper struct {
-	apiLobby  `msgpack:",inline"`
-	NetworkID string             `msgpack:"network" json:"network"`
-	StationID string             `msgpack:"station" json:"station"`
-	Exits     []exitWrapper      `msgpack:"exits" json:"exits"`
-	Schedule  []apiLobbySchedule `msgpack:"schedule" json:"schedule"`
+	apiLobby      `msgpack:",inline"`
+	NetworkID     string                `msgpack:"network" json:"network"`
+	StationID     string                `msgpack:"station" json:"station"`
+	Exits         []exitWrapper         `msgpack:"exits" json:"exits"`
+	Schedule      []apiLobbySchedule    `msgpack:"schedule" json:"schedule"`
+	Accessibility apiLobbyAccessibility `msgpack:"accessibility" json:"accessibility"`
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -76,6 +77,11 @@ func (r *Lobby) Get(c *yarf.Context) error {
 			data.Exits = append(data.Exits, exitWrapper(*exit))
 		}
 
+		data.Accessibility, err = buildAPILobbyAccessibility(tx, lobby, exits)
+		if err != nil {
+			return err
+		}
+
 		data.Schedule = []apiLobbySchedule{}
 		schedules, err := lobby.Schedules(tx)
 		if err != nil {
@@ -114,6 +120,11 @@ func (r *Lobby) Get(c *yarf.Context) error {
 				apilobbies[i].Exits = append(apilobbies[i].Exits, exitWrapper(*exit))
 			}
 
+			apilobbies[i].Accessibility, err = buildAPILobbyAccessibility(tx, lobbies[i], exits)
+			if err != nil {
+				return err
+			}
+
 			apilobbies[i].Schedule = []apiLobbySchedule{}
 			schedules, err := lobbies[i].Schedules(tx)
 			if err != nil {
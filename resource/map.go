@@ -2,6 +2,8 @@ package resource
 
 import (
 	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
 	"github.com/yarf-framework/yarf"
 )
 
@@ -10,11 +12,28 @@ type Map struct {
 	resource
 }
 
+// SchematicMap composites resource, serves a schematic map of a network reflecting its current state
+type SchematicMap struct {
+	resource
+	trains TrainPositionProvider
+}
+
+// TrainPositionProvider provides the current positions of the trains
+type TrainPositionProvider interface {
+	TrainPositions() map[string]*types.VehicleETA
+}
+
 // apiMap contains information about a network diagram/map
 type apiMap struct {
 	Type string `msgpack:"type" json:"type"`
 }
 
+type apiSVGMap struct {
+	apiMap  `msgpack:",inline"`
+	Network string `msgpack:"network" json:"network"`
+	URL     string `msgpack:"url" json:"url"`
+}
+
 type apiHTMLMap struct {
 	apiMap       `msgpack:",inline"`
 	URL          string `msgpack:"url" json:"url"`
@@ -49,6 +68,53 @@ func (r *Map) Get(c *yarf.Context) error {
 			URL:   "mapassets/map-pt-ml-portrait.html",
 			Cache: true,
 		},
+		apiSVGMap{
+			apiMap: apiMap{
+				Type: "svg",
+			},
+			Network: "pt-ml",
+			URL:     "networks/pt-ml/schematic",
+		},
 	}, "s-maxage=10")
 	return nil
 }
+
+// WithNode associates a sqalx Node with this resource
+func (r *SchematicMap) WithNode(node sqalx.Node) *SchematicMap {
+	r.node = node
+	return r
+}
+
+// WithTrainPositionProvider associates a TrainPositionProvider with this resource
+func (r *SchematicMap) WithTrainPositionProvider(trains TrainPositionProvider) *SchematicMap {
+	r.trains = trains
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *SchematicMap) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	network, err := types.GetNetwork(tx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	var trains map[string]*types.VehicleETA
+	if r.trains != nil {
+		trains = r.trains.TrainPositions()
+	}
+	svg, err := compute.RenderSchematicMapSVG(tx, network, trains)
+	if err != nil {
+		return err
+	}
+
+	c.Response.Header().Set("Content-Type", "image/svg+xml")
+	c.Response.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Response.Write(svg)
+	return nil
+}
@@ -12,32 +12,32 @@ type Network struct {
 }
 
 type apiNetwork struct {
-	ID           string               `msgpack:"id" json:"id"`
-	Name         string               `msgpack:"name" json:"name"`
-	MainLocale   string               `msgpack:"mainLocale" json:"mainLocale"`
-	Names        map[string]string    `msgpack:"names" json:"names"`
-	TypicalCars  int                  `msgpack:"typCars" json:"typCars"`
-	Holidays     []int64              `msgpack:"holidays" json:"holidays"`
-	OpenTime     types.Time     `msgpack:"openTime" json:"openTime"`
-	OpenDuration types.Duration `msgpack:"duration" json:"duration"`
-	Timezone     string               `msgpack:"timezone" json:"timezone"`
-	NewsURL      string               `msgpack:"newsURL" json:"newsURL"`
+	ID           string               `msgpack:"id" json:"id" protobuf:"1"`
+	Name         string               `msgpack:"name" json:"name" protobuf:"2"`
+	MainLocale   string               `msgpack:"mainLocale" json:"mainLocale" protobuf:"3"`
+	Names        map[string]string    `msgpack:"names" json:"names" protobuf:"4"`
+	TypicalCars  int                  `msgpack:"typCars" json:"typCars" protobuf:"5"`
+	Holidays     []int64              `msgpack:"holidays" json:"holidays" protobuf:"6"`
+	OpenTime     types.Time     `msgpack:"openTime" json:"openTime" protobuf:"7"`
+	OpenDuration types.Duration `msgpack:"duration" json:"duration" protobuf:"8"`
+	Timezone     string               `msgpack:"timezone" json:"timezone" protobuf:"9"`
+	NewsURL      string               `msgpack:"newsURL" json:"newsURL" protobuf:"10"`
 }
 
 type apiNetworkSchedule struct {
 	Network      *types.Network `msgpack:"-" json:"-"`
-	Holiday      bool                 `msgpack:"holiday" json:"holiday"`
-	Day          int                  `msgpack:"day" json:"day"`
-	Open         bool                 `msgpack:"open" json:"open"`
-	OpenTime     types.Time     `msgpack:"openTime" json:"openTime"`
-	OpenDuration types.Duration `msgpack:"duration" json:"duration"`
+	Holiday      bool                 `msgpack:"holiday" json:"holiday" protobuf:"1"`
+	Day          int                  `msgpack:"day" json:"day" protobuf:"2"`
+	Open         bool                 `msgpack:"open" json:"open" protobuf:"3"`
+	OpenTime     types.Time     `msgpack:"openTime" json:"openTime" protobuf:"4"`
+	OpenDuration types.Duration `msgpack:"duration" json:"duration" protobuf:"5"`
 }
 
 type apiNetworkWrapper struct {
 	apiNetwork `msgpack:",inline"`
-	Lines      []string             `msgpack:"lines" json:"lines"`
-	Stations   []string             `msgpack:"stations" json:"stations"`
-	Schedule   []apiNetworkSchedule `msgpack:"schedule" json:"schedule"`
+	Lines      []string             `msgpack:"lines" json:"lines" protobuf:"11"`
+	Stations   []string             `msgpack:"stations" json:"stations" protobuf:"12"`
+	Schedule   []apiNetworkSchedule `msgpack:"schedule" json:"schedule" protobuf:"13"`
 }
 
 // WithNode associates a sqalx Node with this resource
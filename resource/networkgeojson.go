@@ -0,0 +1,187 @@
+package resource
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// NetworkGeoJSON composites resource
+type NetworkGeoJSON struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *NetworkGeoJSON) WithNode(node sqalx.Node) *NetworkGeoJSON {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *NetworkGeoJSON) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	network, err := types.GetNetwork(tx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	locale := c.Request.URL.Query().Get("locale")
+
+	fc := newGeoJSONFeatureCollection()
+
+	lines, err := network.Lines(tx)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		paths, err := line.Paths(tx)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			coordinates := [][]float64{}
+			for _, point := range path.Path.P {
+				coordinates = append(coordinates, geoJSONPosition([2]float64{point.X, point.Y}))
+			}
+			fc.add(path.ID, apiGeoJSONGeometry{
+				Type:        "LineString",
+				Coordinates: coordinates,
+			}, map[string]interface{}{
+				"kind":  "line",
+				"line":  line.ID,
+				"name":  localizedName(line.Names, line.MainLocale, locale, line.Name),
+				"names": line.Names,
+				"color": "#" + line.Color,
+			})
+		}
+	}
+
+	stations, err := network.Stations(tx)
+	if err != nil {
+		return err
+	}
+	poiIDs := make(map[string]bool)
+	for _, station := range stations {
+		err = r.addStationFeatures(tx, &fc, station, locale, poiIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	RenderData(c, fc, "s-maxage=60")
+	return nil
+}
+
+func (r *NetworkGeoJSON) addStationFeatures(tx sqalx.Node, fc *apiGeoJSONFeatureCollection, station *types.Station, locale string, poiIDs map[string]bool) error {
+	closed, err := station.Closed(tx)
+	if err != nil {
+		return err
+	}
+	lines, err := station.Lines(tx)
+	if err != nil {
+		return err
+	}
+	lineIDs := make([]string, len(lines))
+	for i := range lines {
+		lineIDs[i] = lines[i].ID
+	}
+
+	coords, err := compute.StationWorldCoords(tx, station)
+	if err != nil {
+		return err
+	}
+	if len(coords) > 0 {
+		fc.add(station.ID, geoJSONPoint(worldCoordCentroid(coords)), map[string]interface{}{
+			"kind":     "station",
+			"name":     station.Name,
+			"altNames": station.AltNames,
+			"lines":    lineIDs,
+			"closed":   closed,
+		})
+	}
+
+	lobbies, err := station.Lobbies(tx)
+	if err != nil {
+		return err
+	}
+	for _, lobby := range lobbies {
+		exits, err := lobby.Exits(tx)
+		if err != nil {
+			return err
+		}
+		lobbyClosed, err := lobby.Closed(tx)
+		if err != nil {
+			return err
+		}
+		exitCoords := [][2]float64{}
+		for _, exit := range exits {
+			exitCoords = append(exitCoords, exit.WorldCoord)
+			fc.add(strconv.Itoa(exit.ID), geoJSONPoint(exit.WorldCoord), map[string]interface{}{
+				"kind":    "exit",
+				"name":    strings.Join(exit.Streets, ", "),
+				"type":    exit.Type,
+				"lobby":   lobby.ID,
+				"station": station.ID,
+			})
+		}
+		if len(exitCoords) > 0 {
+			fc.add(lobby.ID, geoJSONPoint(worldCoordCentroid(exitCoords)), map[string]interface{}{
+				"kind":    "lobby",
+				"name":    lobby.Name,
+				"station": station.ID,
+				"closed":  lobbyClosed,
+			})
+		}
+	}
+
+	pois, err := station.POIs(tx)
+	if err != nil {
+		return err
+	}
+	for _, poi := range pois {
+		// POIs can be shared by multiple stations
+		if poiIDs[poi.ID] {
+			continue
+		}
+		poiIDs[poi.ID] = true
+		fc.add(poi.ID, geoJSONPoint(poi.WorldCoord), map[string]interface{}{
+			"kind":  "poi",
+			"name":  localizedName(poi.Names, poi.MainLocale, locale, poi.ID),
+			"names": poi.Names,
+			"type":  poi.Type,
+			"url":   poi.URL,
+		})
+	}
+	return nil
+}
+
+// localizedName returns the name in the requested locale, falling back to the main locale and then to the specified fallback
+func localizedName(names map[string]string, mainLocale, locale, fallback string) string {
+	if name, present := names[locale]; present {
+		return name
+	}
+	if name, present := names[mainLocale]; present {
+		return name
+	}
+	return fallback
+}
+
+// worldCoordCentroid returns the average of [latitude, longitude] coordinates, which is accurate enough for nearby points
+func worldCoordCentroid(coords [][2]float64) [2]float64 {
+	var centroid [2]float64
+	for _, c := range coords {
+		centroid[0] += c[0]
+		centroid[1] += c[1]
+	}
+	centroid[0] /= float64(len(coords))
+	centroid[1] /= float64(len(coords))
+	return centroid
+}
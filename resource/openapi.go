@@ -0,0 +1,596 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// OpenAPI composites resource
+type OpenAPI struct {
+	resource
+	routes     []string
+	docMutex   sync.Mutex
+	cachedSpec map[string]interface{}
+}
+
+// apiOperation documents an operation on an API route.
+// The types of Request and Response (which are usually zero values of api* structs)
+// are used to generate the schemas of the request and response bodies
+type apiOperation struct {
+	Method        string
+	Summary       string
+	Authenticated bool
+	Request       interface{}
+	Response      interface{}
+	// Alternatives contains other possible response bodies, besides Response
+	Alternatives []interface{}
+	// ContentType is the content type of responses that are not encoded using RenderData
+	ContentType  string
+	ResponseCode int
+}
+
+// apiOperations documents all the routes of the API, keyed by the path used when registering the route with yarf
+var apiOperations = map[string][]apiOperation{
+	"/v1/meta":         {{Method: "GET", Summary: "API status and message of the day", Response: apiMeta{}}},
+	"/v1/meta/backers": {{Method: "GET", Summary: "Localized list of project backers", ContentType: "text/html"}},
+	"/v1/gateways":     {{Method: "GET", Summary: "Real-time gateways", Response: []apiMQTTGateway{}}},
+	"/v1/maps":         {{Method: "GET", Summary: "Network maps", Response: []apiMap{}}},
+	"/v1/networks":     {{Method: "GET", Summary: "All networks", Response: []apiNetworkWrapper{}}},
+	"/v1/networks/:id": {{Method: "GET", Summary: "A network", Response: apiNetworkWrapper{}}},
+	"/v1/networks/:id/schematic": {{Method: "GET", Summary: "Schematic map of a network, showing disturbed lines, closed stations and train positions",
+		ContentType: "image/svg+xml"}},
+	"/v1/networks/:id/geojson": {{Method: "GET", Summary: "GeoJSON FeatureCollection with the lines, stations, lobbies, exits and POIs of a network, with names in the requested locale",
+		Response: apiGeoJSONFeatureCollection{}}},
+	"/v1/lines": {{Method: "GET", Summary: "All lines", Response: []apiLineWrapper{}}},
+	"/v1/lines/:id": {{Method: "GET", Summary: "A line, or the latest line conditions if the ID is \"conditions\"",
+		Response: apiLineWrapper{}, Alternatives: []interface{}{[]apiLineConditionWrapper{}}}},
+	"/v1/lines/conditions/:id":         {{Method: "GET", Summary: "A line condition", Response: apiLineConditionWrapper{}, Alternatives: []interface{}{[]apiLineConditionWrapper{}}}},
+	"/v1/lines/:lineid/conditions":     {{Method: "GET", Summary: "The conditions of a line", Response: []apiLineConditionWrapper{}}},
+	"/v1/stations":                     {{Method: "GET", Summary: "All stations", Response: []apiStationWrapper{}}},
+	"/v1/stations/:id":                 {{Method: "GET", Summary: "A station", Response: apiStationWrapper{}}},
+	"/v1/stations/:sid/lobbies":        {{Method: "GET", Summary: "The lobbies of a station", Response: []apiLobbyWrapper{}}},
+	"/v1/lobbies":                      {{Method: "GET", Summary: "All lobbies", Response: []apiLobbyWrapper{}}},
+	"/v1/lobbies/:id":                  {{Method: "GET", Summary: "A lobby", Response: apiLobbyWrapper{}}},
+	"/v1/fares":                        {{Method: "GET", Summary: "Fare zones and products, optionally only those of the network specified with the network parameter", Response: apiFares{}}},
+	"/v1/fares/:id":                    {{Method: "GET", Summary: "A fare product", Response: apiFareProduct{}}},
+	"/v1/pois":                         {{Method: "GET", Summary: "All points of interest", Response: []apiPOI{}}},
+	"/v1/pois/:id":                     {{Method: "GET", Summary: "A point of interest", Response: apiPOI{}}},
+	"/v1/connections":                  {{Method: "GET", Summary: "All connections", Response: []apiConnectionWrapper{}}},
+	"/v1/connections/:from/:to":        {{Method: "GET", Summary: "A connection", Response: apiConnectionWrapper{}}},
+	"/v1/transfers":                    {{Method: "GET", Summary: "All transfers", Response: []apiTransferWrapper{}}},
+	"/v1/transfers/:station/:from/:to": {{Method: "GET", Summary: "A transfer", Response: apiTransferWrapper{}}},
+	"/v1/disturbances":                 {{Method: "GET", Summary: "Disturbances, optionally ongoing only or in a time range, with alternative routes if expand=alternatives (step-free only if accessible=true)", Response: []apiDisturbanceWrapper{}}},
+	"/v1/disturbances/reports": {{Method: "POST", Summary: "Report a disturbance", Authenticated: true,
+		Request: apiDisturbanceReport{}}},
+	"/v1/disturbances/:id":                  {{Method: "GET", Summary: "A disturbance, with alternative routes if expand=alternatives (step-free only if accessible=true)", Response: apiDisturbanceWrapper{}}},
+	"/v1/datasets":                          {{Method: "GET", Summary: "All datasets", Response: []apiDataset{}}},
+	"/v1/datasets/:id":                      {{Method: "GET", Summary: "A dataset", Response: apiDataset{}}},
+	"/v1/stats":                             {{Method: "GET", Summary: "Statistics for all networks", Response: map[string]apiStats{}}},
+	"/v1/stats/:id":                         {{Method: "GET", Summary: "Statistics for a network", Response: apiStats{}}},
+	"/v1/announcements":                     {{Method: "GET", Summary: "All announcements", Response: []apiAnnouncementWrapper{}}},
+	"/v1/announcements/:source":             {{Method: "GET", Summary: "Announcements from a source", Response: []apiAnnouncementWrapper{}}},
+	"/v1/stationkb/*":                       {{Method: "GET", Summary: "Station knowledge base files", ContentType: "application/octet-stream"}},
+	"/v1/mapassets/*":                       {{Method: "GET", Summary: "Map assets", ContentType: "application/octet-stream"}},
+	"/v1/clientconfigs/android":             {{Method: "GET", Summary: "Android client configuration", Response: apiAndroidClientConfig{}}},
+	"/v1/clientconfigs/android/overlayfs/*": {{Method: "GET", Summary: "Android client overlay files", ContentType: "application/octet-stream"}},
+	"/v1/trips": {
+		{Method: "GET", Summary: "Trips of the authenticated pair", Authenticated: true, Response: []apiTripWrapper{}},
+		{Method: "POST", Summary: "Submit a trip", Authenticated: true, Request: apiTripCreationRequest{}, Response: apiTripWrapper{}, ResponseCode: http.StatusCreated},
+	},
+	"/v1/trips/provisional": {{Method: "GET", Summary: "Provisional trips of the authenticated pair", Authenticated: true, Response: []apiTripWrapper{}}},
+	"/v1/trips/provisional/:id": {
+		{Method: "GET", Summary: "A provisional trip", Authenticated: true, Response: apiTripWrapper{}},
+		{Method: "DELETE", Summary: "Discard a provisional trip", Authenticated: true, ResponseCode: http.StatusNoContent},
+	},
+	"/v1/trips/:id": {
+		{Method: "GET", Summary: "A trip", Authenticated: true, Response: apiTripWrapper{}},
+		{Method: "PUT", Summary: "Edit a trip", Authenticated: true, Request: apiTripCreationRequest{}, Response: apiTripWrapper{}},
+	},
+	"/v1/rt":       {{Method: "POST", Summary: "Report the real-time location of the user", Authenticated: true, Request: apiRealtimeLocation{}}},
+	"/v1/feedback": {{Method: "POST", Summary: "Submit feedback", Authenticated: true, Request: apiFeedback{}, Response: apiFeedback{}, ResponseCode: http.StatusCreated}},
+	"/v1/wifiaps/observations": {{Method: "POST", Summary: "Submit the WiFi networks observed in a station the client is confident of being in", Authenticated: true,
+		Request: apiWiFiAPObservation{}, Response: apiWiFiAPObservationResponse{}, ResponseCode: http.StatusCreated}},
+	"/v1/pair": {{Method: "POST", Summary: "Create an API pair. Android clients sign the request, other clients include an approved challenge", Request: PairRequest{}, Response: apiPair{}}},
+	"/v1/pair/challenge": {{Method: "POST", Summary: "Issue a challenge that, once approved by a PosPlay player at the approval URL, can be exchanged for an API pair",
+		Request: apiPairChallengeRequest{}, Response: apiPairChallenge{}}},
+	"/v1/pair/challenge/:id": {{Method: "GET", Summary: "Approval status of a pair challenge", Response: apiPairChallenge{}}},
+	"/v1/pair/connections": {
+		{Method: "GET", Summary: "Service connections of the authenticated pair", Authenticated: true, Response: []apiPairConnection{}},
+		{Method: "POST", Summary: "Connect the authenticated pair to a service", Authenticated: true, Request: apiPairConnectionRequest{}, Response: apiPairConnectionResponse{}},
+	},
+	"/v1/pair/rotate": {{Method: "POST", Summary: "Replace the secret of the authenticated pair. The previous secret remains valid for a week", Authenticated: true, Response: apiPairRotation{}}},
+	"/v1/pair/data": {
+		{Method: "GET", Summary: "JSON archive of all the personal data linked to the authenticated pair", Authenticated: true, Response: types.PersonalDataArchive{}},
+		{Method: "DELETE", Summary: "Erase all the personal data linked to the authenticated pair, including the pair. Trips are anonymised unless trips=delete", Authenticated: true, ResponseCode: http.StatusNoContent},
+	},
+	"/v1/authtest": {{Method: "GET", Summary: "Test authentication", Authenticated: true, Response: apiAuthTestResult{}}},
+	"/v1/routes": {{Method: "GET", Summary: "The fastest route between the stations in the from and to parameters, departing at the RFC3339 departAt time (or now), and its fare when known. Only step-free routes are considered if accessible=true",
+		Response: apiRoute{}}},
+	"/v1/nearby": {{Method: "GET", Summary: "GeoJSON FeatureCollection with the stations, exits and POIs within radius meters (default 500) of the lat and lon parameters",
+		Response: apiGeoJSONFeatureCollection{}}},
+	"/v1/isochrones/:station": {{Method: "GET", Summary: "GeoJSON FeatureCollection with everything reachable within the specified minutes (default 20) from a station, departing at the RFC3339 departAt time (or now), without steps if accessible=true",
+		Response: apiGeoJSONFeatureCollection{}}},
+	"/v1/events": {{Method: "GET", Summary: "Server-Sent Events stream of status, disturbance and line condition changes. Supports resuming with Last-Event-ID",
+		ContentType: "text/event-stream"}},
+	"/v1/openapi.json": {{Method: "GET", Summary: "This document", ContentType: "application/json"}},
+
+	"/v2/disturbances":     {{Method: "GET", Summary: "Page of disturbances", Response: apiDisturbancePage{}}},
+	"/v2/disturbances/:id": {{Method: "GET", Summary: "A disturbance", Response: apiDisturbanceWrapper{}}},
+	"/v2/stations":         {{Method: "GET", Summary: "Page of stations", Response: apiStationPage{}}},
+	"/v2/stations/:id":     {{Method: "GET", Summary: "A station", Response: apiStationWrapper{}}},
+	"/v2/trips":            {{Method: "GET", Summary: "Page of trips of the authenticated pair", Authenticated: true, Response: apiTripPage{}}},
+	"/v2/trips/:id":        {{Method: "GET", Summary: "A trip", Authenticated: true, Response: apiTripWrapper{}}},
+}
+
+// the following types are only used to document the responses of the v2 paginated collections
+type apiDisturbancePage struct {
+	Items      []apiDisturbanceWrapper `msgpack:"items" json:"items"`
+	NextCursor string                  `msgpack:"nextCursor" json:"nextCursor"`
+}
+
+type apiStationPage struct {
+	Items      []apiStationWrapper `msgpack:"items" json:"items"`
+	NextCursor string              `msgpack:"nextCursor" json:"nextCursor"`
+}
+
+type apiTripPage struct {
+	Items      []apiTripWrapper `msgpack:"items" json:"items"`
+	NextCursor string           `msgpack:"nextCursor" json:"nextCursor"`
+}
+
+// WithRoutes sets the paths of the routes to describe in the OpenAPI document
+func (r *OpenAPI) WithRoutes(routes []string) *OpenAPI {
+	r.docMutex.Lock()
+	defer r.docMutex.Unlock()
+	r.routes = routes
+	r.cachedSpec = nil
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *OpenAPI) Get(c *yarf.Context) error {
+	r.docMutex.Lock()
+	if r.cachedSpec == nil {
+		r.cachedSpec = BuildOpenAPIDocument(r.routes)
+	}
+	spec := r.cachedSpec
+	r.docMutex.Unlock()
+
+	c.Response.Header().Set("Cache-Control", "s-maxage=10")
+	c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.RenderJSONIndent(spec)
+	return nil
+}
+
+// UndocumentedRoutes returns the routes, among the specified ones, that are missing from the OpenAPI document
+func UndocumentedRoutes(routes []string) []string {
+	undocumented := []string{}
+	for _, route := range routes {
+		if _, ok := apiOperations[route]; !ok {
+			undocumented = append(undocumented, route)
+		}
+	}
+	return undocumented
+}
+
+// BuildOpenAPIDocument generates an OpenAPI 3 document describing the specified routes
+func BuildOpenAPIDocument(routes []string) map[string]interface{} {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+	for _, route := range routes {
+		operations, ok := apiOperations[route]
+		if !ok {
+			continue
+		}
+		pathItem := make(map[string]interface{})
+		for _, op := range operations {
+			pathItem[strings.ToLower(op.Method)] = buildOpenAPIOperation(route, op, schemas)
+		}
+		paths[openAPIPath(route)] = pathItem
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.2",
+		"info": map[string]interface{}{
+			"title":       "UnderLX API",
+			"description": "Msgpack responses have the same structure as JSON ones, except for the fields whose x-msgpack-type is specified",
+			"version":     "1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"pair": map[string]interface{}{
+					"type":   "http",
+					"scheme": "basic",
+				},
+			},
+		},
+	}
+}
+
+// openAPIPath converts a yarf route path to an OpenAPI path template
+func openAPIPath(route string) string {
+	parts := strings.Split(route, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "{" + part[1:] + "}"
+		} else if part == "*" {
+			parts[i] = "{path}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func buildOpenAPIOperation(route string, op apiOperation, schemas map[string]interface{}) map[string]interface{} {
+	operation := map[string]interface{}{
+		"summary": op.Summary,
+	}
+
+	parameters := []interface{}{}
+	for _, part := range strings.Split(route, "/") {
+		name := ""
+		if strings.HasPrefix(part, ":") {
+			name = part[1:]
+		} else if part == "*" {
+			name = "path"
+		}
+		if name != "" {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.Authenticated {
+		operation["security"] = []interface{}{
+			map[string]interface{}{"pair": []string{}},
+		}
+	}
+
+	if op.Request != nil {
+		schema := openAPISchema(reflect.TypeOf(op.Request), schemas)
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json":    map[string]interface{}{"schema": schema},
+				"application/msgpack": map[string]interface{}{"schema": schema},
+			},
+		}
+	}
+
+	code := op.ResponseCode
+	if code == 0 {
+		code = http.StatusOK
+	}
+	response := map[string]interface{}{
+		"description": http.StatusText(code),
+	}
+	if op.Response != nil {
+		schema := openAPISchema(reflect.TypeOf(op.Response), schemas)
+		if len(op.Alternatives) > 0 {
+			anyOf := []interface{}{schema}
+			for _, alternative := range op.Alternatives {
+				anyOf = append(anyOf, openAPISchema(reflect.TypeOf(alternative), schemas))
+			}
+			schema = map[string]interface{}{"anyOf": anyOf}
+		}
+		response["content"] = map[string]interface{}{
+			"application/json":    map[string]interface{}{"schema": schema},
+			"application/msgpack": map[string]interface{}{"schema": schema},
+		}
+	} else if op.ContentType != "" {
+		response["content"] = map[string]interface{}{
+			op.ContentType: map[string]interface{}{},
+		}
+	}
+	responses := map[string]interface{}{
+		fmt.Sprint(code): response,
+	}
+	if op.Authenticated {
+		responses["401"] = map[string]interface{}{"description": "Unauthorized"}
+	}
+	operation["responses"] = responses
+	return operation
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	typesTime     = reflect.TypeOf(types.Time{})
+	typesDuration = reflect.TypeOf(types.Duration(0))
+)
+
+// openAPISchema returns the schema for the specified type, adding the schemas of named structs to schemas
+func openAPISchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time", "x-msgpack-type": "timestamp"}
+	case typesTime:
+		return map[string]interface{}{"type": "string", "pattern": "^[0-9]{2}:[0-9]{2}:[0-9]{2}$", "x-msgpack-type": "integer"}
+	case typesDuration:
+		return map[string]interface{}{"type": "string", "x-msgpack-type": "integer"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := openAPISchema(t.Elem(), schemas)
+		return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		// nil slices are encoded as null
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem(), schemas), "nullable": true}
+	case reflect.Array:
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem(), schemas),
+			"minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": openAPISchema(t.Elem(), schemas), "nullable": true}
+	case reflect.Struct:
+		name := openAPISchemaName(t)
+		if name == "" {
+			return openAPIStructSchema(t, schemas)
+		}
+		if _, present := schemas[name]; !present {
+			// placeholder to stop recursion in case of self-referencing types
+			schemas[name] = map[string]interface{}{}
+			schemas[name] = openAPIStructSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	// interface{} and other types can be anything
+	return map[string]interface{}{}
+}
+
+func openAPISchemaName(t reflect.Type) string {
+	name := strings.TrimPrefix(t.Name(), "api")
+	if name == "" {
+		return ""
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func openAPIStructSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	openAPICollectProperties(t, schemas, properties, &required)
+	sort.Strings(required)
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func openAPICollectProperties(t reflect.Type, schemas map[string]interface{}, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && strings.Contains(field.Tag.Get("msgpack"), "inline") {
+			openAPICollectProperties(field.Type, schemas, properties, required)
+			continue
+		}
+		tagParts := strings.Split(field.Tag.Get("json"), ",")
+		if field.PkgPath != "" || tagParts[0] == "-" {
+			continue
+		}
+		name := tagParts[0]
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = openAPISchema(field.Type, schemas)
+		omitEmpty := false
+		for _, part := range tagParts[1:] {
+			omitEmpty = omitEmpty || part == "omitempty"
+		}
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// ValidateAgainstOpenAPIDocument checks whether a response body complies with the schema documented for the route.
+// contentType must be either JSON or msgpack; responses with other content types are not checked
+func ValidateAgainstOpenAPIDocument(route string, method string, statusCode int, contentType string, body []byte) error {
+	var op *apiOperation
+	for i := range apiOperations[route] {
+		if apiOperations[route][i].Method == method {
+			op = &apiOperations[route][i]
+		}
+	}
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, route)
+	}
+	expectedCode := op.ResponseCode
+	if expectedCode == 0 {
+		expectedCode = http.StatusOK
+	}
+	if op.Authenticated && statusCode == http.StatusUnauthorized {
+		return nil
+	}
+	if statusCode != expectedCode {
+		return fmt.Errorf("%s %s: unexpected status code %d, expected %d", method, route, statusCode, expectedCode)
+	}
+	if op.Response == nil {
+		return nil
+	}
+
+	var value interface{}
+	var isMsgpack bool
+	switch {
+	case strings.Contains(contentType, "msgpack"):
+		isMsgpack = true
+		if err := msgpack.Unmarshal(body, &value); err != nil {
+			return fmt.Errorf("%s %s: %s", method, route, err)
+		}
+	case strings.Contains(contentType, "json"):
+		if err := json.Unmarshal(body, &value); err != nil {
+			return fmt.Errorf("%s %s: %s", method, route, err)
+		}
+	default:
+		return nil
+	}
+
+	schemas := make(map[string]interface{})
+	candidates := []interface{}{op.Response}
+	candidates = append(candidates, op.Alternatives...)
+	var err error
+	for _, candidate := range candidates {
+		schema := openAPISchema(reflect.TypeOf(candidate), schemas)
+		err = validateOpenAPIValue(schema, schemas, value, isMsgpack, "")
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s: %s", method, route, err)
+}
+
+func validateOpenAPIValue(schema map[string]interface{}, schemas map[string]interface{}, value interface{}, isMsgpack bool, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return validateOpenAPIValue(schemas[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]interface{}),
+			schemas, value, isMsgpack, path)
+	}
+	if value == nil {
+		if schema["nullable"] == true || len(schema) == 0 {
+			return nil
+		}
+		return fmt.Errorf("%s: unexpected null", path)
+	}
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, s := range allOf {
+			if err := validateOpenAPIValue(s.(map[string]interface{}), schemas, value, isMsgpack, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	typ, _ := schema["type"].(string)
+	if msgpackType, ok := schema["x-msgpack-type"].(string); ok && isMsgpack {
+		typ = msgpackType
+	}
+
+	switch typ {
+	case "timestamp":
+		// msgpack encodes timestamps as an array with seconds and nanoseconds
+		if a, ok := value.([]interface{}); !ok || len(a) != 2 || !isOpenAPIInteger(a[0]) || !isOpenAPIInteger(a[1]) {
+			return fmt.Errorf("%s: expected timestamp, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	case "integer":
+		if !isOpenAPIInteger(value) {
+			return fmt.Errorf("%s: expected integer, got %T", path, value)
+		}
+	case "number":
+		if !isOpenAPIInteger(value) {
+			switch value.(type) {
+			case float32, float64:
+			default:
+				return fmt.Errorf("%s: expected number, got %T", path, value)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, value)
+		}
+	case "array":
+		a, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, value)
+		}
+		if min, ok := schema["minItems"].(int); ok && len(a) < min {
+			return fmt.Errorf("%s: expected at least %d items", path, min)
+		}
+		if max, ok := schema["maxItems"].(int); ok && len(a) > max {
+			return fmt.Errorf("%s: expected at most %d items", path, max)
+		}
+		for i, item := range a {
+			err := validateOpenAPIValue(schema["items"].(map[string]interface{}), schemas, item, isMsgpack, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+	case "object":
+		m, ok := openAPIObject(value)
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, value)
+		}
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if _, present := m[name]; !present {
+				return fmt.Errorf("%s: missing property %s", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		for name, v := range m {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				propSchema = additional
+			}
+			if propSchema == nil {
+				continue
+			}
+			if err := validateOpenAPIValue(propSchema, schemas, v, isMsgpack, path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isOpenAPIInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return true
+	case float64:
+		return v == float64(int64(v))
+	}
+	return false
+}
+
+// openAPIObject converts the maps produced by the JSON and msgpack decoders to a common type
+func openAPIObject(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{})
+		for k, v := range m {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			result[key] = v
+		}
+		return result, true
+	}
+	return nil, false
+}
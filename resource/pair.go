@@ -1,17 +1,10 @@
 package resource
 
 import (
-	"crypto/sha256"
-	"encoding/asn1"
-	"encoding/base64"
-	"math/big"
+	"net"
 	"net/http"
 	"time"
 
-	"crypto/ecdsa"
-
-	"net"
-
 	"github.com/gbl08ma/sqalx"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/yarf-framework/yarf"
@@ -20,12 +13,15 @@ import (
 // Pair composites resource
 type Pair struct {
 	resource
-	trustedClientPublicKey *ecdsa.PublicKey
-	hashKey                []byte
-	telemetryChannel       chan bool
+	verifiers        map[string]PairRequestVerifier
+	telemetryChannel chan bool
 }
 
-type apiPairRequest struct {
+// PairRequest is a request for the creation of an APIPair, as sent by clients
+type PairRequest struct {
+	// Type is the type of the client making the request and selects the PairRequestVerifier that handles it.
+	// Android clients predate this field, so when it is empty the request is treated as coming from one
+	Type string `msgpack:"type" json:"type"`
 	// Nonce must be 36 characters long
 	// A v4 UUID can be used, but a random string is fine as well
 	Nonce string `msgpack:"nonce" json:"nonce"`
@@ -35,6 +31,13 @@ type apiPairRequest struct {
 	Timestamp string `msgpack:"timestamp" json:"timestamp"`
 	AndroidID string `msgpack:"androidID" json:"androidID"`
 	Signature string `msgpack:"signature" json:"signature"`
+	// KeyID identifies the trusted key the signature was made with. When empty, the default key is used
+	KeyID string `msgpack:"keyID" json:"keyID"`
+	// Challenge is the ID of an approved PairChallenge, for clients that can't sign their requests
+	Challenge string `msgpack:"challenge" json:"challenge"`
+
+	// IPAddress is the address the request was received from
+	IPAddress net.IP `msgpack:"-" json:"-"`
 }
 
 // apiPair contains the response to the pair creation request
@@ -45,21 +48,19 @@ type apiPair struct {
 	Activation time.Time `msgpack:"activation" json:"activation"`
 }
 
-type ecdsaSignature struct {
-	R, S *big.Int
-}
-
-const maxTimestampSkew = 30 * time.Minute
-
 // WithNode associates a sqalx Node with this resource
 func (r *Pair) WithNode(node sqalx.Node) *Pair {
 	r.node = node
 	return r
 }
 
-// WithPublicKey associates a trusted client public key with this resource
-func (r *Pair) WithPublicKey(key *ecdsa.PublicKey) *Pair {
-	r.trustedClientPublicKey = key
+// WithVerifier associates a PairRequestVerifier with this resource,
+// so that pairs can be issued to the type of clients it handles
+func (r *Pair) WithVerifier(verifier PairRequestVerifier) *Pair {
+	if r.verifiers == nil {
+		r.verifiers = make(map[string]PairRequestVerifier)
+	}
+	r.verifiers[verifier.ClientType()] = verifier
 	return r
 }
 
@@ -94,106 +95,39 @@ func (r *Pair) Post(c *yarf.Context) (err error) {
 	}
 	defer tx.Rollback()
 
-	var pairRequest apiPairRequest
+	var pairRequest PairRequest
 	err = r.DecodeRequest(c, &pairRequest)
 	if err != nil {
 		return err
 	}
-
-	if len(pairRequest.Nonce) != 36 {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Nonce does not meet the length requirements",
-			ErrorBody: "Nonce does not meet the length requirements",
-		}
-	}
-
-	timestamp, err := time.Parse(time.RFC3339, pairRequest.Timestamp)
-	if err != nil {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Failed to parse timestamp",
-			ErrorBody: err.Error(),
-		}
-	}
-	diff := time.Now().UTC().Sub(timestamp)
-	diff = maxDuration(diff, -diff)
-	if diff > maxTimestampSkew {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Timestamp too far from current time",
-			ErrorBody: "Timestamp too far from current time",
-		}
-	}
-
-	if len(pairRequest.AndroidID) > 16 {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Android ID does not meet the length requirements",
-			ErrorBody: "Android ID does not meet the length requirements",
-		}
-	}
-
-	// the "fun" part: verify the signature
-	// start by decoding the signature into something the crypto package can work with
-	signDec, err := base64.StdEncoding.DecodeString(pairRequest.Signature)
-	if err != nil {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Bad signature encoding",
-			ErrorBody: err.Error(),
-		}
-	}
-	var signature ecdsaSignature
-	_, err = asn1.Unmarshal(signDec, &signature)
-	if err != nil {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Bad signature",
-			ErrorBody: err.Error(),
-		}
+	if pairRequest.Type == "" {
+		pairRequest.Type = "android"
 	}
+	pairRequest.IPAddress = net.ParseIP(c.GetClientIP())
 
-	hashedContent := pairRequest.Nonce + pairRequest.Timestamp + pairRequest.AndroidID
-	hash := sha256.Sum256([]byte(hashedContent))
-
-	if !ecdsa.Verify(r.trustedClientPublicKey, hash[:], signature.R, signature.S) {
+	verifier, ok := r.verifiers[pairRequest.Type]
+	if !ok {
 		return &yarf.CustomError{
 			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Bad signature",
-			ErrorBody: err.Error(),
+			ErrorMsg:  "Unsupported client type",
+			ErrorBody: "Unsupported client type",
 		}
 	}
 
-	// signature ok
-
-	ipAddr := net.ParseIP(c.GetClientIP())
-	pReq := types.NewAndroidPairRequest(pairRequest.Nonce, pairRequest.AndroidID, ipAddr)
-
-	activation, err := pReq.CalculateActivationTime(tx, maxTimestampSkew)
-
+	activation, err := verifier.Verify(tx, &pairRequest)
 	if err != nil {
-		return &yarf.CustomError{
-			HTTPCode:  http.StatusBadRequest,
-			ErrorMsg:  "Activation failed",
-			ErrorBody: err.Error(),
-		}
+		return err
 	}
 
 	if activation.IsZero() {
 		return &yarf.CustomError{
 			HTTPCode:  http.StatusForbidden,
 			ErrorMsg:  "Activation failed",
-			ErrorBody: err.Error(),
+			ErrorBody: "Activation failed",
 		}
 	}
 
-	err = pReq.Store(tx)
-	if err != nil {
-		return err
-	}
-
-	pair, err := types.NewPair(tx, "android", activation, r.hashKey)
+	pair, err := types.NewPair(tx, verifier.ClientType(), activation, r.hashKey)
 	if err != nil {
 		return err
 	}
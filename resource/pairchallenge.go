@@ -0,0 +1,126 @@
+package resource
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// PairChallengeLongevity is how long clients have to get their challenges approved and redeem them
+const PairChallengeLongevity = 10 * time.Minute
+
+// PairChallenge composites resource
+type PairChallenge struct {
+	resource
+	clientTypes map[string]bool
+	approvalURL func(challengeID string) string
+}
+
+type apiPairChallengeRequest struct {
+	Type string `msgpack:"type" json:"type"`
+}
+
+type apiPairChallenge struct {
+	ID          string    `msgpack:"id" json:"id"`
+	Type        string    `msgpack:"type" json:"type"`
+	Expires     time.Time `msgpack:"expires" json:"expires"`
+	ApprovalURL string    `msgpack:"approvalURL" json:"approvalURL"`
+	Approved    bool      `msgpack:"approved" json:"approved"`
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *PairChallenge) WithNode(node sqalx.Node) *PairChallenge {
+	r.node = node
+	return r
+}
+
+// WithVerifier allows challenges to be issued for the client type of the specified verifier
+func (r *PairChallenge) WithVerifier(verifier *ChallengePairRequestVerifier) *PairChallenge {
+	if r.clientTypes == nil {
+		r.clientTypes = make(map[string]bool)
+	}
+	r.clientTypes[verifier.ClientType()] = true
+	return r
+}
+
+// WithApprovalURL associates a function that returns the URL of the page where a challenge can be approved
+func (r *PairChallenge) WithApprovalURL(approvalURL func(challengeID string) string) *PairChallenge {
+	r.approvalURL = approvalURL
+	return r
+}
+
+func (r *PairChallenge) render(c *yarf.Context, challenge *types.PairChallenge) {
+	RenderData(c, apiPairChallenge{
+		ID:          challenge.ID,
+		Type:        challenge.ClientType,
+		Expires:     challenge.Expires,
+		ApprovalURL: r.approvalURL(challenge.ID),
+		Approved:    challenge.Approved(),
+	}, "no-cache, no-store, must-revalidate")
+}
+
+// Get serves HTTP GET requests on this resource.
+// Clients use it to find out whether their challenge was already approved
+func (r *PairChallenge) Get(c *yarf.Context) error {
+	challenge, err := types.GetPairChallenge(r.node, c.Param("id"))
+	if err != nil || challenge.Expired() || challenge.Redeemed() {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusNotFound,
+			ErrorMsg:  "Unknown or expired challenge",
+			ErrorBody: "Unknown or expired challenge",
+		}
+	}
+	r.render(c, challenge)
+	return nil
+}
+
+// Post serves HTTP POST requests on this resource.
+// It issues a new challenge for the client type specified in the request
+func (r *PairChallenge) Post(c *yarf.Context) error {
+	var request apiPairChallengeRequest
+	err := r.DecodeRequest(c, &request)
+	if err != nil {
+		return err
+	}
+
+	if !r.clientTypes[request.Type] {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Unsupported client type",
+			ErrorBody: "Unsupported client type",
+		}
+	}
+
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// challenges are useless after expiring. Clean up those that expired a while ago,
+	// so that we don't keep record of who approved them for longer than needed
+	err = types.DeletePairChallengesExpiredBefore(tx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	challenge, err := types.NewPairChallenge(request.Type, PairChallengeLongevity)
+	if err != nil {
+		return err
+	}
+	err = challenge.Store(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	r.render(c, challenge)
+	return nil
+}
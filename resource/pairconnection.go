@@ -62,7 +62,7 @@ func (r *PairConnection) WithHashKey(key []byte) *PairConnection {
 func (r *PairConnection) Get(c *yarf.Context) error {
 	pair, err := r.AuthenticateClient(c)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -104,7 +104,7 @@ func (r *PairConnection) Get(c *yarf.Context) error {
 func (r *PairConnection) Post(c *yarf.Context) error {
 	pair, err := r.AuthenticateClient(c)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -0,0 +1,57 @@
+package resource
+
+import (
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/yarf-framework/yarf"
+)
+
+// PairSecretOverlap is how long the previous secret of a pair remains valid after a rotation
+const PairSecretOverlap = 7 * 24 * time.Hour
+
+// PairRotation composites resource
+type PairRotation struct {
+	resource
+}
+
+type apiPairRotation struct {
+	Key                  string    `msgpack:"key" json:"key"`
+	Secret               string    `msgpack:"secret" json:"secret"`
+	PreviousSecretExpiry time.Time `msgpack:"previousSecretExpiry" json:"previousSecretExpiry"`
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *PairRotation) WithNode(node sqalx.Node) *PairRotation {
+	r.node = node
+	return r
+}
+
+// WithHashKey associates a HMAC key with this resource so it can participate in authentication processes
+func (r *PairRotation) WithHashKey(key []byte) *PairRotation {
+	r.hashKey = key
+	return r
+}
+
+// Post serves HTTP POST requests on this resource.
+// It replaces the secret of the authenticated pair, responding with the new one.
+// The previous secret keeps working for PairSecretOverlap
+func (r *PairRotation) Post(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	err = pair.RotateSecret(r.node, PairSecretOverlap, r.hashKey)
+	if err != nil {
+		return err
+	}
+
+	RenderData(c, apiPairRotation{
+		Key:                  pair.Key,
+		Secret:               pair.Secret,
+		PreviousSecretExpiry: pair.PreviousSecretExpiry,
+	}, "no-cache, no-store, must-revalidate")
+	return nil
+}
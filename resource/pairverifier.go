@@ -0,0 +1,245 @@
+package resource
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// PairRequestVerifier decides whether the pair requests of a type of client should be granted
+type PairRequestVerifier interface {
+	// ClientType returns the type of the clients handled by this verifier,
+	// which is also used as the type of the pairs issued to them
+	ClientType() string
+	// Verify checks the authenticity of the request and returns the activation time for the pair to issue.
+	// If the returned time is zero, a pair should not be granted.
+	// Problems with the request should be returned as yarf errors, as they are sent to the client as-is
+	Verify(node sqalx.Node, request *PairRequest) (time.Time, error)
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+const maxTimestampSkew = 30 * time.Minute
+
+// ECDSAPairRequestVerifier verifies pair requests signed with the private key of a trusted client.
+// Multiple keys, each with an ID, can be trusted at the same time, so that keys can be rotated
+type ECDSAPairRequestVerifier struct {
+	clientType   string
+	keys         map[string]*ecdsa.PublicKey
+	defaultKeyID string
+}
+
+// NewECDSAPairRequestVerifier returns a new ECDSAPairRequestVerifier for the specified client type, trusting no keys
+func NewECDSAPairRequestVerifier(clientType string) *ECDSAPairRequestVerifier {
+	return &ECDSAPairRequestVerifier{
+		clientType: clientType,
+		keys:       make(map[string]*ecdsa.PublicKey),
+	}
+}
+
+// WithKey adds a trusted public key with the specified ID to this verifier.
+// The first key added is used for requests that do not specify a key ID
+func (v *ECDSAPairRequestVerifier) WithKey(keyID string, key *ecdsa.PublicKey) *ECDSAPairRequestVerifier {
+	if len(v.keys) == 0 {
+		v.defaultKeyID = keyID
+	}
+	v.keys[keyID] = key
+	return v
+}
+
+// KeyIDs returns the IDs of the keys trusted by this verifier
+func (v *ECDSAPairRequestVerifier) KeyIDs() []string {
+	ids := []string{}
+	for id := range v.keys {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ClientType implements PairRequestVerifier
+func (v *ECDSAPairRequestVerifier) ClientType() string {
+	return v.clientType
+}
+
+// Verify implements PairRequestVerifier
+func (v *ECDSAPairRequestVerifier) Verify(node sqalx.Node, request *PairRequest) (time.Time, error) {
+	if len(request.Nonce) != 36 {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Nonce does not meet the length requirements",
+			ErrorBody: "Nonce does not meet the length requirements",
+		}
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, request.Timestamp)
+	if err != nil {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Failed to parse timestamp",
+			ErrorBody: err.Error(),
+		}
+	}
+	diff := time.Now().UTC().Sub(timestamp)
+	diff = maxDuration(diff, -diff)
+	if diff > maxTimestampSkew {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Timestamp too far from current time",
+			ErrorBody: "Timestamp too far from current time",
+		}
+	}
+
+	if len(request.AndroidID) > 16 {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Android ID does not meet the length requirements",
+			ErrorBody: "Android ID does not meet the length requirements",
+		}
+	}
+
+	keyID := request.KeyID
+	if keyID == "" {
+		keyID = v.defaultKeyID
+	}
+	key, ok := v.keys[keyID]
+	if !ok {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Unknown key ID",
+			ErrorBody: "Unknown key ID",
+		}
+	}
+
+	// the "fun" part: verify the signature
+	// start by decoding the signature into something the crypto package can work with
+	signDec, err := base64.StdEncoding.DecodeString(request.Signature)
+	if err != nil {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Bad signature encoding",
+			ErrorBody: err.Error(),
+		}
+	}
+	var signature ecdsaSignature
+	_, err = asn1.Unmarshal(signDec, &signature)
+	if err != nil {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Bad signature",
+			ErrorBody: err.Error(),
+		}
+	}
+
+	hashedContent := request.Nonce + request.Timestamp + request.AndroidID
+	hash := sha256.Sum256([]byte(hashedContent))
+
+	if !ecdsa.Verify(key, hash[:], signature.R, signature.S) {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Bad signature",
+			ErrorBody: "Bad signature",
+		}
+	}
+
+	// signature ok
+
+	pReq := types.NewAndroidPairRequest(request.Nonce, request.AndroidID, request.IPAddress)
+
+	activation, err := pReq.CalculateActivationTime(node, maxTimestampSkew)
+	if err != nil {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Activation failed",
+			ErrorBody: err.Error(),
+		}
+	}
+
+	if !activation.IsZero() {
+		err = pReq.Store(node)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	return activation, nil
+}
+
+// MaxChallengePairsPerDay is how many pairs can be obtained through challenges approved by the same player, in 24 hours
+const MaxChallengePairsPerDay = 10
+
+// ChallengePairRequestVerifier verifies pair requests from clients that can't sign their requests,
+// like web clients. These clients must first obtain a PairChallenge, which is then approved by a logged in
+// PosPlay player, and finally include the challenge in their pair request
+type ChallengePairRequestVerifier struct {
+	clientType string
+}
+
+// NewChallengePairRequestVerifier returns a new ChallengePairRequestVerifier for the specified client type
+func NewChallengePairRequestVerifier(clientType string) *ChallengePairRequestVerifier {
+	return &ChallengePairRequestVerifier{
+		clientType: clientType,
+	}
+}
+
+// ClientType implements PairRequestVerifier
+func (v *ChallengePairRequestVerifier) ClientType() string {
+	return v.clientType
+}
+
+// Verify implements PairRequestVerifier
+func (v *ChallengePairRequestVerifier) Verify(node sqalx.Node, request *PairRequest) (time.Time, error) {
+	challenge, err := types.GetPairChallenge(node, request.Challenge)
+	if err != nil || challenge.ClientType != v.clientType {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Unknown challenge",
+			ErrorBody: "Unknown challenge",
+		}
+	}
+
+	if challenge.Expired() || challenge.Redeemed() {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Challenge expired",
+			ErrorBody: "Challenge expired",
+		}
+	}
+
+	if !challenge.Approved() {
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusForbidden,
+			ErrorMsg:  "Challenge not approved",
+			ErrorBody: "Challenge not approved",
+		}
+	}
+
+	count, err := types.CountPairChallengesRedeemedBy(node, challenge.ApprovedBy, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if count >= MaxChallengePairsPerDay {
+		return time.Time{}, nil
+	}
+
+	err = challenge.Redeem(node)
+	if err != nil {
+		// someone else redeemed the challenge in the meantime
+		return time.Time{}, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Challenge expired",
+			ErrorBody: "Challenge expired",
+		}
+	}
+
+	// the approval by a logged in player vouches for the client
+	return time.Now().UTC(), nil
+}
@@ -0,0 +1,84 @@
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/yarf-framework/yarf"
+)
+
+// PersonalData composites resource
+type PersonalData struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *PersonalData) WithNode(node sqalx.Node) *PersonalData {
+	r.node = node
+	return r
+}
+
+// WithHashKey associates a HMAC key with this resource so it can participate in authentication processes
+func (r *PersonalData) WithHashKey(key []byte) *PersonalData {
+	r.hashKey = key
+	return r
+}
+
+// Get serves HTTP GET requests on this resource.
+// It responds with a JSON archive of all the personal data linked to the authenticated pair
+func (r *PersonalData) Get(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	archive, err := compute.ExportPersonalData(r.node, pair, nil, "api")
+	if err != nil {
+		return err
+	}
+
+	// the archive is always JSON, regardless of the Accept header, as it is meant for users and not for the client
+	encoded, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	c.Response.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Response.Header().Set("Content-Disposition", "attachment; filename=\"underlx-"+pair.Key+".json\"")
+	c.Response.Write(encoded)
+	return nil
+}
+
+// Delete serves HTTP DELETE requests on this resource.
+// It erases all the personal data linked to the authenticated pair, including the pair itself.
+// Trips are anonymised unless the "trips" query parameter is "delete"
+func (r *PersonalData) Delete(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	keepTrips := true
+	switch c.Request.URL.Query().Get("trips") {
+	case "", "anonymise":
+	case "delete":
+		keepTrips = false
+	default:
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid trips parameter",
+			ErrorBody: "Invalid trips parameter",
+		}
+	}
+
+	err = compute.ErasePersonalData(r.node, pair, nil, keepTrips, "api")
+	if err != nil {
+		return err
+	}
+	c.Response.WriteHeader(http.StatusNoContent)
+	return nil
+}
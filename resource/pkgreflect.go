@@ -5,51 +5,92 @@ package resource
 import "reflect"
 
 var Types = map[string]reflect.Type{
-	"Announcement":            reflect.TypeOf((*Announcement)(nil)).Elem(),
-	"AuthTest":                reflect.TypeOf((*AuthTest)(nil)).Elem(),
-	"Backers":                 reflect.TypeOf((*Backers)(nil)).Elem(),
-	"Connection":              reflect.TypeOf((*Connection)(nil)).Elem(),
-	"Dataset":                 reflect.TypeOf((*Dataset)(nil)).Elem(),
-	"Disturbance":             reflect.TypeOf((*Disturbance)(nil)).Elem(),
-	"DisturbanceReport":       reflect.TypeOf((*DisturbanceReport)(nil)).Elem(),
-	"Feedback":                reflect.TypeOf((*Feedback)(nil)).Elem(),
-	"Gateway":                 reflect.TypeOf((*Gateway)(nil)).Elem(),
-	"Line":                    reflect.TypeOf((*Line)(nil)).Elem(),
-	"LineCondition":           reflect.TypeOf((*LineCondition)(nil)).Elem(),
-	"Lobby":                   reflect.TypeOf((*Lobby)(nil)).Elem(),
-	"MQTTGatewayInfoProvider": reflect.TypeOf((*MQTTGatewayInfoProvider)(nil)).Elem(),
-	"Map":                     reflect.TypeOf((*Map)(nil)).Elem(),
-	"Meta":                    reflect.TypeOf((*Meta)(nil)).Elem(),
-	"Network":                 reflect.TypeOf((*Network)(nil)).Elem(),
-	"POI":                     reflect.TypeOf((*POI)(nil)).Elem(),
-	"Pair":                    reflect.TypeOf((*Pair)(nil)).Elem(),
-	"PairConnection":          reflect.TypeOf((*PairConnection)(nil)).Elem(),
-	"PairConnectionHandler":   reflect.TypeOf((*PairConnectionHandler)(nil)).Elem(),
-	"Realtime":                reflect.TypeOf((*Realtime)(nil)).Elem(),
-	"RealtimeStatsHandler":    reflect.TypeOf((*RealtimeStatsHandler)(nil)).Elem(),
-	"RealtimeVehicleHandler":  reflect.TypeOf((*RealtimeVehicleHandler)(nil)).Elem(),
-	"ReportHandler":           reflect.TypeOf((*ReportHandler)(nil)).Elem(),
-	"Station":                 reflect.TypeOf((*Station)(nil)).Elem(),
-	"Stats":                   reflect.TypeOf((*Stats)(nil)).Elem(),
-	"StatsCalculator":         reflect.TypeOf((*StatsCalculator)(nil)).Elem(),
-	"Transfer":                reflect.TypeOf((*Transfer)(nil)).Elem(),
-	"Trip":                    reflect.TypeOf((*Trip)(nil)).Elem(),
+	"Announcement":                 reflect.TypeOf((*Announcement)(nil)).Elem(),
+	"AuthTest":                     reflect.TypeOf((*AuthTest)(nil)).Elem(),
+	"Backers":                      reflect.TypeOf((*Backers)(nil)).Elem(),
+	"ChallengePairRequestVerifier": reflect.TypeOf((*ChallengePairRequestVerifier)(nil)).Elem(),
+	"Codec":                        reflect.TypeOf((*Codec)(nil)).Elem(),
+	"Connection":                   reflect.TypeOf((*Connection)(nil)).Elem(),
+	"CrowdingEstimator":            reflect.TypeOf((*CrowdingEstimator)(nil)).Elem(),
+	"Dataset":                      reflect.TypeOf((*Dataset)(nil)).Elem(),
+	"Disturbance":                  reflect.TypeOf((*Disturbance)(nil)).Elem(),
+	"DisturbanceReport":            reflect.TypeOf((*DisturbanceReport)(nil)).Elem(),
+	"DisturbanceV2":                reflect.TypeOf((*DisturbanceV2)(nil)).Elem(),
+	"ECDSAPairRequestVerifier":     reflect.TypeOf((*ECDSAPairRequestVerifier)(nil)).Elem(),
+	"Fare":                         reflect.TypeOf((*Fare)(nil)).Elem(),
+	"Feedback":                     reflect.TypeOf((*Feedback)(nil)).Elem(),
+	"FeedbackListener":             reflect.TypeOf((*FeedbackListener)(nil)).Elem(),
+	"Gateway":                      reflect.TypeOf((*Gateway)(nil)).Elem(),
+	"Isochrone":                    reflect.TypeOf((*Isochrone)(nil)).Elem(),
+	"Line":                         reflect.TypeOf((*Line)(nil)).Elem(),
+	"LineCondition":                reflect.TypeOf((*LineCondition)(nil)).Elem(),
+	"Lobby":                        reflect.TypeOf((*Lobby)(nil)).Elem(),
+	"MQTTGatewayInfoProvider":      reflect.TypeOf((*MQTTGatewayInfoProvider)(nil)).Elem(),
+	"Map":                          reflect.TypeOf((*Map)(nil)).Elem(),
+	"Meta":                         reflect.TypeOf((*Meta)(nil)).Elem(),
+	"Nearby":                       reflect.TypeOf((*Nearby)(nil)).Elem(),
+	"Network":                      reflect.TypeOf((*Network)(nil)).Elem(),
+	"NetworkGeoJSON":               reflect.TypeOf((*NetworkGeoJSON)(nil)).Elem(),
+	"OpenAPI":                      reflect.TypeOf((*OpenAPI)(nil)).Elem(),
+	"POI":                          reflect.TypeOf((*POI)(nil)).Elem(),
+	"Pair":                         reflect.TypeOf((*Pair)(nil)).Elem(),
+	"PairChallenge":                reflect.TypeOf((*PairChallenge)(nil)).Elem(),
+	"PairConnection":               reflect.TypeOf((*PairConnection)(nil)).Elem(),
+	"PairConnectionHandler":        reflect.TypeOf((*PairConnectionHandler)(nil)).Elem(),
+	"PairRequest":                  reflect.TypeOf((*PairRequest)(nil)).Elem(),
+	"PairRequestVerifier":          reflect.TypeOf((*PairRequestVerifier)(nil)).Elem(),
+	"PairRotation":                 reflect.TypeOf((*PairRotation)(nil)).Elem(),
+	"PersonalData":                 reflect.TypeOf((*PersonalData)(nil)).Elem(),
+	"ProvisionalTrip":              reflect.TypeOf((*ProvisionalTrip)(nil)).Elem(),
+	"Realtime":                     reflect.TypeOf((*Realtime)(nil)).Elem(),
+	"RealtimeStatsHandler":         reflect.TypeOf((*RealtimeStatsHandler)(nil)).Elem(),
+	"RealtimeVehicleHandler":       reflect.TypeOf((*RealtimeVehicleHandler)(nil)).Elem(),
+	"ReportHandler":                reflect.TypeOf((*ReportHandler)(nil)).Elem(),
+	"Route":                        reflect.TypeOf((*Route)(nil)).Elem(),
+	"SchematicMap":                 reflect.TypeOf((*SchematicMap)(nil)).Elem(),
+	"Station":                      reflect.TypeOf((*Station)(nil)).Elem(),
+	"StationV2":                    reflect.TypeOf((*StationV2)(nil)).Elem(),
+	"Stats":                        reflect.TypeOf((*Stats)(nil)).Elem(),
+	"StatsCalculator":              reflect.TypeOf((*StatsCalculator)(nil)).Elem(),
+	"StatusEventSource":            reflect.TypeOf((*StatusEventSource)(nil)).Elem(),
+	"StatusEvents":                 reflect.TypeOf((*StatusEvents)(nil)).Elem(),
+	"TrainPositionProvider":        reflect.TypeOf((*TrainPositionProvider)(nil)).Elem(),
+	"Transfer":                     reflect.TypeOf((*Transfer)(nil)).Elem(),
+	"Trip":                         reflect.TypeOf((*Trip)(nil)).Elem(),
+	"TripV2":                       reflect.TypeOf((*TripV2)(nil)).Elem(),
+	"WiFiAPObservation":            reflect.TypeOf((*WiFiAPObservation)(nil)).Elem(),
 }
 
 var Functions = map[string]reflect.Value{
-	"ClearMOTD":                     reflect.ValueOf(ClearMOTD),
-	"RegisterPairConnectionHandler": reflect.ValueOf(RegisterPairConnectionHandler),
-	"RenderData":                    reflect.ValueOf(RenderData),
-	"RenderMsgpack":                 reflect.ValueOf(RenderMsgpack),
-	"RenderUnauthorized":            reflect.ValueOf(RenderUnauthorized),
-	"SetMOTDHTML":                   reflect.ValueOf(SetMOTDHTML),
-	"SetMOTDHTMLForLocale":          reflect.ValueOf(SetMOTDHTMLForLocale),
-	"SetMOTDMainLocale":             reflect.ValueOf(SetMOTDMainLocale),
-	"SetMOTDPriority":               reflect.ValueOf(SetMOTDPriority),
+	"BuildOpenAPIDocument":            reflect.ValueOf(BuildOpenAPIDocument),
+	"ClearMOTD":                       reflect.ValueOf(ClearMOTD),
+	"NewChallengePairRequestVerifier": reflect.ValueOf(NewChallengePairRequestVerifier),
+	"NewECDSAPairRequestVerifier":     reflect.ValueOf(NewECDSAPairRequestVerifier),
+	"RegisterCodec":                   reflect.ValueOf(RegisterCodec),
+	"RegisterFeedbackListener":        reflect.ValueOf(RegisterFeedbackListener),
+	"RegisterPairConnectionHandler":   reflect.ValueOf(RegisterPairConnectionHandler),
+	"RegisterPairUsageTracker":        reflect.ValueOf(RegisterPairUsageTracker),
+	"RenderAuthenticationError":       reflect.ValueOf(RenderAuthenticationError),
+	"RenderData":                      reflect.ValueOf(RenderData),
+	"RenderDataWithETag":              reflect.ValueOf(RenderDataWithETag),
+	"RenderMsgpack":                   reflect.ValueOf(RenderMsgpack),
+	"RenderUnauthorized":              reflect.ValueOf(RenderUnauthorized),
+	"SetMOTDHTML":                     reflect.ValueOf(SetMOTDHTML),
+	"SetMOTDHTMLForLocale":            reflect.ValueOf(SetMOTDHTMLForLocale),
+	"SetMOTDMainLocale":               reflect.ValueOf(SetMOTDMainLocale),
+	"SetMOTDPriority":                 reflect.ValueOf(SetMOTDPriority),
+	"UndocumentedRoutes":              reflect.ValueOf(UndocumentedRoutes),
+	"ValidateAgainstOpenAPIDocument":  reflect.ValueOf(ValidateAgainstOpenAPIDocument),
 }
 
 var Variables = map[string]reflect.Value{
 	"EnableMQTTGateway": reflect.ValueOf(&EnableMQTTGateway),
 }
 
-var Consts = map[string]reflect.Value{}
+var Consts = map[string]reflect.Value{
+	"MaxChallengePairsPerDay":      reflect.ValueOf(MaxChallengePairsPerDay),
+	"MaxWiFiAPObservationAge":      reflect.ValueOf(MaxWiFiAPObservationAge),
+	"MaxWiFiAPObservationNetworks": reflect.ValueOf(MaxWiFiAPObservationNetworks),
+	"PairChallengeLongevity":       reflect.ValueOf(PairChallengeLongevity),
+	"PairSecretOverlap":            reflect.ValueOf(PairSecretOverlap),
+}
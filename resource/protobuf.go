@@ -0,0 +1,520 @@
+package resource
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+// Protocol Buffers encoding of the API types, driven by the protobuf struct tags (which contain the field numbers).
+// The messages are described in api.proto. The rules are:
+// - time.Time is a google.protobuf.Timestamp
+// - types.Time is an int64 with the seconds since midnight, types.Duration an int64 with seconds (like in msgpack)
+// - responses that are lists are wrapped in a message whose field 1 contains the items
+// - lists and maps nested directly in other lists or maps are wrapped in a message whose field 1 contains them
+// - embedded structs with the msgpack inline option are flattened into the message that embeds them
+
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+type protoField struct {
+	number int
+	index  []int
+}
+
+// protoFields returns the fields of a struct type that have a protobuf field number
+func protoFields(t reflect.Type) []protoField {
+	fields := []protoField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && strings.Contains(field.Tag.Get("msgpack"), "inline") {
+			for _, inner := range protoFields(field.Type) {
+				fields = append(fields, protoField{
+					number: inner.number,
+					index:  append([]int{i}, inner.index...),
+				})
+			}
+			continue
+		}
+		number, err := strconv.Atoi(field.Tag.Get("protobuf"))
+		if err != nil || number <= 0 {
+			continue
+		}
+		fields = append(fields, protoField{
+			number: number,
+			index:  []int{i},
+		})
+	}
+	return fields
+}
+
+func isProtoMessage(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType && t != typesTime && len(protoFields(t)) > 0
+}
+
+// protobufCanEncode returns whether v is a message or a list of messages
+func protobufCanEncode(v interface{}) bool {
+	return protoCanEncodeValue(reflect.ValueOf(v))
+}
+
+func protoCanEncodeValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return false
+		}
+		return protoCanEncodeValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		return isProtoMessage(v.Type().Elem())
+	case reflect.Struct:
+		if !isProtoMessage(v.Type()) {
+			return false
+		}
+		// dynamic contents (e.g. the items of a page) must be encodable too
+		for _, field := range protoFields(v.Type()) {
+			f := v.FieldByIndex(field.index)
+			if f.Kind() == reflect.Interface && !f.IsNil() && !protoCanEncodeValue(f) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func protobufMarshal(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return []byte{}, nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return protoAppendMessage(nil, value)
+	case reflect.Slice, reflect.Array:
+		return protoAppendField(nil, 1, value)
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %s", value.Type())
+}
+
+func protoAppendTag(b []byte, number int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(number)<<3|uint64(wireType))
+}
+
+func protoAppendBytes(b []byte, number int, data []byte) []byte {
+	b = protoAppendTag(b, number, protoBytes)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+func protoAppendMessage(b []byte, v reflect.Value) ([]byte, error) {
+	var err error
+	for _, field := range protoFields(v.Type()) {
+		b, err = protoAppendField(b, field.number, v.FieldByIndex(field.index))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// protoScalar returns the wire type and encoding of a scalar value, and false if v isn't a scalar
+func protoScalar(v reflect.Value) (int, []byte, bool) {
+	switch v.Type() {
+	case typesTime:
+		t := time.Time(v.Interface().(types.Time))
+		return protoVarint, binary.AppendUvarint(nil, uint64(t.Hour()*3600+t.Minute()*60+t.Second())), true
+	case typesDuration:
+		seconds := int64(time.Duration(v.Interface().(types.Duration)).Seconds())
+		return protoVarint, binary.AppendUvarint(nil, uint64(seconds)), true
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return protoVarint, []byte{1}, true
+		}
+		return protoVarint, []byte{0}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return protoVarint, binary.AppendUvarint(nil, uint64(v.Int())), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return protoVarint, binary.AppendUvarint(nil, v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return protoFixed64, binary.LittleEndian.AppendUint64(nil, math.Float64bits(v.Float())), true
+	}
+	return 0, nil, false
+}
+
+func protoAppendField(b []byte, number int, v reflect.Value) ([]byte, error) {
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return b, nil
+		}
+		var ts []byte
+		ts = protoAppendTag(ts, 1, protoVarint)
+		ts = binary.AppendUvarint(ts, uint64(t.Unix()))
+		if t.Nanosecond() != 0 {
+			ts = protoAppendTag(ts, 2, protoVarint)
+			ts = binary.AppendUvarint(ts, uint64(t.Nanosecond()))
+		}
+		return protoAppendBytes(b, number, ts), nil
+	}
+	if wireType, encoded, ok := protoScalar(v); ok {
+		if v.IsZero() {
+			// proto3 default value
+			return b, nil
+		}
+		b = protoAppendTag(b, number, wireType)
+		return append(b, encoded...), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 {
+			return b, nil
+		}
+		return protoAppendBytes(b, number, []byte(v.String())), nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return b, nil
+		}
+		return protoAppendField(b, number, v.Elem())
+	case reflect.Struct:
+		message, err := protoAppendMessage(nil, v)
+		if err != nil {
+			return nil, err
+		}
+		return protoAppendBytes(b, number, message), nil
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return b, nil
+		}
+		if _, _, ok := protoScalar(reflect.Zero(v.Type().Elem())); ok {
+			// packed repeated field
+			var packed []byte
+			for i := 0; i < v.Len(); i++ {
+				_, encoded, _ := protoScalar(v.Index(i))
+				packed = append(packed, encoded...)
+			}
+			return protoAppendBytes(b, number, packed), nil
+		}
+		var err error
+		for i := 0; i < v.Len(); i++ {
+			b, err = protoAppendElement(b, number, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			entry, err := protoAppendField(nil, 1, key)
+			if err != nil {
+				return nil, err
+			}
+			entry, err = protoAppendElement(entry, 2, v.MapIndex(key))
+			if err != nil {
+				return nil, err
+			}
+			b = protoAppendBytes(b, number, entry)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %s", v.Type())
+}
+
+// protoAppendElement appends an element of a list or a value of a map, which are always present on the wire
+func protoAppendElement(b []byte, number int, v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		wrapped, err := protoAppendField(nil, 1, v)
+		if err != nil {
+			return nil, err
+		}
+		return protoAppendBytes(b, number, wrapped), nil
+	case reflect.String:
+		return protoAppendBytes(b, number, []byte(v.String())), nil
+	case reflect.Struct:
+		if v.Type() != timeType {
+			message, err := protoAppendMessage(nil, v)
+			if err != nil {
+				return nil, err
+			}
+			return protoAppendBytes(b, number, message), nil
+		}
+	}
+	return protoAppendField(b, number, v)
+}
+
+func protobufUnmarshal(b []byte, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("protobuf: Unmarshal requires a non-nil pointer")
+	}
+	value = value.Elem()
+	switch value.Kind() {
+	case reflect.Struct:
+		if !isProtoMessage(value.Type()) {
+			return fmt.Errorf("protobuf: unsupported type %s", value.Type())
+		}
+		return protoDecodeMessage(b, value)
+	case reflect.Slice:
+		return protoDecodeWrapped(b, value)
+	}
+	return fmt.Errorf("protobuf: unsupported type %s", value.Type())
+}
+
+// protoReadField reads a field from the start of b, returning its number, wire type,
+// the contents (for length-delimited fields) or value (for the other wire types), and the remaining data
+func protoReadField(b []byte) (int, int, []byte, uint64, []byte, error) {
+	key, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, 0, nil, 0, nil, errors.New("protobuf: invalid field key")
+	}
+	b = b[n:]
+	number, wireType := int(key>>3), int(key&7)
+	switch wireType {
+	case protoVarint:
+		value, n := binary.Uvarint(b)
+		if n <= 0 {
+			return 0, 0, nil, 0, nil, errors.New("protobuf: invalid varint")
+		}
+		return number, wireType, nil, value, b[n:], nil
+	case protoFixed64:
+		if len(b) < 8 {
+			return 0, 0, nil, 0, nil, errors.New("protobuf: unexpected end of data")
+		}
+		return number, wireType, nil, binary.LittleEndian.Uint64(b), b[8:], nil
+	case protoFixed32:
+		if len(b) < 4 {
+			return 0, 0, nil, 0, nil, errors.New("protobuf: unexpected end of data")
+		}
+		return number, wireType, nil, uint64(binary.LittleEndian.Uint32(b)), b[4:], nil
+	case protoBytes:
+		length, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < length {
+			return 0, 0, nil, 0, nil, errors.New("protobuf: unexpected end of data")
+		}
+		return number, wireType, b[n : n+int(length)], 0, b[n+int(length):], nil
+	}
+	return 0, 0, nil, 0, nil, fmt.Errorf("protobuf: unsupported wire type %d", wireType)
+}
+
+func protoDecodeMessage(b []byte, v reflect.Value) error {
+	fields := make(map[int][]int)
+	for _, field := range protoFields(v.Type()) {
+		fields[field.number] = field.index
+	}
+	for len(b) > 0 {
+		number, wireType, data, value, rest, err := protoReadField(b)
+		if err != nil {
+			return err
+		}
+		b = rest
+		index, ok := fields[number]
+		if !ok {
+			// unknown fields are ignored
+			continue
+		}
+		err = protoDecodeField(v.FieldByIndex(index), wireType, data, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoDecodeWrapped decodes the contents of field 1 of the message in b into v
+func protoDecodeWrapped(b []byte, v reflect.Value) error {
+	for len(b) > 0 {
+		number, wireType, data, value, rest, err := protoReadField(b)
+		if err != nil {
+			return err
+		}
+		b = rest
+		if number != 1 {
+			continue
+		}
+		err = protoDecodeField(v, wireType, data, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func protoDecodeScalar(v reflect.Value, wireType int, value uint64) (bool, error) {
+	switch v.Type() {
+	case typesTime:
+		v.Set(reflect.ValueOf(types.Time(time.Time{}.AddDate(-1, 0, 0).Add(time.Duration(value) * time.Second))))
+		return true, nil
+	case typesDuration:
+		v.Set(reflect.ValueOf(types.Duration(time.Duration(int64(value)) * time.Second)))
+		return true, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(value != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(value))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		switch wireType {
+		case protoFixed64:
+			v.SetFloat(math.Float64frombits(value))
+		case protoFixed32:
+			v.SetFloat(float64(math.Float32frombits(uint32(value))))
+		default:
+			return true, fmt.Errorf("protobuf: wrong wire type for %s", v.Type())
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func protoDecodeField(v reflect.Value, wireType int, data []byte, value uint64) error {
+	if v.Type() == timeType {
+		var seconds, nanos uint64
+		for len(data) > 0 {
+			number, _, _, fieldValue, rest, err := protoReadField(data)
+			if err != nil {
+				return err
+			}
+			data = rest
+			switch number {
+			case 1:
+				seconds = fieldValue
+			case 2:
+				nanos = fieldValue
+			}
+		}
+		v.Set(reflect.ValueOf(time.Unix(int64(seconds), int64(nanos))))
+		return nil
+	}
+	if wireType != protoBytes {
+		if ok, err := protoDecodeScalar(v, wireType, value); ok {
+			return err
+		}
+		return fmt.Errorf("protobuf: wrong wire type for %s", v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(data))
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return protoDecodeField(v.Elem(), wireType, data, value)
+	case reflect.Struct:
+		return protoDecodeMessage(data, v)
+	case reflect.Slice:
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if _, _, ok := protoScalar(elem); ok {
+			// packed repeated field
+			for len(data) > 0 {
+				var value uint64
+				switch {
+				case elem.Kind() == reflect.Float32 || elem.Kind() == reflect.Float64:
+					if len(data) < 8 {
+						return errors.New("protobuf: unexpected end of data")
+					}
+					value = binary.LittleEndian.Uint64(data)
+					data = data[8:]
+					_, err := protoDecodeScalar(elem, protoFixed64, value)
+					if err != nil {
+						return err
+					}
+				default:
+					var n int
+					value, n = binary.Uvarint(data)
+					if n <= 0 {
+						return errors.New("protobuf: invalid varint")
+					}
+					data = data[n:]
+					protoDecodeScalar(elem, protoVarint, value)
+				}
+				v.Set(reflect.Append(v, elem))
+			}
+			return nil
+		}
+		err := protoDecodeElement(elem, data)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.Append(v, elem))
+	case reflect.Array:
+		// fixed-size arrays are only supported as packed repeated scalars
+		for i := 0; i < v.Len() && len(data) >= 8; i++ {
+			_, err := protoDecodeScalar(v.Index(i), protoFixed64, binary.LittleEndian.Uint64(data))
+			if err != nil {
+				return err
+			}
+			data = data[8:]
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		elem := reflect.New(v.Type().Elem()).Elem()
+		for len(data) > 0 {
+			number, wireType, fieldData, fieldValue, rest, err := protoReadField(data)
+			if err != nil {
+				return err
+			}
+			data = rest
+			switch number {
+			case 1:
+				err = protoDecodeField(key, wireType, fieldData, fieldValue)
+			case 2:
+				if wireType == protoBytes {
+					err = protoDecodeElement(elem, fieldData)
+				} else {
+					err = protoDecodeField(elem, wireType, fieldData, fieldValue)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		v.SetMapIndex(key, elem)
+	default:
+		return fmt.Errorf("protobuf: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// protoDecodeElement decodes an element of a list or a value of a map
+func protoDecodeElement(v reflect.Value, data []byte) error {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return protoDecodeWrapped(data, v)
+	case reflect.Array:
+		return protoDecodeWrapped(data, v)
+	}
+	return protoDecodeField(v, protoBytes, data, 0)
+}
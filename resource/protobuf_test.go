@@ -0,0 +1,272 @@
+package resource
+
+import (
+	"bufio"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/underlx/disturbancesmlx/types"
+)
+
+func TestProtobufGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		encoded string
+	}{
+		{
+			name: "message with a nested message",
+			value: apiFare{
+				Total:    150,
+				Currency: "EUR",
+				Tickets:  []apiFareTicket{{Product: "p", Journeys: 1}},
+			},
+			encoded: "089601" + "1203455552" + "1a05" + "0a0170" + "1001",
+		},
+		{
+			name:    "list wrapped in field 1, with proto3 defaults omitted",
+			value:   []apiFareTicket{{Product: "a"}, {Journeys: 3}},
+			encoded: "0a03" + "0a0161" + "0a02" + "1003",
+		},
+		{
+			name: "timestamp",
+			value: apiRouteTransfer{
+				Station: "s",
+				Start:   time.Unix(1500000000, 5).UTC(),
+			},
+			encoded: "0a0173" + "2208" + "0880dea0cb05" + "1005",
+		},
+		{
+			name: "map entries sorted by key and packed repeated scalars",
+			value: apiNetwork{
+				Names:    map[string]string{"pt": "a", "en": "b"},
+				Holidays: []int64{1, 2},
+			},
+			encoded: "2207" + "0a02656e" + "120162" + "2207" + "0a027074" + "120161" + "32020102",
+		},
+		{
+			name: "map of maps wrapped in StringMap, and empty messages",
+			value: apiStationWrapper{
+				ConnectionURLs: map[string]map[string]string{"bus": {"pt": "x"}},
+			},
+			encoded: "4200" + "6a10" + "0a03627573" + "1209" + "0a07" + "0a027074" + "120178" + "7200",
+		},
+		{
+			name: "list of coordinates wrapped in Coordinates",
+			value: apiLinePath{
+				ID:   "l",
+				Path: [][2]float64{{1, 2}},
+			},
+			encoded: "0a016c" + "1212" + "0a10" + "000000000000f03f" + "0000000000000040",
+		},
+		{
+			name:    "duration in seconds",
+			value:   apiFareProduct{Validity: types.Duration(90 * time.Minute)},
+			encoded: "30982a",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := protobufMarshal(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(encoded) != test.encoded {
+				t.Errorf("got %x, want %s", encoded, test.encoded)
+			}
+		})
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	tests := []interface{}{
+		&apiFare{
+			Total:    -20,
+			Currency: "EUR",
+			Tickets:  []apiFareTicket{{Product: "a", Journeys: 2}, {Product: "b", Journeys: 1}},
+		},
+		&apiStationWrapper{
+			apiStation: apiStation{
+				ID:       "pt-ml-ap",
+				Name:     "Aeroporto",
+				AltNames: []string{"Airport"},
+			},
+			NetworkID:      "pt-ml",
+			Lines:          []string{"pt-ml-vermelha"},
+			Features:       apiFeatures{Lift: true, Airport: true},
+			WiFiAPs:        []wifiWrapper{{BSSID: "00:11:22:33:44:55", Line: "pt-ml-vermelha"}},
+			TriviaURLs:     map[string]string{"pt": "a", "en": "b"},
+			ConnectionURLs: map[string]map[string]string{"bus": {"pt": "x", "en": "y"}, "boat": {"pt": "z"}},
+			Accessibility: apiStationAccessibility{
+				StepFreeLines: []string{"pt-ml-vermelha"},
+				Paths:         []apiStationPath{{ID: "p", Exit: 3, Means: "LIFT", StepFree: true}},
+			},
+		},
+		&apiLinePath{
+			ID:   "l",
+			Path: [][2]float64{{-9.1, 38.7}, {-9.2, 38.8}},
+		},
+		&[]apiFareTicket{{Product: "a"}, {Journeys: 3}},
+	}
+
+	for _, value := range tests {
+		encoded, err := protobufMarshal(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := reflect.New(reflect.TypeOf(value).Elem())
+		err = protobufUnmarshal(encoded, decoded.Interface())
+		if err != nil {
+			t.Fatalf("%T: %s", value, err)
+		}
+		if !reflect.DeepEqual(decoded.Interface(), value) {
+			t.Errorf("%T: got %+v, want %+v", value, decoded.Interface(), value)
+		}
+	}
+}
+
+func TestProtobufRoundTripTimes(t *testing.T) {
+	use := apiStationUseWrapper{
+		apiStationUse: apiStationUse{
+			EntryTime: time.Date(2018, 3, 1, 8, 0, 0, 0, time.UTC),
+			LeaveTime: time.Date(2018, 3, 1, 8, 1, 30, 500, time.UTC),
+			Manual:    true,
+		},
+		StationID:  "pt-ml-ap",
+		TypeString: "NETWORK_ENTRY",
+	}
+	encoded, err := protobufMarshal(use)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded apiStationUseWrapper
+	err = protobufUnmarshal(encoded, &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.EntryTime.Equal(use.EntryTime) || !decoded.LeaveTime.Equal(use.LeaveTime) {
+		t.Errorf("times changed: got %s and %s", decoded.EntryTime, decoded.LeaveTime)
+	}
+	if decoded.StationID != use.StationID || decoded.TypeString != use.TypeString || !decoded.Manual {
+		t.Errorf("got %+v, want %+v", decoded, use)
+	}
+}
+
+// protoMessageTypes maps the messages in api.proto to the types encoded as them
+var protoMessageTypes = map[string][]interface{}{
+	"Schedule":             {apiNetworkSchedule{}, apiLineSchedule{}},
+	"Network":              {apiNetworkWrapper{}},
+	"LinePath":             {apiLinePath{}},
+	"Line":                 {apiLineWrapper{}},
+	"StationFeatures":      {apiFeatures{}},
+	"WiFiAP":               {wifiWrapper{}},
+	"Station":              {apiStationWrapper{}},
+	"Platform":             {apiPlatform{}},
+	"StationPath":          {apiStationPath{}},
+	"LineInterchange":      {apiLineInterchange{}},
+	"StationAccessibility": {apiStationAccessibility{}},
+	"StationPage":          {apiPage{}},
+	"Status":               {apiStatusWrapper{}},
+	"Disturbance":          {apiDisturbanceWrapper{}},
+	"DisturbancePage":      {apiPage{}},
+	"StationUse":           {apiStationUseWrapper{}},
+	"Trip":                 {apiTripWrapper{}},
+	"TripPage":             {apiPage{}},
+	"TripCreationRequest":  {apiTripCreationRequest{}},
+	"RouteLeg":             {apiRouteLeg{}},
+	"RouteTransfer":        {apiRouteTransfer{}},
+	"Route":                {apiRoute{}},
+	"FareZone":             {apiFareZone{}},
+	"FareProduct":          {apiFareProduct{}},
+	"Fares":                {apiFares{}},
+	"FareTicket":           {apiFareTicket{}},
+	"Fare":                 {apiFare{}},
+}
+
+// protoWrapperMessages are the messages in api.proto that only wrap lists and maps, see protobuf.go
+var protoWrapperMessages = map[string]bool{
+	"StringMap":       true,
+	"Coordinates":     true,
+	"NetworkList":     true,
+	"LineList":        true,
+	"StationList":     true,
+	"DisturbanceList": true,
+	"TripList":        true,
+}
+
+// parseAPIProto returns the field numbers of each message in api.proto, indexed by message and field name
+func parseAPIProto(t *testing.T) map[string]map[string]int {
+	f, err := os.Open("api.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	messageRegexp := regexp.MustCompile(`^message (\w+) {`)
+	fieldRegexp := regexp.MustCompile(`^\s+(?:repeated\s+)?(?:map<[^>]+>|[\w.]+)\s+(\w+)\s*=\s*(\d+);`)
+	messages := make(map[string]map[string]int)
+	var current map[string]int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := messageRegexp.FindStringSubmatch(line); m != nil {
+			current = make(map[string]int)
+			messages[m[1]] = current
+		} else if m := fieldRegexp.FindStringSubmatch(line); m != nil && current != nil {
+			current[m[1]], _ = strconv.Atoi(m[2])
+		} else if strings.HasPrefix(line, "}") {
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+func TestProtobufTagsMatchAPIProto(t *testing.T) {
+	messages := parseAPIProto(t)
+	if len(messages) == 0 {
+		t.Fatal("no messages found in api.proto")
+	}
+
+	for message, fields := range messages {
+		values, ok := protoMessageTypes[message]
+		if !ok {
+			if !protoWrapperMessages[message] {
+				t.Errorf("message %s is not mapped to any type", message)
+			}
+			continue
+		}
+		for _, value := range values {
+			typ := reflect.TypeOf(value)
+			tagged := make(map[string]int)
+			for _, field := range protoFields(typ) {
+				name := strings.Split(typ.FieldByIndex(field.index).Tag.Get("json"), ",")[0]
+				tagged[name] = field.number
+			}
+			for name, number := range fields {
+				if tagged[name] != number {
+					t.Errorf("%s.%s is field %d in api.proto, but has protobuf tag %d in %s", message, name, number, tagged[name], typ)
+				}
+			}
+			for name, number := range tagged {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("%s field %s has protobuf tag %d, but is missing from message %s in api.proto", typ, name, number, message)
+				}
+			}
+		}
+	}
+	for message := range protoMessageTypes {
+		if _, ok := messages[message]; !ok {
+			t.Errorf("message %s not found in api.proto", message)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"net/http"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// ProvisionalTrip composites resource
+type ProvisionalTrip struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *ProvisionalTrip) WithNode(node sqalx.Node) *ProvisionalTrip {
+	r.node = node
+	return r
+}
+
+// WithHashKey associates a HMAC key with this resource so it can participate in authentication processes
+func (r *ProvisionalTrip) WithHashKey(key []byte) *ProvisionalTrip {
+	r.hashKey = key
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+// Provisional trips are confirmed by submitting them, with the same ID, through the Trip resource
+func (r *ProvisionalTrip) Get(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	if c.Param("id") != "" {
+		provisionalTrip, err := r.getProvisionalTrip(tx, c.Param("id"), pair)
+		if err != nil {
+			return err
+		}
+
+		trip, err := provisionalTrip.ToTrip(tx)
+		if err != nil {
+			return err
+		}
+
+		RenderData(c, buildAPITripWrapper(trip), "private")
+	} else {
+		provisionalTrips, err := types.GetProvisionalTripsForSubmitter(tx, pair)
+		if err != nil {
+			return err
+		}
+
+		apitrips := []apiTripWrapper{}
+		for _, provisionalTrip := range provisionalTrips {
+			trip, err := provisionalTrip.ToTrip(tx)
+			if err != nil {
+				// provisional trip without reports
+				continue
+			}
+			apitrips = append(apitrips, buildAPITripWrapper(trip))
+		}
+
+		RenderData(c, apitrips, "private")
+	}
+	return nil
+}
+
+// Delete serves HTTP DELETE requests on this resource
+func (r *ProvisionalTrip) Delete(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	provisionalTrip, err := r.getProvisionalTrip(tx, c.Param("id"), pair)
+	if err != nil {
+		return err
+	}
+
+	err = provisionalTrip.Delete(tx)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	c.Response.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (r *ProvisionalTrip) getProvisionalTrip(tx sqalx.Node, id string, pair *types.APIPair) (*types.ProvisionalTrip, error) {
+	provisionalTrip, err := types.GetProvisionalTrip(tx, id)
+	if err != nil || provisionalTrip.Submitter.Key != pair.Key {
+		return nil, &yarf.CustomError{
+			HTTPCode:  http.StatusNotFound,
+			ErrorMsg:  "The specified provisional trip does not exist",
+			ErrorBody: "The specified provisional trip does not exist",
+		}
+	}
+	return provisionalTrip, nil
+}
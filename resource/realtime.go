@@ -64,9 +64,9 @@ func (r *Realtime) Post(c *yarf.Context) error {
 	}
 	defer tx.Commit() // read-only tx
 
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeRealtime)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -0,0 +1,153 @@
+package resource
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// Route composites resource
+type Route struct {
+	resource
+}
+
+type apiRoute struct {
+	From      string             `msgpack:"from" json:"from" protobuf:"1"`
+	To        string             `msgpack:"to" json:"to" protobuf:"2"`
+	Departure time.Time          `msgpack:"departure" json:"departure" protobuf:"3"`
+	Arrival   time.Time          `msgpack:"arrival" json:"arrival" protobuf:"4"`
+	Duration  int                `msgpack:"duration" json:"duration" protobuf:"5"`
+	Legs      []apiRouteLeg      `msgpack:"legs" json:"legs" protobuf:"6"`
+	Transfers []apiRouteTransfer `msgpack:"transfers" json:"transfers" protobuf:"7"`
+	Fare      *apiFare           `msgpack:"fare,omitempty" json:"fare,omitempty" protobuf:"8"`
+}
+
+// in walking legs, Line and Direction are empty
+type apiRouteLeg struct {
+	Line      string    `msgpack:"line" json:"line" protobuf:"1"`
+	Direction string    `msgpack:"direction" json:"direction" protobuf:"2"`
+	Stations  []string  `msgpack:"stations" json:"stations" protobuf:"3"`
+	Departure time.Time `msgpack:"departure" json:"departure" protobuf:"4"`
+	Arrival   time.Time `msgpack:"arrival" json:"arrival" protobuf:"5"`
+	Disturbed bool      `msgpack:"disturbed" json:"disturbed" protobuf:"6"`
+	Walk      bool      `msgpack:"walk" json:"walk" protobuf:"7"`
+}
+
+type apiRouteTransfer struct {
+	Station string    `msgpack:"station" json:"station" protobuf:"1"`
+	From    string    `msgpack:"from" json:"from" protobuf:"2"`
+	To      string    `msgpack:"to" json:"to" protobuf:"3"`
+	Start   time.Time `msgpack:"start" json:"start" protobuf:"4"`
+	End     time.Time `msgpack:"end" json:"end" protobuf:"5"`
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *Route) WithNode(node sqalx.Node) *Route {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *Route) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	from, err := types.GetStation(tx, c.Request.URL.Query().Get("from"))
+	if err != nil {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid origin station",
+			ErrorBody: "Invalid origin station",
+		}
+	}
+	to, err := types.GetStation(tx, c.Request.URL.Query().Get("to"))
+	if err != nil {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid destination station",
+			ErrorBody: "Invalid destination station",
+		}
+	}
+	departAt, err := parseTimeParam(c, "departAt")
+	if err != nil {
+		return err
+	}
+	if departAt.IsZero() {
+		departAt = time.Now()
+	}
+
+	accessibleOnly := c.Request.URL.Query().Get("accessible") == "true"
+
+	route, err := compute.ComputeRoute(tx, from, to, departAt, accessibleOnly)
+	if err == compute.ErrSameStation {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Origin and destination stations are the same",
+			ErrorBody: "Origin and destination stations are the same",
+		}
+	} else if err == compute.ErrNoRoute {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusNotFound,
+			ErrorMsg:  "No route found",
+			ErrorBody: "No route found",
+		}
+	} else if err != nil {
+		return err
+	}
+
+	data := buildAPIRoute(route)
+	fare, err := compute.ComputeFare(tx, route.StationUses())
+	if err == nil {
+		data.Fare = buildAPIFare(fare)
+	} else if err != compute.ErrNoFare {
+		return err
+	}
+
+	RenderData(c, data, "s-maxage=10")
+	return nil
+}
+
+func buildAPIRoute(route *compute.Route) apiRoute {
+	data := apiRoute{
+		From:      route.From.ID,
+		To:        route.To.ID,
+		Departure: route.Departure,
+		Arrival:   route.Arrival,
+		Duration:  int(route.Duration().Seconds()),
+		Legs:      make([]apiRouteLeg, len(route.Legs)),
+		Transfers: make([]apiRouteTransfer, len(route.Transfers)),
+	}
+	for i, leg := range route.Legs {
+		data.Legs[i] = apiRouteLeg{
+			Stations:  make([]string, len(leg.Stations)),
+			Departure: leg.Departure,
+			Arrival:   leg.Arrival,
+			Disturbed: leg.Disturbed,
+			Walk:      leg.Walk,
+		}
+		if !leg.Walk {
+			data.Legs[i].Line = leg.Line.ID
+			data.Legs[i].Direction = leg.Direction.ID
+		}
+		for j, station := range leg.Stations {
+			data.Legs[i].Stations[j] = station.ID
+		}
+	}
+	for i, transfer := range route.Transfers {
+		data.Transfers[i] = apiRouteTransfer{
+			Station: transfer.Station.ID,
+			From:    transfer.From.ID,
+			To:      transfer.To.ID,
+			Start:   transfer.Start,
+			End:     transfer.End,
+		}
+	}
+	return data
+}
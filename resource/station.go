@@ -13,38 +13,39 @@ type Station struct {
 }
 
 type apiStation struct {
-	ID       string               `msgpack:"id" json:"id"`
-	Name     string               `msgpack:"name" json:"name"`
-	AltNames []string             `msgpack:"altNames" json:"altNames"`
-	Tags     []string             `msgpack:"tags" json:"tags"`
-	LowTags  []string             `msgpack:"lowTags" json:"lowTags"`
+	ID       string               `msgpack:"id" json:"id" protobuf:"1"`
+	Name     string               `msgpack:"name" json:"name" protobuf:"2"`
+	AltNames []string             `msgpack:"altNames" json:"altNames" protobuf:"3"`
+	Tags     []string             `msgpack:"tags" json:"tags" protobuf:"4"`
+	LowTags  []string             `msgpack:"lowTags" json:"lowTags" protobuf:"5"`
 	Network  *types.Network `msgpack:"-" json:"-"`
 }
 
 type wifiWrapper struct {
-	BSSID string `msgpack:"bssid" json:"bssid"`
-	Line  string `msgpack:"line" json:"line"`
+	BSSID string `msgpack:"bssid" json:"bssid" protobuf:"1"`
+	Line  string `msgpack:"line" json:"line" protobuf:"2"`
 }
 
 type apiFeatures struct {
 	StationID string `msgpack:"-" json:"-"`
-	Lift      bool   `msgpack:"lift" json:"lift"`
-	Bus       bool   `msgpack:"bus" json:"bus"`
-	Boat      bool   `msgpack:"boat" json:"boat"`
-	Train     bool   `msgpack:"train" json:"train"`
-	Airport   bool   `msgpack:"airport" json:"airport"`
+	Lift      bool   `msgpack:"lift" json:"lift" protobuf:"1"`
+	Bus       bool   `msgpack:"bus" json:"bus" protobuf:"2"`
+	Boat      bool   `msgpack:"boat" json:"boat" protobuf:"3"`
+	Train     bool   `msgpack:"train" json:"train" protobuf:"4"`
+	Airport   bool   `msgpack:"airport" json:"airport" protobuf:"5"`
 }
 
 type apiStationWrapper struct {
 	apiStation     `msgpack:",inline"`
-	NetworkID      string                       `msgpack:"network" json:"network"`
-	Lines          []string                     `msgpack:"lines" json:"lines"`
-	Features       apiFeatures                  `msgpack:"features" json:"features"`
-	Lobbies        []string                     `msgpack:"lobbies" json:"lobbies"`
-	WiFiAPs        []wifiWrapper                `msgpack:"wiFiAPs" json:"wiFiAPs"`
-	POIs           []string                     `msgpack:"pois" json:"pois"`
-	TriviaURLs     map[string]string            `msgpack:"triviaURLs" json:"triviaURLs"`
-	ConnectionURLs map[string]map[string]string `msgpack:"connURLs" json:"connURLs"`
+	NetworkID      string                       `msgpack:"network" json:"network" protobuf:"6"`
+	Lines          []string                     `msgpack:"lines" json:"lines" protobuf:"7"`
+	Features       apiFeatures                  `msgpack:"features" json:"features" protobuf:"8"`
+	Lobbies        []string                     `msgpack:"lobbies" json:"lobbies" protobuf:"9"`
+	WiFiAPs        []wifiWrapper                `msgpack:"wiFiAPs" json:"wiFiAPs" protobuf:"10"`
+	POIs           []string                     `msgpack:"pois" json:"pois" protobuf:"11"`
+	TriviaURLs     map[string]string            `msgpack:"triviaURLs" json:"triviaURLs" protobuf:"12"`
+	ConnectionURLs map[string]map[string]string `msgpack:"connURLs" json:"connURLs" protobuf:"13"`
+	Accessibility  apiStationAccessibility      `msgpack:"accessibility" json:"accessibility" protobuf:"14"`
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -76,59 +77,10 @@ func (r *Station) Get(c *yarf.Context) error {
 	}
 	apistations := make([]apiStationWrapper, len(stations))
 	for i := range stations {
-		apistations[i] = apiStationWrapper{
-			apiStation: apiStation(*stations[i]),
-			NetworkID:  stations[i].Network.ID,
-		}
-
-		apistations[i].Lines = []string{}
-		lines, err := stations[i].Lines(tx)
-		if err != nil {
-			return err
-		}
-		for _, line := range lines {
-			apistations[i].Lines = append(apistations[i].Lines, line.ID)
-		}
-
-		apistations[i].Lobbies = []string{}
-		lobbies, err := stations[i].Lobbies(tx)
-		if err != nil {
-			return err
-		}
-		for _, lobby := range lobbies {
-			apistations[i].Lobbies = append(apistations[i].Lobbies, lobby.ID)
-		}
-
-		apistations[i].WiFiAPs = []wifiWrapper{}
-		wiFiAPs, err := stations[i].WiFiAPs(tx)
-		if err != nil {
-			return err
-		}
-		for _, ap := range wiFiAPs {
-			apistations[i].WiFiAPs = append(apistations[i].WiFiAPs, wifiWrapper{
-				BSSID: ap.BSSID,
-				Line:  ap.Line,
-			})
-		}
-
-		apistations[i].POIs = []string{}
-		pois, err := stations[i].POIs(tx)
+		apistations[i], err = buildAPIStationWrapper(tx, stations[i])
 		if err != nil {
 			return err
 		}
-		for _, poi := range pois {
-			apistations[i].POIs = append(apistations[i].POIs, poi.ID)
-		}
-		apistations[i].TriviaURLs = utils.ComputeStationTriviaURLs(stations[i])
-		apistations[i].ConnectionURLs = utils.StationConnectionURLs(stations[i])
-
-		// compatibility with old clients: set station features
-		// TODO remove this once old clients are no longer supported
-		apistations[i].Features.Airport = stations[i].HasTag("c_airport")
-		apistations[i].Features.Boat = stations[i].HasTag("c_boat")
-		apistations[i].Features.Bus = stations[i].HasTag("c_bus")
-		apistations[i].Features.Lift = stations[i].HasTag("m_lift_platform") || stations[i].HasTag("m_lift_surface")
-		apistations[i].Features.Train = stations[i].HasTag("c_train")
 	}
 
 	if c.Param("id") != "" {
@@ -138,3 +90,64 @@ func (r *Station) Get(c *yarf.Context) error {
 	}
 	return nil
 }
+
+func buildAPIStationWrapper(tx sqalx.Node, station *types.Station) (apiStationWrapper, error) {
+	data := apiStationWrapper{
+		apiStation: apiStation(*station),
+		NetworkID:  station.Network.ID,
+	}
+
+	data.Lines = []string{}
+	lines, err := station.Lines(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, line := range lines {
+		data.Lines = append(data.Lines, line.ID)
+	}
+
+	data.Lobbies = []string{}
+	lobbies, err := station.Lobbies(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, lobby := range lobbies {
+		data.Lobbies = append(data.Lobbies, lobby.ID)
+	}
+
+	data.WiFiAPs = []wifiWrapper{}
+	wiFiAPs, err := station.WiFiAPs(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, ap := range wiFiAPs {
+		data.WiFiAPs = append(data.WiFiAPs, wifiWrapper{
+			BSSID: ap.BSSID,
+			Line:  ap.Line,
+		})
+	}
+
+	data.POIs = []string{}
+	pois, err := station.POIs(tx)
+	if err != nil {
+		return data, err
+	}
+	for _, poi := range pois {
+		data.POIs = append(data.POIs, poi.ID)
+	}
+	data.Accessibility, err = buildAPIStationAccessibility(tx, station)
+	if err != nil {
+		return data, err
+	}
+	data.TriviaURLs = utils.ComputeStationTriviaURLs(station)
+	data.ConnectionURLs = utils.StationConnectionURLs(station)
+
+	// compatibility with old clients: set station features
+	// TODO remove this once old clients are no longer supported
+	data.Features.Airport = station.HasTag("c_airport")
+	data.Features.Boat = station.HasTag("c_boat")
+	data.Features.Bus = station.HasTag("c_bus")
+	data.Features.Lift = station.HasTag("m_lift_platform") || station.HasTag("m_lift_surface")
+	data.Features.Train = station.HasTag("c_train")
+	return data, nil
+}
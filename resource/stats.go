@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/yarf-framework/yarf"
 )
@@ -15,21 +16,46 @@ type StatsCalculator interface {
 	OITInLine(line *types.Line, approximateTo int) int
 }
 
+// CrowdingEstimator estimates how crowded lines and stations are, compared to what is expected
+type CrowdingEstimator interface {
+	LineCrowding(line *types.Line) *compute.Crowding
+	StationCrowding(node sqalx.Node, station *types.Station) (*compute.Crowding, error)
+}
+
 // Stats composites resource
 type Stats struct {
 	resource
 	calculator StatsCalculator
+	crowding   CrowdingEstimator
 }
 
 type apiStats struct {
 	LineStats                map[string]apiLineStats `msgpack:"lineStats" json:"lineStats"`
 	LastDisturbance          time.Time               `msgpack:"lastDisturbance" json:"lastDisturbance"`
 	CurrentlyOnlineInTransit int                     `msgpack:"curOnInTransit" json:"curOnInTransit"`
+	StationCrowding          map[string]*apiCrowding `msgpack:"stationCrowding,omitempty" json:"stationCrowding,omitempty"`
 }
 
 type apiLineStats struct {
 	Availability               float64              `msgpack:"availability" json:"availability"`
 	AverageDisturbanceDuration types.Duration `msgpack:"avgDistDuration" json:"avgDistDuration"`
+	Crowding                   *apiCrowding         `msgpack:"crowding,omitempty" json:"crowding,omitempty"`
+}
+
+type apiCrowding struct {
+	Expected      float64 `msgpack:"expected" json:"expected"`
+	Current       float64 `msgpack:"current" json:"current"`
+	ExpectedLevel string  `msgpack:"expectedLevel" json:"expectedLevel"`
+	CurrentLevel  string  `msgpack:"currentLevel" json:"currentLevel"`
+}
+
+func buildAPICrowding(crowding *compute.Crowding) *apiCrowding {
+	return &apiCrowding{
+		Expected:      crowding.Expected,
+		Current:       crowding.Current,
+		ExpectedLevel: string(crowding.ExpectedLevel),
+		CurrentLevel:  string(crowding.CurrentLevel),
+	}
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -44,6 +70,12 @@ func (r *Stats) WithStats(calculator StatsCalculator) *Stats {
 	return r
 }
 
+// WithCrowding associates a CrowdingEstimator with this resource
+func (r *Stats) WithCrowding(estimator CrowdingEstimator) *Stats {
+	r.crowding = estimator
+	return r
+}
+
 // Get serves HTTP GET requests on this resource
 func (r *Stats) Get(c *yarf.Context) error {
 	tx, err := r.Beginx()
@@ -139,10 +171,29 @@ func (r *Stats) getStatsForNetwork(node sqalx.Node, network *types.Network, star
 		if err != nil {
 			return apiStats{}, err
 		}
-		stats.LineStats[line.ID] = apiLineStats{
+		lineStats := apiLineStats{
 			Availability:               availability,
 			AverageDisturbanceDuration: types.Duration(avgDuration),
 		}
+		if r.crowding != nil {
+			lineStats.Crowding = buildAPICrowding(r.crowding.LineCrowding(line))
+		}
+		stats.LineStats[line.ID] = lineStats
+	}
+
+	if r.crowding != nil {
+		stations, err := network.Stations(tx)
+		if err != nil {
+			return apiStats{}, err
+		}
+		stats.StationCrowding = make(map[string]*apiCrowding)
+		for _, station := range stations {
+			crowding, err := r.crowding.StationCrowding(tx, station)
+			if err != nil {
+				return apiStats{}, err
+			}
+			stats.StationCrowding[station.ID] = buildAPICrowding(crowding)
+		}
 	}
 	return stats, nil
 }
@@ -0,0 +1,74 @@
+package resource
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/utils"
+	"github.com/yarf-framework/yarf"
+)
+
+// StatusEventSource provides a stream of line status changes
+type StatusEventSource interface {
+	Stream(w http.ResponseWriter, r *http.Request, write func(compute.StatusEvent) error) error
+}
+
+// StatusEvents composites resource, streams status changes using Server-Sent Events
+type StatusEvents struct {
+	resource
+	source StatusEventSource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *StatusEvents) WithNode(node sqalx.Node) *StatusEvents {
+	r.node = node
+	return r
+}
+
+// WithEventSource associates a StatusEventSource with this resource
+func (r *StatusEvents) WithEventSource(source StatusEventSource) *StatusEvents {
+	r.source = source
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *StatusEvents) Get(c *yarf.Context) error {
+	err := r.source.Stream(c.Response, c.Request, func(event compute.StatusEvent) error {
+		return r.writeEvent(c, event)
+	})
+	if err == compute.ErrStreamingUnsupported {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusInternalServerError,
+			ErrorMsg:  err.Error(),
+			ErrorBody: err.Error(),
+		}
+	}
+	return err
+}
+
+func (r *StatusEvents) writeEvent(c *yarf.Context, event compute.StatusEvent) error {
+	var data interface{}
+	switch event.Type {
+	case compute.StatusEventNewStatus:
+		data = apiStatusWrapper{
+			apiStatus:      apiStatus(*event.Status),
+			SourceID:       event.Status.Source.ID,
+			OfficialSource: event.Status.Source.Official,
+		}
+	case compute.StatusEventDisturbanceOpen, compute.StatusEventDisturbanceClose:
+		data = buildAPIDisturbanceWrapper(event.Disturbance, false)
+	case compute.StatusEventNewCondition:
+		data = apiLineConditionWrapper{
+			apiLineCondition: apiLineCondition(*event.Condition),
+			LineID:           event.Condition.Line.ID,
+			SourceID:         event.Condition.Source.ID,
+		}
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return utils.WriteServerSentEvent(c.Response, event.ID, string(event.Type), encoded)
+}
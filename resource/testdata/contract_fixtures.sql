@@ -0,0 +1,162 @@
+-- Fixtures for TestAPIContracts: a small network with two lines that meet at one station,
+-- with enough data for every resource to have something to return
+
+INSERT INTO source (id, name, automatic, official) VALUES
+    ('mlxscraper-pt-ml', 'Metro de Lisboa', true, true),
+    ('underlx-community', 'UnderLX community', true, false);
+
+INSERT INTO network (id, name, typ_cars, holidays, open_time, open_duration, timezone, news_url) VALUES
+    ('pt-ml', 'Metro de Lisboa', 6, '{1, 115, 121}', '06:30', '19 hours', 'Europe/Lisbon', 'https://www.metrolisboa.pt/');
+
+INSERT INTO network_name (id, main, lang, name) VALUES
+    ('pt-ml', true, 'pt', 'Metro de Lisboa'),
+    ('pt-ml', false, 'en', 'Lisbon Metro');
+
+INSERT INTO network_schedule (network_id, holiday, day, open, open_time, open_duration)
+    SELECT 'pt-ml', holiday, day, true, '00:00', '24 hours'
+    FROM unnest(ARRAY[false, true]) AS holiday, generate_series(0, 6) AS day;
+
+INSERT INTO mline (id, name, color, network, typ_cars, "order", external_id) VALUES
+    ('pt-ml-azul', 'Azul', '4e84c4', 'pt-ml', 6, 1, 'Azul'),
+    ('pt-ml-verde', 'Verde', '00aa80', 'pt-ml', 3, 2, 'Verde');
+
+INSERT INTO line_name (id, main, lang, name) VALUES
+    ('pt-ml-azul', true, 'pt', 'Azul'),
+    ('pt-ml-azul', false, 'en', 'Blue'),
+    ('pt-ml-verde', true, 'pt', 'Verde'),
+    ('pt-ml-verde', false, 'en', 'Green');
+
+INSERT INTO line_schedule (line_id, holiday, day, open, open_time, open_duration)
+    SELECT line_id, holiday, day, true, '00:00', '24 hours'
+    FROM unnest(ARRAY['pt-ml-azul', 'pt-ml-verde']) AS line_id, unnest(ARRAY[false, true]) AS holiday, generate_series(0, 6) AS day;
+
+INSERT INTO line_path (line_id, id, path) VALUES
+    ('pt-ml-azul', 'pt-ml-azul-0', '[(38.7157, -9.1416), (38.7106, -9.1405)]'),
+    ('pt-ml-verde', 'pt-ml-verde-0', '[(38.7060, -9.1446), (38.7106, -9.1405), (38.7139, -9.1389)]');
+
+INSERT INTO station (id, name, network, alt_names) VALUES
+    ('pt-ml-rs', 'Restauradores', 'pt-ml', '{}'),
+    ('pt-ml-bc', 'Baixa-Chiado', 'pt-ml', '{Baixa, Chiado}'),
+    ('pt-ml-cs', 'Cais do Sodré', 'pt-ml', '{}'),
+    ('pt-ml-ro', 'Rossio', 'pt-ml', '{}');
+
+INSERT INTO line_has_station (line_id, station_id, position) VALUES
+    ('pt-ml-azul', 'pt-ml-rs', 0),
+    ('pt-ml-azul', 'pt-ml-bc', 1),
+    ('pt-ml-verde', 'pt-ml-cs', 0),
+    ('pt-ml-verde', 'pt-ml-bc', 1),
+    ('pt-ml-verde', 'pt-ml-ro', 2);
+
+INSERT INTO connection (from_station, to_station, typ_wait_time, typ_stop_time, typ_time, world_length, from_platform, to_platform) VALUES
+    ('pt-ml-rs', 'pt-ml-bc', 180, 20, 80, 600, '', ''),
+    ('pt-ml-bc', 'pt-ml-rs', 180, 20, 80, 600, '', ''),
+    ('pt-ml-cs', 'pt-ml-bc', 240, 20, 90, 700, '', ''),
+    ('pt-ml-bc', 'pt-ml-cs', 240, 20, 90, 700, '', ''),
+    ('pt-ml-bc', 'pt-ml-ro', 240, 20, 60, 400, '', ''),
+    ('pt-ml-ro', 'pt-ml-bc', 240, 20, 60, 400, '', '');
+
+INSERT INTO transfer (station_id, from_line, to_line, typ_time) VALUES
+    ('pt-ml-bc', 'pt-ml-azul', 'pt-ml-verde', 120),
+    ('pt-ml-bc', 'pt-ml-verde', 'pt-ml-azul', 120);
+
+INSERT INTO wifiap (bssid, ssid) VALUES
+    ('00:11:22:33:44:55', 'metrolisboa');
+
+INSERT INTO station_has_wifiap (station_id, bssid, line_id) VALUES
+    ('pt-ml-bc', '00:11:22:33:44:55', 'pt-ml-azul');
+
+INSERT INTO station_tag (station_id, tag, priority) VALUES
+    ('pt-ml-bc', 'm_lift_platform', 100),
+    ('pt-ml-cs', 'c_boat', 100),
+    ('pt-ml-cs', 'c_train', 90);
+
+INSERT INTO station_lobby (id, station_id, name) VALUES
+    ('pt-ml-bc-baixa', 'pt-ml-bc', 'Baixa'),
+    ('pt-ml-rs-main', 'pt-ml-rs', 'Restauradores');
+
+INSERT INTO station_lobby_exit (id, lobby_id, world_coord, streets, type) VALUES
+    (1, 'pt-ml-bc-baixa', '(38.7106, -9.1405)', '{Rua Augusta}', 'stairs'),
+    (2, 'pt-ml-bc-baixa', '(38.7108, -9.1403)', '{Rua do Crucifixo}', 'lift'),
+    (3, 'pt-ml-rs-main', '(38.7157, -9.1416)', '{Avenida da Liberdade}', 'stairs');
+
+INSERT INTO station_lobby_schedule (lobby_id, holiday, day, open, open_time, open_duration)
+    SELECT lobby_id, holiday, day, true, '00:00', '24 hours'
+    FROM unnest(ARRAY['pt-ml-bc-baixa', 'pt-ml-rs-main']) AS lobby_id, unnest(ARRAY[false, true]) AS holiday, generate_series(0, 6) AS day;
+
+INSERT INTO station_path (id, station_id, lobby_id, exit_id, line_id, to_line_id, means) VALUES
+    ('pt-ml-bc-lift', 'pt-ml-bc', 'pt-ml-bc-baixa', 2, 'pt-ml-azul', NULL, 'LIFT'),
+    ('pt-ml-bc-interchange', 'pt-ml-bc', NULL, NULL, 'pt-ml-azul', 'pt-ml-verde', 'LEVEL');
+
+INSERT INTO station_path_outage (id, path_id, start_time, end_time, description) VALUES
+    ('pt-ml-bc-lift-outage', 'pt-ml-bc-lift', '2018-03-01 08:00:00+00', '2018-03-02 08:00:00+00', 'Maintenance');
+
+INSERT INTO station_platform (station_id, line_id, gap_width, gap_height) VALUES
+    ('pt-ml-bc', 'pt-ml-azul', 5, 3);
+
+INSERT INTO line_status (id, timestamp, mline, downtime, status, source, msgtype) VALUES
+    ('status-1', '2018-03-01 08:00:00+00', 'pt-ml-azul', true, 'Perturbação', 'mlxscraper-pt-ml', 'ML_GENERIC'),
+    ('status-2', '2018-03-01 08:30:00+00', 'pt-ml-azul', false, 'Circulação normal', 'mlxscraper-pt-ml', 'ML_SOLVED');
+
+INSERT INTO line_disturbance (id, time_start, time_end, otime_start, otime_end, mline, description, notes) VALUES
+    ('disturbance-1', '2018-03-01 08:00:00+00', '2018-03-01 08:30:00+00', '2018-03-01 08:00:00+00', '2018-03-01 08:30:00+00',
+        'pt-ml-azul', 'Perturbação', '');
+
+INSERT INTO line_disturbance_has_status (disturbance_id, status_id) VALUES
+    ('disturbance-1', 'status-1'),
+    ('disturbance-1', 'status-2');
+
+INSERT INTO line_condition (id, timestamp, mline, train_cars, train_frequency, source) VALUES
+    ('condition-1', '2018-03-01 08:00:00+00', 'pt-ml-azul', 6, '00:05:30', 'mlxscraper-pt-ml');
+
+INSERT INTO dataset_info (network_id, version, authors) VALUES
+    ('pt-ml', '2018-03-01 00:00:00+00', '{underlx}');
+
+INSERT INTO fare_zone (id, name, network) VALUES
+    ('pt-ml-zone', 'Zona única', 'pt-ml');
+
+INSERT INTO station_has_fare_zone (station_id, zone_id)
+    SELECT id, 'pt-ml-zone' FROM station;
+
+INSERT INTO fare_product (id, name, network, price, currency, validity, max_entries) VALUES
+    ('pt-ml-single', 'Viagem Metro', 'pt-ml', 165, 'EUR', '1 hour', 1);
+
+INSERT INTO fare_product_has_zone (product_id, zone_id) VALUES
+    ('pt-ml-single', 'pt-ml-zone');
+
+INSERT INTO poi (id, type, world_coord, web_url) VALUES
+    ('poi-arco', 'monument', '(38.7087, -9.1366)', 'https://example.com/arco');
+
+INSERT INTO poi_name (id, main, lang, name) VALUES
+    ('poi-arco', true, 'pt', 'Arco da Rua Augusta'),
+    ('poi-arco', false, 'en', 'Rua Augusta Arch');
+
+INSERT INTO station_has_poi (station_id, poi_id) VALUES
+    ('pt-ml-bc', 'poi-arco');
+
+INSERT INTO pp_player (discord_id, joined, lb_privacy, profile_privacy, name_type, in_guild, cached_name) VALUES
+    (1, '2018-03-01 00:00:00+00', 'PUBLIC', 'PUBLIC', 'USERNAME', true, 'tester');
+
+-- challenge-pending is used to check the approval status, challenge-approved is exchanged for a pair
+INSERT INTO pair_challenge (id, client_type, created, expires, approved_by, approval_time, redemption_time) VALUES
+    ('challenge-pending', 'web', now(), now() + interval '1 hour', NULL, NULL, NULL),
+    ('challenge-approved', 'web', now(), now() + interval '1 hour', 1, now(), NULL);
+
+-- the secret of this pair is set by the test, as its hash depends on the hash key
+INSERT INTO api_pair (key, secret, type, activation) VALUES
+    ('contract-pair-01', '', 'android', '2018-01-01 00:00:00+00');
+
+INSERT INTO trip (id, start_time, end_time, submitter, submit_time, user_confirmed) VALUES
+    ('trip-1', '2018-03-01 09:00:00+00', '2018-03-01 09:10:00+00', 'contract-pair-01', '2018-03-01 09:11:00+00', true);
+
+INSERT INTO station_use (trip_id, station_id, entry_time, leave_time, type, manual, source_line, target_line) VALUES
+    ('trip-1', 'pt-ml-rs', '2018-03-01 09:00:00+00', '2018-03-01 09:02:00+00', 'NETWORK_ENTRY', false, NULL, NULL),
+    ('trip-1', 'pt-ml-bc', '2018-03-01 09:04:00+00', '2018-03-01 09:06:00+00', 'INTERCHANGE', false, 'pt-ml-azul', 'pt-ml-verde'),
+    ('trip-1', 'pt-ml-ro', '2018-03-01 09:08:00+00', '2018-03-01 09:10:00+00', 'NETWORK_EXIT', false, NULL, NULL);
+
+INSERT INTO provisional_trip (id, submitter, start_time, last_report_time) VALUES
+    ('provisional-trip-1', 'contract-pair-01', '2018-03-02 09:00:00+00', '2018-03-02 09:04:00+00');
+
+INSERT INTO provisional_trip_report (trip_id, station_id, direction_id, timestamp) VALUES
+    ('provisional-trip-1', 'pt-ml-cs', 'pt-ml-bc', '2018-03-02 09:00:00+00'),
+    ('provisional-trip-1', 'pt-ml-bc', 'pt-ml-ro', '2018-03-02 09:02:00+00'),
+    ('provisional-trip-1', 'pt-ml-ro', NULL, '2018-03-02 09:04:00+00');
@@ -1,10 +1,12 @@
 package resource
 
 import (
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/posplay"
 	"github.com/yarf-framework/yarf"
@@ -16,45 +18,48 @@ type Trip struct {
 }
 
 type apiTrip struct {
-	ID            string                    `msgpack:"id" json:"id"`
-	StartTime     time.Time                 `msgpack:"startTime" json:"startTime"`
-	EndTime       time.Time                 `msgpack:"endTime" json:"endTime"`
+	ID            string                    `msgpack:"id" json:"id" protobuf:"1"`
+	StartTime     time.Time                 `msgpack:"startTime" json:"startTime" protobuf:"2"`
+	EndTime       time.Time                 `msgpack:"endTime" json:"endTime" protobuf:"3"`
 	Submitter     *types.APIPair      `msgpack:"-" json:"-"`
-	SubmitTime    time.Time                 `msgpack:"submitTime" json:"submitTime"`
-	EditTime      time.Time                 `msgpack:"editTime" json:"editTime"`
-	Edited        bool                      `msgpack:"edited" json:"edited"`
-	UserConfirmed bool                      `msgpack:"userConfirmed" json:"userConfirmed"`
+	SubmitTime    time.Time                 `msgpack:"submitTime" json:"submitTime" protobuf:"4"`
+	EditTime      time.Time                 `msgpack:"editTime" json:"editTime" protobuf:"5"`
+	Edited        bool                      `msgpack:"edited" json:"edited" protobuf:"6"`
+	UserConfirmed bool                      `msgpack:"userConfirmed" json:"userConfirmed" protobuf:"7"`
 	StationUses   []*types.StationUse `msgpack:"-" json:"-"`
+	Quality       types.TripQuality   `msgpack:"-" json:"-"`
+	QualityIssues []string            `msgpack:"-" json:"-"`
 }
 
 type apiTripWrapper struct {
 	apiTrip        `msgpack:",inline"`
-	APIstationUses []apiStationUseWrapper `msgpack:"uses" json:"uses"`
+	APIstationUses []apiStationUseWrapper `msgpack:"uses" json:"uses" protobuf:"8"`
 }
 
 type apiTripCreationRequest struct {
 	// ID must be a v4 UUID
-	ID            string                 `msgpack:"id" json:"id"`
-	Uses          []apiStationUseWrapper `msgpack:"uses" json:"uses"`
-	UserConfirmed bool                   `msgpack:"userConfirmed" json:"userConfirmed"`
+	ID            string                 `msgpack:"id" json:"id" protobuf:"1"`
+	Uses          []apiStationUseWrapper `msgpack:"uses" json:"uses" protobuf:"2"`
+	UserConfirmed bool                   `msgpack:"userConfirmed" json:"userConfirmed" protobuf:"3"`
 }
 
 type apiStationUse struct {
 	Station    *types.Station       `msgpack:"-" json:"-"`
-	EntryTime  time.Time                  `msgpack:"entryTime" json:"entryTime"`
-	LeaveTime  time.Time                  `msgpack:"leaveTime" json:"leaveTime"`
+	EntryTime  time.Time                  `msgpack:"entryTime" json:"entryTime" protobuf:"1"`
+	LeaveTime  time.Time                  `msgpack:"leaveTime" json:"leaveTime" protobuf:"2"`
 	Type       types.StationUseType `msgpack:"-" json:"-"`
-	Manual     bool                       `msgpack:"manual" json:"manual"`
+	Manual     bool                       `msgpack:"manual" json:"manual" protobuf:"3"`
 	SourceLine *types.Line          `msgpack:"-" json:"-"`
 	TargetLine *types.Line          `msgpack:"-" json:"-"`
+	Inferred   bool                       `msgpack:"inferred" json:"inferred" protobuf:"8"`
 }
 
 type apiStationUseWrapper struct {
 	apiStationUse `msgpack:",inline"`
-	StationID     string `msgpack:"station" json:"station"`
-	TypeString    string `msgpack:"type" json:"type"`
-	SourceLineID  string `msgpack:"sourceLine" json:"sourceLine"`
-	TargetLineID  string `msgpack:"targetLine" json:"targetLine"`
+	StationID     string `msgpack:"station" json:"station" protobuf:"4"`
+	TypeString    string `msgpack:"type" json:"type" protobuf:"5"`
+	SourceLineID  string `msgpack:"sourceLine" json:"sourceLine" protobuf:"6"`
+	TargetLineID  string `msgpack:"targetLine" json:"targetLine" protobuf:"7"`
 }
 
 // WithNode associates a sqalx Node with this resource
@@ -71,9 +76,9 @@ func (r *Trip) WithHashKey(key []byte) *Trip {
 
 // Get serves HTTP GET requests on this resource
 func (r *Trip) Get(c *yarf.Context) error {
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -126,9 +131,9 @@ func (r *Trip) Get(c *yarf.Context) error {
 
 // Post serves HTTP POST requests on this resource
 func (r *Trip) Post(c *yarf.Context) error {
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -177,6 +182,7 @@ func (r *Trip) Post(c *yarf.Context) error {
 			LeaveTime: requestUse.LeaveTime,
 			Type:      types.StationUseType(requestUse.TypeString),
 			Manual:    requestUse.Manual,
+			Inferred:  requestUse.Inferred,
 		}
 
 		use.Station, err = types.GetStation(tx, requestUse.StationID)
@@ -214,11 +220,25 @@ func (r *Trip) Post(c *yarf.Context) error {
 		trip.StationUses = append(trip.StationUses, &use)
 	}
 
+	err = r.validate(tx, &trip)
+	if err != nil {
+		return err
+	}
+
 	err = trip.Update(tx)
 	if err != nil {
 		return err
 	}
 
+	// submitting a trip with the ID of a provisional trip confirms it
+	provisionalTrip, err := types.GetProvisionalTrip(tx, request.ID)
+	if err == nil && provisionalTrip.Submitter.Key == pair.Key {
+		err = provisionalTrip.Delete(tx)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -228,15 +248,19 @@ func (r *Trip) Post(c *yarf.Context) error {
 	c.Response.Header().Set("Location", "/v1/trips/"+trip.ID)
 	r.render(c, &trip)
 
+	// samples missed here are ingested later by compute.UpdateTypicalSeconds
+	if err := compute.IngestTripTypicalSeconds(r.node, &trip); err != nil {
+		log.Println("IngestTripTypicalSeconds:", err)
+	}
 	posplay.RegisterTripSubmission(&trip)
 	return nil
 }
 
 // Put serves HTTP PUT requests on this resource
 func (r *Trip) Put(c *yarf.Context) error {
-	pair, err := r.AuthenticateClient(c)
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
 	if err != nil {
-		RenderUnauthorized(c)
+		RenderAuthenticationError(c, err)
 		return nil
 	}
 
@@ -257,6 +281,11 @@ func (r *Trip) Put(c *yarf.Context) error {
 		return err
 	}
 
+	err = r.validate(tx, &trip)
+	if err != nil {
+		return err
+	}
+
 	err = trip.Update(tx)
 	if err != nil {
 		return err
@@ -269,6 +298,9 @@ func (r *Trip) Put(c *yarf.Context) error {
 
 	r.render(c, &trip)
 
+	if err := compute.IngestTripTypicalSeconds(r.node, &trip); err != nil {
+		log.Println("IngestTripTypicalSeconds:", err)
+	}
 	if !hadBeenEdited {
 		posplay.RegisterTripFirstEdit(&trip)
 	}
@@ -339,6 +371,22 @@ func (r *Trip) getTripToEdit(tx sqalx.Node, request *apiTripCreationRequest, pai
 	return trip, oldtrip.Edited, nil
 }
 
+// validate fills gaps in the trip path and then scores the plausibility of the trip, storing the result in it
+func (r *Trip) validate(tx sqalx.Node, trip *types.Trip) error {
+	_, err := compute.NormalizeTrip(tx, trip)
+	if err != nil {
+		return err
+	}
+
+	validation, err := compute.ValidateTrip(tx, trip)
+	if err != nil {
+		return err
+	}
+	trip.Quality = validation.Quality
+	trip.QualityIssues = validation.Issues
+	return nil
+}
+
 func (r *Trip) buildStationUses(tx sqalx.Node, request *apiTripCreationRequest, trip *types.Trip) error {
 	var err error
 	for _, requestUse := range request.Uses {
@@ -347,6 +395,7 @@ func (r *Trip) buildStationUses(tx sqalx.Node, request *apiTripCreationRequest,
 			LeaveTime: requestUse.LeaveTime,
 			Type:      types.StationUseType(requestUse.TypeString),
 			Manual:    requestUse.Manual,
+			Inferred:  requestUse.Inferred,
 		}
 		use.Station, err = types.GetStation(tx, requestUse.StationID)
 		if err != nil {
@@ -386,6 +435,10 @@ func (r *Trip) buildStationUses(tx sqalx.Node, request *apiTripCreationRequest,
 }
 
 func (r *Trip) render(c *yarf.Context, trip *types.Trip) {
+	RenderData(c, buildAPITripWrapper(trip), "private")
+}
+
+func buildAPITripWrapper(trip *types.Trip) apiTripWrapper {
 	data := apiTripWrapper{
 		apiTrip:        apiTrip(*trip),
 		APIstationUses: []apiStationUseWrapper{},
@@ -405,6 +458,5 @@ func (r *Trip) render(c *yarf.Context, trip *types.Trip) {
 		}
 		data.APIstationUses = append(data.APIstationUses, sw)
 	}
-
-	RenderData(c, data, "private")
+	return data
 }
@@ -0,0 +1,93 @@
+package resource
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// DisturbanceV2 composites resource
+type DisturbanceV2 struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *DisturbanceV2) WithNode(node sqalx.Node) *DisturbanceV2 {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *DisturbanceV2) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return err
+	}
+	omitDuplicateStatus := c.Request.URL.Query().Get("omitduplicatestatus") == "true"
+
+	if c.Param("id") != "" {
+		disturbance, err := types.GetDisturbance(tx, c.Param("id"))
+		if err != nil {
+			return &yarf.CustomError{
+				HTTPCode:  http.StatusNotFound,
+				ErrorMsg:  "The specified disturbance does not exist",
+				ErrorBody: "The specified disturbance does not exist",
+			}
+		}
+		data := buildAPIDisturbanceWrapper(disturbance, omitDuplicateStatus)
+		RenderDataWithETag(c, selectFields(data, page.Fields), "s-maxage=10")
+		return nil
+	}
+
+	filter := types.DisturbanceFilter{
+		NetworkID:    c.Request.URL.Query().Get("network"),
+		OfficialOnly: c.Request.URL.Query().Get("official") == "true",
+	}
+	if lines := c.Request.URL.Query().Get("line"); lines != "" {
+		filter.LineIDs = strings.Split(lines, ",")
+	}
+	filter.Start, err = parseTimeParam(c, "start")
+	if err != nil {
+		return err
+	}
+	filter.End, err = parseTimeParam(c, "end")
+	if err != nil {
+		return err
+	}
+
+	afterTime, afterID, err := page.cursorTimeAndID()
+	if err != nil {
+		return err
+	}
+
+	// fetch one more than needed to know whether there is a next page
+	disturbances, err := types.GetDisturbancesPage(tx, filter, afterTime, afterID, page.Limit+1)
+	if err != nil {
+		return err
+	}
+
+	data := apiPage{}
+	if uint64(len(disturbances)) > page.Limit {
+		disturbances = disturbances[:page.Limit]
+		last := disturbances[len(disturbances)-1]
+		data.NextCursor = encodeTimeCursor(last.UStartTime, last.ID)
+	}
+
+	apidisturbances := make([]apiDisturbanceWrapper, len(disturbances))
+	for i := range disturbances {
+		apidisturbances[i] = buildAPIDisturbanceWrapper(disturbances[i], omitDuplicateStatus)
+	}
+	data.Items = selectFields(apidisturbances, page.Fields)
+
+	RenderDataWithETag(c, data, "s-maxage=10")
+	return nil
+}
@@ -0,0 +1,219 @@
+package resource
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yarf-framework/yarf"
+)
+
+const (
+	// v2DefaultPageSize is the number of items returned by paginated v2 collections when no limit is specified
+	v2DefaultPageSize = 50
+	// v2MaxPageSize is the maximum number of items that can be requested from paginated v2 collections
+	v2MaxPageSize = 500
+)
+
+type apiPage struct {
+	Items      interface{} `msgpack:"items" json:"items" protobuf:"1"`
+	NextCursor string      `msgpack:"nextCursor" json:"nextCursor" protobuf:"2"`
+}
+
+// pageRequest contains the pagination and field selection parameters of a v2 request
+type pageRequest struct {
+	Limit  uint64
+	Cursor []string
+	Fields []string
+}
+
+func parsePageRequest(c *yarf.Context) (pageRequest, error) {
+	p := pageRequest{
+		Limit: v2DefaultPageSize,
+	}
+	query := c.Request.URL.Query()
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.ParseUint(l, 10, 64)
+		if err != nil || limit == 0 || limit > v2MaxPageSize {
+			return p, &yarf.CustomError{
+				HTTPCode:  http.StatusBadRequest,
+				ErrorMsg:  "Invalid limit",
+				ErrorBody: "limit must be between 1 and " + strconv.Itoa(v2MaxPageSize),
+			}
+		}
+		p.Limit = limit
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return p, &yarf.CustomError{
+				HTTPCode:  http.StatusBadRequest,
+				ErrorMsg:  "Invalid cursor",
+				ErrorBody: "Invalid cursor",
+			}
+		}
+		p.Cursor = strings.Split(string(decoded), "\x00")
+	}
+	p.Fields = parseFieldsParam(c)
+	return p, nil
+}
+
+// cursorTimeAndID returns the time and ID contained in a cursor created with encodeCursor(time, id)
+func (p pageRequest) cursorTimeAndID() (time.Time, string, error) {
+	if len(p.Cursor) == 0 {
+		return time.Time{}, "", nil
+	}
+	if len(p.Cursor) != 2 {
+		return time.Time{}, "", &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid cursor",
+			ErrorBody: "Invalid cursor",
+		}
+	}
+	t, err := time.Parse(time.RFC3339Nano, p.Cursor[0])
+	if err != nil {
+		return time.Time{}, "", &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid cursor",
+			ErrorBody: "Invalid cursor",
+		}
+	}
+	return t, p.Cursor[1], nil
+}
+
+func encodeCursor(parts ...string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(parts, "\x00")))
+}
+
+func encodeTimeCursor(t time.Time, id string) string {
+	return encodeCursor(t.Format(time.RFC3339Nano), id)
+}
+
+func parseFieldsParam(c *yarf.Context) []string {
+	fields := []string{}
+	for _, field := range strings.Split(c.Request.URL.Query().Get("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseTimeParam parses a RFC3339 time in the specified query parameter, returning the zero time if it is missing
+func parseTimeParam(c *yarf.Context, param string) (time.Time, error) {
+	value := c.Request.URL.Query().Get(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid " + param + " time",
+			ErrorBody: "Invalid " + param + " time, must be in RFC3339 format",
+		}
+	}
+	return t, nil
+}
+
+// selectFields returns a representation of data (a struct, or a slice of structs) that only contains the specified fields.
+// Field names are those used in the encoded representation. If no fields are specified, data is returned unchanged
+func selectFields(data interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return data
+	}
+	wanted := make(map[string]bool)
+	for _, field := range fields {
+		wanted[field] = true
+	}
+	return selectFieldsOfValue(reflect.ValueOf(data), wanted)
+}
+
+func selectFieldsOfValue(v reflect.Value, wanted map[string]bool) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return selectFieldsOfValue(v.Elem(), wanted)
+	case reflect.Slice:
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = selectFieldsOfValue(v.Index(i), wanted)
+		}
+		return result
+	case reflect.Struct:
+		result := make(map[string]interface{})
+		collectSelectedFields(v, wanted, result)
+		return result
+	}
+	return v.Interface()
+}
+
+func collectSelectedFields(v reflect.Value, wanted map[string]bool, result map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && strings.Contains(field.Tag.Get("msgpack"), "inline") {
+			collectSelectedFields(v.Field(i), wanted, result)
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+		if wanted[name] {
+			result[name] = v.Field(i).Interface()
+		}
+	}
+}
+
+// RenderDataWithETag works like RenderData, but sets an ETag header based on the data and its content type
+// and responds with 304 Not Modified if it matches the If-None-Match header of the request
+func RenderDataWithETag(c *yarf.Context, data interface{}, cacheControl string) {
+	if cacheControl != "" {
+		c.Response.Header().Set("Cache-Control", cacheControl)
+	}
+	c.Response.Header().Set("Vary", "Accept")
+	contentType, encoded, err := encodeData(c, data)
+	if err != nil {
+		c.Response.Header().Set("Content-Type", contentType)
+		c.Response.Write([]byte(err.Error()))
+		return
+	}
+
+	etag := computeETag(contentType, data, encoded)
+	c.Response.Header().Set("ETag", etag)
+
+	for _, candidate := range strings.Split(c.Request.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			c.Response.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Response.Header().Set("Content-Type", contentType)
+	c.Response.Write(encoded)
+}
+
+// computeETag returns the ETag for the data encoded with the specified content type.
+// The ETag is computed from the JSON representation of the data, because encoding/json always sorts map keys,
+// while other codecs (like msgpack) write them in random order, which would make the ETag change on every request
+func computeETag(contentType string, data interface{}, encoded []byte) string {
+	canonical, err := json.Marshal(data)
+	if err != nil {
+		canonical = encoded
+	}
+	hash := sha1.New()
+	hash.Write([]byte(contentType))
+	hash.Write(canonical)
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`
+}
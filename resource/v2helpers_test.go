@@ -0,0 +1,37 @@
+package resource
+
+import (
+	"testing"
+
+	msgpack "gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestComputeETagIsStableForMaps(t *testing.T) {
+	data := map[string]interface{}{
+		"triviaURLs": map[string]string{"pt": "a", "en": "b", "fr": "c", "es": "d"},
+		"connURLs": map[string]map[string]string{
+			"bus":   {"pt": "a", "en": "b"},
+			"train": {"pt": "c", "en": "d"},
+			"boat":  {"pt": "e", "en": "f"},
+		},
+		"id":   "station",
+		"name": "Station",
+	}
+
+	var etag string
+	for i := 0; i < 50; i++ {
+		encoded, err := msgpack.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := computeETag("application/msgpack", data, encoded)
+		if i > 0 && e != etag {
+			t.Fatalf("ETag changed between encodings of the same data: %s != %s", e, etag)
+		}
+		etag = e
+	}
+
+	if computeETag("application/json", data, nil) == etag {
+		t.Error("ETag is the same for different content types")
+	}
+}
@@ -0,0 +1,85 @@
+package resource
+
+import (
+	"net/http"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// StationV2 composites resource
+type StationV2 struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *StationV2) WithNode(node sqalx.Node) *StationV2 {
+	r.node = node
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *StationV2) Get(c *yarf.Context) error {
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Param("id") != "" {
+		station, err := types.GetStation(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+		data, err := buildAPIStationWrapper(tx, station)
+		if err != nil {
+			return err
+		}
+		RenderDataWithETag(c, selectFields(data, page.Fields), "s-maxage=10")
+		return nil
+	}
+
+	afterID := ""
+	if len(page.Cursor) == 1 {
+		afterID = page.Cursor[0]
+	} else if len(page.Cursor) != 0 {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid cursor",
+			ErrorBody: "Invalid cursor",
+		}
+	}
+
+	// fetch one more than needed to know whether there is a next page
+	stations, err := types.GetStationsPage(tx,
+		c.Request.URL.Query().Get("network"),
+		c.Request.URL.Query().Get("line"),
+		afterID, page.Limit+1)
+	if err != nil {
+		return err
+	}
+
+	data := apiPage{}
+	if uint64(len(stations)) > page.Limit {
+		stations = stations[:page.Limit]
+		data.NextCursor = encodeCursor(stations[len(stations)-1].ID)
+	}
+
+	apistations := make([]apiStationWrapper, len(stations))
+	for i := range stations {
+		apistations[i], err = buildAPIStationWrapper(tx, stations[i])
+		if err != nil {
+			return err
+		}
+	}
+	data.Items = selectFields(apistations, page.Fields)
+
+	RenderDataWithETag(c, data, "s-maxage=10")
+	return nil
+}
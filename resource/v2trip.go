@@ -0,0 +1,95 @@
+package resource
+
+import (
+	"net/http"
+
+	"github.com/gbl08ma/sqalx"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// TripV2 composites resource
+type TripV2 struct {
+	resource
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *TripV2) WithNode(node sqalx.Node) *TripV2 {
+	r.node = node
+	return r
+}
+
+// WithHashKey associates a HMAC key with this resource so it can participate in authentication processes
+func (r *TripV2) WithHashKey(key []byte) *TripV2 {
+	r.hashKey = key
+	return r
+}
+
+// Get serves HTTP GET requests on this resource
+func (r *TripV2) Get(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c, types.PairScopeTrips)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Param("id") != "" {
+		trip, err := types.GetTrip(tx, c.Param("id"))
+		if err != nil || trip.Submitter.Key != pair.Key {
+			return &yarf.CustomError{
+				HTTPCode:  http.StatusNotFound,
+				ErrorMsg:  "The specified trip does not exist",
+				ErrorBody: "The specified trip does not exist",
+			}
+		}
+		RenderDataWithETag(c, selectFields(buildAPITripWrapper(trip), page.Fields), "private")
+		return nil
+	}
+
+	start, err := parseTimeParam(c, "start")
+	if err != nil {
+		return err
+	}
+	end, err := parseTimeParam(c, "end")
+	if err != nil {
+		return err
+	}
+
+	afterTime, afterID, err := page.cursorTimeAndID()
+	if err != nil {
+		return err
+	}
+
+	// fetch one more than needed to know whether there is a next page
+	trips, err := types.GetTripsForSubmitterPage(tx, pair, start, end, afterTime, afterID, page.Limit+1)
+	if err != nil {
+		return err
+	}
+
+	data := apiPage{}
+	if uint64(len(trips)) > page.Limit {
+		trips = trips[:page.Limit]
+		last := trips[len(trips)-1]
+		data.NextCursor = encodeTimeCursor(last.StartTime, last.ID)
+	}
+
+	apitrips := make([]apiTripWrapper, len(trips))
+	for i := range trips {
+		apitrips[i] = buildAPITripWrapper(trips[i])
+	}
+	data.Items = selectFields(apitrips, page.Fields)
+
+	RenderDataWithETag(c, data, "private")
+	return nil
+}
@@ -0,0 +1,142 @@
+package resource
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gbl08ma/sqalx"
+	uuid "github.com/satori/go.uuid"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/yarf-framework/yarf"
+)
+
+// WiFiAPObservation composites resource
+type WiFiAPObservation struct {
+	resource
+}
+
+// MaxWiFiAPObservationNetworks is the maximum number of networks a client can submit at once
+const MaxWiFiAPObservationNetworks = 100
+
+// MaxWiFiAPObservationAge is how long after being made an observation can still be submitted
+const MaxWiFiAPObservationAge = 24 * time.Hour
+
+var bssidRegexp = regexp.MustCompile("^[0-9a-f]{2}(:[0-9a-f]{2}){5}$")
+
+// apiWiFiAPObservation is submitted by clients that are confident of the station they are in
+// (e.g. because they are mid-trip between known stations)
+type apiWiFiAPObservation struct {
+	StationID string                   `msgpack:"stationId" json:"stationId"`
+	Time      time.Time                `msgpack:"timestamp" json:"timestamp"`
+	Networks  []apiObservedWiFiNetwork `msgpack:"networks" json:"networks"`
+}
+
+type apiObservedWiFiNetwork struct {
+	BSSID string `msgpack:"bssid" json:"bssid"`
+	SSID  string `msgpack:"ssid" json:"ssid"`
+	// Level is the signal strength in dBm
+	Level int `msgpack:"level" json:"level"`
+}
+
+type apiWiFiAPObservationResponse struct {
+	Accepted int `msgpack:"accepted" json:"accepted"`
+}
+
+// WithNode associates a sqalx Node with this resource
+func (r *WiFiAPObservation) WithNode(node sqalx.Node) *WiFiAPObservation {
+	r.node = node
+	return r
+}
+
+// WithHashKey associates a HMAC key with this resource so it can participate in authentication processes
+func (r *WiFiAPObservation) WithHashKey(key []byte) *WiFiAPObservation {
+	r.hashKey = key
+	return r
+}
+
+// Post serves HTTP POST requests on this resource
+func (r *WiFiAPObservation) Post(c *yarf.Context) error {
+	pair, err := r.AuthenticateClient(c, types.PairScopeWiFiAPs)
+	if err != nil {
+		RenderAuthenticationError(c, err)
+		return nil
+	}
+
+	var request apiWiFiAPObservation
+	err = r.DecodeRequest(c, &request)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if request.Time.IsZero() {
+		request.Time = now
+	}
+	if request.Time.After(now.Add(5*time.Minute)) || request.Time.Before(now.Add(-MaxWiFiAPObservationAge)) {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid observation time",
+			ErrorBody: "Invalid observation time",
+		}
+	}
+	if len(request.Networks) == 0 || len(request.Networks) > MaxWiFiAPObservationNetworks {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Invalid number of networks",
+			ErrorBody: "Invalid number of networks",
+		}
+	}
+
+	tx, err := r.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	station, err := types.GetStation(tx, request.StationID)
+	if err != nil {
+		return &yarf.CustomError{
+			HTTPCode:  http.StatusBadRequest,
+			ErrorMsg:  "Unknown station",
+			ErrorBody: "Unknown station",
+		}
+	}
+
+	accepted := 0
+	for _, network := range request.Networks {
+		bssid := strings.ToLower(strings.TrimSpace(network.BSSID))
+		// skip what is surely junk instead of failing the whole request
+		if !bssidRegexp.MatchString(bssid) || network.Level > 0 || network.Level < -127 {
+			continue
+		}
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		observation := types.WiFiAPObservation{
+			ID:        id.String(),
+			Submitter: pair,
+			Station:   station,
+			BSSID:     bssid,
+			SSID:      network.SSID,
+			Level:     network.Level,
+			Time:      request.Time,
+		}
+		err = observation.Update(tx)
+		if err != nil {
+			return err
+		}
+		accepted++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	c.Response.WriteHeader(http.StatusCreated)
+	RenderData(c, apiWiFiAPObservationResponse{Accepted: accepted}, "no-cache, no-store, must-revalidate")
+	return nil
+}
@@ -1,3 +1,5 @@
+DROP TABLE pair_challenge;
+DROP TABLE personal_data_audit;
 DROP TABLE pp_notification_setting;
 DROP TABLE pp_player_has_achievement;
 DROP TABLE pp_achievement_name;
@@ -9,14 +11,28 @@ DROP TABLE script;
 DROP TABLE station_has_poi;
 DROP TABLE poi_name;
 DROP TABLE poi;
+DROP TABLE wifiap_observation;
+DROP TABLE wifiap_suggestion;
 DROP TABLE feedback;
 DROP TABLE feedback_type;
+DROP TABLE provisional_trip_report;
+DROP TABLE provisional_trip;
+DROP TABLE fare_product_has_zone;
+DROP TABLE fare_product;
+DROP TABLE station_has_fare_zone;
+DROP TABLE fare_zone;
+DROP TABLE typical_seconds_trip;
+DROP TABLE typical_seconds_sample;
+DROP TABLE typical_seconds_bucket;
 DROP TABLE station_use;
 DROP TABLE station_use_type;
 DROP TABLE trip;
 DROP TABLE android_pair_request;
 DROP TABLE api_pair;
 DROP TABLE dataset_info;
+DROP TABLE station_platform;
+DROP TABLE station_path_outage;
+DROP TABLE station_path;
 DROP TABLE station_lobby_schedule;
 DROP TABLE station_lobby_exit;
 DROP TABLE station_lobby;
@@ -24,6 +40,7 @@ DROP TABLE station_feature;
 DROP TABLE station_has_wifiap;
 DROP TABLE wifiap;
 DROP TABLE line_has_station;
+DROP TABLE connection_hourly_time;
 DROP TABLE transfer;
 DROP TABLE connection;
 DROP TABLE station;
@@ -76,7 +93,7 @@ CREATE TABLE IF NOT EXISTS "mline" (
     color VARCHAR(6) NOT NULL,
     network VARCHAR(36) NOT NULL REFERENCES network (id),
     typ_cars INT NOT NULL,
-    order INT NOT NULL,
+    "order" INT NOT NULL,
     external_id VARCHAR(20) NOT NULL
 );
 
@@ -168,6 +185,17 @@ CREATE TABLE IF NOT EXISTS "transfer" (
     PRIMARY KEY (station_id, from_line, to_line)
 );
 
+CREATE TABLE IF NOT EXISTS "connection_hourly_time" (
+    from_station VARCHAR(36) NOT NULL REFERENCES station (id),
+    to_station VARCHAR(36) NOT NULL REFERENCES station (id),
+    weekday INT NOT NULL,
+    hour INT NOT NULL,
+    typ_wait_time INT NOT NULL,
+    typ_stop_time INT NOT NULL,
+    typ_time INT NOT NULL,
+    PRIMARY KEY (from_station, to_station, weekday, hour)
+);
+
 CREATE TABLE IF NOT EXISTS "line_has_station" (
     line_id VARCHAR(36) NOT NULL REFERENCES mline (id),
     station_id VARCHAR(36) NOT NULL REFERENCES station (id),
@@ -218,6 +246,32 @@ CREATE TABLE IF NOT EXISTS "station_lobby_schedule" (
     PRIMARY KEY (lobby_id, holiday, day)
 );
 
+CREATE TABLE IF NOT EXISTS "station_path" (
+    id VARCHAR(36) PRIMARY KEY,
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    lobby_id VARCHAR(36) REFERENCES station_lobby (id),
+    exit_id INT REFERENCES station_lobby_exit (id),
+    line_id VARCHAR(36) REFERENCES mline (id),
+    to_line_id VARCHAR(36) REFERENCES mline (id),
+    means VARCHAR(20) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "station_path_outage" (
+    id VARCHAR(36) PRIMARY KEY,
+    path_id VARCHAR(36) NOT NULL REFERENCES station_path (id),
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_time TIMESTAMP WITH TIME ZONE,
+    description TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "station_platform" (
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    line_id VARCHAR(36) NOT NULL REFERENCES mline (id),
+    gap_width INT NOT NULL,
+    gap_height INT NOT NULL,
+    PRIMARY KEY (station_id, line_id)
+);
+
 CREATE TABLE IF NOT EXISTS "dataset_info" (
     network_id VARCHAR(36) NOT NULL REFERENCES network (id) PRIMARY KEY,
     version TIMESTAMP WITH TIME ZONE NOT NULL,
@@ -228,9 +282,18 @@ CREATE TABLE IF NOT EXISTS "api_pair" (
     key VARCHAR(16) PRIMARY KEY,
     secret VARCHAR(64) NOT NULL,
     type TEXT NOT NULL,
-    activation TIMESTAMP WITH TIME ZONE NOT NULL
+    activation TIMESTAMP WITH TIME ZONE NOT NULL,
+    revocation_time TIMESTAMP WITH TIME ZONE,
+    revocation_reason TEXT NOT NULL DEFAULT '',
+    previous_secret VARCHAR(64) NOT NULL DEFAULT '',
+    previous_secret_expiry TIMESTAMP WITH TIME ZONE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_seen TIMESTAMP WITH TIME ZONE,
+    request_count BIGINT NOT NULL DEFAULT 0
 );
 
+CREATE INDEX ON "api_pair" (last_seen);
+
 CREATE TABLE IF NOT EXISTS "android_pair_request" (
     nonce VARCHAR(36) PRIMARY KEY,
     request_time TIMESTAMP WITH TIME ZONE NOT NULL,
@@ -248,7 +311,9 @@ CREATE TABLE IF NOT EXISTS "trip" (
     submitter VARCHAR(16) NOT NULL REFERENCES api_pair (key),
     submit_time TIMESTAMP WITH TIME ZONE NOT NULL,
     edit_time TIMESTAMP WITH TIME ZONE,
-    user_confirmed BOOLEAN NOT NULL
+    user_confirmed BOOLEAN NOT NULL,
+    quality VARCHAR(20) NOT NULL DEFAULT 'UNCHECKED',
+    quality_issues TEXT[] NOT NULL DEFAULT '{}'
 );
 
 CREATE TABLE IF NOT EXISTS "station_use_type" (
@@ -265,26 +330,134 @@ CREATE TABLE IF NOT EXISTS "station_use" (
     leave_time TIMESTAMP WITH TIME ZONE NOT NULL,
     type VARCHAR(20) NOT NULL REFERENCES station_use_type (type),
     manual BOOLEAN NOT NULL,
+    inferred BOOLEAN NOT NULL DEFAULT FALSE,
     source_line VARCHAR(36) REFERENCES mline (id),
     target_line VARCHAR(36) REFERENCES mline (id),
     PRIMARY KEY (trip_id, station_id, entry_time)
 );
 
+CREATE TABLE IF NOT EXISTS "typical_seconds_bucket" (
+    kind VARCHAR(20) NOT NULL,
+    station_id VARCHAR(36) NOT NULL,
+    from_id VARCHAR(36) NOT NULL,
+    to_id VARCHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    hour INT NOT NULL,
+    numerator DOUBLE PRECISION NOT NULL,
+    denominator INT NOT NULL,
+    PRIMARY KEY (kind, station_id, from_id, to_id, day, hour)
+);
+
+CREATE TABLE IF NOT EXISTS "typical_seconds_sample" (
+    trip_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    station_id VARCHAR(36) NOT NULL,
+    from_id VARCHAR(36) NOT NULL,
+    to_id VARCHAR(36) NOT NULL,
+    day DATE NOT NULL,
+    hour INT NOT NULL,
+    seconds DOUBLE PRECISION NOT NULL
+);
+CREATE INDEX ON "typical_seconds_sample" (trip_id);
+
+CREATE TABLE IF NOT EXISTS "typical_seconds_trip" (
+    trip_id VARCHAR(36) PRIMARY KEY,
+    ingest_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "provisional_trip" (
+    id VARCHAR(36) PRIMARY KEY,
+    submitter VARCHAR(16) NOT NULL REFERENCES api_pair (key),
+    start_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_report_time TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "provisional_trip_report" (
+    trip_id VARCHAR(36) NOT NULL REFERENCES provisional_trip (id),
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    direction_id VARCHAR(36) REFERENCES station (id),
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (trip_id, timestamp)
+);
+
+CREATE TABLE IF NOT EXISTS "fare_zone" (
+    id VARCHAR(36) PRIMARY KEY,
+    name TEXT NOT NULL,
+    network VARCHAR(36) NOT NULL REFERENCES network (id)
+);
+
+CREATE TABLE IF NOT EXISTS "station_has_fare_zone" (
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    zone_id VARCHAR(36) NOT NULL REFERENCES fare_zone (id),
+    PRIMARY KEY (station_id, zone_id)
+);
+
+CREATE TABLE IF NOT EXISTS "fare_product" (
+    id VARCHAR(36) PRIMARY KEY,
+    name TEXT NOT NULL,
+    network VARCHAR(36) NOT NULL REFERENCES network (id),
+    price INT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    validity INTERVAL NOT NULL,
+    max_entries INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "fare_product_has_zone" (
+    product_id VARCHAR(36) NOT NULL REFERENCES fare_product (id),
+    zone_id VARCHAR(36) NOT NULL REFERENCES fare_zone (id),
+    PRIMARY KEY (product_id, zone_id)
+);
+
 CREATE TABLE IF NOT EXISTS "feedback_type" (
     type VARCHAR(50) PRIMARY KEY
 );
 
 INSERT INTO feedback_type (type)
-    VALUES ('s2ls-incorrect-detection');
+    VALUES ('s2ls-incorrect-detection'), ('wrong-station-data'), ('bug'), ('suggestion'), ('disturbance-correction');
 
 CREATE TABLE IF NOT EXISTS "feedback" (
     id VARCHAR(36) PRIMARY KEY,
     submitter VARCHAR(16) NOT NULL REFERENCES api_pair (key),
     timestamp TIMESTAMP WITH TIME ZONE NOT NULL,
     type VARCHAR(50) NOT NULL REFERENCES feedback_type (type),
-    contents TEXT NOT NULL
+    contents TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'new',
+    assignee TEXT NOT NULL DEFAULT '',
+    discord_message_id VARCHAR(30) NOT NULL DEFAULT ''
 );
 
+CREATE INDEX ON "feedback" (status);
+CREATE INDEX ON "feedback" (discord_message_id);
+
+CREATE TABLE IF NOT EXISTS "wifiap_suggestion" (
+    id VARCHAR(36) PRIMARY KEY,
+    bssid VARCHAR(17) NOT NULL,
+    ssid TEXT NOT NULL,
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    action VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    submitters INT NOT NULL,
+    feedback_ids TEXT[] NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX ON "wifiap_suggestion" (status);
+
+CREATE TABLE IF NOT EXISTS "wifiap_observation" (
+    id VARCHAR(36) PRIMARY KEY,
+    submitter VARCHAR(16) NOT NULL REFERENCES api_pair (key),
+    station_id VARCHAR(36) NOT NULL REFERENCES station (id),
+    bssid VARCHAR(17) NOT NULL,
+    ssid TEXT NOT NULL,
+    level INT NOT NULL,
+    timestamp TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX ON "wifiap_observation" (submitter);
+CREATE INDEX ON "wifiap_observation" (timestamp);
+
 CREATE TABLE IF NOT EXISTS "poi" (
     id VARCHAR(36) PRIMARY KEY,
     type VARCHAR(20) NOT NULL,
@@ -372,4 +545,27 @@ CREATE TABLE IF NOT EXISTS "pp_notification_setting" (
     method VARCHAR(36) NOT NULL,
     enabled BOOLEAN NOT NULL,
     PRIMARY KEY (discord_id, notification_type, method)
-);
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS "personal_data_audit" (
+    id VARCHAR(36) PRIMARY KEY,
+    time TIMESTAMP WITH TIME ZONE NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    pair_key VARCHAR(16) NOT NULL,
+    discord_id BIGINT NOT NULL,
+    origin VARCHAR(20) NOT NULL,
+    details TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "pair_challenge" (
+    id VARCHAR(36) PRIMARY KEY,
+    client_type VARCHAR(20) NOT NULL,
+    created TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires TIMESTAMP WITH TIME ZONE NOT NULL,
+    approved_by BIGINT REFERENCES pp_player (discord_id),
+    approval_time TIMESTAMP WITH TIME ZONE,
+    redemption_time TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX ON "pair_challenge" (approved_by);
+CREATE INDEX ON "pair_challenge" (expires);
\ No newline at end of file
@@ -151,6 +151,14 @@ func handleNewStatus(status *types.Status, allowNotify bool) {
 	}
 
 	lastChange = time.Now().UTC()
+
+	// duplicate statuses are not stored and must not be sent as events
+	if _, err := types.GetStatus(rootSqalxNode, status.ID); err == nil {
+		err = statusEventBroker.PublishStatus(status)
+		if err != nil {
+			mainLog.Println(err)
+		}
+	}
 }
 
 func handleNewCondition(condition *types.LineCondition) {
@@ -168,6 +176,10 @@ func handleNewCondition(condition *types.LineCondition) {
 			mainLog.Println(err)
 			return
 		}
+		if tx.Commit() == nil {
+			statusEventBroker.PublishCondition(condition)
+		}
+		return
 	}
 
 	tx.Commit()
@@ -2,6 +2,7 @@ package main
 
 import (
 	"runtime"
+	"strings"
 	"time"
 
 	cache "github.com/patrickmn/go-cache"
@@ -17,6 +18,10 @@ var APIrequestTelemetry = make(chan interface{}, 10)
 // a pair request succeeds (true) or fails (false)
 var PairRequestTelemetry = make(chan bool, 10)
 
+// RateLimitTelemetry is a channel where the route of a request should be sent whenever
+// a request is rejected for exceeding the rate limit
+var RateLimitTelemetry = make(chan string, 10)
+
 // StatsSender is meant to be called as a goroutine that handles sending telemetry
 // to a statsd (or compatible) server
 func StatsSender() {
@@ -91,6 +96,9 @@ func StatsSender() {
 			} else {
 				c.Increment("pairrequest.failure")
 			}
+		case route := <-RateLimitTelemetry:
+			c.Increment("apicalls.ratelimited")
+			c.Increment("apicalls.ratelimited." + strings.NewReplacer("/", ".", ":", "").Replace(strings.Trim(route, "/")))
 		}
 	}
 }
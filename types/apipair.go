@@ -10,8 +10,26 @@ import (
 
 	"github.com/gbl08ma/sqalx"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 )
 
+// Scopes restrict which resources an APIPair may use. A pair without scopes may use all resources
+const (
+	// PairScopeTrips allows submitting, editing and reading trips
+	PairScopeTrips = "trips"
+	// PairScopeReports allows reporting disturbances
+	PairScopeReports = "reports"
+	// PairScopeRealtime allows sending real-time location reports
+	PairScopeRealtime = "rt"
+	// PairScopeFeedback allows submitting feedback
+	PairScopeFeedback = "feedback"
+	// PairScopeWiFiAPs allows submitting WiFi AP observations
+	PairScopeWiFiAPs = "wifiaps"
+)
+
+// PairScopes contains all the existing APIPair scopes
+var PairScopes = []string{PairScopeTrips, PairScopeReports, PairScopeRealtime, PairScopeFeedback, PairScopeWiFiAPs}
+
 // APIPair contains API auth credentials
 type APIPair struct {
 	Key string
@@ -21,30 +39,109 @@ type APIPair struct {
 	SecretHash string
 	Type       string
 	Activation time.Time
+	// RevocationTime is zero if the pair was never revoked
+	RevocationTime   time.Time
+	RevocationReason string
+	// PreviousSecretHash contains the hash of the secret that was replaced in the last rotation,
+	// which remains valid until PreviousSecretExpiry
+	PreviousSecretHash   string
+	PreviousSecretExpiry time.Time
+	// Scopes is empty if the pair may use all resources
+	Scopes       []string
+	LastSeen     time.Time
+	RequestCount int64
 }
 
-// GetPair returns the API pair with the given ID
-func GetPair(node sqalx.Node, key string) (*APIPair, error) {
-	if value, present := node.Load(getCacheKey("pair", key)); present {
-		return value.(*APIPair), nil
-	}
+func getPairsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*APIPair, error) {
+	pairs := []*APIPair{}
 
-	var pair APIPair
 	tx, err := node.Beginx()
 	if err != nil {
-		return &pair, err
+		return pairs, err
 	}
 	defer tx.Commit() // read-only tx
 
-	err = sdb.Select("key", "secret", "type", "activation").
+	rows, err := sbuilder.Columns("api_pair.key", "api_pair.secret", "api_pair.type", "api_pair.activation",
+		"api_pair.revocation_time", "api_pair.revocation_reason", "api_pair.previous_secret", "api_pair.previous_secret_expiry",
+		"api_pair.scopes", "api_pair.last_seen", "api_pair.request_count").
 		From("api_pair").
-		Where(sq.Eq{"key": key}).
-		RunWith(tx).QueryRow().Scan(&pair.Key, &pair.SecretHash, &pair.Type, &pair.Activation)
+		RunWith(tx).Query()
+	if err != nil {
+		return pairs, fmt.Errorf("getPairsWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pair APIPair
+		var revocationTime, previousSecretExpiry, lastSeen pq.NullTime
+		var scopes pq.StringArray
+		err := rows.Scan(
+			&pair.Key,
+			&pair.SecretHash,
+			&pair.Type,
+			&pair.Activation,
+			&revocationTime,
+			&pair.RevocationReason,
+			&pair.PreviousSecretHash,
+			&previousSecretExpiry,
+			&scopes,
+			&lastSeen,
+			&pair.RequestCount)
+		if err != nil {
+			return pairs, fmt.Errorf("getPairsWithSelect: %s", err)
+		}
+		if revocationTime.Valid {
+			pair.RevocationTime = revocationTime.Time
+		}
+		if previousSecretExpiry.Valid {
+			pair.PreviousSecretExpiry = previousSecretExpiry.Time
+		}
+		if lastSeen.Valid {
+			pair.LastSeen = lastSeen.Time
+		}
+		pair.Scopes = scopes
+		pairs = append(pairs, &pair)
+	}
+	if err := rows.Err(); err != nil {
+		return pairs, fmt.Errorf("getPairsWithSelect: %s", err)
+	}
+	return pairs, nil
+}
+
+// GetRecentlySeenPairs returns the pairs that were used most recently, up to the specified limit
+func GetRecentlySeenPairs(node sqalx.Node, limit uint64) ([]*APIPair, error) {
+	s := sdb.Select().
+		Where("last_seen IS NOT NULL").
+		OrderBy("last_seen DESC").
+		Limit(limit)
+	return getPairsWithSelect(node, s)
+}
+
+// GetRevokedPairs returns all the revoked pairs, most recently revoked first
+func GetRevokedPairs(node sqalx.Node) ([]*APIPair, error) {
+	s := sdb.Select().
+		Where("revocation_time IS NOT NULL").
+		OrderBy("revocation_time DESC")
+	return getPairsWithSelect(node, s)
+}
+
+// GetPair returns the API pair with the given ID
+func GetPair(node sqalx.Node, key string) (*APIPair, error) {
+	if value, present := node.Load(getCacheKey("pair", key)); present {
+		return value.(*APIPair), nil
+	}
+
+	s := sdb.Select().
+		Where(sq.Eq{"key": key})
+	pairs, err := getPairsWithSelect(node, s)
 	if err != nil {
-		return &pair, errors.New("GetPair: " + err.Error())
+		return &APIPair{}, errors.New("GetPair: " + err.Error())
+	}
+	if len(pairs) == 0 {
+		return &APIPair{}, errors.New("GetPair: pair not found")
 	}
-	tx.Store(getCacheKey("pair", key), &pair)
-	return &pair, nil
+	node.Store(getCacheKey("pair", key), pairs[0])
+	return pairs[0], nil
 }
 
 // NewPair creates a new API access pair, stores it in the DB and returns it
@@ -90,20 +187,164 @@ func GetPairIfCorrect(node sqalx.Node, key string, givenSecret string, hashKey [
 	if !pair.Activated() {
 		return nil, errors.New("Pair is not activated")
 	}
+	if pair.Revoked() {
+		return nil, errors.New("Pair is revoked")
+	}
 	if err = pair.CheckSecret(givenSecret, hashKey); err != nil {
 		return nil, err
 	}
 	return pair, nil
 }
 
-// CheckSecret returns no errors if the given secret is correct for this API pair
+// CheckSecret returns no errors if the given secret is correct for this API pair.
+// After a rotation, the previous secret is also accepted until it expires
 func (pair *APIPair) CheckSecret(givenSecret string, hashKey []byte) (err error) {
-	if pair.SecretHash != ComputeAPISecretHash(givenSecret, hashKey) {
-		return errors.New("CheckSecret: the given secret does not match with the pair secret")
+	givenHash := ComputeAPISecretHash(givenSecret, hashKey)
+	if pair.SecretHash == givenHash {
+		return nil
+	}
+	if pair.PreviousSecretHash != "" && pair.PreviousSecretHash == givenHash && time.Now().Before(pair.PreviousSecretExpiry) {
+		return nil
+	}
+	return errors.New("CheckSecret: the given secret does not match with the pair secret")
+}
+
+// Revoked returns whether this pair has been revoked
+func (pair *APIPair) Revoked() bool {
+	return !pair.RevocationTime.IsZero()
+}
+
+// HasScope returns whether this pair may use resources of the specified scope
+func (pair *APIPair) HasScope(scope string) bool {
+	if len(pair.Scopes) == 0 {
+		return true
+	}
+	for _, s := range pair.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoke revokes the pair, preventing it from being used, for the specified reason
+func (pair *APIPair) Revoke(node sqalx.Node, reason string) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	_, err = sdb.Update("api_pair").
+		Set("revocation_time", now).
+		Set("revocation_reason", reason).
+		Where(sq.Eq{"key": pair.Key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RevokeAPIPair: %s", err)
+	}
+	pair.RevocationTime = now
+	pair.RevocationReason = reason
+	tx.Delete(getCacheKey("pair", pair.Key))
+	return tx.Commit()
+}
+
+// Unrevoke lifts the revocation of the pair
+func (pair *APIPair) Unrevoke(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Update("api_pair").
+		Set("revocation_time", nil).
+		Set("revocation_reason", "").
+		Where(sq.Eq{"key": pair.Key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("UnrevokeAPIPair: %s", err)
+	}
+	pair.RevocationTime = time.Time{}
+	pair.RevocationReason = ""
+	tx.Delete(getCacheKey("pair", pair.Key))
+	return tx.Commit()
+}
+
+// RotateSecret replaces the secret of the pair with a new one, which is placed in pair.Secret.
+// The current secret remains valid for the specified overlap period, so that clients have time to switch
+func (pair *APIPair) RotateSecret(node sqalx.Node, overlap time.Duration, hashKey []byte) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	secret := GenerateAPISecret()
+	secretHash := ComputeAPISecretHash(secret, hashKey)
+	expiry := time.Now().Add(overlap)
+	_, err = sdb.Update("api_pair").
+		Set("secret", secretHash).
+		Set("previous_secret", pair.SecretHash).
+		Set("previous_secret_expiry", expiry).
+		Where(sq.Eq{"key": pair.Key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RotateAPIPairSecret: %s", err)
+	}
+	tx.Delete(getCacheKey("pair", pair.Key))
+	err = tx.Commit()
+	if err != nil {
+		return err
 	}
+	pair.PreviousSecretHash = pair.SecretHash
+	pair.PreviousSecretExpiry = expiry
+	pair.Secret = secret
+	pair.SecretHash = secretHash
 	return nil
 }
 
+// SetScopes sets the scopes of the pair. An empty slice allows the pair to use all resources
+func (pair *APIPair) SetScopes(node sqalx.Node, scopes []string) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Update("api_pair").
+		Set("scopes", pq.StringArray(scopes)).
+		Where(sq.Eq{"key": pair.Key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("SetAPIPairScopes: %s", err)
+	}
+	pair.Scopes = scopes
+	tx.Delete(getCacheKey("pair", pair.Key))
+	return tx.Commit()
+}
+
+// RecordPairUsage updates the last seen time of a pair and adds to its request count.
+// The cached pair is not updated, so that usage tracking does not cause cache churn
+func RecordPairUsage(node sqalx.Node, key string, lastSeen time.Time, requests int64) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Update("api_pair").
+		Set("last_seen", sq.Expr("GREATEST(COALESCE(last_seen, ?), ?)", lastSeen, lastSeen)).
+		Set("request_count", sq.Expr("request_count + ?", requests)).
+		Where(sq.Eq{"key": key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RecordPairUsage: %s", err)
+	}
+	return tx.Commit()
+}
+
 // CountPairActivationsByDay counts APIPair activations by day between the specified dates
 func CountPairActivationsByDay(node sqalx.Node, start time.Time, end time.Time) ([]time.Time, []int, error) {
 	tx, err := node.Beginx()
@@ -82,3 +82,29 @@ func GetDataset(node sqalx.Node, networkID string) (*Dataset, error) {
 	}
 	return datasets[0], nil
 }
+
+// Update adds or updates the dataset
+func (dataset *Dataset) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, err := time.Parse(time.RFC3339, dataset.Version)
+	if err != nil {
+		return errors.New("AddDataset: " + err.Error())
+	}
+
+	_, err = sdb.Insert("dataset_info").
+		Columns("network_id", "version", "authors").
+		Values(dataset.Network.ID, version, dataset.Authors).
+		Suffix("ON CONFLICT (network_id) DO UPDATE SET version = ?, authors = ?",
+			version, dataset.Authors).
+		RunWith(tx).Exec()
+
+	if err != nil {
+		return errors.New("AddDataset: " + err.Error())
+	}
+	return tx.Commit()
+}
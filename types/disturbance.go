@@ -89,6 +89,43 @@ func GetDisturbancesBetween(node sqalx.Node, start time.Time, end time.Time, off
 	return getDisturbancesWithSelect(node, s)
 }
 
+// DisturbanceFilter restricts the disturbances returned by GetDisturbancesPage.
+// Zero-valued fields do not restrict the results
+type DisturbanceFilter struct {
+	Start        time.Time
+	End          time.Time
+	NetworkID    string
+	LineIDs      []string
+	OfficialOnly bool
+}
+
+// GetDisturbancesPage returns up to `limit` disturbances matching the filter, ordered by start time and ID.
+// If afterID is not empty, only disturbances that come after the one with the specified start time and ID are returned
+func GetDisturbancesPage(node sqalx.Node, filter DisturbanceFilter, afterTime time.Time, afterID string, limit uint64) ([]*Disturbance, error) {
+	s := sdb.Select()
+	if !filter.Start.IsZero() {
+		s = s.Where(sq.Expr("COALESCE(time_end, now()) >= ?", filter.Start))
+	}
+	if !filter.End.IsZero() {
+		s = s.Where(sq.Expr("time_start <= ?", filter.End))
+	}
+	if filter.NetworkID != "" {
+		s = s.Where(sq.Expr("mline IN (SELECT id FROM mline WHERE network = ?)", filter.NetworkID))
+	}
+	if len(filter.LineIDs) > 0 {
+		s = s.Where(sq.Eq{"mline": filter.LineIDs})
+	}
+	if filter.OfficialOnly {
+		s = s.Where("otime_start IS NOT NULL")
+	}
+	if afterID != "" {
+		s = s.Where(sq.Expr("(time_start, id) > (?, ?)", afterTime, afterID))
+	}
+	s = s.OrderBy("time_start ASC", "id ASC").
+		Limit(limit)
+	return getDisturbancesWithSelect(node, s)
+}
+
 // getDisturbancesWithSelect returns a slice with all disturbances that match the conditions in sbuilder
 func getDisturbancesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Disturbance, error) {
 	disturbances := []*Disturbance{}
@@ -205,6 +242,20 @@ func GetDisturbance(node sqalx.Node, id string) (*Disturbance, error) {
 	return disturbances[0], nil
 }
 
+// GetDisturbanceWithStatus returns the Disturbance that the Status with the given ID belongs to
+func GetDisturbanceWithStatus(node sqalx.Node, statusID string) (*Disturbance, error) {
+	s := sdb.Select().
+		Where("line_disturbance.id IN (SELECT disturbance_id FROM line_disturbance_has_status WHERE status_id = ?)", statusID)
+	disturbances, err := getDisturbancesWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(disturbances) == 0 {
+		return nil, errors.New("Disturbance not found")
+	}
+	return disturbances[0], nil
+}
+
 // LatestStatus returns the most recent status of this disturbance
 func (disturbance *Disturbance) LatestStatus() *Status {
 	var latest *Status
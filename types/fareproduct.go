@@ -0,0 +1,177 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+// FareProduct is a ticket or pass that can be used to travel in a Network
+type FareProduct struct {
+	ID      string
+	Name    string
+	Network *Network
+	// Price is expressed in the smallest unit of the currency (e.g. cents)
+	Price    int
+	Currency string
+	// Validity is for how long after the first entry the product can be used to enter the network again.
+	// A zero validity means the product is only valid for a single journey (interchanges are always included)
+	Validity Duration
+	// MaxEntries is the maximum number of network entries within the validity period (zero means unlimited)
+	MaxEntries int
+	// Zones are the zones where the product is valid. The product is valid in the whole network if empty
+	Zones []*FareZone
+}
+
+// GetFareProducts returns a slice with all registered fare products
+func GetFareProducts(node sqalx.Node) ([]*FareProduct, error) {
+	return getFareProductsWithSelect(node, sdb.Select())
+}
+
+func getFareProductsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*FareProduct, error) {
+	products := []*FareProduct{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return products, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("fare_product.id", "fare_product.name", "fare_product.network",
+		"fare_product.price", "fare_product.currency", "fare_product.validity", "fare_product.max_entries").
+		From("fare_product").
+		OrderBy("fare_product.price ASC", "fare_product.id ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return products, fmt.Errorf("getFareProductsWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	var networkIDs []string
+	for rows.Next() {
+		var product FareProduct
+		var networkID string
+		err := rows.Scan(
+			&product.ID,
+			&product.Name,
+			&networkID,
+			&product.Price,
+			&product.Currency,
+			&product.Validity,
+			&product.MaxEntries)
+		if err != nil {
+			return products, fmt.Errorf("getFareProductsWithSelect: %s", err)
+		}
+		products = append(products, &product)
+		networkIDs = append(networkIDs, networkID)
+	}
+	if err := rows.Err(); err != nil {
+		return products, fmt.Errorf("getFareProductsWithSelect: %s", err)
+	}
+	for i := range networkIDs {
+		products[i].Network, err = GetNetwork(tx, networkIDs[i])
+		if err != nil {
+			return products, fmt.Errorf("getFareProductsWithSelect: %s", err)
+		}
+		s := sdb.Select().
+			Join("fare_product_has_zone ON fare_product_has_zone.product_id = ? AND fare_product_has_zone.zone_id = fare_zone.id", products[i].ID)
+		products[i].Zones, err = getFareZonesWithSelect(tx, s)
+		if err != nil {
+			return products, fmt.Errorf("getFareProductsWithSelect: %s", err)
+		}
+	}
+	return products, nil
+}
+
+// GetFareProduct returns the FareProduct with the given ID
+func GetFareProduct(node sqalx.Node, id string) (*FareProduct, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"fare_product.id": id})
+	products, err := getFareProductsWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, errors.New("FareProduct not found")
+	}
+	return products[0], nil
+}
+
+// ValidInZones returns whether the product can be used in at least one of the specified zones.
+// A station without zones can be used with any product of its network
+func (product *FareProduct) ValidInZones(zones []*FareZone) bool {
+	if len(product.Zones) == 0 || len(zones) == 0 {
+		return true
+	}
+	for _, zone := range zones {
+		for _, productZone := range product.Zones {
+			if zone.ID == productZone.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Update adds or updates the product
+func (product *FareProduct) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = product.Network.Update(tx)
+	if err != nil {
+		return errors.New("AddFareProduct: " + err.Error())
+	}
+
+	_, err = sdb.Insert("fare_product").
+		Columns("id", "name", "network", "price", "currency", "validity", "max_entries").
+		Values(product.ID, product.Name, product.Network.ID, product.Price, product.Currency, product.Validity, product.MaxEntries).
+		Suffix("ON CONFLICT (id) DO UPDATE SET name = ?, network = ?, price = ?, currency = ?, validity = ?, max_entries = ?",
+			product.Name, product.Network.ID, product.Price, product.Currency, product.Validity, product.MaxEntries).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddFareProduct: " + err.Error())
+	}
+
+	_, err = sdb.Delete("fare_product_has_zone").
+		Where(sq.Eq{"product_id": product.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddFareProduct: " + err.Error())
+	}
+	for _, zone := range product.Zones {
+		_, err = sdb.Insert("fare_product_has_zone").
+			Columns("product_id", "zone_id").
+			Values(product.ID, zone.ID).
+			RunWith(tx).Exec()
+		if err != nil {
+			return errors.New("AddFareProduct: " + err.Error())
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the product
+func (product *FareProduct) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("fare_product_has_zone").
+		Where(sq.Eq{"product_id": product.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveFareProduct: %s", err)
+	}
+	_, err = sdb.Delete("fare_product").
+		Where(sq.Eq{"id": product.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveFareProduct: %s", err)
+	}
+	return tx.Commit()
+}
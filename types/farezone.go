@@ -0,0 +1,173 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+// FareZone is a ticketing zone of a Network
+type FareZone struct {
+	ID      string
+	Name    string
+	Network *Network
+}
+
+// GetFareZones returns a slice with all registered fare zones
+func GetFareZones(node sqalx.Node) ([]*FareZone, error) {
+	return getFareZonesWithSelect(node, sdb.Select())
+}
+
+func getFareZonesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*FareZone, error) {
+	zones := []*FareZone{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return zones, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("fare_zone.id", "fare_zone.name", "fare_zone.network").
+		From("fare_zone").
+		OrderBy("fare_zone.id ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return zones, fmt.Errorf("getFareZonesWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	var networkIDs []string
+	for rows.Next() {
+		var zone FareZone
+		var networkID string
+		err := rows.Scan(
+			&zone.ID,
+			&zone.Name,
+			&networkID)
+		if err != nil {
+			return zones, fmt.Errorf("getFareZonesWithSelect: %s", err)
+		}
+		zones = append(zones, &zone)
+		networkIDs = append(networkIDs, networkID)
+	}
+	if err := rows.Err(); err != nil {
+		return zones, fmt.Errorf("getFareZonesWithSelect: %s", err)
+	}
+	for i := range networkIDs {
+		zones[i].Network, err = GetNetwork(tx, networkIDs[i])
+		if err != nil {
+			return zones, fmt.Errorf("getFareZonesWithSelect: %s", err)
+		}
+	}
+	return zones, nil
+}
+
+// GetFareZone returns the FareZone with the given ID
+func GetFareZone(node sqalx.Node, id string) (*FareZone, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"fare_zone.id": id})
+	zones, err := getFareZonesWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return nil, errors.New("FareZone not found")
+	}
+	return zones[0], nil
+}
+
+// Stations returns the stations in this zone
+func (zone *FareZone) Stations(node sqalx.Node) ([]*Station, error) {
+	s := sdb.Select().
+		Join("station_has_fare_zone ON station_has_fare_zone.zone_id = ? AND station_has_fare_zone.station_id = id", zone.ID)
+	return getStationsWithSelect(node, s)
+}
+
+// AddStation associates a station with this zone. Stations at zone boundaries may be in more than one zone
+func (zone *FareZone) AddStation(node sqalx.Node, station *Station) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("station_has_fare_zone").
+		Columns("station_id", "zone_id").
+		Values(station.ID, zone.ID).
+		Suffix("ON CONFLICT DO NOTHING").
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddStation: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// RemoveStation removes the association between a station and this zone
+func (zone *FareZone) RemoveStation(node sqalx.Node, station *Station) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_has_fare_zone").
+		Where(sq.Eq{"station_id": station.ID}, sq.Eq{"zone_id": zone.ID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("RemoveStation: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Update adds or updates the zone
+func (zone *FareZone) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = zone.Network.Update(tx)
+	if err != nil {
+		return errors.New("AddFareZone: " + err.Error())
+	}
+
+	_, err = sdb.Insert("fare_zone").
+		Columns("id", "name", "network").
+		Values(zone.ID, zone.Name, zone.Network.ID).
+		Suffix("ON CONFLICT (id) DO UPDATE SET name = ?, network = ?",
+			zone.Name, zone.Network.ID).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddFareZone: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the zone
+func (zone *FareZone) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_has_fare_zone").
+		Where(sq.Eq{"zone_id": zone.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveFareZone: %s", err)
+	}
+	_, err = sdb.Delete("fare_product_has_zone").
+		Where(sq.Eq{"zone_id": zone.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveFareZone: %s", err)
+	}
+	_, err = sdb.Delete("fare_zone").
+		Where(sq.Eq{"id": zone.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveFareZone: %s", err)
+	}
+	return tx.Commit()
+}
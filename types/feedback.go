@@ -17,6 +17,11 @@ type Feedback struct {
 	Time      time.Time
 	Type      FeedbackType
 	Contents  string
+	Status    FeedbackStatus
+	// Assignee is the name of the admin handling this feedback, empty if unassigned
+	Assignee string
+	// DiscordMessageID is the ID of the message used to triage this feedback on Discord, if any
+	DiscordMessageID string
 }
 
 // FeedbackType corresponds to a type of feedback
@@ -25,13 +30,56 @@ type FeedbackType string
 const (
 	// S2LSincorrectDetection is a type of feedback reserved for incorrect detection of stations by the client
 	S2LSincorrectDetection FeedbackType = "s2ls-incorrect-detection"
+	// WrongStationData is a type of feedback about incorrect station information (exits, schedules, services...)
+	WrongStationData FeedbackType = "wrong-station-data"
+	// BugReport is a type of feedback about problems with the client or the service
+	BugReport FeedbackType = "bug"
+	// Suggestion is a type of feedback containing suggestions for improvements
+	Suggestion FeedbackType = "suggestion"
+	// DisturbanceCorrection is a type of feedback about incorrect or missing disturbance information
+	DisturbanceCorrection FeedbackType = "disturbance-correction"
 )
 
+// FeedbackTypes contains all the types of feedback clients can submit
+var FeedbackTypes = []FeedbackType{S2LSincorrectDetection, WrongStationData, BugReport, Suggestion, DisturbanceCorrection}
+
+// FeedbackStatus corresponds to the state of a feedback in the triage workflow
+type FeedbackStatus string
+
+const (
+	// FeedbackNew is the status of feedback nobody looked at yet
+	FeedbackNew FeedbackStatus = "new"
+	// FeedbackTriaged is the status of feedback that was reviewed and needs to be acted upon
+	FeedbackTriaged FeedbackStatus = "triaged"
+	// FeedbackResolved is the status of feedback that needs no further action
+	FeedbackResolved FeedbackStatus = "resolved"
+)
+
+// FeedbackStatuses contains all the feedback statuses, in workflow order
+var FeedbackStatuses = []FeedbackStatus{FeedbackNew, FeedbackTriaged, FeedbackResolved}
+
 // GetFeedbacks returns a slice with all registered feedback
 func GetFeedbacks(node sqalx.Node) ([]*Feedback, error) {
 	return getFeedbacksWithSelect(node, sdb.Select())
 }
 
+// GetFeedbacksWithStatus returns a slice with the feedback with the specified status, newest first
+func GetFeedbacksWithStatus(node sqalx.Node, status FeedbackStatus) ([]*Feedback, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"status": status}).
+		OrderBy("timestamp DESC")
+	return getFeedbacksWithSelect(node, s)
+}
+
+// GetUnresolvedFeedbacksWithType returns a slice with the feedback of the specified type that is yet to be resolved, oldest first
+func GetUnresolvedFeedbacksWithType(node sqalx.Node, feedbackType FeedbackType) ([]*Feedback, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"type": feedbackType}).
+		Where(sq.NotEq{"status": FeedbackResolved}).
+		OrderBy("timestamp ASC")
+	return getFeedbacksWithSelect(node, s)
+}
+
 func getFeedbacksWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Feedback, error) {
 	feedbacks := []*Feedback{}
 
@@ -41,7 +89,8 @@ func getFeedbacksWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Feed
 	}
 	defer tx.Commit() // read-only tx
 
-	rows, err := sbuilder.Columns("id", "submitter", "timestamp", "type", "contents").
+	rows, err := sbuilder.Columns("id", "submitter", "timestamp", "type", "contents",
+		"status", "assignee", "discord_message_id").
 		From("feedback").
 		RunWith(tx).Query()
 	if err != nil {
@@ -58,7 +107,10 @@ func getFeedbacksWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Feed
 			&submitter,
 			&feedback.Time,
 			&feedback.Type,
-			&feedback.Contents)
+			&feedback.Contents,
+			&feedback.Status,
+			&feedback.Assignee,
+			&feedback.DiscordMessageID)
 		if err != nil {
 			return feedbacks, fmt.Errorf("getFeedbacksWithSelect: %s", err)
 		}
@@ -91,6 +143,20 @@ func GetFeedback(node sqalx.Node, id string) (*Feedback, error) {
 	return feedbacks[0], nil
 }
 
+// GetFeedbackForDiscordMessage returns the Feedback that is being triaged using the Discord message with the given ID
+func GetFeedbackForDiscordMessage(node sqalx.Node, messageID string) (*Feedback, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"discord_message_id": messageID})
+	feedbacks, err := getFeedbacksWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(feedbacks) == 0 {
+		return nil, errors.New("Feedback not found")
+	}
+	return feedbacks[0], nil
+}
+
 // Update adds or updates the feedback
 func (feedback *Feedback) Update(node sqalx.Node) error {
 	tx, err := node.Beginx()
@@ -100,10 +166,12 @@ func (feedback *Feedback) Update(node sqalx.Node) error {
 	defer tx.Rollback()
 
 	_, err = sdb.Insert("feedback").
-		Columns("id", "submitter", "timestamp", "type", "contents").
-		Values(feedback.ID, feedback.Submitter.Key, feedback.Time, feedback.Type, feedback.Contents).
-		Suffix("ON CONFLICT (id) DO UPDATE SET submitter = ?, timestamp = ?, type = ?, contents = ?",
-			feedback.Submitter.Key, feedback.Time, feedback.Type, feedback.Contents).
+		Columns("id", "submitter", "timestamp", "type", "contents", "status", "assignee", "discord_message_id").
+		Values(feedback.ID, feedback.Submitter.Key, feedback.Time, feedback.Type, feedback.Contents,
+			feedback.Status, feedback.Assignee, feedback.DiscordMessageID).
+		Suffix("ON CONFLICT (id) DO UPDATE SET submitter = ?, timestamp = ?, type = ?, contents = ?, status = ?, assignee = ?, discord_message_id = ?",
+			feedback.Submitter.Key, feedback.Time, feedback.Type, feedback.Contents,
+			feedback.Status, feedback.Assignee, feedback.DiscordMessageID).
 		RunWith(tx).Exec()
 
 	if err != nil {
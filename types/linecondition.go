@@ -38,6 +38,14 @@ func GetLineCondition(node sqalx.Node, id string) (*LineCondition, error) {
 	return conditions[0], nil
 }
 
+// GetLineConditionsAfter returns up to limit line conditions registered after the specified time, in chronological order
+func GetLineConditionsAfter(node sqalx.Node, after time.Time, limit uint64) ([]*LineCondition, error) {
+	s := sdb.Select().
+		Where(sq.Gt{"timestamp": after}).
+		Limit(limit)
+	return getLineConditionsWithSelect(node, s)
+}
+
 func getLineConditionsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*LineCondition, error) {
 	conditions := []*LineCondition{}
 
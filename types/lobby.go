@@ -3,6 +3,7 @@ package types
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gbl08ma/sqalx"
 	sq "github.com/Masterminds/squirrel"
@@ -96,6 +97,13 @@ func (lobby *Lobby) Exits(node sqalx.Node) ([]*Exit, error) {
 	return getExitsWithSelect(node, s)
 }
 
+// Paths returns the paths that begin in this lobby
+func (lobby *Lobby) Paths(node sqalx.Node) ([]*StationPath, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"station_path.lobby_id": lobby.ID})
+	return getStationPathsWithSelect(node, s)
+}
+
 // Schedules returns the schedules of this lobby
 func (lobby *Lobby) Schedules(node sqalx.Node) ([]*LobbySchedule, error) {
 	s := sdb.Select().
@@ -123,6 +131,68 @@ func (lobby *Lobby) Closed(node sqalx.Node) (bool, error) {
 	return true, nil
 }
 
+// ClosedAt returns whether this lobby is closed at the specified time, according to its schedule
+func (lobby *Lobby) ClosedAt(node sqalx.Node, at time.Time) (bool, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Commit() // read-only tx
+
+	schedules, err := lobby.Schedules(tx)
+	if err != nil {
+		return false, err
+	}
+
+	location, err := time.LoadLocation(lobby.Station.Network.Timezone)
+	if err != nil {
+		return false, err
+	}
+	at = at.In(location)
+
+	// the schedule of the previous day may extend past midnight
+	for _, day := range []time.Time{at.AddDate(0, 0, -1), at} {
+		schedule := lobby.getScheduleForDay(day, schedules)
+		if schedule == nil || !schedule.Open {
+			continue
+		}
+		openTime := time.Time(schedule.OpenTime)
+		openTime = time.Date(day.Year(), day.Month(), day.Day(), openTime.Hour(), openTime.Minute(), openTime.Second(), openTime.Nanosecond(), day.Location())
+		if !at.Before(openTime) && at.Before(openTime.Add(time.Duration(schedule.OpenDuration))) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (lobby *Lobby) getScheduleForDay(day time.Time, schedules []*LobbySchedule) *LobbySchedule {
+	// look for specific day overrides (holiday == true, day != 0)
+	for _, schedule := range schedules {
+		if schedule.Holiday && schedule.Day == day.YearDay() {
+			return schedule
+		}
+	}
+
+	// check if this is a holiday
+	for _, holiday := range lobby.Station.Network.Holidays {
+		if int(holiday) == day.YearDay() {
+			for _, schedule := range schedules {
+				if schedule.Holiday && schedule.Day == 0 {
+					return schedule
+				}
+			}
+			break
+		}
+	}
+
+	for _, schedule := range schedules {
+		if !schedule.Holiday && schedule.Day == int(day.Weekday()) {
+			return schedule
+		}
+	}
+	return nil
+}
+
 // Update adds or updates the Lobby
 func (lobby *Lobby) Update(node sqalx.Node) error {
 	tx, err := node.Beginx()
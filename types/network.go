@@ -149,6 +149,20 @@ func (network *Network) Lines(node sqalx.Node) ([]*Line, error) {
 	return getLinesWithSelect(node, s)
 }
 
+// FareZones returns the fare zones in this network
+func (network *Network) FareZones(node sqalx.Node) ([]*FareZone, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"fare_zone.network": network.ID})
+	return getFareZonesWithSelect(node, s)
+}
+
+// FareProducts returns the fare products that can be used in this network
+func (network *Network) FareProducts(node sqalx.Node) ([]*FareProduct, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"fare_product.network": network.ID})
+	return getFareProductsWithSelect(node, s)
+}
+
 // Stations returns the stations in this network
 func (network *Network) Stations(node sqalx.Node) ([]*Station, error) {
 	s := sdb.Select().
@@ -0,0 +1,227 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/lib/pq"
+	"github.com/satori/go.uuid"
+)
+
+// PairChallenge is issued to clients that can't attest their authenticity (like web clients) when they want an APIPair.
+// The challenge must be approved by a PosPlay player before the client can redeem it for a pair
+type PairChallenge struct {
+	ID             string
+	ClientType     string
+	Created        time.Time
+	Expires        time.Time
+	ApprovedBy     uint64
+	ApprovalTime   time.Time
+	RedemptionTime time.Time
+}
+
+// getPairChallengesWithSelect returns a slice with all PairChallenges that match the conditions in sbuilder
+func getPairChallengesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*PairChallenge, error) {
+	challenges := []*PairChallenge{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return challenges, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("id", "client_type", "created", "expires",
+		"approved_by", "approval_time", "redemption_time").
+		From("pair_challenge").
+		RunWith(tx).Query()
+	if err != nil {
+		return challenges, fmt.Errorf("getPairChallengesWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var challenge PairChallenge
+		var approvedBy *uint64
+		var approvalTime, redemptionTime pq.NullTime
+		err := rows.Scan(
+			&challenge.ID,
+			&challenge.ClientType,
+			&challenge.Created,
+			&challenge.Expires,
+			&approvedBy,
+			&approvalTime,
+			&redemptionTime)
+		if err != nil {
+			return challenges, fmt.Errorf("getPairChallengesWithSelect: %s", err)
+		}
+		if approvedBy != nil {
+			challenge.ApprovedBy = *approvedBy
+		}
+		if approvalTime.Valid {
+			challenge.ApprovalTime = approvalTime.Time
+		}
+		if redemptionTime.Valid {
+			challenge.RedemptionTime = redemptionTime.Time
+		}
+		challenges = append(challenges, &challenge)
+	}
+	if err := rows.Err(); err != nil {
+		return challenges, fmt.Errorf("getPairChallengesWithSelect: %s", err)
+	}
+	return challenges, nil
+}
+
+// GetPairChallenge returns the PairChallenge with the given ID
+func GetPairChallenge(node sqalx.Node, id string) (*PairChallenge, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"id": id})
+	challenges, err := getPairChallengesWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(challenges) == 0 {
+		return nil, errors.New("PairChallenge not found")
+	}
+	return challenges[0], nil
+}
+
+// CountPairChallengesRedeemedBy returns the number of challenges approved by the specified player
+// that were redeemed since the specified time
+func CountPairChallengesRedeemedBy(node sqalx.Node, discordID uint64, since time.Time) (int, error) {
+	return countWithSelect(node, "pair_challenge", sq.And{
+		sq.Eq{"approved_by": discordID},
+		sq.GtOrEq{"redemption_time": since},
+	})
+}
+
+// NewPairChallenge creates a new PairChallenge for the specified client type, valid for the specified duration, and returns it
+// Does NOT store the challenge in the DB
+func NewPairChallenge(clientType string, longevity time.Duration) (*PairChallenge, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return &PairChallenge{
+		ID:         id.String(),
+		ClientType: clientType,
+		Created:    now,
+		Expires:    now.Add(longevity),
+	}, nil
+}
+
+// Store stores this challenge in the DB
+func (challenge *PairChallenge) Store(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return errors.New("Store: " + err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("pair_challenge").
+		Columns("id", "client_type", "created", "expires").
+		Values(challenge.ID, challenge.ClientType, challenge.Created, challenge.Expires).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("Store: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Expired returns whether this challenge can no longer be approved or redeemed
+func (challenge *PairChallenge) Expired() bool {
+	return time.Now().After(challenge.Expires)
+}
+
+// Approved returns whether this challenge was approved by a player
+func (challenge *PairChallenge) Approved() bool {
+	return !challenge.ApprovalTime.IsZero()
+}
+
+// Redeemed returns whether this challenge was already exchanged for an APIPair
+func (challenge *PairChallenge) Redeemed() bool {
+	return !challenge.RedemptionTime.IsZero()
+}
+
+// Approve marks this challenge as approved by the specified player.
+// Only challenges that were not yet approved can be approved
+func (challenge *PairChallenge) Approve(node sqalx.Node, discordID uint64) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := sdb.Update("pair_challenge").
+		Set("approved_by", discordID).
+		Set("approval_time", now).
+		Where(sq.Eq{"id": challenge.ID}).
+		Where(sq.Eq{"approval_time": nil}).
+		Where(sq.Gt{"expires": now}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("Approve: %s", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Approve: %s", err)
+	}
+	if affected == 0 {
+		return errors.New("Approve: challenge already approved or expired")
+	}
+	challenge.ApprovedBy = discordID
+	challenge.ApprovalTime = now
+	return tx.Commit()
+}
+
+// Redeem marks this challenge as exchanged for an APIPair.
+// Fails if the challenge was not approved, has expired or was already redeemed, so that each challenge is only redeemed once
+func (challenge *PairChallenge) Redeem(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	result, err := sdb.Update("pair_challenge").
+		Set("redemption_time", now).
+		Where(sq.Eq{"id": challenge.ID}).
+		Where(sq.NotEq{"approval_time": nil}).
+		Where(sq.Eq{"redemption_time": nil}).
+		Where(sq.Gt{"expires": now}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("Redeem: %s", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Redeem: %s", err)
+	}
+	if affected == 0 {
+		return errors.New("Redeem: challenge not approved, expired or already redeemed")
+	}
+	challenge.RedemptionTime = now
+	return tx.Commit()
+}
+
+// DeletePairChallengesExpiredBefore deletes the challenges that expired before the specified time
+func DeletePairChallengesExpiredBefore(node sqalx.Node, before time.Time) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("pair_challenge").
+		Where(sq.Lt{"expires": before}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("DeletePairChallengesExpiredBefore: %s", err)
+	}
+	return tx.Commit()
+}
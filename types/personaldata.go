@@ -0,0 +1,527 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/satori/go.uuid"
+)
+
+// AnonymisedPairKey is the key of the APIPair that owns the trips of users who asked for their data to be erased,
+// but whose trips were kept for statistical purposes. It can't authenticate, as its key uses characters
+// that GenerateAPIKey never outputs and it is never activated
+const AnonymisedPairKey = "_anonymised"
+
+// PersonalDataArchive contains all the personal data linked to an APIPair and/or a PosPlay player
+type PersonalDataArchive struct {
+	Generated time.Time                   `json:"generated"`
+	Pair      *PersonalDataPair           `json:"pair,omitempty"`
+	Trips     []*PersonalDataTrip         `json:"trips"`
+	Feedback  []*PersonalDataFeedback     `json:"feedback"`
+	PosPlay   *PersonalDataPosPlayArchive `json:"posplay,omitempty"`
+}
+
+// PersonalDataPair contains the non-secret information of an APIPair
+type PersonalDataPair struct {
+	Key        string    `json:"key"`
+	Type       string    `json:"type"`
+	Activation time.Time `json:"activation"`
+}
+
+// PersonalDataTrip is a Trip, as included in a PersonalDataArchive
+type PersonalDataTrip struct {
+	ID            string                    `json:"id"`
+	StartTime     time.Time                 `json:"startTime"`
+	EndTime       time.Time                 `json:"endTime"`
+	SubmitTime    time.Time                 `json:"submitTime"`
+	EditTime      *time.Time                `json:"editTime,omitempty"`
+	UserConfirmed bool                      `json:"userConfirmed"`
+	Provisional   bool                      `json:"provisional"`
+	StationUses   []*PersonalDataStationUse `json:"uses"`
+}
+
+// PersonalDataStationUse is a StationUse, as included in a PersonalDataArchive
+type PersonalDataStationUse struct {
+	Station    string    `json:"station"`
+	EntryTime  time.Time `json:"entryTime"`
+	LeaveTime  time.Time `json:"leaveTime"`
+	Type       string    `json:"type,omitempty"`
+	Manual     bool      `json:"manual"`
+	Inferred   bool      `json:"inferred"`
+	SourceLine string    `json:"sourceLine,omitempty"`
+	TargetLine string    `json:"targetLine,omitempty"`
+}
+
+// PersonalDataFeedback is a Feedback, as included in a PersonalDataArchive
+type PersonalDataFeedback struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`
+	Contents string    `json:"contents"`
+}
+
+// PersonalDataPosPlayArchive contains the PosPlay data of a player
+type PersonalDataPosPlayArchive struct {
+	DiscordID            uint64                             `json:"discordID,string"`
+	Joined               time.Time                          `json:"joined"`
+	LBPrivacy            string                             `json:"leaderboardPrivacy"`
+	ProfilePrivacy       string                             `json:"profilePrivacy"`
+	NameType             string                             `json:"nameType"`
+	InGuild              bool                               `json:"inGuild"`
+	CachedName           string                             `json:"cachedName"`
+	PairKey              string                             `json:"pairKey,omitempty"`
+	PairedDeviceName     string                             `json:"pairedDeviceName,omitempty"`
+	Paired               *time.Time                         `json:"paired,omitempty"`
+	XPTransactions       []*PersonalDataXPTransaction       `json:"xpTransactions"`
+	Achievements         []*PersonalDataAchievement         `json:"achievements"`
+	NotificationSettings []*PersonalDataNotificationSetting `json:"notificationSettings"`
+}
+
+// PersonalDataXPTransaction is a PPXPTransaction, as included in a PersonalDataArchive
+type PersonalDataXPTransaction struct {
+	ID    string    `json:"id"`
+	Time  time.Time `json:"time"`
+	Value int       `json:"value"`
+	Type  string    `json:"type"`
+	Extra string    `json:"extra"`
+}
+
+// PersonalDataAchievement is a PPPlayerAchievement, as included in a PersonalDataArchive
+type PersonalDataAchievement struct {
+	ID           string     `json:"id"`
+	Achieved     bool       `json:"achieved"`
+	AchievedTime *time.Time `json:"achievedTime,omitempty"`
+	Extra        string     `json:"extra"`
+}
+
+// PersonalDataNotificationSetting is a PPNotificationSetting, as included in a PersonalDataArchive
+type PersonalDataNotificationSetting struct {
+	Type    string `json:"type"`
+	Method  string `json:"method"`
+	Enabled bool   `json:"enabled"`
+}
+
+// PersonalDataOperation is an operation over personal data that is recorded for auditing purposes
+type PersonalDataOperation string
+
+const (
+	// PersonalDataExport is used when the personal data of a user is exported
+	PersonalDataExport PersonalDataOperation = "EXPORT"
+	// PersonalDataErasure is used when the personal data of a user is deleted, with trips being deleted too
+	PersonalDataErasure PersonalDataOperation = "ERASURE"
+	// PersonalDataAnonymisation is used when the personal data of a user is deleted, with trips being anonymised
+	PersonalDataAnonymisation PersonalDataOperation = "ANONYMISATION"
+)
+
+// PersonalDataAuditEntry records an operation over the personal data of a user
+type PersonalDataAuditEntry struct {
+	ID        string
+	Time      time.Time
+	Operation PersonalDataOperation
+	// PairKey is empty if the operation did not involve an APIPair
+	PairKey string
+	// DiscordID is zero if the operation did not involve a PosPlay player
+	DiscordID uint64
+	// Origin identifies where the operation was requested (e.g. "api", "posplay")
+	Origin string
+	// Details contains a human-readable summary of what was affected
+	Details string
+}
+
+// GetPersonalDataAuditEntries returns a slice with all the personal data audit entries
+func GetPersonalDataAuditEntries(node sqalx.Node) ([]*PersonalDataAuditEntry, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sdb.Select("id", "time", "operation", "pair_key", "discord_id", "origin", "details").
+		From("personal_data_audit").
+		OrderBy("time DESC").
+		RunWith(tx).Query()
+	if err != nil {
+		return nil, fmt.Errorf("GetPersonalDataAuditEntries: %s", err)
+	}
+	defer rows.Close()
+
+	entries := []*PersonalDataAuditEntry{}
+	for rows.Next() {
+		var entry PersonalDataAuditEntry
+		err := rows.Scan(&entry.ID, &entry.Time, &entry.Operation, &entry.PairKey, &entry.DiscordID, &entry.Origin, &entry.Details)
+		if err != nil {
+			return entries, fmt.Errorf("GetPersonalDataAuditEntries: %s", err)
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return entries, fmt.Errorf("GetPersonalDataAuditEntries: %s", err)
+	}
+	return entries, nil
+}
+
+// LogPersonalDataOperation stores a new personal data audit entry
+func LogPersonalDataOperation(node sqalx.Node, operation PersonalDataOperation, pairKey string, discordID uint64, origin, details string) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return errors.New("LogPersonalDataOperation: " + err.Error())
+	}
+
+	_, err = sdb.Insert("personal_data_audit").
+		Columns("id", "time", "operation", "pair_key", "discord_id", "origin", "details").
+		Values(id.String(), time.Now(), operation, pairKey, discordID, origin, details).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("LogPersonalDataOperation: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// ExportPersonalData returns an archive with the personal data linked to the specified APIPair
+// and/or PosPlay player. Either may be nil. The data of the player linked to the pair (and vice-versa)
+// is not automatically included: callers should resolve the link with GetPPPairForKey/GetPPPair
+func ExportPersonalData(node sqalx.Node, pair *APIPair, player *PPPlayer) (*PersonalDataArchive, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	archive := &PersonalDataArchive{
+		Generated: time.Now(),
+		Trips:     []*PersonalDataTrip{},
+		Feedback:  []*PersonalDataFeedback{},
+	}
+
+	if pair != nil {
+		archive.Pair = &PersonalDataPair{
+			Key:        pair.Key,
+			Type:       pair.Type,
+			Activation: pair.Activation,
+		}
+
+		tripIDs, err := getTripIDsWithSelect(tx, sdb.Select().Where(sq.Eq{"submitter": pair.Key}))
+		if err != nil {
+			return nil, err
+		}
+		// instantiate each trip from DB individually to reduce memory usage
+		for _, tripID := range tripIDs {
+			trip, err := GetTrip(tx, tripID)
+			if err != nil {
+				return nil, err
+			}
+			archive.Trips = append(archive.Trips, personalDataTrip(trip))
+		}
+
+		provisionalTrips, err := GetProvisionalTripsForSubmitter(tx, pair)
+		if err != nil {
+			return nil, err
+		}
+		for _, provisionalTrip := range provisionalTrips {
+			t := &PersonalDataTrip{
+				ID:          provisionalTrip.ID,
+				StartTime:   provisionalTrip.StartTime,
+				EndTime:     provisionalTrip.LastReportTime,
+				Provisional: true,
+				StationUses: []*PersonalDataStationUse{},
+			}
+			for _, report := range provisionalTrip.Reports {
+				t.StationUses = append(t.StationUses, &PersonalDataStationUse{
+					Station:   report.Station.ID,
+					EntryTime: report.Time,
+					LeaveTime: report.Time,
+				})
+			}
+			archive.Trips = append(archive.Trips, t)
+		}
+
+		feedbacks, err := getFeedbacksWithSelect(tx, sdb.Select().Where(sq.Eq{"submitter": pair.Key}))
+		if err != nil {
+			return nil, err
+		}
+		for _, feedback := range feedbacks {
+			archive.Feedback = append(archive.Feedback, &PersonalDataFeedback{
+				ID:       feedback.ID,
+				Time:     feedback.Time,
+				Type:     string(feedback.Type),
+				Contents: feedback.Contents,
+			})
+		}
+	}
+
+	if player != nil {
+		archive.PosPlay, err = personalDataPosPlay(tx, player)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return archive, nil
+}
+
+func personalDataTrip(trip *Trip) *PersonalDataTrip {
+	t := &PersonalDataTrip{
+		ID:            trip.ID,
+		StartTime:     trip.StartTime,
+		EndTime:       trip.EndTime,
+		SubmitTime:    trip.SubmitTime,
+		UserConfirmed: trip.UserConfirmed,
+		StationUses:   []*PersonalDataStationUse{},
+	}
+	if trip.Edited {
+		editTime := trip.EditTime
+		t.EditTime = &editTime
+	}
+	for _, use := range trip.StationUses {
+		u := &PersonalDataStationUse{
+			Station:   use.Station.ID,
+			EntryTime: use.EntryTime,
+			LeaveTime: use.LeaveTime,
+			Type:      string(use.Type),
+			Manual:    use.Manual,
+			Inferred:  use.Inferred,
+		}
+		if use.SourceLine != nil {
+			u.SourceLine = use.SourceLine.ID
+		}
+		if use.TargetLine != nil {
+			u.TargetLine = use.TargetLine.ID
+		}
+		t.StationUses = append(t.StationUses, u)
+	}
+	return t
+}
+
+func personalDataPosPlay(node sqalx.Node, player *PPPlayer) (*PersonalDataPosPlayArchive, error) {
+	archive := &PersonalDataPosPlayArchive{
+		DiscordID:            player.DiscordID,
+		Joined:               player.Joined,
+		LBPrivacy:            player.LBPrivacy,
+		ProfilePrivacy:       player.ProfilePrivacy,
+		NameType:             player.NameType,
+		InGuild:              player.InGuild,
+		CachedName:           player.CachedName,
+		XPTransactions:       []*PersonalDataXPTransaction{},
+		Achievements:         []*PersonalDataAchievement{},
+		NotificationSettings: []*PersonalDataNotificationSetting{},
+	}
+
+	ppPair, err := GetPPPair(node, player.DiscordID)
+	if err == nil {
+		archive.PairKey = ppPair.Pair.Key
+		archive.PairedDeviceName = ppPair.DeviceName
+		paired := ppPair.Paired
+		archive.Paired = &paired
+	}
+
+	transactions, err := player.XPTransactions(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		archive.XPTransactions = append(archive.XPTransactions, &PersonalDataXPTransaction{
+			ID:    transaction.ID,
+			Time:  transaction.Time,
+			Value: transaction.Value,
+			Type:  transaction.Type,
+			Extra: transaction.Extra,
+		})
+	}
+
+	achievements, err := player.Achievements(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, achievement := range achievements {
+		a := &PersonalDataAchievement{
+			ID:       achievement.Achievement.ID,
+			Achieved: achievement.Achieved,
+			Extra:    achievement.Extra,
+		}
+		if achievement.Achieved {
+			achievedTime := achievement.AchievedTime
+			a.AchievedTime = &achievedTime
+		}
+		archive.Achievements = append(archive.Achievements, a)
+	}
+
+	settings, err := getPPNotificationSettingsWithSelect(node, sdb.Select().Where(sq.Eq{"discord_id": player.DiscordID}))
+	if err != nil {
+		return nil, err
+	}
+	for _, setting := range settings {
+		archive.NotificationSettings = append(archive.NotificationSettings, &PersonalDataNotificationSetting{
+			Type:    setting.NotificationType,
+			Method:  setting.Method,
+			Enabled: setting.Enabled,
+		})
+	}
+	return archive, nil
+}
+
+// getAnonymisedPair returns the pair that owns anonymised trips, creating it if needed
+func getAnonymisedPair(node sqalx.Node) (*APIPair, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	pair, err := GetPair(tx, AnonymisedPairKey)
+	if err == nil {
+		return pair, tx.Commit()
+	}
+
+	// the secret hash is not a valid hash of anything, and the pair is never activated
+	_, err = sdb.Insert("api_pair").
+		Columns("key", "secret", "type", "activation").
+		Values(AnonymisedPairKey, "-", "anonymised", time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)).
+		RunWith(tx).Exec()
+	if err != nil {
+		return nil, errors.New("getAnonymisedPair: " + err.Error())
+	}
+
+	pair, err = GetPair(tx, AnonymisedPairKey)
+	if err != nil {
+		return nil, err
+	}
+	return pair, tx.Commit()
+}
+
+// ErasePairPersonalData deletes the APIPair and all the data linked to it.
+// If keepTrips is true, the trips of the pair are transferred to a shared anonymous pair instead of being deleted,
+// so that they can keep contributing to statistics without being linkable to each other or to the user.
+// Otherwise, trips are deleted along with their typical seconds samples.
+// The PosPlay pair linking to this APIPair, if any, is deleted too. It returns the number of affected trips
+func ErasePairPersonalData(node sqalx.Node, pair *APIPair, keepTrips bool) (int, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	tripIDs, err := getTripIDsWithSelect(tx, sdb.Select().Where(sq.Eq{"submitter": pair.Key}))
+	if err != nil {
+		return 0, errors.New("ErasePairPersonalData: " + err.Error())
+	}
+
+	if keepTrips {
+		anonPair, err := getAnonymisedPair(tx)
+		if err != nil {
+			return 0, errors.New("ErasePairPersonalData: " + err.Error())
+		}
+		_, err = sdb.Update("trip").
+			Set("submitter", anonPair.Key).
+			Where(sq.Eq{"submitter": pair.Key}).
+			RunWith(tx).Exec()
+		if err != nil {
+			return 0, errors.New("ErasePairPersonalData: " + err.Error())
+		}
+	} else {
+		for _, tripID := range tripIDs {
+			trip, err := GetTrip(tx, tripID)
+			if err != nil {
+				return 0, errors.New("ErasePairPersonalData: " + err.Error())
+			}
+			err = trip.Delete(tx)
+			if err != nil {
+				return 0, errors.New("ErasePairPersonalData: " + err.Error())
+			}
+		}
+	}
+
+	provisionalTrips, err := GetProvisionalTripsForSubmitter(tx, pair)
+	if err != nil {
+		return 0, errors.New("ErasePairPersonalData: " + err.Error())
+	}
+	for _, provisionalTrip := range provisionalTrips {
+		err = provisionalTrip.Delete(tx)
+		if err != nil {
+			return 0, errors.New("ErasePairPersonalData: " + err.Error())
+		}
+	}
+
+	_, err = sdb.Delete("feedback").
+		Where(sq.Eq{"submitter": pair.Key}).RunWith(tx).Exec()
+	if err != nil {
+		return 0, errors.New("ErasePairPersonalData: " + err.Error())
+	}
+
+	_, err = sdb.Delete("wifiap_observation").
+		Where(sq.Eq{"submitter": pair.Key}).RunWith(tx).Exec()
+	if err != nil {
+		return 0, errors.New("ErasePairPersonalData: " + err.Error())
+	}
+
+	ppPair, err := GetPPPairForKey(tx, pair.Key)
+	if err == nil {
+		err = ppPair.Delete(tx)
+		if err != nil {
+			return 0, errors.New("ErasePairPersonalData: " + err.Error())
+		}
+	}
+
+	err = pair.Delete(tx)
+	if err != nil {
+		return 0, errors.New("ErasePairPersonalData: " + err.Error())
+	}
+	return len(tripIDs), tx.Commit()
+}
+
+// ErasePPPlayerPersonalData deletes the PosPlay player and all the PosPlay data linked to it,
+// including its XP transactions, achievements, notification settings and PosPlay pair.
+// The APIPair the player was paired with is not affected
+func ErasePPPlayerPersonalData(node sqalx.Node, player *PPPlayer) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ppPair, err := GetPPPair(tx, player.DiscordID)
+	if err == nil {
+		err = ppPair.Delete(tx)
+		if err != nil {
+			return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+		}
+	}
+
+	transactions, err := player.XPTransactions(tx)
+	if err != nil {
+		return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+	}
+	for _, transaction := range transactions {
+		err = transaction.Delete(tx)
+		if err != nil {
+			return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+		}
+	}
+
+	for _, table := range []string{"pp_player_has_achievement", "pp_notification_setting"} {
+		_, err = sdb.Delete(table).
+			Where(sq.Eq{"discord_id": player.DiscordID}).RunWith(tx).Exec()
+		if err != nil {
+			return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+		}
+	}
+
+	_, err = sdb.Delete("pair_challenge").
+		Where(sq.Eq{"approved_by": player.DiscordID}).RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+	}
+
+	err = player.Delete(tx)
+	if err != nil {
+		return errors.New("ErasePPPlayerPersonalData: " + err.Error())
+	}
+	return tx.Commit()
+}
@@ -5,171 +5,300 @@ package types
 import "reflect"
 
 var Types = map[string]reflect.Type{
-	"APIPair":               reflect.TypeOf((*APIPair)(nil)).Elem(),
-	"AndroidPairRequest":    reflect.TypeOf((*AndroidPairRequest)(nil)).Elem(),
-	"Announcement":          reflect.TypeOf((*Announcement)(nil)).Elem(),
-	"AnnouncementStore":     reflect.TypeOf((*AnnouncementStore)(nil)).Elem(),
-	"BaseReport":            reflect.TypeOf((*BaseReport)(nil)).Elem(),
-	"Connection":            reflect.TypeOf((*Connection)(nil)).Elem(),
-	"Dataset":               reflect.TypeOf((*Dataset)(nil)).Elem(),
-	"Disturbance":           reflect.TypeOf((*Disturbance)(nil)).Elem(),
-	"DisturbanceCategory":   reflect.TypeOf((*DisturbanceCategory)(nil)).Elem(),
-	"Duration":              reflect.TypeOf((*Duration)(nil)).Elem(),
-	"Exit":                  reflect.TypeOf((*Exit)(nil)).Elem(),
-	"Feedback":              reflect.TypeOf((*Feedback)(nil)).Elem(),
-	"FeedbackType":          reflect.TypeOf((*FeedbackType)(nil)).Elem(),
-	"Line":                  reflect.TypeOf((*Line)(nil)).Elem(),
-	"LineCondition":         reflect.TypeOf((*LineCondition)(nil)).Elem(),
-	"LineDisturbanceReport": reflect.TypeOf((*LineDisturbanceReport)(nil)).Elem(),
-	"LinePath":              reflect.TypeOf((*LinePath)(nil)).Elem(),
-	"LineSchedule":          reflect.TypeOf((*LineSchedule)(nil)).Elem(),
-	"Lobby":                 reflect.TypeOf((*Lobby)(nil)).Elem(),
-	"LobbySchedule":         reflect.TypeOf((*LobbySchedule)(nil)).Elem(),
-	"Network":               reflect.TypeOf((*Network)(nil)).Elem(),
-	"NetworkSchedule":       reflect.TypeOf((*NetworkSchedule)(nil)).Elem(),
-	"POI":                   reflect.TypeOf((*POI)(nil)).Elem(),
-	"PPAchievement":         reflect.TypeOf((*PPAchievement)(nil)).Elem(),
-	"PPAchievementContext":  reflect.TypeOf((*PPAchievementContext)(nil)).Elem(),
-	"PPAchievementStrategy": reflect.TypeOf((*PPAchievementStrategy)(nil)).Elem(),
-	"PPLeaderboardEntry":    reflect.TypeOf((*PPLeaderboardEntry)(nil)).Elem(),
-	"PPNotificationSetting": reflect.TypeOf((*PPNotificationSetting)(nil)).Elem(),
-	"PPPair":                reflect.TypeOf((*PPPair)(nil)).Elem(),
-	"PPPlayer":              reflect.TypeOf((*PPPlayer)(nil)).Elem(),
-	"PPPlayerAchievement":   reflect.TypeOf((*PPPlayerAchievement)(nil)).Elem(),
-	"PPXPTransaction":       reflect.TypeOf((*PPXPTransaction)(nil)).Elem(),
-	"PairConnection":        reflect.TypeOf((*PairConnection)(nil)).Elem(),
-	"Point":                 reflect.TypeOf((*Point)(nil)).Elem(),
-	"Report":                reflect.TypeOf((*Report)(nil)).Elem(),
-	"Script":                reflect.TypeOf((*Script)(nil)).Elem(),
-	"Source":                reflect.TypeOf((*Source)(nil)).Elem(),
-	"Station":               reflect.TypeOf((*Station)(nil)).Elem(),
-	"StationTags":           reflect.TypeOf((*StationTags)(nil)).Elem(),
-	"StationUse":            reflect.TypeOf((*StationUse)(nil)).Elem(),
-	"StationUseType":        reflect.TypeOf((*StationUseType)(nil)).Elem(),
-	"Status":                reflect.TypeOf((*Status)(nil)).Elem(),
-	"StatusMessageType":     reflect.TypeOf((*StatusMessageType)(nil)).Elem(),
-	"StatusNotification":    reflect.TypeOf((*StatusNotification)(nil)).Elem(),
-	"Time":                  reflect.TypeOf((*Time)(nil)).Elem(),
-	"Transfer":              reflect.TypeOf((*Transfer)(nil)).Elem(),
-	"Trip":                  reflect.TypeOf((*Trip)(nil)).Elem(),
-	"WiFiAP":                reflect.TypeOf((*WiFiAP)(nil)).Elem(),
+	"APIPair":                         reflect.TypeOf((*APIPair)(nil)).Elem(),
+	"AndroidPairRequest":              reflect.TypeOf((*AndroidPairRequest)(nil)).Elem(),
+	"Announcement":                    reflect.TypeOf((*Announcement)(nil)).Elem(),
+	"AnnouncementStore":               reflect.TypeOf((*AnnouncementStore)(nil)).Elem(),
+	"BaseReport":                      reflect.TypeOf((*BaseReport)(nil)).Elem(),
+	"Connection":                      reflect.TypeOf((*Connection)(nil)).Elem(),
+	"ConnectionHourlyTimes":           reflect.TypeOf((*ConnectionHourlyTimes)(nil)).Elem(),
+	"Dataset":                         reflect.TypeOf((*Dataset)(nil)).Elem(),
+	"Disturbance":                     reflect.TypeOf((*Disturbance)(nil)).Elem(),
+	"DisturbanceCategory":             reflect.TypeOf((*DisturbanceCategory)(nil)).Elem(),
+	"DisturbanceFilter":               reflect.TypeOf((*DisturbanceFilter)(nil)).Elem(),
+	"Duration":                        reflect.TypeOf((*Duration)(nil)).Elem(),
+	"Exit":                            reflect.TypeOf((*Exit)(nil)).Elem(),
+	"FareProduct":                     reflect.TypeOf((*FareProduct)(nil)).Elem(),
+	"FareZone":                        reflect.TypeOf((*FareZone)(nil)).Elem(),
+	"Feedback":                        reflect.TypeOf((*Feedback)(nil)).Elem(),
+	"FeedbackStatus":                  reflect.TypeOf((*FeedbackStatus)(nil)).Elem(),
+	"FeedbackType":                    reflect.TypeOf((*FeedbackType)(nil)).Elem(),
+	"Line":                            reflect.TypeOf((*Line)(nil)).Elem(),
+	"LineCondition":                   reflect.TypeOf((*LineCondition)(nil)).Elem(),
+	"LineDisturbanceReport":           reflect.TypeOf((*LineDisturbanceReport)(nil)).Elem(),
+	"LinePath":                        reflect.TypeOf((*LinePath)(nil)).Elem(),
+	"LineSchedule":                    reflect.TypeOf((*LineSchedule)(nil)).Elem(),
+	"Lobby":                           reflect.TypeOf((*Lobby)(nil)).Elem(),
+	"LobbySchedule":                   reflect.TypeOf((*LobbySchedule)(nil)).Elem(),
+	"Network":                         reflect.TypeOf((*Network)(nil)).Elem(),
+	"NetworkSchedule":                 reflect.TypeOf((*NetworkSchedule)(nil)).Elem(),
+	"POI":                             reflect.TypeOf((*POI)(nil)).Elem(),
+	"PPAchievement":                   reflect.TypeOf((*PPAchievement)(nil)).Elem(),
+	"PPAchievementContext":            reflect.TypeOf((*PPAchievementContext)(nil)).Elem(),
+	"PPAchievementStrategy":           reflect.TypeOf((*PPAchievementStrategy)(nil)).Elem(),
+	"PPLeaderboardEntry":              reflect.TypeOf((*PPLeaderboardEntry)(nil)).Elem(),
+	"PPNotificationSetting":           reflect.TypeOf((*PPNotificationSetting)(nil)).Elem(),
+	"PPPair":                          reflect.TypeOf((*PPPair)(nil)).Elem(),
+	"PPPlayer":                        reflect.TypeOf((*PPPlayer)(nil)).Elem(),
+	"PPPlayerAchievement":             reflect.TypeOf((*PPPlayerAchievement)(nil)).Elem(),
+	"PPXPTransaction":                 reflect.TypeOf((*PPXPTransaction)(nil)).Elem(),
+	"PairChallenge":                   reflect.TypeOf((*PairChallenge)(nil)).Elem(),
+	"PairConnection":                  reflect.TypeOf((*PairConnection)(nil)).Elem(),
+	"PersonalDataAchievement":         reflect.TypeOf((*PersonalDataAchievement)(nil)).Elem(),
+	"PersonalDataArchive":             reflect.TypeOf((*PersonalDataArchive)(nil)).Elem(),
+	"PersonalDataAuditEntry":          reflect.TypeOf((*PersonalDataAuditEntry)(nil)).Elem(),
+	"PersonalDataFeedback":            reflect.TypeOf((*PersonalDataFeedback)(nil)).Elem(),
+	"PersonalDataNotificationSetting": reflect.TypeOf((*PersonalDataNotificationSetting)(nil)).Elem(),
+	"PersonalDataOperation":           reflect.TypeOf((*PersonalDataOperation)(nil)).Elem(),
+	"PersonalDataPair":                reflect.TypeOf((*PersonalDataPair)(nil)).Elem(),
+	"PersonalDataPosPlayArchive":      reflect.TypeOf((*PersonalDataPosPlayArchive)(nil)).Elem(),
+	"PersonalDataStationUse":          reflect.TypeOf((*PersonalDataStationUse)(nil)).Elem(),
+	"PersonalDataTrip":                reflect.TypeOf((*PersonalDataTrip)(nil)).Elem(),
+	"PersonalDataXPTransaction":       reflect.TypeOf((*PersonalDataXPTransaction)(nil)).Elem(),
+	"Platform":                        reflect.TypeOf((*Platform)(nil)).Elem(),
+	"Point":                           reflect.TypeOf((*Point)(nil)).Elem(),
+	"ProvisionalTrip":                 reflect.TypeOf((*ProvisionalTrip)(nil)).Elem(),
+	"RealtimeLocationReport":          reflect.TypeOf((*RealtimeLocationReport)(nil)).Elem(),
+	"Report":                          reflect.TypeOf((*Report)(nil)).Elem(),
+	"Script":                          reflect.TypeOf((*Script)(nil)).Elem(),
+	"Source":                          reflect.TypeOf((*Source)(nil)).Elem(),
+	"Station":                         reflect.TypeOf((*Station)(nil)).Elem(),
+	"StationPath":                     reflect.TypeOf((*StationPath)(nil)).Elem(),
+	"StationPathMeans":                reflect.TypeOf((*StationPathMeans)(nil)).Elem(),
+	"StationPathOutage":               reflect.TypeOf((*StationPathOutage)(nil)).Elem(),
+	"StationTags":                     reflect.TypeOf((*StationTags)(nil)).Elem(),
+	"StationUse":                      reflect.TypeOf((*StationUse)(nil)).Elem(),
+	"StationUseType":                  reflect.TypeOf((*StationUseType)(nil)).Elem(),
+	"Status":                          reflect.TypeOf((*Status)(nil)).Elem(),
+	"StatusMessageType":               reflect.TypeOf((*StatusMessageType)(nil)).Elem(),
+	"StatusNotification":              reflect.TypeOf((*StatusNotification)(nil)).Elem(),
+	"Time":                            reflect.TypeOf((*Time)(nil)).Elem(),
+	"Transfer":                        reflect.TypeOf((*Transfer)(nil)).Elem(),
+	"Trip":                            reflect.TypeOf((*Trip)(nil)).Elem(),
+	"TripQuality":                     reflect.TypeOf((*TripQuality)(nil)).Elem(),
+	"TypicalSecondsKind":              reflect.TypeOf((*TypicalSecondsKind)(nil)).Elem(),
+	"TypicalSecondsSample":            reflect.TypeOf((*TypicalSecondsSample)(nil)).Elem(),
+	"TypicalSecondsSum":               reflect.TypeOf((*TypicalSecondsSum)(nil)).Elem(),
+	"WiFiAP":                          reflect.TypeOf((*WiFiAP)(nil)).Elem(),
+	"WiFiAPObservation":               reflect.TypeOf((*WiFiAPObservation)(nil)).Elem(),
+	"WiFiAPObservationSummary":        reflect.TypeOf((*WiFiAPObservationSummary)(nil)).Elem(),
+	"WiFiAPSuggestion":                reflect.TypeOf((*WiFiAPSuggestion)(nil)).Elem(),
+	"WiFiAPSuggestionAction":          reflect.TypeOf((*WiFiAPSuggestionAction)(nil)).Elem(),
+	"WiFiAPSuggestionSource":          reflect.TypeOf((*WiFiAPSuggestionSource)(nil)).Elem(),
+	"WiFiAPSuggestionStatus":          reflect.TypeOf((*WiFiAPSuggestionStatus)(nil)).Elem(),
 }
 
 var Functions = map[string]reflect.Value{
-	"ComputeAPISecretHash":               reflect.ValueOf(ComputeAPISecretHash),
-	"CountPPPlayerAchievementsAchieved":  reflect.ValueOf(CountPPPlayerAchievementsAchieved),
-	"CountPPPlayers":                     reflect.ValueOf(CountPPPlayers),
-	"CountPPXPTransactionsWithType":      reflect.ValueOf(CountPPXPTransactionsWithType),
-	"CountPairActivationsByDay":          reflect.ValueOf(CountPairActivationsByDay),
-	"CountTripsByDay":                    reflect.ValueOf(CountTripsByDay),
-	"GenerateAPIKey":                     reflect.ValueOf(GenerateAPIKey),
-	"GenerateAPISecret":                  reflect.ValueOf(GenerateAPISecret),
-	"GetAutorunScriptsWithType":          reflect.ValueOf(GetAutorunScriptsWithType),
-	"GetConnection":                      reflect.ValueOf(GetConnection),
-	"GetConnections":                     reflect.ValueOf(GetConnections),
-	"GetDataset":                         reflect.ValueOf(GetDataset),
-	"GetDatasets":                        reflect.ValueOf(GetDatasets),
-	"GetDisturbance":                     reflect.ValueOf(GetDisturbance),
-	"GetDisturbances":                    reflect.ValueOf(GetDisturbances),
-	"GetDisturbancesBetween":             reflect.ValueOf(GetDisturbancesBetween),
-	"GetExit":                            reflect.ValueOf(GetExit),
-	"GetExits":                           reflect.ValueOf(GetExits),
-	"GetFeedbacks":                       reflect.ValueOf(GetFeedbacks),
-	"GetLatestNDisturbances":             reflect.ValueOf(GetLatestNDisturbances),
-	"GetLine":                            reflect.ValueOf(GetLine),
-	"GetLineCondition":                   reflect.ValueOf(GetLineCondition),
-	"GetLineConditions":                  reflect.ValueOf(GetLineConditions),
-	"GetLinePaths":                       reflect.ValueOf(GetLinePaths),
-	"GetLineSchedules":                   reflect.ValueOf(GetLineSchedules),
-	"GetLines":                           reflect.ValueOf(GetLines),
-	"GetLobbies":                         reflect.ValueOf(GetLobbies),
-	"GetLobbiesForStation":               reflect.ValueOf(GetLobbiesForStation),
-	"GetLobby":                           reflect.ValueOf(GetLobby),
-	"GetLobbySchedules":                  reflect.ValueOf(GetLobbySchedules),
-	"GetNetwork":                         reflect.ValueOf(GetNetwork),
-	"GetNetworkSchedules":                reflect.ValueOf(GetNetworkSchedules),
-	"GetNetworks":                        reflect.ValueOf(GetNetworks),
-	"GetOngoingDisturbances":             reflect.ValueOf(GetOngoingDisturbances),
-	"GetPOI":                             reflect.ValueOf(GetPOI),
-	"GetPOIs":                            reflect.ValueOf(GetPOIs),
-	"GetPPAchievement":                   reflect.ValueOf(GetPPAchievement),
-	"GetPPAchievements":                  reflect.ValueOf(GetPPAchievements),
-	"GetPPNotificationSetting":           reflect.ValueOf(GetPPNotificationSetting),
-	"GetPPPair":                          reflect.ValueOf(GetPPPair),
-	"GetPPPairForKey":                    reflect.ValueOf(GetPPPairForKey),
-	"GetPPPairs":                         reflect.ValueOf(GetPPPairs),
-	"GetPPPlayer":                        reflect.ValueOf(GetPPPlayer),
-	"GetPPPlayerAchievement":             reflect.ValueOf(GetPPPlayerAchievement),
-	"GetPPPlayerAchievements":            reflect.ValueOf(GetPPPlayerAchievements),
-	"GetPPPlayers":                       reflect.ValueOf(GetPPPlayers),
-	"GetPPXPTransaction":                 reflect.ValueOf(GetPPXPTransaction),
-	"GetPPXPTransactions":                reflect.ValueOf(GetPPXPTransactions),
-	"GetPPXPTransactionsBetween":         reflect.ValueOf(GetPPXPTransactionsBetween),
-	"GetPPXPTransactionsTotal":           reflect.ValueOf(GetPPXPTransactionsTotal),
-	"GetPPXPTransactionsWithType":        reflect.ValueOf(GetPPXPTransactionsWithType),
-	"GetPair":                            reflect.ValueOf(GetPair),
-	"GetPairIfCorrect":                   reflect.ValueOf(GetPairIfCorrect),
-	"GetScript":                          reflect.ValueOf(GetScript),
-	"GetScripts":                         reflect.ValueOf(GetScripts),
-	"GetScriptsWithType":                 reflect.ValueOf(GetScriptsWithType),
-	"GetSource":                          reflect.ValueOf(GetSource),
-	"GetSources":                         reflect.ValueOf(GetSources),
-	"GetStation":                         reflect.ValueOf(GetStation),
-	"GetStationTags":                     reflect.ValueOf(GetStationTags),
-	"GetStationUses":                     reflect.ValueOf(GetStationUses),
-	"GetStations":                        reflect.ValueOf(GetStations),
-	"GetStatus":                          reflect.ValueOf(GetStatus),
-	"GetStatuses":                        reflect.ValueOf(GetStatuses),
-	"GetTransfer":                        reflect.ValueOf(GetTransfer),
-	"GetTransfers":                       reflect.ValueOf(GetTransfers),
-	"GetTrip":                            reflect.ValueOf(GetTrip),
-	"GetTripIDs":                         reflect.ValueOf(GetTripIDs),
-	"GetTripIDsBetween":                  reflect.ValueOf(GetTripIDsBetween),
-	"GetTrips":                           reflect.ValueOf(GetTrips),
-	"GetTripsForSubmitter":               reflect.ValueOf(GetTripsForSubmitter),
-	"GetTripsForSubmitterBetween":        reflect.ValueOf(GetTripsForSubmitterBetween),
-	"GetWiFiAP":                          reflect.ValueOf(GetWiFiAP),
-	"GetWiFiAPs":                         reflect.ValueOf(GetWiFiAPs),
-	"NewAndroidPairRequest":              reflect.ValueOf(NewAndroidPairRequest),
-	"NewLineDisturbanceReport":           reflect.ValueOf(NewLineDisturbanceReport),
-	"NewLineDisturbanceReportDebug":      reflect.ValueOf(NewLineDisturbanceReportDebug),
-	"NewLineDisturbanceReportThroughAPI": reflect.ValueOf(NewLineDisturbanceReportThroughAPI),
-	"NewPair":                            reflect.ValueOf(NewPair),
-	"PPLeaderboardBetween":               reflect.ValueOf(PPLeaderboardBetween),
-	"PosPlayLevelToXP":                   reflect.ValueOf(PosPlayLevelToXP),
-	"PosPlayPlayerLevel":                 reflect.ValueOf(PosPlayPlayerLevel),
-	"RegisterPPAchievementStrategy":      reflect.ValueOf(RegisterPPAchievementStrategy),
-	"SetPPNotificationSetting":           reflect.ValueOf(SetPPNotificationSetting),
-	"UnregisterPPAchievementStrategy":    reflect.ValueOf(UnregisterPPAchievementStrategy),
+	"AnonymiseFeedbackOlderThan":           reflect.ValueOf(AnonymiseFeedbackOlderThan),
+	"AnonymiseTripsOlderThan":              reflect.ValueOf(AnonymiseTripsOlderThan),
+	"ComputeAPISecretHash":                 reflect.ValueOf(ComputeAPISecretHash),
+	"CountAndroidPairRequestsOlderThan":    reflect.ValueOf(CountAndroidPairRequestsOlderThan),
+	"CountFeedbackWithSubmitterOlderThan":  reflect.ValueOf(CountFeedbackWithSubmitterOlderThan),
+	"CountPPPlayerAchievementsAchieved":    reflect.ValueOf(CountPPPlayerAchievementsAchieved),
+	"CountPPPlayers":                       reflect.ValueOf(CountPPPlayers),
+	"CountPPXPTransactionsWithType":        reflect.ValueOf(CountPPXPTransactionsWithType),
+	"CountPairActivationsByDay":            reflect.ValueOf(CountPairActivationsByDay),
+	"CountPairChallengesRedeemedBy":        reflect.ValueOf(CountPairChallengesRedeemedBy),
+	"CountTripsByDay":                      reflect.ValueOf(CountTripsByDay),
+	"CountTripsWithSubmitterOlderThan":     reflect.ValueOf(CountTripsWithSubmitterOlderThan),
+	"DeleteAllConnectionHourlyTimes":       reflect.ValueOf(DeleteAllConnectionHourlyTimes),
+	"DeleteAndroidPairRequestsOlderThan":   reflect.ValueOf(DeleteAndroidPairRequestsOlderThan),
+	"DeletePairChallengesExpiredBefore":    reflect.ValueOf(DeletePairChallengesExpiredBefore),
+	"DeleteProvisionalTripsOlderThan":      reflect.ValueOf(DeleteProvisionalTripsOlderThan),
+	"DeleteWiFiAPObservationsOlderThan":    reflect.ValueOf(DeleteWiFiAPObservationsOlderThan),
+	"ErasePPPlayerPersonalData":            reflect.ValueOf(ErasePPPlayerPersonalData),
+	"ErasePairPersonalData":                reflect.ValueOf(ErasePairPersonalData),
+	"ExportPersonalData":                   reflect.ValueOf(ExportPersonalData),
+	"GenerateAPIKey":                       reflect.ValueOf(GenerateAPIKey),
+	"GenerateAPISecret":                    reflect.ValueOf(GenerateAPISecret),
+	"GetAutorunScriptsWithType":            reflect.ValueOf(GetAutorunScriptsWithType),
+	"GetConnection":                        reflect.ValueOf(GetConnection),
+	"GetConnections":                       reflect.ValueOf(GetConnections),
+	"GetDataset":                           reflect.ValueOf(GetDataset),
+	"GetDatasets":                          reflect.ValueOf(GetDatasets),
+	"GetDisturbance":                       reflect.ValueOf(GetDisturbance),
+	"GetDisturbanceWithStatus":             reflect.ValueOf(GetDisturbanceWithStatus),
+	"GetDisturbances":                      reflect.ValueOf(GetDisturbances),
+	"GetDisturbancesBetween":               reflect.ValueOf(GetDisturbancesBetween),
+	"GetDisturbancesPage":                  reflect.ValueOf(GetDisturbancesPage),
+	"GetExit":                              reflect.ValueOf(GetExit),
+	"GetExits":                             reflect.ValueOf(GetExits),
+	"GetFareProduct":                       reflect.ValueOf(GetFareProduct),
+	"GetFareProducts":                      reflect.ValueOf(GetFareProducts),
+	"GetFareZone":                          reflect.ValueOf(GetFareZone),
+	"GetFareZones":                         reflect.ValueOf(GetFareZones),
+	"GetFeedbackForDiscordMessage":         reflect.ValueOf(GetFeedbackForDiscordMessage),
+	"GetFeedbacks":                         reflect.ValueOf(GetFeedbacks),
+	"GetFeedbacksWithStatus":               reflect.ValueOf(GetFeedbacksWithStatus),
+	"GetLatestNDisturbances":               reflect.ValueOf(GetLatestNDisturbances),
+	"GetLatestProvisionalTripForSubmitter": reflect.ValueOf(GetLatestProvisionalTripForSubmitter),
+	"GetLine":                              reflect.ValueOf(GetLine),
+	"GetLineCondition":                     reflect.ValueOf(GetLineCondition),
+	"GetLineConditions":                    reflect.ValueOf(GetLineConditions),
+	"GetLineConditionsAfter":               reflect.ValueOf(GetLineConditionsAfter),
+	"GetLinePaths":                         reflect.ValueOf(GetLinePaths),
+	"GetLineSchedules":                     reflect.ValueOf(GetLineSchedules),
+	"GetLines":                             reflect.ValueOf(GetLines),
+	"GetLobbies":                           reflect.ValueOf(GetLobbies),
+	"GetLobbiesForStation":                 reflect.ValueOf(GetLobbiesForStation),
+	"GetLobby":                             reflect.ValueOf(GetLobby),
+	"GetLobbySchedules":                    reflect.ValueOf(GetLobbySchedules),
+	"GetNetwork":                           reflect.ValueOf(GetNetwork),
+	"GetNetworkSchedules":                  reflect.ValueOf(GetNetworkSchedules),
+	"GetNetworks":                          reflect.ValueOf(GetNetworks),
+	"GetOngoingDisturbances":               reflect.ValueOf(GetOngoingDisturbances),
+	"GetOngoingStationPathOutages":         reflect.ValueOf(GetOngoingStationPathOutages),
+	"GetPOI":                               reflect.ValueOf(GetPOI),
+	"GetPOIs":                              reflect.ValueOf(GetPOIs),
+	"GetPPAchievement":                     reflect.ValueOf(GetPPAchievement),
+	"GetPPAchievements":                    reflect.ValueOf(GetPPAchievements),
+	"GetPPNotificationSetting":             reflect.ValueOf(GetPPNotificationSetting),
+	"GetPPPair":                            reflect.ValueOf(GetPPPair),
+	"GetPPPairForKey":                      reflect.ValueOf(GetPPPairForKey),
+	"GetPPPairs":                           reflect.ValueOf(GetPPPairs),
+	"GetPPPlayer":                          reflect.ValueOf(GetPPPlayer),
+	"GetPPPlayerAchievement":               reflect.ValueOf(GetPPPlayerAchievement),
+	"GetPPPlayerAchievements":              reflect.ValueOf(GetPPPlayerAchievements),
+	"GetPPPlayers":                         reflect.ValueOf(GetPPPlayers),
+	"GetPPXPTransaction":                   reflect.ValueOf(GetPPXPTransaction),
+	"GetPPXPTransactions":                  reflect.ValueOf(GetPPXPTransactions),
+	"GetPPXPTransactionsBetween":           reflect.ValueOf(GetPPXPTransactionsBetween),
+	"GetPPXPTransactionsTotal":             reflect.ValueOf(GetPPXPTransactionsTotal),
+	"GetPPXPTransactionsWithType":          reflect.ValueOf(GetPPXPTransactionsWithType),
+	"GetPair":                              reflect.ValueOf(GetPair),
+	"GetPairChallenge":                     reflect.ValueOf(GetPairChallenge),
+	"GetPairIfCorrect":                     reflect.ValueOf(GetPairIfCorrect),
+	"GetPersonalDataAuditEntries":          reflect.ValueOf(GetPersonalDataAuditEntries),
+	"GetPlatforms":                         reflect.ValueOf(GetPlatforms),
+	"GetProvisionalTrip":                   reflect.ValueOf(GetProvisionalTrip),
+	"GetProvisionalTripsForSubmitter":      reflect.ValueOf(GetProvisionalTripsForSubmitter),
+	"GetRecentlySeenPairs":                 reflect.ValueOf(GetRecentlySeenPairs),
+	"GetRevokedPairs":                      reflect.ValueOf(GetRevokedPairs),
+	"GetScript":                            reflect.ValueOf(GetScript),
+	"GetScripts":                           reflect.ValueOf(GetScripts),
+	"GetScriptsWithType":                   reflect.ValueOf(GetScriptsWithType),
+	"GetSource":                            reflect.ValueOf(GetSource),
+	"GetSources":                           reflect.ValueOf(GetSources),
+	"GetStation":                           reflect.ValueOf(GetStation),
+	"GetStationPath":                       reflect.ValueOf(GetStationPath),
+	"GetStationPathOutage":                 reflect.ValueOf(GetStationPathOutage),
+	"GetStationPaths":                      reflect.ValueOf(GetStationPaths),
+	"GetStationTags":                       reflect.ValueOf(GetStationTags),
+	"GetStationUses":                       reflect.ValueOf(GetStationUses),
+	"GetStations":                          reflect.ValueOf(GetStations),
+	"GetStationsPage":                      reflect.ValueOf(GetStationsPage),
+	"GetStatus":                            reflect.ValueOf(GetStatus),
+	"GetStatuses":                          reflect.ValueOf(GetStatuses),
+	"GetStatusesAfter":                     reflect.ValueOf(GetStatusesAfter),
+	"GetTransfer":                          reflect.ValueOf(GetTransfer),
+	"GetTransfers":                         reflect.ValueOf(GetTransfers),
+	"GetTrip":                              reflect.ValueOf(GetTrip),
+	"GetTripIDs":                           reflect.ValueOf(GetTripIDs),
+	"GetTripIDsBetween":                    reflect.ValueOf(GetTripIDsBetween),
+	"GetTripIDsPendingTypicalSeconds":      reflect.ValueOf(GetTripIDsPendingTypicalSeconds),
+	"GetTrips":                             reflect.ValueOf(GetTrips),
+	"GetTripsForSubmitter":                 reflect.ValueOf(GetTripsForSubmitter),
+	"GetTripsForSubmitterBetween":          reflect.ValueOf(GetTripsForSubmitterBetween),
+	"GetTripsForSubmitterPage":             reflect.ValueOf(GetTripsForSubmitterPage),
+	"GetTypicalSecondsSamplesForTrip":      reflect.ValueOf(GetTypicalSecondsSamplesForTrip),
+	"GetUnpublishedWiFiAPSuggestions":      reflect.ValueOf(GetUnpublishedWiFiAPSuggestions),
+	"GetUnresolvedFeedbacksWithType":       reflect.ValueOf(GetUnresolvedFeedbacksWithType),
+	"GetWiFiAP":                            reflect.ValueOf(GetWiFiAP),
+	"GetWiFiAPObservationSummariesSince":   reflect.ValueOf(GetWiFiAPObservationSummariesSince),
+	"GetWiFiAPSuggestion":                  reflect.ValueOf(GetWiFiAPSuggestion),
+	"GetWiFiAPSuggestions":                 reflect.ValueOf(GetWiFiAPSuggestions),
+	"GetWiFiAPSuggestionsWithStatus":       reflect.ValueOf(GetWiFiAPSuggestionsWithStatus),
+	"GetWiFiAPs":                           reflect.ValueOf(GetWiFiAPs),
+	"LogPersonalDataOperation":             reflect.ValueOf(LogPersonalDataOperation),
+	"NewAndroidPairRequest":                reflect.ValueOf(NewAndroidPairRequest),
+	"NewLineDisturbanceReport":             reflect.ValueOf(NewLineDisturbanceReport),
+	"NewLineDisturbanceReportDebug":        reflect.ValueOf(NewLineDisturbanceReportDebug),
+	"NewLineDisturbanceReportThroughAPI":   reflect.ValueOf(NewLineDisturbanceReportThroughAPI),
+	"NewPair":                              reflect.ValueOf(NewPair),
+	"NewPairChallenge":                     reflect.ValueOf(NewPairChallenge),
+	"NewProvisionalTrip":                   reflect.ValueOf(NewProvisionalTrip),
+	"PPLeaderboardBetween":                 reflect.ValueOf(PPLeaderboardBetween),
+	"PosPlayLevelToXP":                     reflect.ValueOf(PosPlayLevelToXP),
+	"PosPlayPlayerLevel":                   reflect.ValueOf(PosPlayPlayerLevel),
+	"PruneTypicalSeconds":                  reflect.ValueOf(PruneTypicalSeconds),
+	"RecordPairUsage":                      reflect.ValueOf(RecordPairUsage),
+	"RegisterPPAchievementStrategy":        reflect.ValueOf(RegisterPPAchievementStrategy),
+	"RetractTypicalSecondsSamples":         reflect.ValueOf(RetractTypicalSecondsSamples),
+	"SetPPNotificationSetting":             reflect.ValueOf(SetPPNotificationSetting),
+	"StoreTypicalSecondsSamples":           reflect.ValueOf(StoreTypicalSecondsSamples),
+	"SumTypicalSecondsBuckets":             reflect.ValueOf(SumTypicalSecondsBuckets),
+	"UnregisterPPAchievementStrategy":      reflect.ValueOf(UnregisterPPAchievementStrategy),
 }
 
 var Variables = map[string]reflect.Value{
 	"ErrTimeParse":          reflect.ValueOf(&ErrTimeParse),
+	"FeedbackStatuses":      reflect.ValueOf(&FeedbackStatuses),
+	"FeedbackTypes":         reflect.ValueOf(&FeedbackTypes),
 	"NewStatusNotification": reflect.ValueOf(&NewStatusNotification),
+	"PairScopes":            reflect.ValueOf(&PairScopes),
 }
 
 var Consts = map[string]reflect.Value{
-	"CommunityReportedCategory": reflect.ValueOf(CommunityReportedCategory),
-	"GoneThrough":               reflect.ValueOf(GoneThrough),
-	"Interchange":               reflect.ValueOf(Interchange),
-	"MLClosedMessage":           reflect.ValueOf(MLClosedMessage),
-	"MLCompositeMessage":        reflect.ValueOf(MLCompositeMessage),
-	"MLGenericMessage":          reflect.ValueOf(MLGenericMessage),
-	"MLSolvedMessage":           reflect.ValueOf(MLSolvedMessage),
-	"MLSpecialServiceMessage":   reflect.ValueOf(MLSpecialServiceMessage),
-	"NetworkEntry":              reflect.ValueOf(NetworkEntry),
-	"NetworkExit":               reflect.ValueOf(NetworkExit),
-	"PassengerIncidentCategory": reflect.ValueOf(PassengerIncidentCategory),
-	"PowerOutageCategory":       reflect.ValueOf(PowerOutageCategory),
-	"RawMessage":                reflect.ValueOf(RawMessage),
-	"ReportBeginMessage":        reflect.ValueOf(ReportBeginMessage),
-	"ReportConfirmMessage":      reflect.ValueOf(ReportConfirmMessage),
-	"ReportReconfirmMessage":    reflect.ValueOf(ReportReconfirmMessage),
-	"ReportSolvedMessage":       reflect.ValueOf(ReportSolvedMessage),
-	"S2LSincorrectDetection":    reflect.ValueOf(S2LSincorrectDetection),
-	"SignalFailureCategory":     reflect.ValueOf(SignalFailureCategory),
-	"StationAnomalyCategory":    reflect.ValueOf(StationAnomalyCategory),
-	"ThirdPartyFaultCategory":   reflect.ValueOf(ThirdPartyFaultCategory),
-	"TrainFailureCategory":      reflect.ValueOf(TrainFailureCategory),
-	"Visit":                     reflect.ValueOf(Visit),
+	"AnonymisedPairKey":                reflect.ValueOf(AnonymisedPairKey),
+	"BugReport":                        reflect.ValueOf(BugReport),
+	"CommunityReportedCategory":        reflect.ValueOf(CommunityReportedCategory),
+	"DisturbanceCorrection":            reflect.ValueOf(DisturbanceCorrection),
+	"EscalatorPathMeans":               reflect.ValueOf(EscalatorPathMeans),
+	"FeedbackNew":                      reflect.ValueOf(FeedbackNew),
+	"FeedbackResolved":                 reflect.ValueOf(FeedbackResolved),
+	"FeedbackTriaged":                  reflect.ValueOf(FeedbackTriaged),
+	"GoneThrough":                      reflect.ValueOf(GoneThrough),
+	"Interchange":                      reflect.ValueOf(Interchange),
+	"LevelPathMeans":                   reflect.ValueOf(LevelPathMeans),
+	"LiftPathMeans":                    reflect.ValueOf(LiftPathMeans),
+	"MLClosedMessage":                  reflect.ValueOf(MLClosedMessage),
+	"MLCompositeMessage":               reflect.ValueOf(MLCompositeMessage),
+	"MLGenericMessage":                 reflect.ValueOf(MLGenericMessage),
+	"MLSolvedMessage":                  reflect.ValueOf(MLSolvedMessage),
+	"MLSpecialServiceMessage":          reflect.ValueOf(MLSpecialServiceMessage),
+	"NetworkEntry":                     reflect.ValueOf(NetworkEntry),
+	"NetworkExit":                      reflect.ValueOf(NetworkExit),
+	"PairScopeFeedback":                reflect.ValueOf(PairScopeFeedback),
+	"PairScopeRealtime":                reflect.ValueOf(PairScopeRealtime),
+	"PairScopeReports":                 reflect.ValueOf(PairScopeReports),
+	"PairScopeTrips":                   reflect.ValueOf(PairScopeTrips),
+	"PairScopeWiFiAPs":                 reflect.ValueOf(PairScopeWiFiAPs),
+	"PassengerIncidentCategory":        reflect.ValueOf(PassengerIncidentCategory),
+	"PersonalDataAnonymisation":        reflect.ValueOf(PersonalDataAnonymisation),
+	"PersonalDataErasure":              reflect.ValueOf(PersonalDataErasure),
+	"PersonalDataExport":               reflect.ValueOf(PersonalDataExport),
+	"PlatformMaxStepFreeGapHeight":     reflect.ValueOf(PlatformMaxStepFreeGapHeight),
+	"PlatformMaxStepFreeGapWidth":      reflect.ValueOf(PlatformMaxStepFreeGapWidth),
+	"PowerOutageCategory":              reflect.ValueOf(PowerOutageCategory),
+	"RampPathMeans":                    reflect.ValueOf(RampPathMeans),
+	"RawMessage":                       reflect.ValueOf(RawMessage),
+	"ReportBeginMessage":               reflect.ValueOf(ReportBeginMessage),
+	"ReportConfirmMessage":             reflect.ValueOf(ReportConfirmMessage),
+	"ReportReconfirmMessage":           reflect.ValueOf(ReportReconfirmMessage),
+	"ReportSolvedMessage":              reflect.ValueOf(ReportSolvedMessage),
+	"S2LSincorrectDetection":           reflect.ValueOf(S2LSincorrectDetection),
+	"SignalFailureCategory":            reflect.ValueOf(SignalFailureCategory),
+	"StairsPathMeans":                  reflect.ValueOf(StairsPathMeans),
+	"StationAnomalyCategory":           reflect.ValueOf(StationAnomalyCategory),
+	"Suggestion":                       reflect.ValueOf(Suggestion),
+	"ThirdPartyFaultCategory":          reflect.ValueOf(ThirdPartyFaultCategory),
+	"TrainFailureCategory":             reflect.ValueOf(TrainFailureCategory),
+	"TripQualityGood":                  reflect.ValueOf(TripQualityGood),
+	"TripQualityImplausible":           reflect.ValueOf(TripQualityImplausible),
+	"TripQualitySuspicious":            reflect.ValueOf(TripQualitySuspicious),
+	"TripQualityUnchecked":             reflect.ValueOf(TripQualityUnchecked),
+	"TypicalSecondsConnection":         reflect.ValueOf(TypicalSecondsConnection),
+	"TypicalSecondsConnectionStop":     reflect.ValueOf(TypicalSecondsConnectionStop),
+	"TypicalSecondsConnectionWait":     reflect.ValueOf(TypicalSecondsConnectionWait),
+	"TypicalSecondsTransfer":           reflect.ValueOf(TypicalSecondsTransfer),
+	"Visit":                            reflect.ValueOf(Visit),
+	"WiFiAPSuggestionAdd":              reflect.ValueOf(WiFiAPSuggestionAdd),
+	"WiFiAPSuggestionApproved":         reflect.ValueOf(WiFiAPSuggestionApproved),
+	"WiFiAPSuggestionFromFeedback":     reflect.ValueOf(WiFiAPSuggestionFromFeedback),
+	"WiFiAPSuggestionFromObservations": reflect.ValueOf(WiFiAPSuggestionFromObservations),
+	"WiFiAPSuggestionPending":          reflect.ValueOf(WiFiAPSuggestionPending),
+	"WiFiAPSuggestionPublished":        reflect.ValueOf(WiFiAPSuggestionPublished),
+	"WiFiAPSuggestionRejected":         reflect.ValueOf(WiFiAPSuggestionRejected),
+	"WiFiAPSuggestionRemove":           reflect.ValueOf(WiFiAPSuggestionRemove),
+	"WrongStationData":                 reflect.ValueOf(WrongStationData),
 }
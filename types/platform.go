@@ -0,0 +1,123 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+const (
+	// PlatformMaxStepFreeGapWidth is the maximum horizontal gap, in millimeters, for boarding without assistance
+	PlatformMaxStepFreeGapWidth = 75
+	// PlatformMaxStepFreeGapHeight is the maximum vertical gap, in millimeters, for boarding without assistance
+	PlatformMaxStepFreeGapHeight = 50
+)
+
+// Platform contains the accessibility attributes of the platforms of a Line in a Station
+type Platform struct {
+	Station *Station
+	Line    *Line
+	// GapWidth and GapHeight are the horizontal and vertical distances, in millimeters, between the platform and the trains
+	GapWidth  int
+	GapHeight int
+}
+
+// GetPlatforms returns a slice with all registered platforms
+func GetPlatforms(node sqalx.Node) ([]*Platform, error) {
+	return getPlatformsWithSelect(node, sdb.Select())
+}
+
+func getPlatformsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Platform, error) {
+	platforms := []*Platform{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return platforms, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("station_id", "line_id", "gap_width", "gap_height").
+		From("station_platform").
+		OrderBy("station_id, line_id ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return platforms, fmt.Errorf("getPlatformsWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	var stationIDs, lineIDs []string
+	for rows.Next() {
+		var platform Platform
+		var stationID, lineID string
+		err := rows.Scan(
+			&stationID,
+			&lineID,
+			&platform.GapWidth,
+			&platform.GapHeight)
+		if err != nil {
+			return platforms, fmt.Errorf("getPlatformsWithSelect: %s", err)
+		}
+		platforms = append(platforms, &platform)
+		stationIDs = append(stationIDs, stationID)
+		lineIDs = append(lineIDs, lineID)
+	}
+	if err := rows.Err(); err != nil {
+		return platforms, fmt.Errorf("getPlatformsWithSelect: %s", err)
+	}
+	for i := range platforms {
+		platforms[i].Station, err = GetStation(tx, stationIDs[i])
+		if err != nil {
+			return platforms, fmt.Errorf("getPlatformsWithSelect: %s", err)
+		}
+		platforms[i].Line, err = GetLine(tx, lineIDs[i])
+		if err != nil {
+			return platforms, fmt.Errorf("getPlatformsWithSelect: %s", err)
+		}
+	}
+	return platforms, nil
+}
+
+// StepFree returns whether trains can be boarded from the platform without assistance
+func (platform *Platform) StepFree() bool {
+	return platform.GapWidth <= PlatformMaxStepFreeGapWidth && platform.GapHeight <= PlatformMaxStepFreeGapHeight
+}
+
+// Update adds or updates the platform
+func (platform *Platform) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("station_platform").
+		Columns("station_id", "line_id", "gap_width", "gap_height").
+		Values(platform.Station.ID, platform.Line.ID, platform.GapWidth, platform.GapHeight).
+		Suffix("ON CONFLICT (station_id, line_id) DO UPDATE SET gap_width = ?, gap_height = ?",
+			platform.GapWidth, platform.GapHeight).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddPlatform: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the platform
+func (platform *Platform) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_platform").
+		Where(sq.Eq{"station_id": platform.Station.ID},
+			sq.Eq{"line_id": platform.Line.ID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemovePlatform: %s", err)
+	}
+	return tx.Commit()
+}
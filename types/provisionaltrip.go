@@ -0,0 +1,385 @@
+package types
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/satori/go.uuid"
+)
+
+// ProvisionalTrip is a trip stitched together on the server from the real-time location reports of a pair.
+// It can later be confirmed by the pair, becoming a regular Trip
+type ProvisionalTrip struct {
+	ID             string
+	Submitter      *APIPair
+	StartTime      time.Time
+	LastReportTime time.Time
+	Reports        []*RealtimeLocationReport
+}
+
+// RealtimeLocationReport is a real-time location report sent by a client
+type RealtimeLocationReport struct {
+	Station *Station
+	// Direction is nil if the user had just entered the network when the report was sent
+	Direction *Station
+	Time      time.Time
+}
+
+// NewProvisionalTrip creates a new provisional trip for the specified submitter, starting at the specified time
+func NewProvisionalTrip(node sqalx.Node, submitter *APIPair, startTime time.Time) (*ProvisionalTrip, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	trip := &ProvisionalTrip{
+		ID:             id.String(),
+		Submitter:      submitter,
+		StartTime:      startTime,
+		LastReportTime: startTime,
+		Reports:        []*RealtimeLocationReport{},
+	}
+	return trip, trip.Update(node)
+}
+
+// GetProvisionalTripsForSubmitter returns a slice with all provisional trips of the specified submitter
+func GetProvisionalTripsForSubmitter(node sqalx.Node, submitter *APIPair) ([]*ProvisionalTrip, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"submitter": submitter.Key}).
+		OrderBy("start_time ASC")
+	return getProvisionalTripsWithSelect(node, s)
+}
+
+// GetLatestProvisionalTripForSubmitter returns the provisional trip of the specified submitter that received a report most recently
+func GetLatestProvisionalTripForSubmitter(node sqalx.Node, submitter *APIPair) (*ProvisionalTrip, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"submitter": submitter.Key}).
+		OrderBy("last_report_time DESC").
+		Limit(1)
+	trips, err := getProvisionalTripsWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(trips) == 0 {
+		return nil, errors.New("ProvisionalTrip not found")
+	}
+	return trips[0], nil
+}
+
+// GetProvisionalTrip returns the ProvisionalTrip with the given ID
+func GetProvisionalTrip(node sqalx.Node, id string) (*ProvisionalTrip, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"id": id})
+	trips, err := getProvisionalTripsWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(trips) == 0 {
+		return nil, errors.New("ProvisionalTrip not found")
+	}
+	return trips[0], nil
+}
+
+func getProvisionalTripsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*ProvisionalTrip, error) {
+	trips := []*ProvisionalTrip{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return trips, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("id", "submitter", "start_time", "last_report_time").
+		From("provisional_trip").
+		RunWith(tx).Query()
+	if err != nil {
+		return trips, fmt.Errorf("getProvisionalTripsWithSelect: %s", err)
+	}
+
+	submitters := []string{}
+	for rows.Next() {
+		var trip ProvisionalTrip
+		var submitter string
+		err := rows.Scan(
+			&trip.ID,
+			&submitter,
+			&trip.StartTime,
+			&trip.LastReportTime)
+		if err != nil {
+			rows.Close()
+			return trips, fmt.Errorf("getProvisionalTripsWithSelect: %s", err)
+		}
+		trips = append(trips, &trip)
+		submitters = append(submitters, submitter)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return trips, fmt.Errorf("getProvisionalTripsWithSelect: %s", err)
+	}
+	rows.Close()
+
+	for i := range trips {
+		trips[i].Submitter, err = GetPair(tx, submitters[i])
+		if err != nil {
+			return trips, fmt.Errorf("getProvisionalTripsWithSelect: %s", err)
+		}
+
+		trips[i].Reports, err = trips[i].getReports(tx)
+		if err != nil {
+			return trips, fmt.Errorf("getProvisionalTripsWithSelect: %s", err)
+		}
+	}
+	return trips, nil
+}
+
+func (trip *ProvisionalTrip) getReports(node sqalx.Node) ([]*RealtimeLocationReport, error) {
+	reports := []*RealtimeLocationReport{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return reports, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sdb.Select("station_id", "direction_id", "timestamp").
+		From("provisional_trip_report").
+		Where(sq.Eq{"trip_id": trip.ID}).
+		OrderBy("timestamp ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return reports, err
+	}
+
+	stationIDs := []string{}
+	directionIDs := []sql.NullString{}
+	for rows.Next() {
+		var report RealtimeLocationReport
+		var stationID string
+		var directionID sql.NullString
+		err := rows.Scan(&stationID, &directionID, &report.Time)
+		if err != nil {
+			rows.Close()
+			return reports, err
+		}
+		reports = append(reports, &report)
+		stationIDs = append(stationIDs, stationID)
+		directionIDs = append(directionIDs, directionID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return reports, err
+	}
+	rows.Close()
+
+	for i := range reports {
+		reports[i].Station, err = GetStation(tx, stationIDs[i])
+		if err != nil {
+			return reports, err
+		}
+		if directionIDs[i].Valid {
+			reports[i].Direction, err = GetStation(tx, directionIDs[i].String)
+			if err != nil {
+				return reports, err
+			}
+		}
+	}
+	return reports, nil
+}
+
+// AddReport adds a real-time location report to this provisional trip
+func (trip *ProvisionalTrip) AddReport(node sqalx.Node, report *RealtimeLocationReport) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var directionID sql.NullString
+	if report.Direction != nil {
+		directionID.String = report.Direction.ID
+		directionID.Valid = true
+	}
+
+	_, err = sdb.Insert("provisional_trip_report").
+		Columns("trip_id", "station_id", "direction_id", "timestamp").
+		Values(trip.ID, report.Station.ID, directionID, report.Time).
+		Suffix("ON CONFLICT (trip_id, timestamp) DO NOTHING").
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddReport: " + err.Error())
+	}
+
+	trip.Reports = append(trip.Reports, report)
+	if report.Time.After(trip.LastReportTime) {
+		trip.LastReportTime = report.Time
+	}
+	err = trip.Update(tx)
+	if err != nil {
+		return errors.New("AddReport: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// HasDirection returns whether any of the reports in this provisional trip were made while travelling in some direction
+func (trip *ProvisionalTrip) HasDirection() bool {
+	for _, report := range trip.Reports {
+		if report.Direction != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ToTrip stitches the reports of this provisional trip into a Trip with the same ID.
+// The returned trip is not stored in the database
+func (trip *ProvisionalTrip) ToTrip(node sqalx.Node) (*Trip, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	if len(trip.Reports) == 0 {
+		return nil, errors.New("ToTrip: provisional trip has no reports")
+	}
+
+	// consecutive reports on the same station correspond to the same station use
+	uses := []*StationUse{}
+	// lines used when leaving each station use, may be nil
+	lines := []*Line{}
+	for _, report := range trip.Reports {
+		var line *Line
+		if report.Direction != nil {
+			line, err = commonLine(tx, report.Station, report.Direction)
+			if err != nil {
+				return nil, errors.New("ToTrip: " + err.Error())
+			}
+		}
+
+		if len(uses) > 0 && uses[len(uses)-1].Station.ID == report.Station.ID {
+			uses[len(uses)-1].LeaveTime = report.Time
+			if line != nil {
+				lines[len(lines)-1] = line
+			}
+			continue
+		}
+		uses = append(uses, &StationUse{
+			Station:   report.Station,
+			EntryTime: report.Time,
+			LeaveTime: report.Time,
+		})
+		lines = append(lines, line)
+	}
+
+	for i, use := range uses {
+		switch {
+		case len(uses) == 1:
+			use.Type = Visit
+		case i == 0:
+			use.Type = NetworkEntry
+		case i == len(uses)-1:
+			use.Type = NetworkExit
+		case lines[i-1] != nil && lines[i] != nil && lines[i-1].ID != lines[i].ID:
+			use.Type = Interchange
+			use.SourceLine = lines[i-1]
+			use.TargetLine = lines[i]
+		default:
+			use.Type = GoneThrough
+		}
+	}
+
+	return &Trip{
+		ID:          trip.ID,
+		StartTime:   uses[0].EntryTime,
+		EndTime:     uses[len(uses)-1].LeaveTime,
+		Submitter:   trip.Submitter,
+		StationUses: uses,
+	}, nil
+}
+
+// commonLine returns a line that serves both specified stations
+func commonLine(node sqalx.Node, a *Station, b *Station) (*Line, error) {
+	aLines, err := a.Lines(node)
+	if err != nil {
+		return nil, err
+	}
+	bLines, err := b.Lines(node)
+	if err != nil {
+		return nil, err
+	}
+	for _, aLine := range aLines {
+		for _, bLine := range bLines {
+			if aLine.ID == bLine.ID {
+				return aLine, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("stations %s and %s have no line in common", a.ID, b.ID)
+}
+
+// Update adds or updates the provisional trip, without touching its reports
+func (trip *ProvisionalTrip) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("provisional_trip").
+		Columns("id", "submitter", "start_time", "last_report_time").
+		Values(trip.ID, trip.Submitter.Key, trip.StartTime, trip.LastReportTime).
+		Suffix("ON CONFLICT (id) DO UPDATE SET submitter = ?, start_time = ?, last_report_time = ?",
+			trip.Submitter.Key, trip.StartTime, trip.LastReportTime).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddProvisionalTrip: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the provisional trip and its reports
+func (trip *ProvisionalTrip) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("provisional_trip_report").
+		Where(sq.Eq{"trip_id": trip.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveProvisionalTrip: %s", err)
+	}
+
+	_, err = sdb.Delete("provisional_trip").
+		Where(sq.Eq{"id": trip.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveProvisionalTrip: %s", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteProvisionalTripsOlderThan deletes provisional trips that have not received reports since the specified time
+func DeleteProvisionalTripsOlderThan(node sqalx.Node, before time.Time) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM provisional_trip_report WHERE trip_id IN "+
+		"(SELECT id FROM provisional_trip WHERE last_report_time < $1)", before)
+	if err != nil {
+		return fmt.Errorf("DeleteProvisionalTripsOlderThan: %s", err)
+	}
+
+	_, err = sdb.Delete("provisional_trip").
+		Where(sq.Lt{"last_report_time": before}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("DeleteProvisionalTripsOlderThan: %s", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+func countWithSelect(node sqalx.Node, table string, preds ...interface{}) (int, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Commit() // read-only tx
+
+	s := sdb.Select("COUNT(*)").From(table)
+	for _, pred := range preds {
+		s = s.Where(pred)
+	}
+
+	var count int
+	err = s.RunWith(tx).QueryRow().Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("countWithSelect: %s", err)
+	}
+	return count, nil
+}
+
+// CountTripsWithSubmitterOlderThan counts the trips started before the specified time that are still linked to their submitter
+func CountTripsWithSubmitterOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	return countWithSelect(node, "trip",
+		sq.Lt{"start_time": before},
+		sq.NotEq{"submitter": AnonymisedPairKey})
+}
+
+// AnonymiseTripsOlderThan transfers the trips started before the specified time to the shared anonymous pair,
+// unlinking them from their submitters while keeping their station uses intact. It returns the number of affected trips
+func AnonymiseTripsOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	return anonymiseSubmitterOlderThan(node, "trip", "start_time", before)
+}
+
+// CountFeedbackWithSubmitterOlderThan counts the feedback submitted before the specified time that is still linked to its submitter
+func CountFeedbackWithSubmitterOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	return countWithSelect(node, "feedback",
+		sq.Lt{"timestamp": before},
+		sq.NotEq{"submitter": AnonymisedPairKey})
+}
+
+// AnonymiseFeedbackOlderThan transfers the feedback submitted before the specified time to the shared anonymous pair,
+// unlinking it from its submitters. It returns the number of affected feedback entries
+func AnonymiseFeedbackOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	return anonymiseSubmitterOlderThan(node, "feedback", "timestamp", before)
+}
+
+func anonymiseSubmitterOlderThan(node sqalx.Node, table, timeColumn string, before time.Time) (int, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	anonPair, err := getAnonymisedPair(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := sdb.Update(table).
+		Set("submitter", anonPair.Key).
+		Where(sq.Lt{timeColumn: before}).
+		Where(sq.NotEq{"submitter": anonPair.Key}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return 0, errors.New("anonymiseSubmitterOlderThan: " + err.Error())
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.New("anonymiseSubmitterOlderThan: " + err.Error())
+	}
+	return int(affected), tx.Commit()
+}
+
+// CountAndroidPairRequestsOlderThan counts the Android pair requests made before the specified time
+func CountAndroidPairRequestsOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	return countWithSelect(node, "android_pair_request", sq.Lt{"request_time": before})
+}
+
+// DeleteAndroidPairRequestsOlderThan deletes the Android pair requests, and with them the IP addresses
+// and Android IDs of the requesters, made before the specified time. It returns the number of deleted requests
+func DeleteAndroidPairRequestsOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := sdb.Delete("android_pair_request").
+		Where(sq.Lt{"request_time": before}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return 0, fmt.Errorf("DeleteAndroidPairRequestsOlderThan: %s", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("DeleteAndroidPairRequestsOlderThan: %s", err)
+	}
+	return int(affected), tx.Commit()
+}
@@ -24,6 +24,24 @@ func GetStations(node sqalx.Node) ([]*Station, error) {
 	return getStationsWithSelect(node, sdb.Select())
 }
 
+// GetStationsPage returns up to `limit` stations ordered by ID, optionally restricted to a network and/or line.
+// If afterID is not empty, only stations with an ID greater than it are returned
+func GetStationsPage(node sqalx.Node, networkID string, lineID string, afterID string, limit uint64) ([]*Station, error) {
+	s := sdb.Select()
+	if networkID != "" {
+		s = s.Where(sq.Eq{"network": networkID})
+	}
+	if lineID != "" {
+		s = s.Where(sq.Expr("id IN (SELECT station_id FROM line_has_station WHERE line_id = ?)", lineID))
+	}
+	if afterID != "" {
+		s = s.Where(sq.Gt{"id": afterID})
+	}
+	s = s.OrderBy("id ASC").
+		Limit(limit)
+	return getStationsWithSelect(node, s)
+}
+
 func getStationsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Station, error) {
 	stations := []*Station{}
 	stationMap := make(map[string]*Station)
@@ -133,6 +151,27 @@ func (station *Station) POIs(node sqalx.Node) ([]*POI, error) {
 	return getPOIsWithSelect(node, s)
 }
 
+// Paths returns the paths inside this station
+func (station *Station) Paths(node sqalx.Node) ([]*StationPath, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"station_path.station_id": station.ID})
+	return getStationPathsWithSelect(node, s)
+}
+
+// Platforms returns the platforms of this station
+func (station *Station) Platforms(node sqalx.Node) ([]*Platform, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"station_id": station.ID})
+	return getPlatformsWithSelect(node, s)
+}
+
+// FareZones returns the fare zones this station is in
+func (station *Station) FareZones(node sqalx.Node) ([]*FareZone, error) {
+	s := sdb.Select().
+		Join("station_has_fare_zone ON station_has_fare_zone.station_id = ? AND station_has_fare_zone.zone_id = fare_zone.id", station.ID)
+	return getFareZonesWithSelect(node, s)
+}
+
 // Directions returns the directions (stations at an end of a line) that can be reached directly from this station
 // (i.e. without additional line changes)
 func (station *Station) Directions(node sqalx.Node, withAllServices bool) ([]*Station, error) {
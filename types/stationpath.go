@@ -0,0 +1,361 @@
+package types
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/lib/pq"
+)
+
+// StationPath is a path inside a station. It connects a lobby to one of its exits (when Exit is set),
+// a lobby to the platforms of a line (when Line is set), or the platforms of two lines (when Lobby is nil).
+// Paths can be traversed in both directions, and there may be multiple paths between the same places
+type StationPath struct {
+	ID      string
+	Station *Station
+	Lobby   *Lobby
+	Exit    *Exit
+	Line    *Line
+	ToLine  *Line
+	// Means is the least accessible way of moving along the path
+	Means StationPathMeans
+}
+
+// StationPathMeans is a way of moving along a StationPath
+type StationPathMeans string
+
+const (
+	// LevelPathMeans is used for paths without steps nor significant slopes
+	LevelPathMeans StationPathMeans = "LEVEL"
+	// RampPathMeans is used for paths with ramps
+	RampPathMeans StationPathMeans = "RAMP"
+	// LiftPathMeans is used for paths with lifts
+	LiftPathMeans StationPathMeans = "LIFT"
+	// EscalatorPathMeans is used for paths with escalators
+	EscalatorPathMeans StationPathMeans = "ESCALATOR"
+	// StairsPathMeans is used for paths with stairs
+	StairsPathMeans StationPathMeans = "STAIRS"
+)
+
+// StepFree returns whether the means can be used by people who can't use steps, such as wheelchair users
+func (means StationPathMeans) StepFree() bool {
+	return means == LevelPathMeans || means == RampPathMeans || means == LiftPathMeans
+}
+
+// StationPathOutage is a period during which a StationPath can't be used, e.g. because a lift is broken
+type StationPathOutage struct {
+	ID          string
+	Path        *StationPath
+	StartTime   time.Time
+	EndTime     time.Time
+	Ended       bool
+	Description string
+}
+
+// GetStationPaths returns a slice with all registered station paths
+func GetStationPaths(node sqalx.Node) ([]*StationPath, error) {
+	return getStationPathsWithSelect(node, sdb.Select())
+}
+
+func getStationPathsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*StationPath, error) {
+	paths := []*StationPath{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return paths, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("station_path.id", "station_path.station_id", "station_path.lobby_id",
+		"station_path.exit_id", "station_path.line_id", "station_path.to_line_id", "station_path.means").
+		From("station_path").
+		OrderBy("station_path.id ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	var stationIDs, lobbyIDs, lineIDs, toLineIDs []string
+	var exitIDs []sql.NullInt64
+	for rows.Next() {
+		var path StationPath
+		var stationID string
+		var lobbyID, lineID, toLineID sql.NullString
+		var exitID sql.NullInt64
+		err := rows.Scan(
+			&path.ID,
+			&stationID,
+			&lobbyID,
+			&exitID,
+			&lineID,
+			&toLineID,
+			&path.Means)
+		if err != nil {
+			return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+		}
+		paths = append(paths, &path)
+		stationIDs = append(stationIDs, stationID)
+		lobbyIDs = append(lobbyIDs, lobbyID.String)
+		exitIDs = append(exitIDs, exitID)
+		lineIDs = append(lineIDs, lineID.String)
+		toLineIDs = append(toLineIDs, toLineID.String)
+	}
+	if err := rows.Err(); err != nil {
+		return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+	}
+	for i := range paths {
+		paths[i].Station, err = GetStation(tx, stationIDs[i])
+		if err != nil {
+			return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+		}
+		if lobbyIDs[i] != "" {
+			paths[i].Lobby, err = GetLobby(tx, lobbyIDs[i])
+			if err != nil {
+				return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+			}
+		}
+		if exitIDs[i].Valid {
+			paths[i].Exit, err = GetExit(tx, int(exitIDs[i].Int64))
+			if err != nil {
+				return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+			}
+		}
+		if lineIDs[i] != "" {
+			paths[i].Line, err = GetLine(tx, lineIDs[i])
+			if err != nil {
+				return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+			}
+		}
+		if toLineIDs[i] != "" {
+			paths[i].ToLine, err = GetLine(tx, toLineIDs[i])
+			if err != nil {
+				return paths, fmt.Errorf("getStationPathsWithSelect: %s", err)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// GetStationPath returns the StationPath with the given ID
+func GetStationPath(node sqalx.Node, id string) (*StationPath, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"station_path.id": id})
+	paths, err := getStationPathsWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, errors.New("StationPath not found")
+	}
+	return paths[0], nil
+}
+
+// Outages returns the outages of this path
+func (path *StationPath) Outages(node sqalx.Node) ([]*StationPathOutage, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"path_id": path.ID})
+	return getStationPathOutagesWithSelect(node, s, path)
+}
+
+// OutOfServiceAt returns whether the path can't be used at the specified time due to an outage
+func (path *StationPath) OutOfServiceAt(node sqalx.Node, at time.Time) (bool, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"path_id": path.ID}).
+		Where(sq.LtOrEq{"start_time": at}).
+		Where(sq.Or{
+			sq.Eq{"end_time": nil},
+			sq.Gt{"end_time": at},
+		})
+	outages, err := getStationPathOutagesWithSelect(node, s, path)
+	if err != nil {
+		return false, err
+	}
+	return len(outages) > 0, nil
+}
+
+// Update adds or updates the path
+func (path *StationPath) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if path.Exit != nil && path.Lobby == nil {
+		return errors.New("AddStationPath: paths to exits must begin in a lobby")
+	}
+	if path.Lobby == nil && (path.Line == nil || path.ToLine == nil) {
+		return errors.New("AddStationPath: paths between platforms must connect two lines")
+	}
+	if path.Exit == nil && path.Line == nil {
+		return errors.New("AddStationPath: paths must lead to an exit or to the platforms of a line")
+	}
+
+	var lobbyID, lineID, toLineID sql.NullString
+	var exitID sql.NullInt64
+	if path.Lobby != nil {
+		lobbyID = sql.NullString{String: path.Lobby.ID, Valid: true}
+	}
+	if path.Exit != nil {
+		exitID = sql.NullInt64{Int64: int64(path.Exit.ID), Valid: true}
+	}
+	if path.Line != nil {
+		lineID = sql.NullString{String: path.Line.ID, Valid: true}
+	}
+	if path.ToLine != nil {
+		toLineID = sql.NullString{String: path.ToLine.ID, Valid: true}
+	}
+
+	_, err = sdb.Insert("station_path").
+		Columns("id", "station_id", "lobby_id", "exit_id", "line_id", "to_line_id", "means").
+		Values(path.ID, path.Station.ID, lobbyID, exitID, lineID, toLineID, path.Means).
+		Suffix("ON CONFLICT (id) DO UPDATE SET station_id = ?, lobby_id = ?, exit_id = ?, line_id = ?, to_line_id = ?, means = ?",
+			path.Station.ID, lobbyID, exitID, lineID, toLineID, path.Means).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddStationPath: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the path and its outages
+func (path *StationPath) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_path_outage").
+		Where(sq.Eq{"path_id": path.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveStationPath: %s", err)
+	}
+	_, err = sdb.Delete("station_path").
+		Where(sq.Eq{"id": path.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveStationPath: %s", err)
+	}
+	return tx.Commit()
+}
+
+// GetOngoingStationPathOutages returns a slice with the outages that have not ended
+func GetOngoingStationPathOutages(node sqalx.Node) ([]*StationPathOutage, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"end_time": nil})
+	return getStationPathOutagesWithSelect(node, s, nil)
+}
+
+// GetStationPathOutage returns the StationPathOutage with the given ID
+func GetStationPathOutage(node sqalx.Node, id string) (*StationPathOutage, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"id": id})
+	outages, err := getStationPathOutagesWithSelect(node, s, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(outages) == 0 {
+		return nil, errors.New("StationPathOutage not found")
+	}
+	return outages[0], nil
+}
+
+// getStationPathOutagesWithSelect returns the outages that match the conditions in sbuilder.
+// If path is not nil, it is assumed to be the path of all the outages
+func getStationPathOutagesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder, path *StationPath) ([]*StationPathOutage, error) {
+	outages := []*StationPathOutage{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return outages, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("id", "path_id", "start_time", "end_time", "description").
+		From("station_path_outage").
+		OrderBy("start_time ASC").
+		RunWith(tx).Query()
+	if err != nil {
+		return outages, fmt.Errorf("getStationPathOutagesWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	var pathIDs []string
+	for rows.Next() {
+		var outage StationPathOutage
+		var pathID string
+		var endTime pq.NullTime
+		err := rows.Scan(
+			&outage.ID,
+			&pathID,
+			&outage.StartTime,
+			&endTime,
+			&outage.Description)
+		if err != nil {
+			return outages, fmt.Errorf("getStationPathOutagesWithSelect: %s", err)
+		}
+		outage.EndTime = endTime.Time
+		outage.Ended = endTime.Valid
+		outages = append(outages, &outage)
+		pathIDs = append(pathIDs, pathID)
+	}
+	if err := rows.Err(); err != nil {
+		return outages, fmt.Errorf("getStationPathOutagesWithSelect: %s", err)
+	}
+	for i := range outages {
+		outages[i].Path = path
+		if path == nil {
+			outages[i].Path, err = GetStationPath(tx, pathIDs[i])
+			if err != nil {
+				return outages, fmt.Errorf("getStationPathOutagesWithSelect: %s", err)
+			}
+		}
+	}
+	return outages, nil
+}
+
+// Update adds or updates the outage
+func (outage *StationPathOutage) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	endTime := pq.NullTime{
+		Time:  outage.EndTime,
+		Valid: outage.Ended,
+	}
+
+	_, err = sdb.Insert("station_path_outage").
+		Columns("id", "path_id", "start_time", "end_time", "description").
+		Values(outage.ID, outage.Path.ID, outage.StartTime, endTime, outage.Description).
+		Suffix("ON CONFLICT (id) DO UPDATE SET path_id = ?, start_time = ?, end_time = ?, description = ?",
+			outage.Path.ID, outage.StartTime, endTime, outage.Description).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddStationPathOutage: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the outage
+func (outage *StationPathOutage) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_path_outage").
+		Where(sq.Eq{"id": outage.ID}).RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveStationPathOutage: %s", err)
+	}
+	return tx.Commit()
+}
@@ -20,6 +20,9 @@ type StationUse struct {
 	Manual     bool
 	SourceLine *Line
 	TargetLine *Line
+	// Inferred is true for uses added server-side to fill in stations the client missed.
+	// Like manual ones, inferred uses don't contain valid time data
+	Inferred bool
 }
 
 // StationUseType corresponds to a type of station use (i.e. "how" the station was used)
@@ -56,7 +59,7 @@ func getStationUsesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*St
 	defer tx.Commit() // read-only tx
 
 	rows, err := sbuilder.Columns("station_use.station_id", "station_use.entry_time", "station_use.leave_time",
-		"station_use.type", "station_use.manual", "station_use.source_line", "station_use.target_line").
+		"station_use.type", "station_use.manual", "station_use.inferred", "station_use.source_line", "station_use.target_line").
 		From("station_use").
 		RunWith(tx).Query()
 	if err != nil {
@@ -78,6 +81,7 @@ func getStationUsesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*St
 			&stationUse.LeaveTime,
 			&stationUse.Type,
 			&stationUse.Manual,
+			&stationUse.Inferred,
 			&sourceLine,
 			&targetLine)
 		if err != nil {
@@ -132,10 +136,10 @@ func (stationUse *StationUse) Update(node sqalx.Node, tripID string) error {
 	defer tx.Rollback()
 
 	_, err = sdb.Insert("station_use").
-		Columns("trip_id", "station_id", "entry_time", "leave_time", "type", "manual", "source_line", "target_line").
-		Values(tripID, stationUse.Station.ID, stationUse.EntryTime, stationUse.LeaveTime, stationUse.Type, stationUse.Manual, sourceLine, targetLine).
-		Suffix("ON CONFLICT (trip_id, station_id, entry_time) DO UPDATE SET leave_time = ?, type = ?, manual = ?, source_line = ?, target_line = ?",
-			stationUse.LeaveTime, stationUse.Type, stationUse.Manual, sourceLine, targetLine).
+		Columns("trip_id", "station_id", "entry_time", "leave_time", "type", "manual", "inferred", "source_line", "target_line").
+		Values(tripID, stationUse.Station.ID, stationUse.EntryTime, stationUse.LeaveTime, stationUse.Type, stationUse.Manual, stationUse.Inferred, sourceLine, targetLine).
+		Suffix("ON CONFLICT (trip_id, station_id, entry_time) DO UPDATE SET leave_time = ?, type = ?, manual = ?, inferred = ?, source_line = ?, target_line = ?",
+			stationUse.LeaveTime, stationUse.Type, stationUse.Manual, stationUse.Inferred, sourceLine, targetLine).
 		RunWith(tx).Exec()
 
 	if err != nil {
@@ -88,6 +88,14 @@ func GetStatus(node sqalx.Node, id string) (*Status, error) {
 	return statuses[0], nil
 }
 
+// GetStatusesAfter returns up to limit statuses registered after the specified time, in chronological order
+func GetStatusesAfter(node sqalx.Node, after time.Time, limit uint64) ([]*Status, error) {
+	s := sdb.Select().
+		Where(sq.Gt{"timestamp": after}).
+		Limit(limit)
+	return getStatusesWithSelect(node, s)
+}
+
 func getStatusesWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Status, error) {
 	statuss := []*Status{}
 
@@ -11,6 +11,20 @@ import (
 	"github.com/satori/go.uuid"
 )
 
+// TripQuality indicates how plausible a Trip is, given the network graph and typical travel times
+type TripQuality string
+
+const (
+	// TripQualityUnchecked is the quality of trips that were never validated
+	TripQualityUnchecked TripQuality = "UNCHECKED"
+	// TripQualityGood is the quality of trips that passed validation
+	TripQualityGood TripQuality = "GOOD"
+	// TripQualitySuspicious is the quality of trips with some anomalies, that should be discounted
+	TripQualitySuspicious TripQuality = "SUSPICIOUS"
+	// TripQualityImplausible is the quality of trips that are physically impossible or inconsistent with the network, that should be ignored
+	TripQualityImplausible TripQuality = "IMPLAUSIBLE"
+)
+
 // Trip represents a user-submitted subway trip
 type Trip struct {
 	ID            string
@@ -22,6 +36,9 @@ type Trip struct {
 	Edited        bool
 	UserConfirmed bool
 	StationUses   []*StationUse
+	Quality       TripQuality
+	// QualityIssues contains human-readable descriptions of the anomalies found when validating the trip
+	QualityIssues []string
 }
 
 // GetTrips returns a slice with all registered trips
@@ -53,6 +70,26 @@ func GetTripsForSubmitterBetween(node sqalx.Node, submitter *APIPair, start time
 	return getTripsWithSelect(node, s)
 }
 
+// GetTripsForSubmitterPage returns up to `limit` trips submitted by the specified submitter, ordered by start time and ID.
+// Zero start or end times do not restrict the results.
+// If afterID is not empty, only trips that come after the one with the specified start time and ID are returned
+func GetTripsForSubmitterPage(node sqalx.Node, submitter *APIPair, start time.Time, end time.Time, afterTime time.Time, afterID string, limit uint64) ([]*Trip, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"submitter": submitter.Key})
+	if !start.IsZero() {
+		s = s.Where(sq.GtOrEq{"end_time": start})
+	}
+	if !end.IsZero() {
+		s = s.Where(sq.LtOrEq{"start_time": end})
+	}
+	if afterID != "" {
+		s = s.Where(sq.Expr("(start_time, id) > (?, ?)", afterTime, afterID))
+	}
+	s = s.OrderBy("start_time ASC", "id ASC").
+		Limit(limit)
+	return getTripsWithSelect(node, s)
+}
+
 // getTripsWithSelect returns a slice with all trips that match the conditions in sbuilder
 func getTripsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Trip, error) {
 	trips := []*Trip{}
@@ -64,7 +101,8 @@ func getTripsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Trip, er
 	defer tx.Commit() // read-only tx
 
 	rows, err := sbuilder.Columns("trip.id", "trip.start_time", "trip.end_time",
-		"trip.submitter", "trip.submit_time", "trip.edit_time", "trip.user_confirmed").
+		"trip.submitter", "trip.submit_time", "trip.edit_time", "trip.user_confirmed",
+		"trip.quality", "trip.quality_issues").
 		From("trip").
 		RunWith(tx).Query()
 	if err != nil {
@@ -76,6 +114,7 @@ func getTripsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Trip, er
 		var trip Trip
 		var timeEdit pq.NullTime
 		var submitter string
+		var qualityIssues pq.StringArray
 		err := rows.Scan(
 			&trip.ID,
 			&trip.StartTime,
@@ -83,13 +122,16 @@ func getTripsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*Trip, er
 			&submitter,
 			&trip.SubmitTime,
 			&timeEdit,
-			&trip.UserConfirmed)
+			&trip.UserConfirmed,
+			&trip.Quality,
+			&qualityIssues)
 		if err != nil {
 			rows.Close()
 			return trips, fmt.Errorf("getTripsWithSelect: %s", err)
 		}
 		trip.EditTime = timeEdit.Time
 		trip.Edited = timeEdit.Valid
+		trip.QualityIssues = qualityIssues
 
 		trips = append(trips, &trip)
 		submitters = append(submitters, submitter)
@@ -231,7 +273,7 @@ func (trip *Trip) AverageSpeed(node sqalx.Node) (speed float64, totalDistance in
 	}
 	defer tx.Commit() // read-only tx
 
-	if len(trip.StationUses) <= 1 {
+	if len(trip.StationUses) <= 1 || trip.Quality == TripQualityImplausible {
 		// station visit or invalid trip
 		// can't extract any data about connections
 		return 0, 0, 0, fmt.Errorf("Trip not suitable for computing average speed")
@@ -241,7 +283,7 @@ func (trip *Trip) AverageSpeed(node sqalx.Node) (speed float64, totalDistance in
 	for useIdx := 0; useIdx < len(trip.StationUses)-1; useIdx++ {
 		sourceUse := trip.StationUses[useIdx]
 
-		if sourceUse.Manual {
+		if sourceUse.Manual || sourceUse.Inferred {
 			// manual path extensions don't contain valid time data
 			// skip
 			continue
@@ -254,7 +296,7 @@ func (trip *Trip) AverageSpeed(node sqalx.Node) (speed float64, totalDistance in
 
 		targetUse := trip.StationUses[useIdx+1]
 
-		if targetUse.Manual {
+		if targetUse.Manual || targetUse.Inferred {
 			// manual path extensions don't contain valid time data
 			// skip
 			continue
@@ -319,11 +361,18 @@ func (trip *Trip) Update(node sqalx.Node) error {
 		Valid: trip.Edited,
 	}
 
+	if trip.Quality == "" {
+		trip.Quality = TripQualityUnchecked
+	}
+	if trip.QualityIssues == nil {
+		trip.QualityIssues = []string{}
+	}
+
 	_, err = sdb.Insert("trip").
-		Columns("id", "start_time", "end_time", "submitter", "submit_time", "edit_time", "user_confirmed").
-		Values(trip.ID, trip.StartTime, trip.EndTime, trip.Submitter.Key, trip.SubmitTime, timeEdit, trip.UserConfirmed).
-		Suffix("ON CONFLICT (id) DO UPDATE SET start_time = ?, end_time = ?, submitter = ?, submit_time = ?, edit_time = ?, user_confirmed = ?",
-			trip.StartTime, trip.EndTime, trip.Submitter.Key, trip.SubmitTime, timeEdit, trip.UserConfirmed).
+		Columns("id", "start_time", "end_time", "submitter", "submit_time", "edit_time", "user_confirmed", "quality", "quality_issues").
+		Values(trip.ID, trip.StartTime, trip.EndTime, trip.Submitter.Key, trip.SubmitTime, timeEdit, trip.UserConfirmed, trip.Quality, pq.Array(trip.QualityIssues)).
+		Suffix("ON CONFLICT (id) DO UPDATE SET start_time = ?, end_time = ?, submitter = ?, submit_time = ?, edit_time = ?, user_confirmed = ?, quality = ?, quality_issues = ?",
+			trip.StartTime, trip.EndTime, trip.Submitter.Key, trip.SubmitTime, timeEdit, trip.UserConfirmed, trip.Quality, pq.Array(trip.QualityIssues)).
 		RunWith(tx).Exec()
 	if err != nil {
 		return errors.New("AddTrip: " + err.Error())
@@ -362,6 +411,11 @@ func (trip *Trip) Delete(node sqalx.Node) error {
 		}
 	}
 
+	err = RetractTypicalSecondsSamples(tx, trip.ID)
+	if err != nil {
+		return errors.New("RemoveTrip: " + err.Error())
+	}
+
 	_, err = sdb.Delete("trip").
 		Where(sq.Eq{"id": trip.ID}).RunWith(tx).Exec()
 	if err != nil {
@@ -0,0 +1,373 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+// TypicalSecondsKind identifies what a TypicalSecondsSample measures
+type TypicalSecondsKind string
+
+const (
+	// TypicalSecondsConnection measures the time it takes to travel a Connection, including the waiting time at its origin
+	TypicalSecondsConnection TypicalSecondsKind = "CONNECTION"
+	// TypicalSecondsConnectionStop measures the time trains stop at the origin of a Connection
+	TypicalSecondsConnectionStop TypicalSecondsKind = "CONNECTION_STOP"
+	// TypicalSecondsConnectionWait measures the time users wait for a train at the origin of a Connection
+	TypicalSecondsConnectionWait TypicalSecondsKind = "CONNECTION_WAIT"
+	// TypicalSecondsTransfer measures the time it takes to go through a Transfer
+	TypicalSecondsTransfer TypicalSecondsKind = "TRANSFER"
+)
+
+// TypicalSecondsSample is a single duration observation extracted from a Trip
+type TypicalSecondsSample struct {
+	Kind TypicalSecondsKind
+	// Station is the ID of the station where a transfer happens, empty for connections
+	Station string
+	// From and To are station IDs for connections and line IDs for transfers
+	From string
+	To   string
+	// Day and Hour identify the time bucket of the sample, in the local time of the network
+	Day     time.Time
+	Hour    int
+	Seconds float64
+}
+
+// TypicalSecondsSum contains the sum of the samples for a connection or transfer in a weekday and hour
+type TypicalSecondsSum struct {
+	Kind        TypicalSecondsKind
+	Station     string
+	From        string
+	To          string
+	Weekday     time.Weekday
+	Hour        int
+	Numerator   float64
+	Denominator int64
+}
+
+// GetTypicalSecondsSamplesForTrip returns the samples that were extracted from the specified trip
+func GetTypicalSecondsSamplesForTrip(node sqalx.Node, tripID string) ([]*TypicalSecondsSample, error) {
+	samples := []*TypicalSecondsSample{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return samples, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sdb.Select("kind", "station_id", "from_id", "to_id", "day", "hour", "seconds").
+		From("typical_seconds_sample").
+		Where(sq.Eq{"trip_id": tripID}).
+		RunWith(tx).Query()
+	if err != nil {
+		return samples, fmt.Errorf("GetTypicalSecondsSamplesForTrip: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sample TypicalSecondsSample
+		err := rows.Scan(
+			&sample.Kind,
+			&sample.Station,
+			&sample.From,
+			&sample.To,
+			&sample.Day,
+			&sample.Hour,
+			&sample.Seconds)
+		if err != nil {
+			return samples, fmt.Errorf("GetTypicalSecondsSamplesForTrip: %s", err)
+		}
+		samples = append(samples, &sample)
+	}
+	if err := rows.Err(); err != nil {
+		return samples, fmt.Errorf("GetTypicalSecondsSamplesForTrip: %s", err)
+	}
+	return samples, nil
+}
+
+// GetTripIDsPendingTypicalSeconds returns the IDs of the trips started after the specified time
+// whose samples were never stored, or that were edited after their samples were stored
+func GetTripIDsPendingTypicalSeconds(node sqalx.Node, since time.Time) ([]string, error) {
+	return getTripIDsWithSelect(node, sdb.Select().
+		LeftJoin("typical_seconds_trip ON trip.id = typical_seconds_trip.trip_id").
+		Where(sq.GtOrEq{"trip.start_time": since}).
+		Where("(typical_seconds_trip.trip_id IS NULL OR trip.edit_time > typical_seconds_trip.ingest_time)").
+		OrderBy("trip.start_time ASC"))
+}
+
+func addToTypicalSecondsBucket(node sqalx.Node, sample *TypicalSecondsSample, sign int) error {
+	_, err := sdb.Insert("typical_seconds_bucket").
+		Columns("kind", "station_id", "from_id", "to_id", "day", "hour", "numerator", "denominator").
+		Values(sample.Kind, sample.Station, sample.From, sample.To, sample.Day, sample.Hour, float64(sign)*sample.Seconds, sign).
+		Suffix("ON CONFLICT (kind, station_id, from_id, to_id, day, hour) DO UPDATE SET " +
+			"numerator = typical_seconds_bucket.numerator + EXCLUDED.numerator, " +
+			"denominator = typical_seconds_bucket.denominator + EXCLUDED.denominator").
+		RunWith(node).Exec()
+	return err
+}
+
+// typicalSecondsLockClass is the first key of the advisory locks taken by lockTypicalSecondsTrip
+const typicalSecondsLockClass = 1
+
+// lockTypicalSecondsTrip serializes the changes to the samples of a trip until the transaction ends,
+// so that concurrent ingestions of the same trip can't both retract nothing and then both add their samples
+func lockTypicalSecondsTrip(tx sqalx.Node, tripID string) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock($1, hashtext($2))", typicalSecondsLockClass, tripID)
+	if err != nil {
+		return errors.New("lockTypicalSecondsTrip: " + err.Error())
+	}
+	return nil
+}
+
+// StoreTypicalSecondsSamples stores the samples extracted from a trip and adds them to the running sums,
+// replacing any samples previously stored for the same trip
+func StoreTypicalSecondsSamples(node sqalx.Node, tripID string, samples []*TypicalSecondsSample) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockTypicalSecondsTrip(tx, tripID)
+	if err != nil {
+		return err
+	}
+
+	err = RetractTypicalSecondsSamples(tx, tripID)
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		_, err = sdb.Insert("typical_seconds_sample").
+			Columns("trip_id", "kind", "station_id", "from_id", "to_id", "day", "hour", "seconds").
+			Values(tripID, sample.Kind, sample.Station, sample.From, sample.To, sample.Day, sample.Hour, sample.Seconds).
+			RunWith(tx).Exec()
+		if err != nil {
+			return errors.New("StoreTypicalSecondsSamples: " + err.Error())
+		}
+
+		err = addToTypicalSecondsBucket(tx, sample, 1)
+		if err != nil {
+			return errors.New("StoreTypicalSecondsSamples: " + err.Error())
+		}
+	}
+
+	_, err = sdb.Insert("typical_seconds_trip").
+		Columns("trip_id", "ingest_time").
+		Values(tripID, time.Now()).
+		Suffix("ON CONFLICT (trip_id) DO UPDATE SET ingest_time = EXCLUDED.ingest_time").
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("StoreTypicalSecondsSamples: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// RetractTypicalSecondsSamples removes the samples of a trip from the running sums and deletes them
+func RetractTypicalSecondsSamples(node sqalx.Node, tripID string) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = lockTypicalSecondsTrip(tx, tripID)
+	if err != nil {
+		return err
+	}
+
+	samples, err := GetTypicalSecondsSamplesForTrip(tx, tripID)
+	if err != nil {
+		return err
+	}
+
+	for _, sample := range samples {
+		err = addToTypicalSecondsBucket(tx, sample, -1)
+		if err != nil {
+			return errors.New("RetractTypicalSecondsSamples: " + err.Error())
+		}
+	}
+
+	_, err = sdb.Delete("typical_seconds_sample").
+		Where(sq.Eq{"trip_id": tripID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("RetractTypicalSecondsSamples: " + err.Error())
+	}
+
+	_, err = sdb.Delete("typical_seconds_trip").
+		Where(sq.Eq{"trip_id": tripID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("RetractTypicalSecondsSamples: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// PruneTypicalSeconds deletes the running sums and samples of the days before the specified one
+func PruneTypicalSeconds(node sqalx.Node, before time.Time) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("typical_seconds_bucket").
+		Where(sq.Lt{"day": before}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("PruneTypicalSeconds: " + err.Error())
+	}
+
+	_, err = sdb.Delete("typical_seconds_trip").
+		Where("trip_id IN (SELECT id FROM trip WHERE start_time < ?)", before).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("PruneTypicalSeconds: " + err.Error())
+	}
+
+	_, err = sdb.Delete("typical_seconds_sample").
+		Where(sq.Lt{"day": before}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("PruneTypicalSeconds: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// SumTypicalSecondsBuckets returns the running sums of the days in the specified interval,
+// aggregated per connection or transfer, weekday and hour
+func SumTypicalSecondsBuckets(node sqalx.Node, start time.Time, end time.Time) ([]*TypicalSecondsSum, error) {
+	sums := []*TypicalSecondsSum{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return sums, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sdb.Select("kind", "station_id", "from_id", "to_id",
+		"CAST(EXTRACT(DOW FROM day) AS INT)", "hour", "SUM(numerator)", "SUM(denominator)").
+		From("typical_seconds_bucket").
+		Where(sq.GtOrEq{"day": start}, sq.Lt{"day": end}).
+		GroupBy("kind", "station_id", "from_id", "to_id", "EXTRACT(DOW FROM day)", "hour").
+		Having("SUM(denominator) > 0").
+		RunWith(tx).Query()
+	if err != nil {
+		return sums, fmt.Errorf("SumTypicalSecondsBuckets: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sum TypicalSecondsSum
+		err := rows.Scan(
+			&sum.Kind,
+			&sum.Station,
+			&sum.From,
+			&sum.To,
+			&sum.Weekday,
+			&sum.Hour,
+			&sum.Numerator,
+			&sum.Denominator)
+		if err != nil {
+			return sums, fmt.Errorf("SumTypicalSecondsBuckets: %s", err)
+		}
+		sums = append(sums, &sum)
+	}
+	if err := rows.Err(); err != nil {
+		return sums, fmt.Errorf("SumTypicalSecondsBuckets: %s", err)
+	}
+	return sums, nil
+}
+
+// ConnectionHourlyTimes contains the typical times of a Connection at a specific weekday and hour
+type ConnectionHourlyTimes struct {
+	Connection            *Connection
+	Weekday               time.Weekday
+	Hour                  int
+	TypicalWaitingSeconds int
+	TypicalStopSeconds    int
+	TypicalSeconds        int
+}
+
+// HourlyTimes returns the typical times of the connection at the specified weekday and hour
+func (connection *Connection) HourlyTimes(node sqalx.Node, weekday time.Weekday, hour int) (*ConnectionHourlyTimes, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Commit() // read-only tx
+
+	times := ConnectionHourlyTimes{
+		Connection: connection,
+		Weekday:    weekday,
+		Hour:       hour,
+	}
+	err = sdb.Select("typ_wait_time", "typ_stop_time", "typ_time").
+		From("connection_hourly_time").
+		Where(sq.Eq{"from_station": connection.From.ID},
+			sq.Eq{"to_station": connection.To.ID},
+			sq.Eq{"weekday": int(weekday)},
+			sq.Eq{"hour": hour}).
+		RunWith(tx).QueryRow().
+		Scan(&times.TypicalWaitingSeconds, &times.TypicalStopSeconds, &times.TypicalSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("HourlyTimes: %s", err)
+	}
+	return &times, nil
+}
+
+// TypicalSecondsAt returns the typical travel and stop times of the connection at the specified time,
+// falling back to the overall typical times when there is no hour-specific data
+func (connection *Connection) TypicalSecondsAt(node sqalx.Node, at time.Time) (travel int, stop int) {
+	if loc, err := time.LoadLocation(connection.From.Network.Timezone); err == nil {
+		at = at.In(loc)
+	}
+	times, err := connection.HourlyTimes(node, at.Weekday(), at.Hour())
+	if err != nil {
+		return connection.TypicalSeconds, connection.TypicalStopSeconds
+	}
+	return times.TypicalSeconds, times.TypicalStopSeconds
+}
+
+// Update adds or updates the hourly times
+func (times *ConnectionHourlyTimes) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("connection_hourly_time").
+		Columns("from_station", "to_station", "weekday", "hour", "typ_wait_time", "typ_stop_time", "typ_time").
+		Values(times.Connection.From.ID, times.Connection.To.ID, int(times.Weekday), times.Hour,
+			times.TypicalWaitingSeconds, times.TypicalStopSeconds, times.TypicalSeconds).
+		Suffix("ON CONFLICT (from_station, to_station, weekday, hour) DO UPDATE SET typ_wait_time = ?, typ_stop_time = ?, typ_time = ?",
+			times.TypicalWaitingSeconds, times.TypicalStopSeconds, times.TypicalSeconds).
+		RunWith(tx).Exec()
+	if err != nil {
+		return errors.New("AddConnectionHourlyTimes: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// DeleteAllConnectionHourlyTimes deletes the hourly times of all connections
+func DeleteAllConnectionHourlyTimes(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("connection_hourly_time").
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("DeleteAllConnectionHourlyTimes: %s", err)
+	}
+	return tx.Commit()
+}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 
@@ -40,13 +41,15 @@ func getWiFiAPsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*WiFiAP
 
 	for rows.Next() {
 		var wiFiAP WiFiAP
+		var line sql.NullString
 		err := rows.Scan(
 			&wiFiAP.BSSID,
 			&wiFiAP.SSID,
-			&wiFiAP.Line)
+			&line)
 		if err != nil {
 			return wiFiAPs, fmt.Errorf("getWiFiAPsWithSelect: %s", err)
 		}
+		wiFiAP.Line = line.String
 		wiFiAPs = append(wiFiAPs, &wiFiAP)
 	}
 	if err := rows.Err(); err != nil {
@@ -85,7 +88,7 @@ func (wiFiAP *WiFiAP) Update(node sqalx.Node) error {
 	defer tx.Rollback()
 
 	_, err = sdb.Insert("wifiap").
-		Columns("bssid", "name").
+		Columns("bssid", "ssid").
 		Values(wiFiAP.BSSID, wiFiAP.SSID).
 		Suffix("ON CONFLICT (bssid) DO UPDATE SET ssid = ?",
 			wiFiAP.SSID).
@@ -112,3 +115,64 @@ func (wiFiAP *WiFiAP) Delete(node sqalx.Node) error {
 	}
 	return tx.Commit()
 }
+
+// AddToStation associates the wiFiAP with the specified station, adding the wiFiAP if it doesn't exist.
+// line may be nil when the AP can't be associated with a specific line of the station
+func (wiFiAP *WiFiAP) AddToStation(node sqalx.Node, station *Station, line *Line) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = wiFiAP.Update(tx)
+	if err != nil {
+		return err
+	}
+
+	var lineID sql.NullString
+	if line != nil {
+		lineID = sql.NullString{String: line.ID, Valid: true}
+	}
+
+	_, err = sdb.Insert("station_has_wifiap").
+		Columns("station_id", "bssid", "line_id").
+		Values(station.ID, wiFiAP.BSSID, lineID).
+		Suffix("ON CONFLICT (station_id, bssid) DO UPDATE SET line_id = ?",
+			lineID).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("AddWiFiAPToStation: %s", err)
+	}
+	return tx.Commit()
+}
+
+// RemoveFromStation dissociates the wiFiAP from the specified station.
+// The wiFiAP is deleted once it is no longer associated with any station
+func (wiFiAP *WiFiAP) RemoveFromStation(node sqalx.Node, station *Station) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("station_has_wifiap").
+		Where(sq.Eq{"station_id": station.ID}).
+		Where(sq.Eq{"bssid": wiFiAP.BSSID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveWiFiAPFromStation: %s", err)
+	}
+
+	count, err := countWithSelect(tx, "station_has_wifiap", sq.Eq{"bssid": wiFiAP.BSSID})
+	if err != nil {
+		return fmt.Errorf("RemoveWiFiAPFromStation: %s", err)
+	}
+	if count == 0 {
+		err = wiFiAP.Delete(tx)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
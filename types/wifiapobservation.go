@@ -0,0 +1,119 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+)
+
+// WiFiAPObservation is a WiFi AP that a client observed while it was confident of being in a station
+type WiFiAPObservation struct {
+	ID        string
+	Submitter *APIPair
+	Station   *Station
+	BSSID     string
+	SSID      string
+	// Level is the signal strength in dBm
+	Level int
+	Time  time.Time
+}
+
+// WiFiAPObservationSummary aggregates the observations of an AP in a station
+type WiFiAPObservationSummary struct {
+	BSSID     string
+	SSID      string
+	StationID string
+	// Submitters is the number of distinct API pairs that observed the AP in the station
+	Submitters   int
+	Observations int
+	AverageLevel float64
+}
+
+// GetWiFiAPObservationSummariesSince returns the summaries of the observations made since the specified time,
+// one for each AP and station where it was observed
+func GetWiFiAPObservationSummariesSince(node sqalx.Node, since time.Time) ([]*WiFiAPObservationSummary, error) {
+	summaries := []*WiFiAPObservationSummary{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return summaries, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sdb.Select("bssid", "MAX(ssid)", "station_id", "COUNT(DISTINCT submitter)", "COUNT(*)", "AVG(level)").
+		From("wifiap_observation").
+		Where(sq.GtOrEq{"timestamp": since}).
+		GroupBy("bssid", "station_id").
+		RunWith(tx).Query()
+	if err != nil {
+		return summaries, fmt.Errorf("GetWiFiAPObservationSummariesSince: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var summary WiFiAPObservationSummary
+		err := rows.Scan(
+			&summary.BSSID,
+			&summary.SSID,
+			&summary.StationID,
+			&summary.Submitters,
+			&summary.Observations,
+			&summary.AverageLevel)
+		if err != nil {
+			return summaries, fmt.Errorf("GetWiFiAPObservationSummariesSince: %s", err)
+		}
+		summaries = append(summaries, &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return summaries, fmt.Errorf("GetWiFiAPObservationSummariesSince: %s", err)
+	}
+	return summaries, nil
+}
+
+// DeleteWiFiAPObservationsOlderThan deletes the observations made before the specified time,
+// returning the number of deleted observations
+func DeleteWiFiAPObservationsOlderThan(node sqalx.Node, before time.Time) (int, error) {
+	tx, err := node.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := sdb.Delete("wifiap_observation").
+		Where(sq.Lt{"timestamp": before}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return 0, fmt.Errorf("DeleteWiFiAPObservationsOlderThan: %s", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("DeleteWiFiAPObservationsOlderThan: %s", err)
+	}
+	return int(affected), tx.Commit()
+}
+
+// Update adds or updates the WiFiAPObservation
+func (observation *WiFiAPObservation) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("wifiap_observation").
+		Columns("id", "submitter", "station_id", "bssid", "ssid", "level", "timestamp").
+		Values(observation.ID, observation.Submitter.Key, observation.Station.ID,
+			observation.BSSID, observation.SSID, observation.Level, observation.Time).
+		Suffix("ON CONFLICT (id) DO UPDATE SET submitter = ?, station_id = ?, bssid = ?, ssid = ?, level = ?, timestamp = ?",
+			observation.Submitter.Key, observation.Station.ID,
+			observation.BSSID, observation.SSID, observation.Level, observation.Time).
+		RunWith(tx).Exec()
+
+	if err != nil {
+		return errors.New("AddWiFiAPObservation: " + err.Error())
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,193 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gbl08ma/sqalx"
+	"github.com/lib/pq"
+)
+
+// WiFiAPSuggestion is a suggested change to the WiFi APs of a station, inferred from user feedback
+// or from crowd-sourced observations, which must be reviewed by an admin before being published
+type WiFiAPSuggestion struct {
+	ID      string
+	BSSID   string
+	SSID    string
+	Station *Station
+	Action  WiFiAPSuggestionAction
+	Status  WiFiAPSuggestionStatus
+	Source  WiFiAPSuggestionSource
+	// Submitters is the number of distinct API pairs whose feedback or observations support this suggestion
+	Submitters int
+	// FeedbackIDs contains the IDs of the feedback supporting this suggestion, when its source is feedback
+	FeedbackIDs pq.StringArray
+	Created     time.Time
+	Updated     time.Time
+}
+
+// WiFiAPSuggestionAction is the change proposed by a WiFiAPSuggestion
+type WiFiAPSuggestionAction string
+
+const (
+	// WiFiAPSuggestionAdd suggests associating the AP with the station
+	WiFiAPSuggestionAdd WiFiAPSuggestionAction = "add"
+	// WiFiAPSuggestionRemove suggests dissociating the AP from the station
+	WiFiAPSuggestionRemove WiFiAPSuggestionAction = "remove"
+)
+
+// WiFiAPSuggestionSource identifies the data a WiFiAPSuggestion was inferred from
+type WiFiAPSuggestionSource string
+
+const (
+	// WiFiAPSuggestionFromFeedback is the source of suggestions inferred from S2LS feedback
+	WiFiAPSuggestionFromFeedback WiFiAPSuggestionSource = "feedback"
+	// WiFiAPSuggestionFromObservations is the source of suggestions inferred from crowd-sourced WiFi AP observations
+	WiFiAPSuggestionFromObservations WiFiAPSuggestionSource = "observations"
+)
+
+// WiFiAPSuggestionStatus corresponds to the state of a WiFiAPSuggestion in the review workflow
+type WiFiAPSuggestionStatus string
+
+const (
+	// WiFiAPSuggestionPending is the status of suggestions awaiting review
+	WiFiAPSuggestionPending WiFiAPSuggestionStatus = "pending"
+	// WiFiAPSuggestionApproved is the status of suggestions that were approved but not yet published
+	WiFiAPSuggestionApproved WiFiAPSuggestionStatus = "approved"
+	// WiFiAPSuggestionRejected is the status of suggestions that were rejected
+	WiFiAPSuggestionRejected WiFiAPSuggestionStatus = "rejected"
+	// WiFiAPSuggestionPublished is the status of suggestions that were applied to a published dataset
+	WiFiAPSuggestionPublished WiFiAPSuggestionStatus = "published"
+)
+
+// GetWiFiAPSuggestions returns a slice with all registered WiFiAPSuggestions
+func GetWiFiAPSuggestions(node sqalx.Node) ([]*WiFiAPSuggestion, error) {
+	return getWiFiAPSuggestionsWithSelect(node, sdb.Select())
+}
+
+// GetWiFiAPSuggestionsWithStatus returns a slice with the WiFiAPSuggestions with the specified status,
+// with the most supported ones first
+func GetWiFiAPSuggestionsWithStatus(node sqalx.Node, status WiFiAPSuggestionStatus) ([]*WiFiAPSuggestion, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"status": status}).
+		OrderBy("submitters DESC", "created ASC")
+	return getWiFiAPSuggestionsWithSelect(node, s)
+}
+
+// GetUnpublishedWiFiAPSuggestions returns a slice with the WiFiAPSuggestions from the specified source
+// that were not published yet, including the rejected ones
+func GetUnpublishedWiFiAPSuggestions(node sqalx.Node, source WiFiAPSuggestionSource) ([]*WiFiAPSuggestion, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"source": source}).
+		Where(sq.NotEq{"status": WiFiAPSuggestionPublished})
+	return getWiFiAPSuggestionsWithSelect(node, s)
+}
+
+func getWiFiAPSuggestionsWithSelect(node sqalx.Node, sbuilder sq.SelectBuilder) ([]*WiFiAPSuggestion, error) {
+	suggestions := []*WiFiAPSuggestion{}
+
+	tx, err := node.Beginx()
+	if err != nil {
+		return suggestions, err
+	}
+	defer tx.Commit() // read-only tx
+
+	rows, err := sbuilder.Columns("id", "bssid", "ssid", "station_id", "action", "status", "source",
+		"submitters", "feedback_ids", "created", "updated").
+		From("wifiap_suggestion").
+		RunWith(tx).Query()
+	if err != nil {
+		return suggestions, fmt.Errorf("getWiFiAPSuggestionsWithSelect: %s", err)
+	}
+	defer rows.Close()
+
+	stationIDs := []string{}
+	for rows.Next() {
+		var suggestion WiFiAPSuggestion
+		var stationID string
+		err := rows.Scan(
+			&suggestion.ID,
+			&suggestion.BSSID,
+			&suggestion.SSID,
+			&stationID,
+			&suggestion.Action,
+			&suggestion.Status,
+			&suggestion.Source,
+			&suggestion.Submitters,
+			&suggestion.FeedbackIDs,
+			&suggestion.Created,
+			&suggestion.Updated)
+		if err != nil {
+			return suggestions, fmt.Errorf("getWiFiAPSuggestionsWithSelect: %s", err)
+		}
+		suggestions = append(suggestions, &suggestion)
+		stationIDs = append(stationIDs, stationID)
+	}
+	if err := rows.Err(); err != nil {
+		return suggestions, fmt.Errorf("getWiFiAPSuggestionsWithSelect: %s", err)
+	}
+	for i := range suggestions {
+		suggestions[i].Station, err = GetStation(tx, stationIDs[i])
+		if err != nil {
+			return suggestions, fmt.Errorf("getWiFiAPSuggestionsWithSelect: %s", err)
+		}
+	}
+	return suggestions, nil
+}
+
+// GetWiFiAPSuggestion returns the WiFiAPSuggestion with the given ID
+func GetWiFiAPSuggestion(node sqalx.Node, id string) (*WiFiAPSuggestion, error) {
+	s := sdb.Select().
+		Where(sq.Eq{"id": id})
+	suggestions, err := getWiFiAPSuggestionsWithSelect(node, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
+		return nil, errors.New("WiFiAPSuggestion not found")
+	}
+	return suggestions[0], nil
+}
+
+// Update adds or updates the WiFiAPSuggestion
+func (suggestion *WiFiAPSuggestion) Update(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Insert("wifiap_suggestion").
+		Columns("id", "bssid", "ssid", "station_id", "action", "status", "source",
+			"submitters", "feedback_ids", "created", "updated").
+		Values(suggestion.ID, suggestion.BSSID, suggestion.SSID, suggestion.Station.ID, suggestion.Action, suggestion.Status, suggestion.Source,
+			suggestion.Submitters, suggestion.FeedbackIDs, suggestion.Created, suggestion.Updated).
+		Suffix("ON CONFLICT (id) DO UPDATE SET bssid = ?, ssid = ?, station_id = ?, action = ?, status = ?, source = ?, submitters = ?, feedback_ids = ?, created = ?, updated = ?",
+			suggestion.BSSID, suggestion.SSID, suggestion.Station.ID, suggestion.Action, suggestion.Status, suggestion.Source,
+			suggestion.Submitters, suggestion.FeedbackIDs, suggestion.Created, suggestion.Updated).
+		RunWith(tx).Exec()
+
+	if err != nil {
+		return errors.New("AddWiFiAPSuggestion: " + err.Error())
+	}
+	return tx.Commit()
+}
+
+// Delete deletes the WiFiAPSuggestion
+func (suggestion *WiFiAPSuggestion) Delete(node sqalx.Node) error {
+	tx, err := node.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = sdb.Delete("wifiap_suggestion").
+		Where(sq.Eq{"id": suggestion.ID}).
+		RunWith(tx).Exec()
+	if err != nil {
+		return fmt.Errorf("RemoveWiFiAPSuggestion: %s", err)
+	}
+	return tx.Commit()
+}
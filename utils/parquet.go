@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ParquetColumnType is the type of a column in a file written by WriteParquet
+type ParquetColumnType int
+
+const (
+	// ParquetInt64 columns hold int or int64 values
+	ParquetInt64 ParquetColumnType = iota
+	// ParquetString columns hold UTF-8 string values
+	ParquetString
+)
+
+// ParquetColumn describes a column in a file written by WriteParquet
+type ParquetColumn struct {
+	Name string
+	Type ParquetColumnType
+}
+
+// parquet physical types and other enum values from the Parquet thrift definition
+const (
+	parquetTypeInt64      = 2
+	parquetTypeByteArray  = 6
+	parquetRepRequired    = 0
+	parquetConvertedUTF8  = 0
+	parquetEncodingPlain  = 0
+	parquetEncodingRLE    = 3
+	parquetCodecNone      = 0
+	parquetPageTypeData   = 0
+	parquetFormatVersion  = 1
+	parquetCreatedBy      = "disturbancesmlx"
+	parquetMagic          = "PAR1"
+	thriftCompactI32      = 5
+	thriftCompactI64      = 6
+	thriftCompactBinary   = 8
+	thriftCompactList     = 9
+	thriftCompactStruct   = 12
+	thriftCompactFieldEnd = 0
+)
+
+// WriteParquet writes rows as an uncompressed, flat Parquet file with a single row group.
+// All columns are required (non-nullable) and each row must contain one value per column,
+// of a Go type matching the column type
+func WriteParquet(w io.Writer, columns []ParquetColumn, rows [][]interface{}) error {
+	file := new(bytes.Buffer)
+	file.WriteString(parquetMagic)
+
+	type chunkInfo struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunkInfo, len(columns))
+
+	for colIdx, column := range columns {
+		data := new(bytes.Buffer)
+		for rowIdx, row := range rows {
+			if len(row) != len(columns) {
+				return fmt.Errorf("WriteParquet: row %d has %d values, expected %d", rowIdx, len(row), len(columns))
+			}
+			switch column.Type {
+			case ParquetInt64:
+				var v int64
+				switch value := row[colIdx].(type) {
+				case int:
+					v = int64(value)
+				case int64:
+					v = value
+				default:
+					return fmt.Errorf("WriteParquet: value for column %s in row %d is not an integer", column.Name, rowIdx)
+				}
+				binary.Write(data, binary.LittleEndian, v)
+			case ParquetString:
+				value, ok := row[colIdx].(string)
+				if !ok {
+					return fmt.Errorf("WriteParquet: value for column %s in row %d is not a string", column.Name, rowIdx)
+				}
+				binary.Write(data, binary.LittleEndian, uint32(len(value)))
+				data.WriteString(value)
+			}
+		}
+
+		header := newThriftCompactWriter()
+		header.i32Field(1, parquetPageTypeData)
+		header.i32Field(2, int32(data.Len()))
+		header.i32Field(3, int32(data.Len()))
+		header.structField(5)
+		header.i32Field(1, int32(len(rows)))
+		header.i32Field(2, parquetEncodingPlain)
+		header.i32Field(3, parquetEncodingRLE)
+		header.i32Field(4, parquetEncodingRLE)
+		header.structEnd()
+		header.structEnd()
+
+		chunks[colIdx].offset = int64(file.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(data.Bytes())
+		chunks[colIdx].size = int64(file.Len()) - chunks[colIdx].offset
+	}
+
+	totalSize := int64(0)
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+
+	meta := newThriftCompactWriter()
+	meta.i32Field(1, parquetFormatVersion)
+	meta.listField(2, thriftCompactStruct, len(columns)+1)
+	meta.structBegin()
+	meta.binaryField(4, "schema")
+	meta.i32Field(5, int32(len(columns)))
+	meta.structEnd()
+	for _, column := range columns {
+		meta.structBegin()
+		if column.Type == ParquetString {
+			meta.i32Field(1, parquetTypeByteArray)
+		} else {
+			meta.i32Field(1, parquetTypeInt64)
+		}
+		meta.i32Field(3, parquetRepRequired)
+		meta.binaryField(4, column.Name)
+		if column.Type == ParquetString {
+			meta.i32Field(6, parquetConvertedUTF8)
+		}
+		meta.structEnd()
+	}
+	meta.i64Field(3, int64(len(rows)))
+	meta.listField(4, thriftCompactStruct, 1)
+	meta.structBegin()
+	meta.listField(1, thriftCompactStruct, len(columns))
+	for colIdx, column := range columns {
+		meta.structBegin()
+		meta.i64Field(2, chunks[colIdx].offset)
+		meta.structField(3)
+		if column.Type == ParquetString {
+			meta.i32Field(1, parquetTypeByteArray)
+		} else {
+			meta.i32Field(1, parquetTypeInt64)
+		}
+		meta.listField(2, thriftCompactI32, 1)
+		meta.varint(zigzag(parquetEncodingPlain))
+		meta.listField(3, thriftCompactBinary, 1)
+		meta.binary(column.Name)
+		meta.i32Field(4, parquetCodecNone)
+		meta.i64Field(5, int64(len(rows)))
+		meta.i64Field(6, chunks[colIdx].size)
+		meta.i64Field(7, chunks[colIdx].size)
+		meta.i64Field(9, chunks[colIdx].offset)
+		meta.structEnd()
+		meta.structEnd()
+	}
+	meta.i64Field(2, totalSize)
+	meta.i64Field(3, int64(len(rows)))
+	meta.structEnd()
+	meta.binaryField(6, parquetCreatedBy)
+	meta.structEnd()
+
+	file.Write(meta.buf.Bytes())
+	binary.Write(file, binary.LittleEndian, uint32(meta.buf.Len()))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// thriftCompactWriter implements the subset of the Thrift compact protocol needed to write Parquet metadata
+type thriftCompactWriter struct {
+	buf          *bytes.Buffer
+	lastFieldIDs []int16
+}
+
+func newThriftCompactWriter() *thriftCompactWriter {
+	return &thriftCompactWriter{
+		buf:          new(bytes.Buffer),
+		lastFieldIDs: []int16{0},
+	}
+}
+
+func zigzag(n int64) uint64 {
+	return uint64((n << 1) ^ (n >> 63))
+}
+
+func (t *thriftCompactWriter) varint(n uint64) {
+	for n >= 0x80 {
+		t.buf.WriteByte(byte(n) | 0x80)
+		n >>= 7
+	}
+	t.buf.WriteByte(byte(n))
+}
+
+func (t *thriftCompactWriter) fieldHeader(id int16, fieldType byte) {
+	last := &t.lastFieldIDs[len(t.lastFieldIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		t.buf.WriteByte(fieldType)
+		t.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (t *thriftCompactWriter) binary(s string) {
+	t.varint(uint64(len(s)))
+	t.buf.WriteString(s)
+}
+
+func (t *thriftCompactWriter) i32Field(id int16, v int32) {
+	t.fieldHeader(id, thriftCompactI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thriftCompactWriter) i64Field(id int16, v int64) {
+	t.fieldHeader(id, thriftCompactI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thriftCompactWriter) binaryField(id int16, s string) {
+	t.fieldHeader(id, thriftCompactBinary)
+	t.binary(s)
+}
+
+func (t *thriftCompactWriter) listField(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftCompactList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.buf.WriteByte(0xf0 | elemType)
+		t.varint(uint64(size))
+	}
+}
+
+// structField begins a struct-typed field. It must be matched by a call to structEnd
+func (t *thriftCompactWriter) structField(id int16) {
+	t.fieldHeader(id, thriftCompactStruct)
+	t.structBegin()
+}
+
+// structBegin begins a struct that is not a field (e.g. a list element). It must be matched by a call to structEnd
+func (t *thriftCompactWriter) structBegin() {
+	t.lastFieldIDs = append(t.lastFieldIDs, 0)
+}
+
+func (t *thriftCompactWriter) structEnd() {
+	t.buf.WriteByte(thriftCompactFieldEnd)
+	if len(t.lastFieldIDs) > 1 {
+		t.lastFieldIDs = t.lastFieldIDs[:len(t.lastFieldIDs)-1]
+	}
+}
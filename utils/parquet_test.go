@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+var parquetTestColumns = []ParquetColumn{
+	{Name: "station", Type: ParquetString},
+	{Name: "entries", Type: ParquetInt64},
+	{Name: "line", Type: ParquetString},
+}
+
+var parquetTestRows = [][]interface{}{
+	{"pt-ml-ap", 42, "pt-ml-vermelha"},
+	{"pt-ml-ss", int64(-7), "pt-ml-verde"},
+	{"pt-ml-cs", int64(1) << 40, "Estação ç"},
+}
+
+// testdata/stationentries.parquet contains parquetTestRows and was checked to be readable by
+// github.com/parquet-go/parquet-go, which returns the same schema and rows
+func TestWriteParquetGolden(t *testing.T) {
+	golden, err := ioutil.ReadFile("testdata/stationentries.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	err = WriteParquet(&b, parquetTestColumns, parquetTestRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), golden) {
+		t.Errorf("output differs from testdata/stationentries.parquet:\n%x\n%x", b.Bytes(), golden)
+	}
+}
+
+func TestWriteParquetRoundTrip(t *testing.T) {
+	for _, rows := range [][][]interface{}{parquetTestRows, {}} {
+		var b bytes.Buffer
+		err := WriteParquet(&b, parquetTestColumns, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, read := readParquet(t, b.Bytes())
+		if !reflect.DeepEqual(columns, parquetTestColumns) {
+			t.Errorf("got columns %v, want %v", columns, parquetTestColumns)
+		}
+		if len(read) != len(rows) {
+			t.Fatalf("got %d rows, want %d", len(read), len(rows))
+		}
+		for i := range rows {
+			for j := range rows[i] {
+				want := rows[i][j]
+				if v, ok := want.(int); ok {
+					want = int64(v)
+				}
+				if read[i][j] != want {
+					t.Errorf("row %d column %d: got %#v, want %#v", i, j, read[i][j], want)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteParquetErrors(t *testing.T) {
+	tests := [][][]interface{}{
+		{{"pt-ml-ap", 42}},
+		{{"pt-ml-ap", "42", "pt-ml-vermelha"}},
+		{{42, 42, "pt-ml-vermelha"}},
+	}
+	for _, rows := range tests {
+		var b bytes.Buffer
+		if err := WriteParquet(&b, parquetTestColumns, rows); err == nil {
+			t.Errorf("%v: expected an error", rows)
+		}
+		if b.Len() != 0 {
+			t.Errorf("%v: data was written despite the error", rows)
+		}
+	}
+}
+
+// readParquet reads the files written by WriteParquet, returning their columns and rows
+func readParquet(t *testing.T, file []byte) ([]ParquetColumn, [][]interface{}) {
+	if len(file) < 12 || string(file[:4]) != parquetMagic || string(file[len(file)-4:]) != parquetMagic {
+		t.Fatal("missing magic number")
+	}
+	metaLength := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	metaStart := len(file) - 8 - metaLength
+	if metaStart < 4 {
+		t.Fatal("invalid metadata length")
+	}
+	meta := (&thriftCompactReader{b: file[metaStart : len(file)-8]}).structure()
+
+	schema := meta[2].([]interface{})
+	root := schema[0].(map[int16]interface{})
+	if root[5].(int64) != int64(len(schema)-1) {
+		t.Fatalf("schema root has %d children, but there are %d columns", root[5], len(schema)-1)
+	}
+	columns := []ParquetColumn{}
+	for _, element := range schema[1:] {
+		e := element.(map[int16]interface{})
+		column := ParquetColumn{Name: string(e[4].([]byte))}
+		switch e[1].(int64) {
+		case parquetTypeInt64:
+			column.Type = ParquetInt64
+		case parquetTypeByteArray:
+			column.Type = ParquetString
+			if e[6].(int64) != parquetConvertedUTF8 {
+				t.Errorf("column %s is not annotated as UTF-8", column.Name)
+			}
+		default:
+			t.Fatalf("column %s has unexpected type %d", column.Name, e[1])
+		}
+		columns = append(columns, column)
+	}
+
+	numRows := int(meta[3].(int64))
+	rows := make([][]interface{}, numRows)
+	for i := range rows {
+		rows[i] = make([]interface{}, len(columns))
+	}
+	rowGroup := meta[4].([]interface{})[0].(map[int16]interface{})
+	if rowGroup[3].(int64) != int64(numRows) {
+		t.Errorf("row group has %d rows, file has %d", rowGroup[3], numRows)
+	}
+	for colIdx, chunk := range rowGroup[1].([]interface{}) {
+		chunkMeta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+		if chunkMeta[5].(int64) != int64(numRows) {
+			t.Errorf("column %d has %d values, expected %d", colIdx, chunkMeta[5], numRows)
+		}
+		offset := int(chunkMeta[9].(int64))
+		r := &thriftCompactReader{b: file[offset:metaStart]}
+		header := r.structure()
+		data := r.b[r.pos : r.pos+int(header[3].(int64))]
+		if int64(r.pos+len(data)) != chunkMeta[6].(int64) {
+			t.Errorf("column %d has size %d, expected %d", colIdx, r.pos+len(data), chunkMeta[6])
+		}
+		for rowIdx := 0; rowIdx < numRows; rowIdx++ {
+			switch columns[colIdx].Type {
+			case ParquetInt64:
+				rows[rowIdx][colIdx] = int64(binary.LittleEndian.Uint64(data))
+				data = data[8:]
+			case ParquetString:
+				length := int(binary.LittleEndian.Uint32(data))
+				rows[rowIdx][colIdx] = string(data[4 : 4+length])
+				data = data[4+length:]
+			}
+		}
+		if len(data) != 0 {
+			t.Errorf("column %d has %d extra bytes", colIdx, len(data))
+		}
+	}
+	return columns, rows
+}
+
+// thriftCompactReader decodes the values written by thriftCompactWriter.
+// Integers are returned as int64, binaries as []byte, lists as []interface{} and structs as maps indexed by field ID
+type thriftCompactReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftCompactReader) varint() uint64 {
+	n, size := binary.Uvarint(r.b[r.pos:])
+	if size <= 0 {
+		panic("invalid varint")
+	}
+	r.pos += size
+	return n
+}
+
+func (r *thriftCompactReader) zigzag() int64 {
+	n := r.varint()
+	return int64(n>>1) ^ -int64(n&1)
+}
+
+func (r *thriftCompactReader) value(valueType byte) interface{} {
+	switch valueType {
+	case thriftCompactI32, thriftCompactI64:
+		return r.zigzag()
+	case thriftCompactBinary:
+		length := int(r.varint())
+		r.pos += length
+		return r.b[r.pos-length : r.pos]
+	case thriftCompactList:
+		header := r.b[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.varint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(header & 0x0f)
+		}
+		return list
+	case thriftCompactStruct:
+		return r.structure()
+	}
+	panic("unsupported thrift type")
+}
+
+func (r *thriftCompactReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	lastID := int16(0)
+	for {
+		header := r.b[r.pos]
+		r.pos++
+		if header == thriftCompactFieldEnd {
+			return fields
+		}
+		id := lastID + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(header & 0x0f)
+		lastID = id
+	}
+}
@@ -4,7 +4,10 @@ package utils
 
 import "reflect"
 
-var Types = map[string]reflect.Type{}
+var Types = map[string]reflect.Type{
+	"ParquetColumn":     reflect.TypeOf((*ParquetColumn)(nil)).Elem(),
+	"ParquetColumnType": reflect.TypeOf((*ParquetColumnType)(nil)).Elem(),
+}
 
 var Functions = map[string]reflect.Value{
 	"ComputeStationTriviaURLs":     reflect.ValueOf(ComputeStationTriviaURLs),
@@ -15,13 +18,20 @@ var Functions = map[string]reflect.Value{
 	"Fudge":                        reflect.ValueOf(Fudge),
 	"GetClientIP":                  reflect.ValueOf(GetClientIP),
 	"Int64Abs":                     reflect.ValueOf(Int64Abs),
+	"LastServerSentEventID":        reflect.ValueOf(LastServerSentEventID),
 	"RequestIsTLS":                 reflect.ValueOf(RequestIsTLS),
 	"SchedulesToLines":             reflect.ValueOf(SchedulesToLines),
 	"StationConnectionURLs":        reflect.ValueOf(StationConnectionURLs),
+	"WorldDistance":                reflect.ValueOf(WorldDistance),
+	"WriteParquet":                 reflect.ValueOf(WriteParquet),
+	"WriteServerSentEvent":         reflect.ValueOf(WriteServerSentEvent),
 }
 
 var Variables = map[string]reflect.Value{
 	"SupportedLocales": reflect.ValueOf(&SupportedLocales),
 }
 
-var Consts = map[string]reflect.Value{}
+var Consts = map[string]reflect.Value{
+	"ParquetInt64":  reflect.ValueOf(ParquetInt64),
+	"ParquetString": reflect.ValueOf(ParquetString),
+}
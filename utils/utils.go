@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"net/http"
@@ -363,3 +364,47 @@ func Fudge(value, approximateTo int) int {
 
 	return value
 }
+
+// WorldDistance returns the great-circle distance in meters between two [latitude, longitude] coordinates
+func WorldDistance(a, b [2]float64) float64 {
+	const earthRadius = 6371000
+	lat1 := a[0] * math.Pi / 180
+	lat2 := b[0] * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b[1] - a[1]) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// WriteServerSentEvent writes an event in the text/event-stream format and flushes it to the client.
+// id and event are omitted when empty
+func WriteServerSentEvent(w http.ResponseWriter, id, event string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		b.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		b.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	_, err := w.Write([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// LastServerSentEventID returns the ID of the last event received by a reconnecting EventSource client
+func LastServerSentEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	// for clients that can't set headers, like some EventSource polyfills
+	return r.URL.Query().Get("lastEventId")
+}
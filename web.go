@@ -21,7 +21,7 @@ func WebServer() {
 
 	// main perturbacoes.pt website
 	website.Initialize(rootSqalxNode, webKeybox, webLog, reportHandler,
-		vehicleHandler, vehicleETAHandler, statsHandler, kiddie)
+		vehicleHandler, vehicleETAHandler, statsHandler, crowdingHandler, odMatrixHandler, retentionHandler, statusEventBroker, kiddie)
 
 	posplayKeybox, present := secrets.GetBox("posplay")
 	if !present {
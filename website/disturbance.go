@@ -34,8 +34,9 @@ func DisturbancePage(w http.ResponseWriter, r *http.Request) {
 
 	p := struct {
 		PageCommons
-		Disturbance *types.Disturbance
-		CanEdit     bool
+		Disturbance  *types.Disturbance
+		Alternatives []*compute.Route
+		CanEdit      bool
 	}{
 		CanEdit: hasSession && session.IsAdmin,
 	}
@@ -74,6 +75,14 @@ func DisturbancePage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if !p.Disturbance.UEnded {
+		p.Alternatives, err = compute.AlternativeRoutesCached(tx, p.Disturbance.Line, false)
+		if err != nil {
+			// the disturbance is more important than the alternatives, so show it without them
+			webLog.Println(err)
+		}
+	}
+
 	latestStatus := p.Disturbance.LatestStatus()
 	if latestStatus != nil {
 		imageType := ""
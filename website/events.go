@@ -0,0 +1,81 @@
+package website
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// StatusEventsStream streams line status changes using Server-Sent Events.
+// Events include HTML fragments rendered with the same templates as the pages, so they can be updated live
+func StatusEventsStream(w http.ResponseWriter, r *http.Request) {
+	officialOnly := ShowOfficialDataOnly(w, r)
+
+	err := statusEventBroker.Stream(w, r, func(event compute.StatusEvent) error {
+		return writeStatusEvent(w, event, officialOnly)
+	})
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func writeStatusEvent(w http.ResponseWriter, event compute.StatusEvent, officialOnly bool) error {
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // read-only tx
+
+	data := struct {
+		Line            string `json:"line"`
+		Down            bool   `json:"down"`
+		LineStatusHTML  string `json:"lineStatusHTML"`
+		DisturbanceHTML string `json:"disturbanceHTML,omitempty"`
+	}{
+		Line: event.Line.ID,
+	}
+
+	lineData := struct {
+		ID       string
+		Down     bool
+		Official bool
+		Minutes  int
+	}{
+		ID: event.Line.ID,
+	}
+	lineData.Down, lineData.Official, lineData.Minutes = lineStatus(tx, event.Line)
+	data.Down = lineData.Down
+
+	var buf bytes.Buffer
+	err = webtemplate.ExecuteTemplate(&buf, "component-linestatus.html", lineData)
+	if err != nil {
+		return err
+	}
+	data.LineStatusHTML = buf.String()
+
+	if event.Type != compute.StatusEventNewCondition {
+		disturbance, err := event.Line.LastDisturbance(tx, officialOnly)
+		if err == nil {
+			sort.Slice(disturbance.Statuses, func(j, k int) bool {
+				return disturbance.Statuses[j].Time.Before(disturbance.Statuses[k].Time)
+			})
+			buf.Reset()
+			err = webtemplate.ExecuteTemplate(&buf, "component-disturbance.html", disturbance)
+			if err != nil {
+				return err
+			}
+			data.DisturbanceHTML = buf.String()
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return utils.WriteServerSentEvent(w, event.ID, string(event.Type), encoded)
+}
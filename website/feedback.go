@@ -0,0 +1,111 @@
+package website
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/underlx/disturbancesmlx/discordbot"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// FeedbackPage serves the internal page for triaging user feedback
+func FeedbackPage(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequestIsTLS(r) && !DEBUG {
+		w.WriteHeader(http.StatusUpgradeRequired)
+		return
+	}
+
+	hasSession, session, err := AuthGetSession(w, r, true)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if !hasSession {
+		return
+	} else if !session.IsAdmin {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	status := types.FeedbackStatus(r.URL.Query().Get("status"))
+	validStatus := false
+	for _, s := range types.FeedbackStatuses {
+		if status == s {
+			validStatus = true
+			break
+		}
+	}
+	if !validStatus {
+		status = types.FeedbackNew
+	}
+
+	message := ""
+	if r.Method == http.MethodPost && r.ParseForm() == nil {
+		feedback, err := types.GetFeedback(tx, r.Form.Get("feedback"))
+		if err != nil {
+			message = "Feedback " + r.Form.Get("feedback") + " not found"
+		} else {
+			switch r.Form.Get("action") {
+			case "setStatus":
+				for _, s := range types.FeedbackStatuses {
+					if r.Form.Get("status") == string(s) {
+						feedback.Status = s
+					}
+				}
+			case "assign":
+				feedback.Assignee = strings.TrimSpace(r.Form.Get("assignee"))
+			}
+			err = feedback.Update(tx)
+			if err != nil {
+				webLog.Println(err)
+				message = "Failed to update feedback " + feedback.ID + ": " + err.Error()
+			} else {
+				message = "Feedback " + feedback.ID + " updated"
+				discordbot.TheFeedbackTriage.FeedbackUpdated(feedback)
+			}
+		}
+	}
+
+	p := struct {
+		PageCommons
+		Message   string
+		Username  string
+		Status    types.FeedbackStatus
+		Statuses  []types.FeedbackStatus
+		Feedbacks []*types.Feedback
+	}{
+		Message:  message,
+		Username: session.DisplayName,
+		Status:   status,
+		Statuses: types.FeedbackStatuses,
+	}
+
+	p.PageCommons, err = InitPageCommons(tx, w, r, "Feedback dos utilizadores")
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.Feedbacks, err = types.GetFeedbacksWithStatus(tx, status)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	err = webtemplate.ExecuteTemplate(w, "internalfeedback.html", p)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
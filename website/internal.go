@@ -3,8 +3,11 @@ package website
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/gbl08ma/sqalx"
+
 	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/discordbot"
@@ -43,11 +46,30 @@ func InternalPage(w http.ResponseWriter, r *http.Request) {
 		switch r.Form.Get("action") {
 		case "reloadTemplates":
 			vehicleHandler.ClearTypicalSecondsCache()
+			compute.ClearTripNormalizerCache()
 			ReloadTemplates()
 			message = "Templates reloaded"
 		case "computeMsgTypes":
 			compute.UpdateStatusMsgTypes(tx)
 			message = "Line status types recomputed"
+		case "normalizeTrips":
+			go func() {
+				err := compute.NormalizeAllTrips(rootSqalxNode, 10*time.Millisecond)
+				if err != nil {
+					webLog.Println(err)
+				}
+			}()
+			message = "Trip normalization started"
+		case "retentionDryRun":
+			report, err := retentionHandler.Run(true)
+			if err != nil {
+				webLog.Println(err)
+				message = "Retention dry run failed: " + err.Error()
+			} else {
+				message = report.String()
+			}
+		case "revokePair", "unrevokePair", "setPairScopes":
+			message = handlePairAdminAction(tx, r, session.DisplayName)
 		case "killDiscordBot":
 			discordbot.Stop()
 			message = "Discord bot stopped"
@@ -78,6 +100,12 @@ func InternalPage(w http.ResponseWriter, r *http.Request) {
 		PassengerReadings    []compute.PassengerReading
 		TrainETAs            []compute.TrainETA
 		UsersOnlineInNetwork int
+		RetentionPolicy      compute.RetentionPolicy
+		RetentionReport      *compute.RetentionReport
+		PairScopes           []string
+		Pair                 *types.APIPair
+		RecentlySeenPairs    []*types.APIPair
+		RevokedPairs         []*types.APIPair
 	}{
 		Message:              message,
 		UserID:               session.UserID,
@@ -85,6 +113,9 @@ func InternalPage(w http.ResponseWriter, r *http.Request) {
 		PassengerReadings:    vehicleHandler.Readings(),
 		UsersOnlineInNetwork: statsHandler.OITInNetwork(n, 0),
 		TrainETAs:            []compute.TrainETA{},
+		RetentionPolicy:      retentionHandler.Policy(),
+		RetentionReport:      retentionHandler.LastReport(),
+		PairScopes:           types.PairScopes,
 	}
 
 	p.PageCommons, err = InitPageCommons(tx, w, r, "Página interna")
@@ -161,6 +192,26 @@ func InternalPage(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
+	if key := r.FormValue("pair"); key != "" {
+		p.Pair, err = types.GetPair(tx, key)
+		if err != nil {
+			p.Pair = nil
+			p.Message = "Pair " + key + " not found"
+		}
+	}
+	p.RecentlySeenPairs, err = types.GetRecentlySeenPairs(tx, 20)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	p.RevokedPairs, err = types.GetRevokedPairs(tx)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	p.Dependencies.Charts = true
 	err = webtemplate.ExecuteTemplate(w, "internal.html", p)
 	if err != nil {
@@ -168,3 +219,48 @@ func InternalPage(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+// handlePairAdminAction handles the API pair management actions of the internal page, returning a message for the user
+func handlePairAdminAction(tx sqalx.Node, r *http.Request, admin string) string {
+	pair, err := types.GetPair(tx, r.Form.Get("pair"))
+	if err != nil {
+		return "Pair " + r.Form.Get("pair") + " not found"
+	}
+
+	switch r.Form.Get("action") {
+	case "revokePair":
+		reason := strings.TrimSpace(r.Form.Get("reason"))
+		if reason == "" {
+			return "A revocation reason is required"
+		}
+		err = pair.Revoke(tx, reason)
+		if err == nil {
+			webLog.Printf("Pair %s revoked by %s: %s\n", pair.Key, admin, reason)
+			return "Pair " + pair.Key + " revoked"
+		}
+	case "unrevokePair":
+		err = pair.Unrevoke(tx)
+		if err == nil {
+			webLog.Printf("Pair %s unrevoked by %s\n", pair.Key, admin)
+			return "Pair " + pair.Key + " unrevoked"
+		}
+	case "setPairScopes":
+		scopes := []string{}
+		for _, scope := range types.PairScopes {
+			if r.Form.Get("scope-"+scope) != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+		if len(scopes) == len(types.PairScopes) {
+			// store unrestricted pairs without scopes, so that they can use any scopes added in the future
+			scopes = []string{}
+		}
+		err = pair.SetScopes(tx, scopes)
+		if err == nil {
+			webLog.Printf("Pair %s scopes set by %s: %v\n", pair.Key, admin, scopes)
+			return "Pair " + pair.Key + " scopes updated"
+		}
+	}
+	webLog.Println(err)
+	return "Failed to update pair " + pair.Key + ": " + err.Error()
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/thoas/go-funk"
 
 	"github.com/gorilla/mux"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 )
 
@@ -37,6 +38,7 @@ func LinePage(w http.ResponseWriter, r *http.Request) {
 		Disturbances      []*types.Disturbance
 		CurTrains         []*types.VehicleETA
 		Condition         *types.LineCondition
+		Crowding          *compute.Crowding
 	}{}
 
 	p.Line, err = types.GetLine(tx, mux.Vars(r)["id"])
@@ -143,6 +145,8 @@ func LinePage(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	p.Crowding = crowdingHandler.LineCrowding(p.Line)
+
 	err = webtemplate.ExecuteTemplate(w, "line.html", p)
 	if err != nil {
 		webLog.Println(err)
@@ -0,0 +1,172 @@
+package website
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// ODMatrixPage serves a page with origin-destination statistics obtained from submitted trips
+func ODMatrixPage(w http.ResponseWriter, r *http.Request) {
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	n, err := types.GetNetwork(tx, MLnetworkID)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p := struct {
+		PageCommons
+		DayTypes          []compute.ODDayType
+		HourBands         []compute.ODHourBand
+		DayType           compute.ODDayType
+		HourBand          string
+		Matrix            *compute.ODMatrix
+		TopFlows          []*compute.ODFlow
+		ChordNames        []string
+		ChordMatrix       [][]int
+		MinimumSubmitters int
+	}{
+		DayTypes:          compute.ODDayTypes,
+		HourBands:         compute.ODHourBands,
+		DayType:           compute.ODWeekday,
+		HourBand:          compute.ODHourBands[1].ID,
+		MinimumSubmitters: compute.ODMatrixMinimumSubmitters,
+	}
+
+	if day := r.URL.Query().Get("day"); day != "" {
+		p.DayType = compute.ODDayType(day)
+	}
+	if band := r.URL.Query().Get("band"); band != "" {
+		p.HourBand = band
+	}
+
+	p.PageCommons, err = InitPageCommons(tx, w, r, "Origens e destinos das viagens")
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.Matrix = odMatrixHandler.Matrix(n, p.DayType, p.HourBand)
+	if p.Matrix != nil {
+		p.TopFlows = p.Matrix.Flows
+		if len(p.TopFlows) > 20 {
+			p.TopFlows = p.TopFlows[:20]
+		}
+
+		stations := p.Matrix.Stations()
+		stationIndexes := make(map[string]int)
+		p.ChordMatrix = make([][]int, len(stations))
+		for i, station := range stations {
+			stationIndexes[station.ID] = i
+			p.ChordNames = append(p.ChordNames, station.Name)
+			p.ChordMatrix[i] = make([]int, len(stations))
+		}
+		for _, flow := range p.Matrix.Flows {
+			p.ChordMatrix[stationIndexes[flow.Origin.ID]][stationIndexes[flow.Destination.ID]] = flow.Trips
+		}
+	}
+
+	p.Dependencies.Charts = true
+	err = webtemplate.ExecuteTemplate(w, "odmatrix.html", p)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// ODMatrixExport serves the anonymised origin-destination matrices of all day types and hour bands,
+// in CSV or Parquet format
+func ODMatrixExport(w http.ResponseWriter, r *http.Request) {
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	n, err := types.GetNetwork(tx, MLnetworkID)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	columns := []utils.ParquetColumn{
+		{Name: "network", Type: utils.ParquetString},
+		{Name: "day_type", Type: utils.ParquetString},
+		{Name: "hour_band", Type: utils.ParquetString},
+		{Name: "hour_start", Type: utils.ParquetInt64},
+		{Name: "hour_end", Type: utils.ParquetInt64},
+		{Name: "origin", Type: utils.ParquetString},
+		{Name: "destination", Type: utils.ParquetString},
+		{Name: "trips", Type: utils.ParquetInt64},
+	}
+	rows := [][]interface{}{}
+	for _, matrix := range odMatrixHandler.Matrices(n) {
+		for _, flow := range matrix.Flows {
+			rows = append(rows, []interface{}{
+				n.ID,
+				string(matrix.DayType),
+				matrix.HourBand.ID,
+				matrix.HourBand.Start,
+				matrix.HourBand.End,
+				flow.Origin.ID,
+				flow.Destination.ID,
+				flow.Trips,
+			})
+		}
+	}
+
+	windowStart, windowEnd := odMatrixHandler.Window()
+	filename := "od-" + n.ID + "-" + windowStart.Format("20060102") + "-" + windowEnd.Format("20060102")
+
+	switch r.URL.Query().Get("format") {
+	case "parquet":
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".parquet\"")
+		err = utils.WriteParquet(w, columns, rows)
+		if err != nil {
+			webLog.Println(err)
+		}
+	default:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".csv\"")
+		cw := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		cw.Write(header)
+		for _, row := range rows {
+			record := make([]string, len(row))
+			for i, value := range row {
+				switch v := value.(type) {
+				case string:
+					record[i] = v
+				case int:
+					record[i] = strconv.Itoa(v)
+				}
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			webLog.Println(err)
+		}
+	}
+}
@@ -21,6 +21,8 @@ var Functions = map[string]reflect.Value{
 	"DisturbanceListPage":    reflect.ValueOf(DisturbanceListPage),
 	"DisturbancePage":        reflect.ValueOf(DisturbancePage),
 	"DonatePage":             reflect.ValueOf(DonatePage),
+	"FeedbackPage":           reflect.ValueOf(FeedbackPage),
+	"GeoMapPage":             reflect.ValueOf(GeoMapPage),
 	"HomePage":               reflect.ValueOf(HomePage),
 	"InitPageCommons":        reflect.ValueOf(InitPageCommons),
 	"Initialize":             reflect.ValueOf(Initialize),
@@ -29,16 +31,21 @@ var Functions = map[string]reflect.Value{
 	"LookingGlass":           reflect.ValueOf(LookingGlass),
 	"MapPage":                reflect.ValueOf(MapPage),
 	"MetaStatsPage":          reflect.ValueOf(MetaStatsPage),
+	"ODMatrixExport":         reflect.ValueOf(ODMatrixExport),
+	"ODMatrixPage":           reflect.ValueOf(ODMatrixPage),
 	"PrivacyPolicyPage":      reflect.ValueOf(PrivacyPolicyPage),
 	"RSSFeed":                reflect.ValueOf(RSSFeed),
 	"ReadStationConnections": reflect.ValueOf(ReadStationConnections),
 	"ReadStationTrivia":      reflect.ValueOf(ReadStationTrivia),
 	"ReloadTemplates":        reflect.ValueOf(ReloadTemplates),
 	"ReportPage":             reflect.ValueOf(ReportPage),
+	"SchematicMapSVG":        reflect.ValueOf(SchematicMapSVG),
 	"SessionStore":           reflect.ValueOf(SessionStore),
 	"ShowOfficialDataOnly":   reflect.ValueOf(ShowOfficialDataOnly),
 	"StationPage":            reflect.ValueOf(StationPage),
+	"StatusEventsStream":     reflect.ValueOf(StatusEventsStream),
 	"TermsPage":              reflect.ValueOf(TermsPage),
+	"WiFiAPSuggestionsPage":  reflect.ValueOf(WiFiAPSuggestionsPage),
 }
 
 var Variables = map[string]reflect.Value{}
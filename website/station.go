@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/underlx/disturbancesmlx/compute"
 	"github.com/underlx/disturbancesmlx/types"
 	"github.com/underlx/disturbancesmlx/utils"
 )
@@ -34,6 +36,9 @@ func StationPage(w http.ResponseWriter, r *http.Request) {
 		Trivia         string
 		Connections    []ConnectionData
 		POIs           []*types.POI
+		Accessibility  *compute.StationAccessibility
+		Platforms      []*types.Platform
+		Crowding       *compute.Crowding
 		Closed         bool
 		PrevNext       []struct {
 			Prev *types.Station
@@ -127,6 +132,27 @@ func StationPage(w http.ResponseWriter, r *http.Request) {
 		p.LobbyExits = append(p.LobbyExits, exits)
 	}
 
+	p.Accessibility, err = compute.ComputeStationAccessibility(tx, p.Station, time.Now())
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.Platforms, err = p.Station.Platforms(tx)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.Crowding, err = crowdingHandler.StationCrowding(tx, p.Station)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	p.Trivia, err = ReadStationTrivia(p.Station.ID, "pt")
 	if err != nil {
 		webLog.Println(err)
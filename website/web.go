@@ -38,6 +38,10 @@ var vehicleHandler *compute.VehicleHandler
 var vehicleETAHandler *compute.VehicleETAHandler
 var reportHandler *compute.ReportHandler
 var statsHandler *compute.StatsHandler
+var crowdingHandler *compute.CrowdingHandler
+var odMatrixHandler *compute.ODMatrixHandler
+var retentionHandler *compute.RetentionHandler
+var statusEventBroker *compute.StatusEventBroker
 var parentAnkiddie *ankiddie.Ankiddie
 var csrfMiddleware mux.MiddlewareFunc
 
@@ -80,6 +84,8 @@ func ConfigureRouter(router *mux.Router) {
 	router.HandleFunc("/report", ReportPage)
 	router.HandleFunc("/lookingglass", LookingGlass)
 	router.HandleFunc("/internal", InternalPage)
+	router.HandleFunc("/internal/feedback", FeedbackPage)
+	router.HandleFunc("/internal/wifiaps", WiFiAPSuggestionsPage)
 	router.HandleFunc("/d/{id:[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-4[0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}}", DisturbancePage)
 	router.HandleFunc("/disturbances/{id:[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-4[0-9A-Fa-f]{3}-[89ABab][0-9A-Fa-f]{3}-[0-9A-Fa-f]{12}}", DisturbancePage)
 	router.HandleFunc("/d/{year:[0-9]{4}}/{month:[0-9]{2}}", DisturbanceListPage)
@@ -91,7 +97,11 @@ func ConfigureRouter(router *mux.Router) {
 	router.HandleFunc("/l/{id:[-0-9A-Za-z]{1,36}}", LinePage)
 	router.HandleFunc("/lines/{id:[-0-9A-Za-z]{1,36}}", LinePage)
 	router.HandleFunc("/meta/stats", MetaStatsPage)
+	router.HandleFunc("/meta/od", ODMatrixPage)
+	router.HandleFunc("/meta/od/export", ODMatrixExport)
 	router.HandleFunc("/map", MapPage)
+	router.HandleFunc("/map/geo", GeoMapPage)
+	router.HandleFunc("/map/schematic.svg", SchematicMapSVG)
 	router.HandleFunc("/about", AboutPage)
 	router.HandleFunc("/donate", DonatePage)
 	router.HandleFunc("/privacy", PrivacyPolicyPage)
@@ -99,6 +109,7 @@ func ConfigureRouter(router *mux.Router) {
 	router.HandleFunc("/terms", TermsPage)
 	router.HandleFunc("/terms/{lang:[a-z]{2}}", TermsPage)
 	router.HandleFunc("/feed", RSSFeed)
+	router.HandleFunc("/events", StatusEventsStream)
 	router.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("static/"))))
 
 	router.HandleFunc("/auth", AuthHandler)
@@ -123,14 +134,18 @@ func ConfigureRouter(router *mux.Router) {
 // Initialize initializes the package
 func Initialize(snode sqalx.Node, webKeybox *keybox.Keybox, log *log.Logger,
 	rh *compute.ReportHandler, vh *compute.VehicleHandler,
-	veh *compute.VehicleETAHandler, sh *compute.StatsHandler,
-	a *ankiddie.Ankiddie) {
+	veh *compute.VehicleETAHandler, sh *compute.StatsHandler, ch *compute.CrowdingHandler,
+	odh *compute.ODMatrixHandler, reth *compute.RetentionHandler, eb *compute.StatusEventBroker, a *ankiddie.Ankiddie) {
 	webLog = log
 	rootSqalxNode = snode
 	reportHandler = rh
 	vehicleHandler = vh
 	vehicleETAHandler = veh
 	statsHandler = sh
+	crowdingHandler = ch
+	odMatrixHandler = odh
+	retentionHandler = reth
+	statusEventBroker = eb
 	parentAnkiddie = a
 
 	authKey, present := webKeybox.Get("cookieAuthKey")
@@ -249,17 +264,22 @@ func InitPageCommons(node sqalx.Node, w http.ResponseWriter, r *http.Request, ti
 
 	for i := range lines {
 		commons.Lines[i].Line = lines[i]
-		d, err := lines[i].LastOngoingDisturbance(tx, false)
-		commons.Lines[i].Down = err == nil
-		commons.Lines[i].Official = err == nil && d.Official
-		if err == nil {
-			commons.Lines[i].Minutes = int(time.Since(d.UStartTime).Minutes())
-		}
+		commons.Lines[i].Down, commons.Lines[i].Official, commons.Lines[i].Minutes = lineStatus(tx, lines[i])
 	}
 
 	return commons, nil
 }
 
+// lineStatus returns whether a line has an ongoing disturbance, whether it is official
+// and for how many minutes it has been ongoing
+func lineStatus(node sqalx.Node, line *types.Line) (down bool, official bool, minutes int) {
+	d, err := line.LastOngoingDisturbance(node, false)
+	if err != nil {
+		return false, false, 0
+	}
+	return true, d.Official, int(time.Since(d.UStartTime).Minutes())
+}
+
 // LookingGlass serves the looking glass page
 func LookingGlass(w http.ResponseWriter, r *http.Request) {
 	tx, err := rootSqalxNode.Beginx()
@@ -353,6 +373,100 @@ func MapPage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GeoMapPage serves the geographic network map page, where lines are coloured according to their status
+func GeoMapPage(w http.ResponseWriter, r *http.Request) {
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	type lineData struct {
+		ID    string
+		Name  string
+		Color string
+		Down  bool
+	}
+	p := struct {
+		PageCommons
+		NetworkID string
+		Lines     []lineData
+	}{
+		NetworkID: MLnetworkID,
+	}
+
+	p.PageCommons, err = InitPageCommons(tx, w, r, "Mapa geográfico da rede")
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	network, err := types.GetNetwork(tx, MLnetworkID)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	lines, err := network.Lines(tx)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	for _, line := range lines {
+		ld := lineData{
+			ID:    line.ID,
+			Name:  line.Name,
+			Color: line.Color,
+		}
+		ld.Down, _, _ = lineStatus(tx, line)
+		p.Lines = append(p.Lines, ld)
+	}
+
+	p.Dependencies.Leaflet = true
+	err = webtemplate.ExecuteTemplate(w, "geomap.html", p)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// SchematicMapSVG serves a schematic map of the network showing disturbed lines, closed stations and train positions
+func SchematicMapSVG(w http.ResponseWriter, r *http.Request) {
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	network, err := types.GetNetwork(tx, MLnetworkID)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var trains map[string]*types.VehicleETA
+	if vehicleETAHandler != nil {
+		trains = vehicleETAHandler.TrainPositions()
+	}
+	svg, err := compute.RenderSchematicMapSVG(tx, network, trains)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write(svg)
+}
+
 // RSSFeed serves the RSS feed
 func RSSFeed(w http.ResponseWriter, r *http.Request) {
 	tx, err := rootSqalxNode.Beginx()
@@ -428,6 +542,10 @@ func ReloadTemplates() {
 			loc, _ := time.LoadLocation("Europe/Lisbon")
 			return t.In(loc).Format("02 Jan 2006 15:04")
 		},
+		"formatTimeOfDay": func(t time.Time) string {
+			loc, _ := time.LoadLocation("Europe/Lisbon")
+			return t.In(loc).Format("15:04")
+		},
 		"formatTrainFrequency": func(dd types.Duration) string {
 			d := time.Duration(dd)
 			d = d.Round(time.Second)
@@ -436,6 +554,20 @@ func ReloadTemplates() {
 			s := d / time.Second
 			return fmt.Sprintf("%02d:%02d", m, s)
 		},
+		"crowdingLevelString": func(level compute.CrowdingLevel) string {
+			switch level {
+			case compute.CrowdingLow:
+				return "baixa"
+			case compute.CrowdingMedium:
+				return "moderada"
+			case compute.CrowdingHigh:
+				return "elevada"
+			case compute.CrowdingVeryHigh:
+				return "muito elevada"
+			default:
+				return "desconhecida"
+			}
+		},
 		"formatPortugueseMonth":        utils.FormatPortugueseMonth,
 		"formatPortugueseDurationLong": utils.FormatPortugueseDurationLong,
 		"disturbanceReasonString":      utils.DisturbanceReasonString,
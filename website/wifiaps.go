@@ -0,0 +1,150 @@
+package website
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/underlx/disturbancesmlx/compute"
+	"github.com/underlx/disturbancesmlx/discordbot"
+	"github.com/underlx/disturbancesmlx/types"
+	"github.com/underlx/disturbancesmlx/utils"
+)
+
+// WiFiAPSuggestionsPage serves the internal page for reviewing the WiFi AP changes suggested by S2LS feedback
+// and crowd-sourced observations
+func WiFiAPSuggestionsPage(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequestIsTLS(r) && !DEBUG {
+		w.WriteHeader(http.StatusUpgradeRequired)
+		return
+	}
+
+	hasSession, session, err := AuthGetSession(w, r, true)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if !hasSession {
+		return
+	} else if !session.IsAdmin {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	tx, err := rootSqalxNode.Beginx()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		webLog.Println(err)
+		return
+	}
+	defer tx.Commit()
+
+	n, err := types.GetNetwork(tx, MLnetworkID)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	message := ""
+	if r.Method == http.MethodPost && r.ParseForm() == nil {
+		switch r.Form.Get("action") {
+		case "aggregate":
+			minSubmitters, err := strconv.Atoi(r.Form.Get("minSubmitters"))
+			if err != nil || minSubmitters < 1 {
+				minSubmitters = compute.DefaultWiFiAPSuggestionMinSubmitters
+			}
+			err = compute.UpdateWiFiAPSuggestions(tx, minSubmitters)
+			if err == nil {
+				err = compute.UpdateWiFiAPSuggestionsFromObservations(tx, compute.WiFiAPObservationWindow, minSubmitters)
+			}
+			if err != nil {
+				webLog.Println(err)
+				message = "Failed to aggregate feedback: " + err.Error()
+			} else {
+				message = "Suggestions updated"
+			}
+		case "setStatus":
+			suggestion, err := types.GetWiFiAPSuggestion(tx, r.Form.Get("suggestion"))
+			if err != nil {
+				message = "Suggestion " + r.Form.Get("suggestion") + " not found"
+				break
+			}
+			switch types.WiFiAPSuggestionStatus(r.Form.Get("status")) {
+			case types.WiFiAPSuggestionPending:
+				suggestion.Status = types.WiFiAPSuggestionPending
+			case types.WiFiAPSuggestionApproved:
+				suggestion.Status = types.WiFiAPSuggestionApproved
+			case types.WiFiAPSuggestionRejected:
+				suggestion.Status = types.WiFiAPSuggestionRejected
+			}
+			suggestion.Updated = time.Now()
+			err = suggestion.Update(tx)
+			if err != nil {
+				webLog.Println(err)
+				message = "Failed to update suggestion " + suggestion.ID + ": " + err.Error()
+			} else {
+				message = "Suggestion " + suggestion.ID + " updated"
+			}
+		case "publish":
+			resolved, err := compute.PublishWiFiAPSuggestions(tx, n, session.DisplayName)
+			if err != nil {
+				webLog.Println(err)
+				message = "Failed to publish dataset: " + err.Error()
+			} else {
+				message = "Dataset published, " + strconv.Itoa(len(resolved)) + " feedback entries resolved"
+				for _, feedback := range resolved {
+					discordbot.TheFeedbackTriage.FeedbackUpdated(feedback)
+				}
+			}
+		}
+	}
+
+	p := struct {
+		PageCommons
+		Message              string
+		DefaultMinSubmitters int
+		Dataset              *types.Dataset
+		Statuses             []types.WiFiAPSuggestionStatus
+		Sections             []struct {
+			Status      types.WiFiAPSuggestionStatus
+			Suggestions []*types.WiFiAPSuggestion
+		}
+	}{
+		Message:              message,
+		DefaultMinSubmitters: compute.DefaultWiFiAPSuggestionMinSubmitters,
+		Statuses:             []types.WiFiAPSuggestionStatus{types.WiFiAPSuggestionApproved, types.WiFiAPSuggestionPending, types.WiFiAPSuggestionRejected},
+	}
+
+	p.PageCommons, err = InitPageCommons(tx, w, r, "Sugestões de pontos de acesso WiFi")
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	p.Dataset, err = types.GetDataset(tx, n.ID)
+	if err != nil {
+		// the network may not have a dataset published yet
+		p.Dataset = nil
+	}
+
+	for _, status := range p.Statuses {
+		suggestions, err := types.GetWiFiAPSuggestionsWithStatus(tx, status)
+		if err != nil {
+			webLog.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		p.Sections = append(p.Sections, struct {
+			Status      types.WiFiAPSuggestionStatus
+			Suggestions []*types.WiFiAPSuggestion
+		}{status, suggestions})
+	}
+
+	err = webtemplate.ExecuteTemplate(w, "internalwifiaps.html", p)
+	if err != nil {
+		webLog.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}